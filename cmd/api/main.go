@@ -16,14 +16,30 @@ import (
 	"github.com/kharisma-wardhana/final-project-spe-academy/entity"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/http/auth"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/http/handler"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/http/middleware"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mongodb"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis"
 	usecase_account "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/account"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	usecase_blocklist "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist"
+	usecase_dispute "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/dispute"
+	usecase_export "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/export"
+	usecase_fraud "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud"
+	usecase_importer "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/importer"
+	usecase_ledger "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/ledger"
+	usecase_limit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/limit"
 	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
 	usecase_merchant "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/merchant"
+	usecase_onboarding "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/onboarding"
+	usecase_outlet "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/outlet"
+	usecase_participant "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/participant"
+	usecase_payout "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/payout"
 	usecase_qr "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/qr"
+	usecase_reconciliation "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reconciliation"
+	usecase_reference "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference"
 	usecase_transaction "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/transaction"
 
 	"github.com/gin-contrib/cors"
@@ -91,20 +107,58 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// MongoDB keeps the audit trail the audit.insert worker writes
+	mongoDB, err := config.NewMongodb(context.Background(), &cfg.MongodbOption)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mongoDB.Client().Disconnect(context.Background())
+
 	// AUTH : Write authetincation mechanism method (JWT, Basic Auth, etc.)
 
 	// REPOSITORY : Write repository code here (database, cache, etc.)
 	accountRepo := mysql.NewAccountRepository(mysqlDB)
 	merchantRepo := mysql.NewMerchantRepository(mysqlDB)
 	transactionRepo := mysql.NewTransactionRepository(mysqlDB)
+	exportJobRepo := mysql.NewExportJobRepository(mysqlDB)
+	ledgerRepo := mysql.NewLedgerRepository(mysqlDB)
+	payoutRepo := mysql.NewPayoutRepository(mysqlDB)
+	reconciliationRepo := mysql.NewReconciliationRepository(mysqlDB)
+	disputeRepo := mysql.NewDisputeRepository(mysqlDB)
+	participantRepo := mysql.NewParticipantRepository(mysqlDB)
+	transactionLimitRepo := mysql.NewTransactionLimitRepository(mysqlDB)
+	fraudRepo := mysql.NewFraudRepository(mysqlDB)
+	blocklistRepo := mysql.NewBlocklistRepository(mysqlDB)
+	outletRepo := mysql.NewOutletRepository(mysqlDB)
+	onboardingRepo := mysql.NewMerchantOnboardingRepository(mysqlDB)
+	importRepo := mysql.NewMerchantImportRepository(mysqlDB)
+	referenceRepo := mysql.NewReferenceRepository(mysqlDB)
 	qrRepo := redis.NewQRRepository(redisDB)
+	qrEventRepo := redis.NewQREventRepository(redisDB)
+	limitCounterRepo := redis.NewLimitCounterRepository(redisDB)
+	blocklistCacheRepo := redis.NewBlocklistRepository(redisDB)
+	auditRepo := mongodb.NewAuditRepository(mongoDB)
 
 	// USECASE : Write bussines logic code here (validation, business logic, etc.)
 	logUseCase := usecase_log.NewLogUseCase(queue, logger)
-	accountUseCase := usecase_account.NewAccountUseCase(logUseCase, accountRepo)
-	merchantUseCase := usecase_merchant.NewMerchantUseCase(logUseCase, merchantRepo)
-	transactionUseCase := usecase_transaction.NewTransactionUseCase(logUseCase, transactionRepo, qrRepo)
-	qrUseCase := usecase_qr.NewQRUseCase(logUseCase, qrRepo, merchantRepo)
+	auditUseCase := usecase_audit.NewAuditUseCase(logUseCase, queue, auditRepo)
+	accountUseCase := usecase_account.NewAccountUseCase(logUseCase, accountRepo, merchantRepo, auditUseCase)
+	referenceUseCase := usecase_reference.NewReferenceUseCase(logUseCase, referenceRepo)
+	merchantUseCase := usecase_merchant.NewMerchantUseCase(logUseCase, merchantRepo, referenceUseCase, accountRepo, auditUseCase)
+	ledgerUseCase := usecase_ledger.NewLedgerUseCase(logUseCase, ledgerRepo, merchantRepo)
+	limitUseCase := usecase_limit.NewLimitUseCase(logUseCase, transactionLimitRepo, limitCounterRepo, merchantRepo, auditUseCase)
+	fraudUseCase := usecase_fraud.NewFraudUseCase(logUseCase, fraudRepo, merchantRepo, transactionRepo, auditUseCase, &cfg.FraudOption)
+	blocklistUseCase := usecase_blocklist.NewBlocklistUseCase(logUseCase, blocklistRepo, blocklistCacheRepo, merchantRepo, &cfg.BlocklistOption)
+	transactionUseCase := usecase_transaction.NewTransactionUseCase(logUseCase, queue, transactionRepo, qrRepo, qrEventRepo, ledgerUseCase, participantRepo, limitUseCase, fraudUseCase, blocklistUseCase, merchantRepo, outletRepo)
+	qrUseCase := usecase_qr.NewQRUseCase(logUseCase, qrRepo, qrEventRepo, merchantRepo, transactionRepo, limitUseCase, blocklistUseCase, outletRepo)
+	exportUseCase := usecase_export.NewExportUseCase(logUseCase, queue, exportJobRepo, transactionRepo, merchantRepo, &cfg.ExportOption)
+	payoutUseCase := usecase_payout.NewPayoutUseCase(logUseCase, payoutRepo, ledgerRepo, merchantRepo, ledgerUseCase, &cfg.PayoutOption)
+	reconciliationUseCase := usecase_reconciliation.NewReconciliationUseCase(logUseCase, reconciliationRepo, transactionRepo)
+	participantUseCase := usecase_participant.NewParticipantUseCase(logUseCase, participantRepo, transactionRepo, auditUseCase)
+	disputeUseCase := usecase_dispute.NewDisputeUseCase(logUseCase, disputeRepo, transactionRepo, merchantRepo, ledgerUseCase, &cfg.DisputeOption)
+	outletUseCase := usecase_outlet.NewOutletUseCase(logUseCase, outletRepo, merchantRepo)
+	onboardingUseCase := usecase_onboarding.NewOnboardingUseCase(logUseCase, onboardingRepo, merchantRepo, auditUseCase, &cfg.OnboardingOption)
+	importUseCase := usecase_importer.NewImportUseCase(logUseCase, queue, importRepo, merchantRepo, referenceUseCase, auditUseCase, &cfg.MerchantImportOption)
 
 	api := app.Group("/api/v1")
 
@@ -112,13 +166,43 @@ func main() {
 	app.Get("/metrics", monitor.New())
 
 	// HANDLER : Write handler code here (HTTP, gRPC, etc.)
-	handler.NewMerchantHandler(parser, presenterJson, merchantUseCase, transactionUseCase, qrUseCase).Register(api)
-	handler.NewAccountHandler(parser, presenterJson, accountUseCase).Register(api)
+	merchantHandler := handler.NewMerchantHandler(parser, presenterJson, merchantUseCase, transactionUseCase, qrUseCase)
+	merchantHandler.Register(api)
+	accountHandler := handler.NewAccountHandler(parser, presenterJson, accountUseCase)
+	accountHandler.Register(api)
+	// Public like the merchant routes, the onboarding form fills its dropdowns from them
+	handler.NewReferenceHandler(parser, presenterJson, referenceUseCase).Register(api)
+	// Download links are signed per export, see ExportHandler.DownloadExport
+	exportHandler := handler.NewExportHandler(parser, presenterJson, exportUseCase)
+	exportHandler.Register(api)
+	// Registered before the signature check, browsers' EventSource cannot send custom headers
+	qrHandler := handler.NewQRHandler(parser, presenterJson, qrUseCase)
+	qrHandler.Register(api)
+	// The switch signs its notifications with its participant credential instead of a merchant account
+	notificationSignature := auth.NewNotificationSignature(participantRepo, &cfg.SwitchOption)
+	handler.NewNotificationHandler(parser, presenterJson, transactionUseCase).
+		Register(api.Group("/notifications", notificationSignature.VerifySignature))
 
 	signature := auth.NewSignature(parser, accountRepo, merchantRepo)
 	app.Use(signature.VerifySignature)
 
+	merchantHandler.RegisterSigned(api)
+	accountHandler.RegisterSigned(api)
+	exportHandler.RegisterSigned(api)
+	qrHandler.RegisterSigned(api)
 	handler.NewTransactionHandler(parser, presenterJson, transactionUseCase).Register(api)
+	handler.NewLedgerHandler(parser, presenterJson, ledgerUseCase).Register(api)
+	handler.NewPayoutHandler(parser, presenterJson, payoutUseCase).Register(api)
+	handler.NewReconciliationHandler(parser, presenterJson, reconciliationUseCase).Register(api)
+	handler.NewDisputeHandler(parser, presenterJson, disputeUseCase).Register(api)
+	handler.NewParticipantHandler(parser, presenterJson, participantUseCase).Register(api)
+	handler.NewTransactionLimitHandler(parser, presenterJson, limitUseCase).Register(api)
+	handler.NewFraudHandler(parser, presenterJson, fraudUseCase).Register(api)
+	handler.NewBlocklistHandler(parser, presenterJson, blocklistUseCase).Register(api)
+	handler.NewMerchantOnboardingHandler(parser, presenterJson, onboardingUseCase).Register(api)
+	handler.NewOutletHandler(parser, presenterJson, outletUseCase).Register(api)
+	handler.NewMerchantImportHandler(parser, presenterJson, importUseCase).Register(api)
+	handler.NewAuditHandler(parser, presenterJson, auditUseCase).Register(api)
 
 	// Handle Route not found
 	app.Use(routeNotFound)
@@ -133,7 +217,8 @@ func setupMiddleware(app *fiber.App, cfg *config.Config) {
 			cors.New(cors.Config{
 				AllowCredentials: true,
 				AllowOrigins:     cfg.AllowedCredentialOrigins,
-				AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+				AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match"},
+				ExposeHeaders:    []string{"ETag"},
 				AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH"},
 			}),
 		)
@@ -153,6 +238,7 @@ func setupMiddleware(app *fiber.App, cfg *config.Config) {
 			},
 			EnableStackTrace: true,
 		}),
+		middleware.AuditActor,
 	)
 }
 
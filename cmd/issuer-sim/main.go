@@ -0,0 +1,203 @@
+// Command issuer-sim plays the customer wallet in end to end tests. It decodes a QRIS
+// payload produced by the QR API and reports the payment through the signed switch
+// notification endpoint.
+//
+//	go run ./cmd/issuer-sim -qr "<payload>" -outcome success
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/transaction/entity"
+
+	"github.com/subosito/gotenv"
+)
+
+const (
+	outcomeSuccess   = "success"
+	outcomeFailure   = "failure"
+	outcomeTimeout   = "timeout"
+	outcomeDuplicate = "duplicate"
+)
+
+type options struct {
+	api           string
+	qr            string
+	outcome       string
+	clientID      string
+	secret        string
+	issuer        string
+	acquirer      string
+	mpan          string
+	paymentMethod string
+}
+
+func init() {
+	_ = gotenv.Load()
+}
+
+func main() {
+	var opt options
+	flag.StringVar(&opt.api, "api", "http://localhost:7011", "base URL of the merchant API")
+	flag.StringVar(&opt.qr, "qr", "", "QRIS payload, read from stdin when empty")
+	flag.StringVar(&opt.outcome, "outcome", outcomeSuccess, "success, failure, timeout or duplicate")
+	flag.StringVar(&opt.clientID, "client-id", os.Getenv("ISSUER_SIM_CLIENT_ID"), "client ID of a participant with the switch role")
+	flag.StringVar(&opt.secret, "secret", os.Getenv("ISSUER_SIM_CLIENT_SECRET"), "client secret of the switch participant")
+	flag.StringVar(&opt.issuer, "issuer", "SIMBANK", "participant code of the issuer")
+	flag.StringVar(&opt.acquirer, "acquirer", "SIMBANK", "participant code of the acquirer")
+	flag.StringVar(&opt.mpan, "mpan", "9360000000000000001", "customer PAN sent to the API")
+	flag.StringVar(&opt.paymentMethod, "payment-method", "ewallet", "payment method of the customer")
+	flag.Parse()
+
+	if err := run(opt); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(opt options) error {
+	if opt.clientID == "" || opt.secret == "" {
+		return errors.New("client ID and secret are required")
+	}
+
+	payload, err := readPayload(opt.qr)
+	if err != nil {
+		return err
+	}
+
+	req, err := buildNotification(payload, opt)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("billing %s, amount %.2f %s\n", req.BillingID, req.Amount, req.Currency)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	switch opt.outcome {
+	case outcomeSuccess, outcomeFailure:
+		return send(opt, body)
+	case outcomeTimeout:
+		fmt.Println("no notification sent, the scheduler expires the pending transaction")
+		return nil
+	case outcomeDuplicate:
+		// Both deliveries carry the same signature, like a switch retrying on a lost response
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = post(opt, timestamp, body)
+			}(i)
+		}
+		wg.Wait()
+		return errors.Join(errs...)
+	default:
+		return fmt.Errorf("unknown outcome %q", opt.outcome)
+	}
+}
+
+func readPayload(qr string) (string, error) {
+	if qr != "" {
+		return strings.TrimSpace(qr), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func buildNotification(payload string, opt options) (*entity.PaymentNotificationRequest, error) {
+	tags, err := helper.ParseQRIS(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	additional, err := helper.ParseTLV(tags["62"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid additional data: %w", err)
+	}
+	billingID := additional["05"]
+	if billingID == "" {
+		return nil, errors.New("payload has no billing ID")
+	}
+
+	amount, err := strconv.ParseFloat(tags["54"], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+
+	status := entity.NotificationStatusSuccess
+	if opt.outcome == outcomeFailure {
+		status = entity.NotificationStatusFailed
+	}
+
+	return &entity.PaymentNotificationRequest{
+		Status:        status,
+		RefID:         fmt.Sprintf("SIM%d", time.Now().UnixNano()),
+		BillingID:     billingID,
+		Amount:        amount,
+		Currency:      tags["53"],
+		PaymentMethod: opt.paymentMethod,
+		Issuer:        opt.issuer,
+		Acquirer:      opt.acquirer,
+		CustomerMPAN:  opt.mpan,
+	}, nil
+}
+
+func send(opt options, body []byte) error {
+	return post(opt, strconv.FormatInt(time.Now().Unix(), 10), body)
+}
+
+// post signs the body the way the notification endpoint expects:
+// hex HMAC-SHA256 of "<timestamp>:<body>" keyed with the client secret
+func post(opt options, timestamp string, body []byte) error {
+	mac := hmac.New(sha256.New, []byte(opt.secret))
+	mac.Write([]byte(timestamp + ":"))
+	mac.Write(body)
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(opt.api, "/")+"/api/v1/notifications/payment", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Client-ID", opt.clientID)
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", resp.Status, respBody)
+	return nil
+}
@@ -1,17 +1,43 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
-	"github.com/kharisma-wardhana/final-project-spe-academy/entity"
-	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mongodb"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+	usecase_blocklist "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist"
+	usecase_dispute "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/dispute"
+	usecase_fraud "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud"
+	usecase_ledger "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/ledger"
+	usecase_limit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/limit"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	usecase_merchant "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/merchant"
+	usecase_payout "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/payout"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/payout/entity"
+	usecase_reference "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference"
+	usecase_transaction "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/transaction"
 
 	"github.com/go-co-op/gocron/v2"
 	"github.com/subosito/gotenv"
 )
 
+// Every scheduler instance triggers the jobs, the Redis lock of a job is shared by all
+// of them so only one runs it
+const (
+	settlementLock    = "scheduler:settlement"
+	payoutBatchLock   = "scheduler:payout-batch"
+	disputeExpiryLock = "scheduler:dispute-expiry"
+	pendingExpiryLock = "scheduler:pending-expiry"
+	merchantPurgeLock = "scheduler:merchant-purge"
+)
+
 func init() {
 	_ = gotenv.Load()
 }
@@ -28,30 +54,176 @@ func main() {
 
 	fmt.Println("Starting scheduler...")
 
-	// cfg := config.NewConfig()
-	// queue, err := config.NewRabbitMQInstance(context.Background(), &cfg.RabbitMQOption)
-	// if err != nil {
-	// 	log.Fatal(err)
-	// }
+	cfg := config.NewConfig()
+	queue, err := config.NewRabbitMQInstance(context.Background(), &cfg.RabbitMQOption)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, err := config.NewZapLog(cfg.AppEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	redisDB := config.NewRedis(&cfg.RedisOption)
+
+	gormLogger := config.NewGormLogMysqlConfig(&cfg.MysqlOption)
+	mysqlDB, err := config.NewMysql(cfg.AppEnv, &cfg.MysqlOption, gormLogger)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// add a job to the scheduler
+	mongoDB, err := config.NewMongodb(context.Background(), &cfg.MongodbOption)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mongoDB.Client().Disconnect(context.Background())
+
+	// REPOSITORY
+	merchantRepo := mysql.NewMerchantRepository(mysqlDB)
+	transactionRepo := mysql.NewTransactionRepository(mysqlDB)
+	ledgerRepo := mysql.NewLedgerRepository(mysqlDB)
+	payoutRepo := mysql.NewPayoutRepository(mysqlDB)
+	disputeRepo := mysql.NewDisputeRepository(mysqlDB)
+	participantRepo := mysql.NewParticipantRepository(mysqlDB)
+	transactionLimitRepo := mysql.NewTransactionLimitRepository(mysqlDB)
+	fraudRepo := mysql.NewFraudRepository(mysqlDB)
+	blocklistRepo := mysql.NewBlocklistRepository(mysqlDB)
+	outletRepo := mysql.NewOutletRepository(mysqlDB)
+	accountRepo := mysql.NewAccountRepository(mysqlDB)
+	referenceRepo := mysql.NewReferenceRepository(mysqlDB)
+	auditRepo := mongodb.NewAuditRepository(mongoDB)
+	qrRepo := redis.NewQRRepository(redisDB)
+	qrEventRepo := redis.NewQREventRepository(redisDB)
+	limitCounterRepo := redis.NewLimitCounterRepository(redisDB)
+	blocklistCacheRepo := redis.NewBlocklistRepository(redisDB)
+	lockRepo := redis.NewLockRepository(redisDB)
+
+	// USECASE
+	logUseCase := usecase_log.NewLogUseCase(queue, logger)
+	auditUseCase := usecase_audit.NewAuditUseCase(logUseCase, queue, auditRepo)
+	ledgerUseCase := usecase_ledger.NewLedgerUseCase(logUseCase, ledgerRepo, merchantRepo)
+	limitUseCase := usecase_limit.NewLimitUseCase(logUseCase, transactionLimitRepo, limitCounterRepo, merchantRepo, auditUseCase)
+	fraudUseCase := usecase_fraud.NewFraudUseCase(logUseCase, fraudRepo, merchantRepo, transactionRepo, auditUseCase, &cfg.FraudOption)
+	blocklistUseCase := usecase_blocklist.NewBlocklistUseCase(logUseCase, blocklistRepo, blocklistCacheRepo, merchantRepo, &cfg.BlocklistOption)
+	transactionUseCase := usecase_transaction.NewTransactionUseCase(logUseCase, queue, transactionRepo, qrRepo, qrEventRepo, ledgerUseCase, participantRepo, limitUseCase, fraudUseCase, blocklistUseCase, merchantRepo, outletRepo)
+	payoutUseCase := usecase_payout.NewPayoutUseCase(logUseCase, payoutRepo, ledgerRepo, merchantRepo, ledgerUseCase, &cfg.PayoutOption)
+	disputeUseCase := usecase_dispute.NewDisputeUseCase(logUseCase, disputeRepo, transactionRepo, merchantRepo, ledgerUseCase, &cfg.DisputeOption)
+	referenceUseCase := usecase_reference.NewReferenceUseCase(logUseCase, referenceRepo)
+	merchantUseCase := usecase_merchant.NewMerchantUseCase(logUseCase, merchantRepo, referenceUseCase, accountRepo, auditUseCase)
+
+	// Settle completed transactions older than the settlement delay. A run that
+	// overlaps the previous one is skipped, the next run picks up what is left.
 	_, err = s.NewJob(
-		gocron.DurationJob(
-			4*time.Second,
+		gocron.CronJob(cfg.SettlementOption.Cron, false),
+		gocron.NewTask(
+			withLock(lockRepo, settlementLock, cfg.SettlementOption.LockTTLMinutes, func(ctx context.Context) {
+				today := time.Now().In(location)
+				cutoff := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, location).
+					AddDate(0, 0, 1-cfg.SettlementOption.DelayDays)
+
+				settled, err := transactionUseCase.SettleTransactions(ctx, cutoff, cfg.SettlementOption.BatchSize)
+				if err != nil {
+					log.Printf("[Scheduler] settlement stopped after %d transactions: %s", settled, err.Error())
+					return
+				}
+				log.Printf("[Scheduler] settled %d transactions made before %s", settled, cutoff.Format(time.RFC3339))
+			}),
 		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Build the day's payout batch from the available balances. The file still has to
+	// be handed to the bank and the batch marked as sent through the API.
+	_, err = s.NewJob(
+		gocron.CronJob(cfg.PayoutOption.Cron, false),
 		gocron.NewTask(
-			func(a string, b int) {
-				// do things
-				fmt.Println("uwu")
-
-				helper.LogInfo("Process", "func_name", entity.CaptureFields{}, "message")
-			},
-			"hello",
-			1,
+			withLock(lockRepo, payoutBatchLock, cfg.PayoutOption.LockTTLMinutes, func(ctx context.Context) {
+				batch, err := payoutUseCase.CreateBatch(ctx, &entity.PayoutBatchRequest{})
+				if err != nil {
+					log.Printf("[Scheduler] payout batch failed: %s", err.Error())
+					return
+				}
+				log.Printf("[Scheduler] payout batch %s holds %d payouts", batch.BatchDate, batch.PayoutCount)
+			}),
 		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
 	)
 	if err != nil {
-		// handle error
+		log.Fatal(err)
+	}
+
+	// Close as lost the disputes the merchant did not answer in time
+	_, err = s.NewJob(
+		gocron.CronJob(cfg.DisputeOption.Cron, false),
+		gocron.NewTask(
+			withLock(lockRepo, disputeExpiryLock, cfg.DisputeOption.LockTTLMinutes, func(ctx context.Context) {
+				expired, err := disputeUseCase.ExpireDisputes(ctx, time.Now(), cfg.DisputeOption.BatchSize)
+				if err != nil {
+					log.Printf("[Scheduler] dispute expiry stopped after %d disputes: %s", expired, err.Error())
+					return
+				}
+				log.Printf("[Scheduler] closed %d overdue disputes as lost", expired)
+			}),
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Fail pending transactions the issuer never confirmed
+	expiryTimeouts, err := cfg.PendingExpiryOption.Timeouts()
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = s.NewJob(
+		gocron.CronJob(cfg.PendingExpiryOption.Cron, false),
+		gocron.NewTask(
+			withLock(lockRepo, pendingExpiryLock, cfg.PendingExpiryOption.LockTTLMinutes, func(ctx context.Context) {
+				expired, err := transactionUseCase.ExpirePendingTransactions(
+					ctx,
+					expiryTimeouts,
+					time.Duration(cfg.PendingExpiryOption.DefaultTimeoutMinutes)*time.Minute,
+					cfg.PendingExpiryOption.BatchSize,
+				)
+				if err != nil {
+					log.Printf("[Scheduler] pending expiry stopped after %d transactions: %s", expired, err.Error())
+					return
+				}
+				log.Printf("[Scheduler] expired %d pending transactions", expired)
+			}),
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Remove for good the merchants soft deleted longer than the retention period,
+	// merchants with financial history stay soft deleted
+	_, err = s.NewJob(
+		gocron.CronJob(cfg.MerchantPurgeOption.Cron, false),
+		gocron.NewTask(
+			withLock(lockRepo, merchantPurgeLock, cfg.MerchantPurgeOption.LockTTLMinutes, func(ctx context.Context) {
+				deletedBefore := time.Now().AddDate(0, 0, -cfg.MerchantPurgeOption.RetentionDays)
+
+				ctx = usecase_audit.WithActor(ctx, auditEntity.Actor{ID: "scheduler:merchant-purge"})
+				purged, err := merchantUseCase.PurgeMerchants(ctx, deletedBefore, cfg.MerchantPurgeOption.BatchSize)
+				if err != nil {
+					log.Printf("[Scheduler] merchant purge stopped after %d merchants: %s", purged, err.Error())
+					return
+				}
+				log.Printf("[Scheduler] purged %d merchants deleted before %s", purged, deletedBefore.Format(time.RFC3339))
+			}),
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	s.Start()
@@ -60,3 +232,24 @@ func main() {
 	// Keep the main program running indefinitely
 	select {} // Infinite loop
 }
+
+// withLock wraps a job so that it runs on one scheduler instance at a time. An instance
+// that cannot take the lock skips the run, the lock expires after ttlMinutes in case
+// its holder dies.
+func withLock(lockRepo redis.ILockRepository, name string, ttlMinutes int, job func(ctx context.Context)) func() {
+	return func() {
+		ctx := context.Background()
+		token, acquired, err := lockRepo.Acquire(ctx, name, time.Duration(ttlMinutes)*time.Minute)
+		if err != nil {
+			log.Printf("[Scheduler] lock %s failed: %s", name, err.Error())
+			return
+		}
+		if !acquired {
+			log.Printf("[Scheduler] %s is running on another instance", name)
+			return
+		}
+		defer lockRepo.Release(ctx, name, token)
+
+		job(ctx)
+	}
+}
@@ -0,0 +1,67 @@
+// Command seed loads the MCC and region reference data from CSV into MySQL. It can
+// be run again after the files change, existing rows are updated in place.
+//
+//	go run ./cmd/seed -dir database/seed
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	usecase_reference "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference"
+
+	"github.com/subosito/gotenv"
+)
+
+func init() {
+	_ = gotenv.Load()
+}
+
+func main() {
+	dir := flag.String("dir", "database/seed", "directory holding mccs.csv and regions.csv")
+	flag.Parse()
+
+	cfg := config.NewConfig()
+	queue, err := config.NewRabbitMQInstance(context.Background(), &cfg.RabbitMQOption)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, err := config.NewZapLog(cfg.AppEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gormLogger := config.NewGormLogMysqlConfig(&cfg.MysqlOption)
+	mysqlDB, err := config.NewMysql(cfg.AppEnv, &cfg.MysqlOption, gormLogger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mccs, err := os.Open(filepath.Join(*dir, "mccs.csv"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mccs.Close()
+
+	regions, err := os.Open(filepath.Join(*dir, "regions.csv"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer regions.Close()
+
+	logUseCase := usecase_log.NewLogUseCase(queue, logger)
+	referenceUseCase := usecase_reference.NewReferenceUseCase(logUseCase, mysql.NewReferenceRepository(mysqlDB))
+
+	result, err := referenceUseCase.Seed(context.Background(), mccs, regions)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("[Seed] %d MCCs and %d regions loaded from %s", result.MCCs, result.Regions, *dir)
+}
@@ -11,6 +11,12 @@ import (
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/queue"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/queue/consumer"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mongodb"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	usecase_export "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/export"
+	usecase_importer "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/importer"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	usecase_reference "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference"
 
 	"github.com/subosito/gotenv"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -63,6 +69,67 @@ func main() {
 	case queue.ProcessSyncLog:
 		log.Printf("[Worker] Listening to %v", queue.ProcessSyncLog)
 		go app.queue.HandleConsumedDeliveries(queue.ProcessSyncLog, logConsumer.ProcessSyncLog)
+	case queue.ProcessSyncAudit:
+		auditMongoRepo := mongodb.NewAuditRepository(app.mongoDB)
+		if err := auditMongoRepo.EnsureIndexes(app.ctx); err != nil {
+			log.Fatal(err)
+		}
+		auditConsumer := consumer.NewAuditConsumer(context.Background(), auditMongoRepo)
+
+		log.Printf("[Worker] Listening to %v", queue.ProcessSyncAudit)
+		go app.queue.HandleConsumedDeliveries(queue.ProcessSyncAudit, auditConsumer.ProcessSyncAudit)
+	case queue.ProcessTransactionExport:
+		// Only the export worker needs MySQL, the log worker keeps running without it
+		gormLogger := config.NewGormLogMysqlConfig(&cfg.MysqlOption)
+		mysqlDB, err := config.NewMysql(cfg.AppEnv, &cfg.MysqlOption, gormLogger)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		zapLogger, err := config.NewZapLog(cfg.AppEnv)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		logUseCase := usecase_log.NewLogUseCase(app.queue, zapLogger)
+		exportUseCase := usecase_export.NewExportUseCase(
+			logUseCase,
+			app.queue,
+			mysql.NewExportJobRepository(mysqlDB),
+			mysql.NewTransactionRepository(mysqlDB),
+			mysql.NewMerchantRepository(mysqlDB),
+			&cfg.ExportOption,
+		)
+		exportConsumer := consumer.NewExportConsumer(context.Background(), exportUseCase)
+
+		log.Printf("[Worker] Listening to %v", queue.ProcessTransactionExport)
+		go app.queue.HandleConsumedDeliveries(queue.ProcessTransactionExport, exportConsumer.ProcessTransactionExport)
+	case queue.ProcessMerchantImport:
+		gormLogger := config.NewGormLogMysqlConfig(&cfg.MysqlOption)
+		mysqlDB, err := config.NewMysql(cfg.AppEnv, &cfg.MysqlOption, gormLogger)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		zapLogger, err := config.NewZapLog(cfg.AppEnv)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		logUseCase := usecase_log.NewLogUseCase(app.queue, zapLogger)
+		importUseCase := usecase_importer.NewImportUseCase(
+			logUseCase,
+			app.queue,
+			mysql.NewMerchantImportRepository(mysqlDB),
+			mysql.NewMerchantRepository(mysqlDB),
+			usecase_reference.NewReferenceUseCase(logUseCase, mysql.NewReferenceRepository(mysqlDB)),
+			usecase_audit.NewAuditUseCase(logUseCase, app.queue, mongodb.NewAuditRepository(app.mongoDB)),
+			&cfg.MerchantImportOption,
+		)
+		importConsumer := consumer.NewMerchantImportConsumer(context.Background(), importUseCase)
+
+		log.Printf("[Worker] Listening to %v", queue.ProcessMerchantImport)
+		go app.queue.HandleConsumedDeliveries(queue.ProcessMerchantImport, importConsumer.ProcessMerchantImport)
 	default:
 		log.Fatalf("[Worker] topic not found : %v", os.Args[1])
 	}
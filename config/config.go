@@ -1,6 +1,13 @@
 package config
 
-import "github.com/joeshaw/envdecode"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeshaw/envdecode"
+)
 
 var StorageDirectory = "./storage/app/"
 
@@ -22,6 +29,17 @@ type Config struct {
 	MongodbOption
 	RedisOption
 	PostgreSqlOption
+	ExportOption
+	SettlementOption
+	PayoutOption
+	DisputeOption
+	PendingExpiryOption
+	SwitchOption
+	FraudOption
+	BlocklistOption
+	OnboardingOption
+	MerchantImportOption
+	MerchantPurgeOption
 }
 
 // MysqlOption contains mySQL connection options
@@ -57,6 +75,112 @@ type RedisOption struct {
 	WriteTimeoutMs int16  `env:"REDIS_WRITE_TIMEOUT,required"`
 }
 
+// ExportOption contains transaction export job options
+type ExportOption struct {
+	DownloadSecret     string `env:"EXPORT_DOWNLOAD_SECRET,required"`
+	DownloadTTLMinutes int    `env:"EXPORT_DOWNLOAD_TTL_MINUTES,default=30"`
+	MaxRangeDays       int    `env:"EXPORT_MAX_RANGE_DAYS,default=31"`
+}
+
+// SettlementOption contains the settlement job options, transactions settle
+// DelayDays after the day they were made (T+1 by default)
+type SettlementOption struct {
+	Cron           string `env:"SETTLEMENT_CRON,default=0 1 * * *"`
+	DelayDays      int    `env:"SETTLEMENT_DELAY_DAYS,default=1"`
+	BatchSize      int    `env:"SETTLEMENT_BATCH_SIZE,default=500"`
+	LockTTLMinutes int    `env:"SETTLEMENT_LOCK_TTL_MINUTES,default=60"`
+}
+
+// PayoutOption contains the payout batch options. FileFormat selects the bank
+// disbursement layout: csv or fixed_width.
+type PayoutOption struct {
+	Cron           string  `env:"PAYOUT_CRON,default=0 3 * * *"`
+	FileFormat     string  `env:"PAYOUT_FILE_FORMAT,default=csv"`
+	SourceAccount  string  `env:"PAYOUT_SOURCE_ACCOUNT"`
+	MinAmount      float64 `env:"PAYOUT_MIN_AMOUNT,default=10000"`
+	LockTTLMinutes int     `env:"PAYOUT_LOCK_TTL_MINUTES,default=30"`
+}
+
+// DisputeOption contains the dispute options. Merchants have ResponseDays to answer
+// a dispute, the Cron job closes unanswered disputes as lost.
+type DisputeOption struct {
+	Cron              string `env:"DISPUTE_CRON,default=0 * * * *"`
+	ResponseDays      int    `env:"DISPUTE_RESPONSE_DAYS,default=7"`
+	MaxEvidenceSizeMB int    `env:"DISPUTE_MAX_EVIDENCE_SIZE_MB,default=5"`
+	BatchSize         int    `env:"DISPUTE_BATCH_SIZE,default=100"`
+	LockTTLMinutes    int    `env:"DISPUTE_LOCK_TTL_MINUTES,default=10"`
+}
+
+// SwitchOption contains the options of inbound switch calls, signed with the credential
+// of a participant. Calls older than TimestampToleranceSeconds are rejected as replays.
+type SwitchOption struct {
+	TimestampToleranceSeconds int `env:"SWITCH_TIMESTAMP_TOLERANCE_SECONDS,default=300"`
+}
+
+// FraudOption contains the fraud engine options. A transaction scoring ReviewScore is
+// queued for review and one scoring BlockScore is rejected. Rules are reloaded from the
+// database every RulesRefreshSeconds.
+type FraudOption struct {
+	ReviewScore         int `env:"FRAUD_REVIEW_SCORE,default=50"`
+	BlockScore          int `env:"FRAUD_BLOCK_SCORE,default=80"`
+	RulesRefreshSeconds int `env:"FRAUD_RULES_REFRESH_SECONDS,default=60"`
+}
+
+// BlocklistOption contains the blocklist options. The Redis copy of the blocklist is
+// rebuilt from the database every CacheRefreshSeconds.
+type BlocklistOption struct {
+	CacheRefreshSeconds int `env:"BLOCKLIST_CACHE_REFRESH_SECONDS,default=300"`
+}
+
+// OnboardingOption contains the merchant onboarding options
+type OnboardingOption struct {
+	MaxDocumentSizeMB int `env:"ONBOARDING_MAX_DOCUMENT_SIZE_MB,default=5"`
+}
+
+// MerchantImportOption contains the bulk merchant import options. Valid rows are
+// inserted ChunkSize at a time.
+type MerchantImportOption struct {
+	MaxFileSizeMB int `env:"MERCHANT_IMPORT_MAX_FILE_SIZE_MB,default=10"`
+	ChunkSize     int `env:"MERCHANT_IMPORT_CHUNK_SIZE,default=100"`
+}
+
+// MerchantPurgeOption contains the options of the job removing soft deleted merchants
+// for good once they have been deleted for RetentionDays
+type MerchantPurgeOption struct {
+	Cron           string `env:"MERCHANT_PURGE_CRON,default=0 2 * * *"`
+	RetentionDays  int    `env:"MERCHANT_PURGE_RETENTION_DAYS,default=30"`
+	BatchSize      int    `env:"MERCHANT_PURGE_BATCH_SIZE,default=100"`
+	LockTTLMinutes int    `env:"MERCHANT_PURGE_LOCK_TTL_MINUTES,default=30"`
+}
+
+// PendingExpiryOption contains the options of the job failing transactions the issuer
+// never confirmed. TimeoutMinutes overrides DefaultTimeoutMinutes per payment method as
+// "method=minutes" pairs separated by ";", e.g. "ewallet=15;bank_transfer=1440".
+type PendingExpiryOption struct {
+	Cron                  string   `env:"PENDING_EXPIRY_CRON,default=*/5 * * * *"`
+	DefaultTimeoutMinutes int      `env:"PENDING_EXPIRY_DEFAULT_TIMEOUT_MINUTES,default=60"`
+	TimeoutMinutes        []string `env:"PENDING_EXPIRY_TIMEOUT_MINUTES"`
+	BatchSize             int      `env:"PENDING_EXPIRY_BATCH_SIZE,default=100"`
+	LockTTLMinutes        int      `env:"PENDING_EXPIRY_LOCK_TTL_MINUTES,default=10"`
+}
+
+// Timeouts parses TimeoutMinutes into a timeout per payment method
+func (o *PendingExpiryOption) Timeouts() (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(o.TimeoutMinutes))
+	for _, pair := range o.TimeoutMinutes {
+		method, minutes, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid pending expiry timeout %q, expected method=minutes", pair)
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(minutes))
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid pending expiry timeout %q, expected method=minutes", pair)
+		}
+		timeouts[strings.TrimSpace(method)] = time.Duration(value) * time.Minute
+	}
+	return timeouts, nil
+}
+
 func NewConfig() *Config {
 	var cfg Config
 	if err := envdecode.Decode(&cfg); err != nil {
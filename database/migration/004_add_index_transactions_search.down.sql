@@ -0,0 +1,7 @@
+-- The foreign key on merchant_id needs an index left behind once the composite ones are gone
+ALTER TABLE transactions
+    ADD INDEX idx_transactions_merchant_id (merchant_id),
+    DROP INDEX idx_transactions_merchant_date,
+    DROP INDEX idx_transactions_merchant_status_date,
+    DROP INDEX idx_transactions_merchant_type_date,
+    DROP INDEX idx_transactions_merchant_payment_method_date;
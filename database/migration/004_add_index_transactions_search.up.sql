@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    ADD INDEX idx_transactions_merchant_date (merchant_id, transaction_date, id),
+    ADD INDEX idx_transactions_merchant_status_date (merchant_id, status, transaction_date, id),
+    ADD INDEX idx_transactions_merchant_type_date (merchant_id, type, transaction_date, id),
+    ADD INDEX idx_transactions_merchant_payment_method_date (merchant_id, payment_method, transaction_date, id);
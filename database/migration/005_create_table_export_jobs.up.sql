@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    merchant_id BIGINT UNSIGNED NOT NULL,
+    format ENUM('csv', 'xlsx') NOT NULL,
+    date_from DATE NOT NULL,
+    date_to DATE NOT NULL,
+    transaction_status VARCHAR(20),
+    status ENUM('queued', 'processing', 'completed', 'failed') DEFAULT 'queued',
+    file_path VARCHAR(255),
+    row_count INT UNSIGNED DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP NULL,
+    completed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_export_jobs_merchant (merchant_id, created_at),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id) ON DELETE CASCADE
+);
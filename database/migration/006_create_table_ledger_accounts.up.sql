@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    merchant_id BIGINT UNSIGNED NULL,
+    code VARCHAR(50) NOT NULL,
+    type ENUM('asset', 'liability', 'revenue') NOT NULL,
+    normal_balance ENUM('debit', 'credit') NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT '360',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_ledger_accounts_code_merchant (code, merchant_id),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id)
+);
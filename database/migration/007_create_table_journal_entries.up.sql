@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    merchant_id BIGINT UNSIGNED NULL,
+    entry_type ENUM('payment', 'mdr', 'refund', 'settlement', 'payout') NOT NULL,
+    reference_type VARCHAR(50) NOT NULL,
+    reference_id BIGINT UNSIGNED NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_journal_entries_reference (entry_type, reference_type, reference_id),
+    INDEX idx_journal_entries_merchant (merchant_id, created_at),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id)
+);
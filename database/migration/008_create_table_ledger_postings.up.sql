@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    journal_entry_id BIGINT UNSIGNED NOT NULL,
+    ledger_account_id BIGINT UNSIGNED NOT NULL,
+    direction ENUM('debit', 'credit') NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_ledger_postings_account (ledger_account_id, direction),
+    CONSTRAINT chk_ledger_postings_amount CHECK (amount > 0),
+    FOREIGN KEY (journal_entry_id) REFERENCES journal_entries(id),
+    FOREIGN KEY (ledger_account_id) REFERENCES ledger_accounts(id)
+);
@@ -0,0 +1 @@
+DROP TRIGGER IF EXISTS trg_ledger_postings_no_update;
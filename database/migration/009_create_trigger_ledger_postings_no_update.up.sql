@@ -0,0 +1,2 @@
+CREATE TRIGGER trg_ledger_postings_no_update BEFORE UPDATE ON ledger_postings
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'ledger postings are immutable';
@@ -0,0 +1 @@
+DELETE FROM ledger_accounts WHERE merchant_id IS NULL AND code IN ('SETTLEMENT_CLEARING', 'MDR_REVENUE', 'PAYOUT_CLEARING');
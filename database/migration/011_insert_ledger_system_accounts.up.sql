@@ -0,0 +1,4 @@
+INSERT INTO ledger_accounts (merchant_id, code, type, normal_balance) VALUES
+    (NULL, 'SETTLEMENT_CLEARING', 'asset', 'debit'),
+    (NULL, 'MDR_REVENUE', 'revenue', 'credit'),
+    (NULL, 'PAYOUT_CLEARING', 'liability', 'credit');
@@ -0,0 +1 @@
+DROP INDEX idx_transactions_status_date ON transactions;
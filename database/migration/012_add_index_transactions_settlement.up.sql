@@ -0,0 +1 @@
+CREATE INDEX idx_transactions_status_date ON transactions (status, transaction_date);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS payout_batches (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    batch_date DATE NOT NULL,
+    file_format ENUM('csv', 'fixed_width') NOT NULL,
+    file_path VARCHAR(255),
+    payout_count INT UNSIGNED DEFAULT 0,
+    total_amount DECIMAL(15, 2) DEFAULT 0,
+    status ENUM('created', 'sent', 'completed') DEFAULT 'created',
+    sent_at TIMESTAMP NULL,
+    completed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_payout_batches_date (batch_date)
+);
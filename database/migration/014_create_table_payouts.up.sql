@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS payouts (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    batch_id BIGINT UNSIGNED NOT NULL,
+    merchant_id BIGINT UNSIGNED NOT NULL,
+    reference VARCHAR(50) NOT NULL,
+    account_number VARCHAR(50) NOT NULL,
+    account_name VARCHAR(255) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    status ENUM('created', 'sent', 'confirmed', 'rejected') DEFAULT 'created',
+    rejection_reason VARCHAR(255),
+    sent_at TIMESTAMP NULL,
+    resolved_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_payouts_reference (reference),
+    UNIQUE INDEX idx_payouts_batch_merchant (batch_id, merchant_id),
+    INDEX idx_payouts_merchant (merchant_id, created_at),
+    FOREIGN KEY (batch_id) REFERENCES payout_batches(id),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id)
+);
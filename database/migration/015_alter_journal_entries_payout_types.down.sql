@@ -0,0 +1 @@
+ALTER TABLE journal_entries MODIFY entry_type ENUM('payment', 'mdr', 'refund', 'settlement', 'payout') NOT NULL;
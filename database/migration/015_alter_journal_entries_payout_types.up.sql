@@ -0,0 +1 @@
+ALTER TABLE journal_entries MODIFY entry_type ENUM('payment', 'mdr', 'refund', 'settlement', 'payout', 'payout_confirmation', 'payout_reversal') NOT NULL;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS reconciliation_runs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    settlement_date DATE NOT NULL,
+    file_format VARCHAR(20) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    status ENUM('processing', 'completed', 'failed') DEFAULT 'processing',
+    their_count INT UNSIGNED NOT NULL DEFAULT 0,
+    our_count INT UNSIGNED NOT NULL DEFAULT 0,
+    matched_count INT UNSIGNED NOT NULL DEFAULT 0,
+    missing_ours_count INT UNSIGNED NOT NULL DEFAULT 0,
+    missing_theirs_count INT UNSIGNED NOT NULL DEFAULT 0,
+    amount_mismatch_count INT UNSIGNED NOT NULL DEFAULT 0,
+    error_message VARCHAR(255),
+    completed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_reconciliation_runs_settlement_date (settlement_date)
+);
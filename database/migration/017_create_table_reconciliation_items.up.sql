@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS reconciliation_items (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    run_id BIGINT UNSIGNED NOT NULL,
+    transaction_id BIGINT UNSIGNED NULL,
+    reference_id VARCHAR(50) NOT NULL,
+    billing_id VARCHAR(50),
+    our_amount DECIMAL(15, 2) NULL,
+    their_amount DECIMAL(15, 2) NULL,
+    transaction_date DATE NULL,
+    result ENUM('matched', 'missing_ours', 'missing_theirs', 'amount_mismatch') NOT NULL,
+    status ENUM('open', 'resolved') DEFAULT 'open',
+    resolution_note VARCHAR(255),
+    resolved_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_reconciliation_items_run_result (run_id, result, status),
+    INDEX idx_reconciliation_items_reference (reference_id),
+    FOREIGN KEY (run_id) REFERENCES reconciliation_runs(id) ON DELETE CASCADE
+);
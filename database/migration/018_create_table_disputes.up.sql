@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS disputes (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    transaction_id BIGINT UNSIGNED NOT NULL,
+    merchant_id BIGINT UNSIGNED NOT NULL,
+    reason_code VARCHAR(20) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    status ENUM('open', 'merchant_response', 'won', 'lost') DEFAULT 'open',
+    merchant_response TEXT,
+    resolution_note VARCHAR(255),
+    refund_transaction_id BIGINT UNSIGNED NULL,
+    response_due_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP NULL,
+    resolved_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_disputes_transaction (transaction_id),
+    INDEX idx_disputes_merchant_status (merchant_id, status, created_at),
+    INDEX idx_disputes_status_due (status, response_due_at),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id),
+    FOREIGN KEY (refund_transaction_id) REFERENCES transactions(id)
+);
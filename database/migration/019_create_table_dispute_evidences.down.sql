@@ -0,0 +1 @@
+DROP TABLE IF EXISTS dispute_evidences;
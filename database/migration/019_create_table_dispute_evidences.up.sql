@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS dispute_evidences (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    dispute_id BIGINT UNSIGNED NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_path VARCHAR(500) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT UNSIGNED NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_dispute_evidences_dispute (dispute_id),
+    FOREIGN KEY (dispute_id) REFERENCES disputes(id) ON DELETE CASCADE
+);
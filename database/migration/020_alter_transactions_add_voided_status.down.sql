@@ -0,0 +1,3 @@
+UPDATE transactions SET status = 'failed' WHERE status = 'voided';
+ALTER TABLE transactions
+    MODIFY COLUMN status ENUM('pending', 'completed', 'settled', 'failed') DEFAULT 'pending';
@@ -0,0 +1,2 @@
+ALTER TABLE transactions
+    MODIFY COLUMN status ENUM('pending', 'completed', 'settled', 'failed', 'voided') DEFAULT 'pending';
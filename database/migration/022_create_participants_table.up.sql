@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS participants (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    roles SET('issuer', 'acquirer', 'switch') NOT NULL,
+    client_id VARCHAR(100) NOT NULL,
+    client_secret VARCHAR(255) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_participants_code (code),
+    UNIQUE KEY uq_participants_client_id (client_id)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS transaction_limits (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    merchant_id BIGINT UNSIGNED NULL,
+    mcc VARCHAR(5) NULL,
+    max_single_amount DECIMAL(15, 2) NULL,
+    max_daily_volume DECIMAL(18, 2) NULL,
+    max_monthly_volume DECIMAL(18, 2) NULL,
+    max_per_minute INT UNSIGNED NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_transaction_limits_merchant (merchant_id),
+    UNIQUE INDEX idx_transaction_limits_mcc (mcc),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id) ON DELETE CASCADE,
+    CONSTRAINT chk_transaction_limits_scope CHECK ((merchant_id IS NULL) <> (mcc IS NULL))
+);
@@ -0,0 +1 @@
+DELETE FROM fraud_rules WHERE code IN ('NEW_MERCHANT_VOLUME', 'MPAN_MERCHANT_SPREAD', 'ROUND_AMOUNT', 'NIGHT_SPIKE');
@@ -0,0 +1,5 @@
+INSERT INTO fraud_rules (code, name, type, params, weight) VALUES
+    ('NEW_MERCHANT_VOLUME', 'New merchant with high daily volume', 'new_merchant_volume', '{"max_age_days": 30, "daily_volume": 50000000}', 40),
+    ('MPAN_MERCHANT_SPREAD', 'Customer paying many merchants', 'mpan_merchant_spread', '{"window_minutes": 60, "max_merchants": 5}', 50),
+    ('ROUND_AMOUNT', 'Large round amount', 'round_amount', '{"multiple": 1000000, "min_amount": 5000000}', 20),
+    ('NIGHT_SPIKE', 'Night-time transaction spike', 'night_spike', '{"start_hour": 0, "end_hour": 5, "window_minutes": 10, "max_transactions": 10}', 30);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS fraud_decisions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    transaction_id BIGINT UNSIGNED NULL,
+    reference_id VARCHAR(100) NOT NULL,
+    billing_id VARCHAR(100) NOT NULL,
+    merchant_id BIGINT UNSIGNED NOT NULL,
+    customer_mpan VARCHAR(100) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    score INT UNSIGNED NOT NULL,
+    decision ENUM('allow', 'review', 'block') NOT NULL,
+    triggered_rules JSON NOT NULL,
+    review_status ENUM('pending', 'approved', 'rejected') NULL,
+    reviewed_by VARCHAR(100) NULL,
+    review_note VARCHAR(255) NULL,
+    reviewed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_fraud_decisions_review (decision, review_status, created_at),
+    INDEX idx_fraud_decisions_merchant (merchant_id, created_at),
+    INDEX idx_fraud_decisions_transaction (transaction_id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id)
+);
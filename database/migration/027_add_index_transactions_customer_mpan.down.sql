@@ -0,0 +1 @@
+DROP INDEX idx_transactions_customer_mpan_date ON transactions;
@@ -0,0 +1 @@
+CREATE INDEX idx_transactions_customer_mpan_date ON transactions (customer_mpan, transaction_date);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS blocklist_entries (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    type ENUM('customer_mpan', 'merchant_id', 'nmid', 'ip') NOT NULL,
+    value VARCHAR(100) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NULL,
+    created_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_blocklist_entries_type_value (type, value),
+    INDEX idx_blocklist_entries_expires_at (expires_at)
+);
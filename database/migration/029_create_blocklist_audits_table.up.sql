@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS blocklist_audits (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    entry_id BIGINT UNSIGNED NOT NULL,
+    action ENUM('add', 'remove') NOT NULL,
+    type ENUM('customer_mpan', 'merchant_id', 'nmid', 'ip') NOT NULL,
+    value VARCHAR(100) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NULL,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_blocklist_audits_entry (entry_id),
+    INDEX idx_blocklist_audits_type_value (type, value, created_at)
+);
@@ -0,0 +1,8 @@
+ALTER TABLE merchants
+    MODIFY status ENUM('active', 'inactive', 'draft', 'submitted', 'under_review', 'approved', 'rejected', 'suspended') NULL DEFAULT 'active';
+
+UPDATE merchants SET status = 'active' WHERE status = 'approved';
+UPDATE merchants SET status = 'inactive' WHERE status <> 'active';
+
+ALTER TABLE merchants
+    MODIFY status ENUM('active', 'inactive') DEFAULT 'active';
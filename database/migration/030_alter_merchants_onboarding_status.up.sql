@@ -0,0 +1,9 @@
+ALTER TABLE merchants
+    MODIFY status ENUM('active', 'inactive', 'draft', 'submitted', 'under_review', 'approved', 'rejected', 'suspended') NULL DEFAULT 'draft';
+
+-- Merchants that were live keep taking payments, deactivated ones stay blocked
+UPDATE merchants SET status = 'approved' WHERE status = 'active' OR status IS NULL;
+UPDATE merchants SET status = 'suspended' WHERE status = 'inactive';
+
+ALTER TABLE merchants
+    MODIFY status ENUM('draft', 'submitted', 'under_review', 'approved', 'rejected', 'suspended') NOT NULL DEFAULT 'draft';
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS outlets (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    merchant_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address VARCHAR(500) NOT NULL,
+    postal_code VARCHAR(20),
+    province VARCHAR(100),
+    district VARCHAR(100),
+    subdistrict VARCHAR(100),
+    city VARCHAR(100),
+    latitude DECIMAL(10, 7),
+    longitude DECIMAL(10, 7),
+    status ENUM('active', 'inactive') NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_outlets_merchant (merchant_id, status),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id) ON DELETE CASCADE
+);
@@ -0,0 +1,18 @@
+-- terminal_id is the TID assigned by the acquirer, a device is bound to at most one terminal
+CREATE TABLE IF NOT EXISTS terminals (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    merchant_id BIGINT UNSIGNED NOT NULL,
+    outlet_id BIGINT UNSIGNED NOT NULL,
+    terminal_id VARCHAR(16) NOT NULL,
+    device_serial VARCHAR(100),
+    binding_status ENUM('unbound', 'bound', 'blocked') NOT NULL DEFAULT 'unbound',
+    bound_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_terminals_terminal_id (terminal_id),
+    UNIQUE KEY uq_terminals_device_serial (device_serial),
+    INDEX idx_terminals_merchant_outlet (merchant_id, outlet_id),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE
+);
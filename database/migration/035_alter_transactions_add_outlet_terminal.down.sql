@@ -0,0 +1,7 @@
+ALTER TABLE transactions
+    DROP FOREIGN KEY fk_transactions_outlet,
+    DROP FOREIGN KEY fk_transactions_terminal,
+    DROP INDEX idx_transactions_merchant_outlet_date,
+    DROP INDEX idx_transactions_merchant_terminal_date,
+    DROP COLUMN outlet_id,
+    DROP COLUMN terminal_id;
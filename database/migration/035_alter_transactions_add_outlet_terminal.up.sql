@@ -0,0 +1,8 @@
+-- Transactions made before outlets existed keep both columns empty
+ALTER TABLE transactions
+    ADD COLUMN outlet_id BIGINT UNSIGNED NULL AFTER merchant_id,
+    ADD COLUMN terminal_id BIGINT UNSIGNED NULL AFTER outlet_id,
+    ADD INDEX idx_transactions_merchant_outlet_date (merchant_id, outlet_id, transaction_date, id),
+    ADD INDEX idx_transactions_merchant_terminal_date (merchant_id, terminal_id, transaction_date, id),
+    ADD CONSTRAINT fk_transactions_outlet FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_transactions_terminal FOREIGN KEY (terminal_id) REFERENCES terminals(id) ON DELETE SET NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE merchants
+    DROP FOREIGN KEY fk_merchants_parent,
+    DROP INDEX idx_merchants_parent,
+    DROP COLUMN settle_at_parent,
+    DROP COLUMN parent_id,
+    DROP COLUMN type;
@@ -0,0 +1,8 @@
+-- type follows MerchantType: 1 individual, 2 corporate. Only corporate merchants
+-- own children and a child may have its payouts paid to the parent's account.
+ALTER TABLE merchants
+    ADD COLUMN type TINYINT UNSIGNED NOT NULL DEFAULT 1 AFTER name,
+    ADD COLUMN parent_id BIGINT UNSIGNED NULL AFTER type,
+    ADD COLUMN settle_at_parent BOOLEAN NOT NULL DEFAULT FALSE AFTER parent_id,
+    ADD INDEX idx_merchants_parent (parent_id),
+    ADD CONSTRAINT fk_merchants_parent FOREIGN KEY (parent_id) REFERENCES merchants(id) ON DELETE RESTRICT;
@@ -0,0 +1,8 @@
+-- ISO 18245 merchant category codes, loaded by cmd/seed
+CREATE TABLE IF NOT EXISTS mccs (
+    code CHAR(4) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (code)
+);
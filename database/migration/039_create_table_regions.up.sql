@@ -0,0 +1,17 @@
+-- Indonesian administrative hierarchy keyed by the Kemendagri code, loaded by
+-- cmd/seed. Each region points at its parent: province > city > district >
+-- subdistrict, only subdistricts carry a postal code.
+CREATE TABLE IF NOT EXISTS regions (
+    code VARCHAR(13) NOT NULL,
+    parent_code VARCHAR(13) NULL,
+    level ENUM('province', 'city', 'district', 'subdistrict') NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    postal_code CHAR(5) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (code),
+    INDEX idx_regions_parent_name (parent_code, name),
+    INDEX idx_regions_level_name (level, name),
+    INDEX idx_regions_postal_code (postal_code),
+    CONSTRAINT fk_regions_parent FOREIGN KEY (parent_code) REFERENCES regions(code) ON DELETE RESTRICT
+);
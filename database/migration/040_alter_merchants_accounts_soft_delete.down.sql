@@ -0,0 +1,15 @@
+ALTER TABLE accounts DROP FOREIGN KEY fk_accounts_merchant;
+ALTER TABLE accounts
+    ADD CONSTRAINT accounts_ibfk_1 FOREIGN KEY (merchant_id) REFERENCES merchants(id) ON DELETE CASCADE;
+
+ALTER TABLE transactions DROP FOREIGN KEY fk_transactions_merchant;
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_ibfk_1 FOREIGN KEY (merchant_id) REFERENCES merchants(id) ON DELETE CASCADE;
+
+ALTER TABLE accounts
+    DROP INDEX idx_accounts_merchant_deleted,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE merchants
+    DROP INDEX idx_merchants_deleted,
+    DROP COLUMN deleted_at;
@@ -0,0 +1,18 @@
+-- Merchants and accounts are soft deleted and only removed for good by the purge
+-- job. Transactions and accounts no longer cascade from their merchant, removing a
+-- merchant that still has either now fails instead of silently wiping them.
+ALTER TABLE merchants
+    ADD COLUMN deleted_at TIMESTAMP NULL,
+    ADD INDEX idx_merchants_deleted (deleted_at);
+
+ALTER TABLE accounts
+    ADD COLUMN deleted_at TIMESTAMP NULL,
+    ADD INDEX idx_accounts_merchant_deleted (merchant_id, deleted_at);
+
+ALTER TABLE transactions DROP FOREIGN KEY transactions_ibfk_1;
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transactions_merchant FOREIGN KEY (merchant_id) REFERENCES merchants(id) ON DELETE RESTRICT;
+
+ALTER TABLE accounts DROP FOREIGN KEY accounts_ibfk_1;
+ALTER TABLE accounts
+    ADD CONSTRAINT fk_accounts_merchant FOREIGN KEY (merchant_id) REFERENCES merchants(id) ON DELETE RESTRICT;
@@ -0,0 +1,3 @@
+ALTER TABLE accounts DROP COLUMN version;
+
+ALTER TABLE merchants DROP COLUMN version;
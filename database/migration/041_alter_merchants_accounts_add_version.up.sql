@@ -0,0 +1,7 @@
+-- Every update of a merchant or account bumps its version, updates made against an
+-- older version are rejected so concurrent edits no longer overwrite each other.
+ALTER TABLE merchants
+    ADD COLUMN version INT UNSIGNED NOT NULL DEFAULT 1;
+
+ALTER TABLE accounts
+    ADD COLUMN version INT UNSIGNED NOT NULL DEFAULT 1;
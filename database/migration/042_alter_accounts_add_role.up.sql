@@ -0,0 +1,4 @@
+-- Backoffice accounts review merchants and may act on every merchant. They are
+-- provisioned directly in the database, the account API only creates merchant accounts.
+ALTER TABLE accounts
+    ADD COLUMN role ENUM('merchant', 'backoffice') NOT NULL DEFAULT 'merchant';
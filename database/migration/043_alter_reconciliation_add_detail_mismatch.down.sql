@@ -0,0 +1,4 @@
+DELETE FROM reconciliation_items WHERE result = 'detail_mismatch';
+ALTER TABLE reconciliation_items
+    MODIFY COLUMN result ENUM('matched', 'missing_ours', 'missing_theirs', 'amount_mismatch') NOT NULL;
+ALTER TABLE reconciliation_runs DROP COLUMN detail_mismatch_count;
@@ -0,0 +1,6 @@
+-- A switch record paired with our transaction by reference ID whose billing ID or
+-- date differs is a detail mismatch instead of a missing row on each side.
+ALTER TABLE reconciliation_items
+    MODIFY COLUMN result ENUM('matched', 'missing_ours', 'missing_theirs', 'amount_mismatch', 'detail_mismatch') NOT NULL;
+ALTER TABLE reconciliation_runs
+    ADD COLUMN detail_mismatch_count INT UNSIGNED NOT NULL DEFAULT 0 AFTER amount_mismatch_count;
@@ -0,0 +1,4 @@
+-- The MDR a merchant is charged on payments recorded from a switch notification, the
+-- ones created through the transaction API carry their own.
+ALTER TABLE merchants
+    ADD COLUMN mdr_percentage DECIMAL(5, 2) NOT NULL DEFAULT 0 AFTER mcc;
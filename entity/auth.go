@@ -7,3 +7,28 @@ type Claims struct {
 	AccountID  uint64 `json:"account_id"`
 	MerchantID uint64 `json:"merchant_id"`
 }
+
+// Caller is the account a signed request was verified for, the signature check keeps
+// it in the request's locals
+type Caller struct {
+	ClientID string
+	// MerchantID is the merchant the account belongs to
+	MerchantID uint64
+	// ChildIDs are the children of a corporate merchant, its account acts on them too
+	ChildIDs []uint64
+	// Backoffice accounts review merchants and may act on every merchant
+	Backoffice bool
+}
+
+// CanActOn reports whether the caller may act on the merchant
+func (c *Caller) CanActOn(merchantID uint64) bool {
+	if c.Backoffice || merchantID == c.MerchantID {
+		return true
+	}
+	for _, childID := range c.ChildIDs {
+		if childID == merchantID {
+			return true
+		}
+	}
+	return false
+}
@@ -1,22 +1,46 @@
 package entity
 
 const (
-	SUCCESS_CODE           = "00"
-	SUCCESS_MSG            = "Success"
-	INVALID_AUTH_CODE      = "01"
-	INVALID_AUTH_MSG       = "Invalid Email or Password"
-	INVALID_PAYLOAD_CODE   = "02"
-	INVALID_PAYLOAD_MSG    = "Invalid Payload Request Data"
-	INVALID_TOKEN_CODE     = "05"
-	INVALID_TOKEN_MSG      = "Invalid Access Token"
-	INVALID_SIGNATURE_CODE = "06"
-	INVALID_SIGNATURE_MSG  = "Invalid Signature"
-	BAD_REQUEST_CODE       = "30"
-	BAD_REQUEST_MSG        = "Bad Request"
-	DATA_NOT_FOUND_MSG     = "Data not found"
-	USER_NOT_FOUND_MSG     = "User not found"
-	GENERAL_ERROR_CODE     = "99"
-	GENERAL_ERROR_MESSAGE  = "Something went wrong. Please try again later."
+	SUCCESS_CODE               = "00"
+	SUCCESS_MSG                = "Success"
+	INVALID_AUTH_CODE          = "01"
+	INVALID_AUTH_MSG           = "Invalid Email or Password"
+	INVALID_PAYLOAD_CODE       = "02"
+	INVALID_PAYLOAD_MSG        = "Invalid Payload Request Data"
+	INVALID_TOKEN_CODE         = "05"
+	INVALID_TOKEN_MSG          = "Invalid Access Token"
+	INVALID_SIGNATURE_CODE     = "06"
+	INVALID_SIGNATURE_MSG      = "Invalid Signature"
+	INVALID_LINK_CODE          = "07"
+	INVALID_LINK_MSG           = "Invalid or expired link"
+	FORBIDDEN_CODE             = "08"
+	FORBIDDEN_MSG              = "Account is not allowed to access this resource"
+	BAD_REQUEST_CODE           = "30"
+	BAD_REQUEST_MSG            = "Bad Request"
+	NOT_READY_CODE             = "31"
+	NOT_READY_MSG              = "Resource is not ready yet"
+	LIMIT_SINGLE_CODE          = "40"
+	LIMIT_SINGLE_MSG           = "Amount exceeds the merchant's single transaction limit"
+	LIMIT_DAILY_CODE           = "41"
+	LIMIT_DAILY_MSG            = "Merchant daily transaction volume limit exceeded"
+	LIMIT_MONTHLY_CODE         = "42"
+	LIMIT_MONTHLY_MSG          = "Merchant monthly transaction volume limit exceeded"
+	LIMIT_VELOCITY_CODE        = "43"
+	LIMIT_VELOCITY_MSG         = "Too many transactions for the merchant, please try again later"
+	FRAUD_BLOCKED_CODE         = "44"
+	FRAUD_BLOCKED_MSG          = "Transaction declined by risk checks"
+	BLOCKLISTED_CODE           = "45"
+	BLOCKLISTED_MSG            = "Request declined, a party to it is blocklisted"
+	MERCHANT_NOT_APPROVED_CODE = "46"
+	MERCHANT_NOT_APPROVED_MSG  = "Merchant has not been approved to accept payments"
+	STALE_VERSION_CODE         = "47"
+	STALE_VERSION_MSG          = "Data has been changed by another request, reload it and try again"
+	VERSION_REQUIRED_CODE      = "48"
+	VERSION_REQUIRED_MSG       = "If-Match header with the version to update is required"
+	DATA_NOT_FOUND_MSG         = "Data not found"
+	USER_NOT_FOUND_MSG         = "User not found"
+	GENERAL_ERROR_CODE         = "99"
+	GENERAL_ERROR_MESSAGE      = "Something went wrong. Please try again later."
 )
 
 type GeneralResponse struct {
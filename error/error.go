@@ -46,6 +46,102 @@ func ErrInvalidSignature() CustomErrorResponse {
 	}
 }
 
+func ErrInvalidLink() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.INVALID_LINK_MSG,
+		ErrCode:  entity.INVALID_LINK_CODE,
+		HTTPCode: http.StatusForbidden,
+	}
+}
+
+func ErrForbidden() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.FORBIDDEN_MSG,
+		ErrCode:  entity.FORBIDDEN_CODE,
+		HTTPCode: http.StatusForbidden,
+	}
+}
+
+func ErrNotReady() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.NOT_READY_MSG,
+		ErrCode:  entity.NOT_READY_CODE,
+		HTTPCode: http.StatusConflict,
+	}
+}
+
+func ErrSingleAmountLimit() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.LIMIT_SINGLE_MSG,
+		ErrCode:  entity.LIMIT_SINGLE_CODE,
+		HTTPCode: http.StatusUnprocessableEntity,
+	}
+}
+
+func ErrDailyVolumeLimit() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.LIMIT_DAILY_MSG,
+		ErrCode:  entity.LIMIT_DAILY_CODE,
+		HTTPCode: http.StatusUnprocessableEntity,
+	}
+}
+
+func ErrMonthlyVolumeLimit() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.LIMIT_MONTHLY_MSG,
+		ErrCode:  entity.LIMIT_MONTHLY_CODE,
+		HTTPCode: http.StatusUnprocessableEntity,
+	}
+}
+
+func ErrVelocityLimit() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.LIMIT_VELOCITY_MSG,
+		ErrCode:  entity.LIMIT_VELOCITY_CODE,
+		HTTPCode: http.StatusTooManyRequests,
+	}
+}
+
+func ErrFraudBlocked() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.FRAUD_BLOCKED_MSG,
+		ErrCode:  entity.FRAUD_BLOCKED_CODE,
+		HTTPCode: http.StatusUnprocessableEntity,
+	}
+}
+
+func ErrBlocklisted() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.BLOCKLISTED_MSG,
+		ErrCode:  entity.BLOCKLISTED_CODE,
+		HTTPCode: http.StatusForbidden,
+	}
+}
+
+func ErrMerchantNotApproved() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.MERCHANT_NOT_APPROVED_MSG,
+		ErrCode:  entity.MERCHANT_NOT_APPROVED_CODE,
+		HTTPCode: http.StatusForbidden,
+	}
+}
+
+func ErrStaleVersion() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.STALE_VERSION_MSG,
+		ErrCode:  entity.STALE_VERSION_CODE,
+		HTTPCode: http.StatusPreconditionFailed,
+	}
+}
+
+func ErrVersionRequired() CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  entity.VERSION_REQUIRED_MSG,
+		ErrCode:  entity.VERSION_REQUIRED_CODE,
+		HTTPCode: http.StatusPreconditionRequired,
+	}
+}
+
 func ErrInvalidPayload(meta []entity.ErrorResponse) CustomErrorResponseWithMeta {
 	return CustomErrorResponseWithMeta{
 		Message:  entity.INVALID_PAYLOAD_MSG,
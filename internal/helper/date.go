@@ -31,6 +31,12 @@ func DatetimeNowJakarta() time.Time {
 	return time.Now().In(jakartaLocation)
 }
 
+func DatetimeInJakarta(t time.Time) time.Time {
+	jakartaLocation, _ := time.LoadLocation("Asia/Jakarta")
+
+	return t.In(jakartaLocation)
+}
+
 func ParseDate(dateStr string) (time.Time, error) {
 	const layout = "2006-01-02"
 	return time.Parse(layout, dateStr)
@@ -0,0 +1,26 @@
+package helper
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FormatETag renders a row version as a strong entity tag
+func FormatETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// ParseETag is the reverse of FormatETag, a bare version is accepted as well. Weak tags
+// never match for an update, so they are rejected like any other malformed tag.
+func ParseETag(tag string) (uint64, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) >= 2 && strings.HasPrefix(tag, `"`) && strings.HasSuffix(tag, `"`) {
+		tag = tag[1 : len(tag)-1]
+	}
+
+	version, err := strconv.ParseUint(tag, 10, 64)
+	if err != nil || version == 0 {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QRISCRC returns the CRC-16/CCITT-FALSE checksum of a QRIS payload as 4 hex digits.
+// The input is the payload up to and including the "6304" CRC tag header.
+func QRISCRC(input string) string {
+	crc := uint16(0xFFFF)
+	for _, b := range []byte(input) {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
+
+// ParseTLV splits EMV tag-length-value data into its tags. Every tag is two digits
+// followed by a two digit length, nested templates such as tag 62 are parsed by
+// calling ParseTLV again on their value.
+func ParseTLV(data string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for pos := 0; pos < len(data); {
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf("truncated tag at position %d", pos)
+		}
+		tag := data[pos : pos+2]
+		length, err := strconv.Atoi(data[pos+2 : pos+4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid length of tag %s", tag)
+		}
+		pos += 4
+		if pos+length > len(data) {
+			return nil, fmt.Errorf("value of tag %s is truncated", tag)
+		}
+		tags[tag] = data[pos : pos+length]
+		pos += length
+	}
+	return tags, nil
+}
+
+// ParseQRIS parses a QRIS payload after checking its CRC, which must be the last tag
+func ParseQRIS(payload string) (map[string]string, error) {
+	if len(payload) < 8 || payload[len(payload)-8:len(payload)-4] != "6304" {
+		return nil, fmt.Errorf("CRC tag is missing")
+	}
+	if !strings.EqualFold(QRISCRC(payload[:len(payload)-4]), payload[len(payload)-4:]) {
+		return nil, fmt.Errorf("CRC does not match the payload")
+	}
+
+	return ParseTLV(payload)
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+)
+
+// NotificationSignature verifies requests sent by an active switch participant. The
+// X-Signature header is the hex HMAC-SHA256 of "<X-Timestamp>:<raw body>" keyed with
+// the participant's client secret.
+type NotificationSignature struct {
+	participantRepo mysql.IParticipantRepository
+	option          *config.SwitchOption
+}
+
+func NewNotificationSignature(participantRepo mysql.IParticipantRepository, option *config.SwitchOption) ISignature {
+	return &NotificationSignature{participantRepo, option}
+}
+
+func (u *NotificationSignature) VerifySignature(c *fiber.Ctx) error {
+	participant, err := u.participantRepo.FindByClientID(c.Context(), c.Get("X-Client-ID"))
+	if err != nil || !participant.IsActive || !participant.HasRole(entity.ParticipantRoleSwitch) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid client",
+		})
+	}
+
+	timestamp := c.Get("X-Timestamp")
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "X-Timestamp header is missing or invalid",
+		})
+	}
+	tolerance := time.Duration(u.option.TimestampToleranceSeconds) * time.Second
+	if age := time.Since(time.Unix(sentAt, 0)); age > tolerance || age < -tolerance {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Request has expired",
+		})
+	}
+
+	signature, err := hex.DecodeString(c.Get("X-Signature"))
+	if err != nil || !hmac.Equal(signature, notificationMAC(participant.ClientSecret, timestamp, c.Body())) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid signature",
+		})
+	}
+
+	return c.Next()
+}
+
+func notificationMAC(secret string, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte(":"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotificationSignatureTestSuite struct {
+	suite.Suite
+	app *fiber.App
+}
+
+func TestNotificationSignature(t *testing.T) {
+	suite.Run(t, new(NotificationSignatureTestSuite))
+}
+
+// participantRepoStub only answers FindByClientID, the other methods are not used
+type participantRepoStub struct {
+	mysql.IParticipantRepository
+	participants []entity.ParticipantEntity
+}
+
+func (r *participantRepoStub) FindByClientID(ctx context.Context, clientID string) (*entity.ParticipantEntity, error) {
+	for i := range r.participants {
+		if r.participants[i].ClientID == clientID {
+			return &r.participants[i], nil
+		}
+	}
+	return nil, apperr.ErrRecordNotFound()
+}
+
+func (s *NotificationSignatureTestSuite) SetupTest() {
+	participantRepo := &participantRepoStub{participants: []entity.ParticipantEntity{
+		{ClientID: "switch", ClientSecret: "secret", Roles: "switch", IsActive: true},
+		{ClientID: "issuer", ClientSecret: "secret", Roles: "issuer", IsActive: true},
+		{ClientID: "inactive", ClientSecret: "secret", Roles: "switch", IsActive: false},
+	}}
+	signature := NewNotificationSignature(participantRepo, &config.SwitchOption{
+		TimestampToleranceSeconds: 300,
+	})
+
+	s.app = fiber.New()
+	s.app.Post("/notify", signature.VerifySignature, func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+}
+
+func (s *NotificationSignatureTestSuite) TestVerifySignature() {
+	body := `{"billing_id":"ST-1"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	sign := func(secret string, timestamp string, body string) string {
+		return hex.EncodeToString(notificationMAC(secret, timestamp, []byte(body)))
+	}
+
+	testcases := []struct {
+		name      string
+		clientID  string
+		timestamp string
+		signature string
+		body      string
+		expected  int
+	}{
+		{
+			name:      "valid signature",
+			clientID:  "switch",
+			timestamp: now,
+			signature: sign("secret", now, body),
+			body:      body,
+			expected:  http.StatusOK,
+		},
+		{
+			name:      "unknown client",
+			clientID:  "other",
+			timestamp: now,
+			signature: sign("secret", now, body),
+			body:      body,
+			expected:  http.StatusUnauthorized,
+		},
+		{
+			name:      "participant without switch role",
+			clientID:  "issuer",
+			timestamp: now,
+			signature: sign("secret", now, body),
+			body:      body,
+			expected:  http.StatusUnauthorized,
+		},
+		{
+			name:      "inactive participant",
+			clientID:  "inactive",
+			timestamp: now,
+			signature: sign("secret", now, body),
+			body:      body,
+			expected:  http.StatusUnauthorized,
+		},
+		{
+			name:      "wrong secret",
+			clientID:  "switch",
+			timestamp: now,
+			signature: sign("other", now, body),
+			body:      body,
+			expected:  http.StatusUnauthorized,
+		},
+		{
+			name:      "tampered body",
+			clientID:  "switch",
+			timestamp: now,
+			signature: sign("secret", now, body),
+			body:      `{"billing_id":"ST-2"}`,
+			expected:  http.StatusUnauthorized,
+		},
+		{
+			name:      "stale timestamp",
+			clientID:  "switch",
+			timestamp: stale,
+			signature: sign("secret", stale, body),
+			body:      body,
+			expected:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		s.Run(tc.name, func() {
+			req := httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(tc.body))
+			req.Header.Set("X-Client-ID", tc.clientID)
+			req.Header.Set("X-Timestamp", tc.timestamp)
+			req.Header.Set("X-Signature", tc.signature)
+
+			resp, err := s.app.Test(req)
+			s.NoError(err)
+			s.Equal(tc.expected, resp.StatusCode)
+		})
+	}
+}
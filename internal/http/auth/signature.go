@@ -3,11 +3,15 @@ package auth
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
 )
 
 type ISignature interface {
@@ -57,6 +61,37 @@ func (u *Signature) VerifySignature(c *fiber.Ctx) error {
 		})
 	}
 
+	children, err := u.merchantRepo.FindChildren(c.Context(), account.MerchantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Cannot load the merchants of the account",
+		})
+	}
+	caller := &generalEntity.Caller{
+		ClientID:   account.ClientID,
+		MerchantID: account.MerchantID,
+		Backoffice: account.IsBackoffice(),
+	}
+	for _, child := range children {
+		caller.ChildIDs = append(caller.ChildIDs, child.ID)
+	}
+
+	// Credentials act on their own merchant, a corporate merchant's credentials may
+	// act on one of its children by naming it in X-Merchant-ID
+	merchantID := account.MerchantID
+	if header := c.Get("X-Merchant-ID"); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+		if err != nil || !caller.CanActOn(id) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Account cannot act on this merchant",
+			})
+		}
+		merchantID = id
+	}
+	c.Locals("merchant_id", merchantID)
+	c.Locals(parser.CallerKey, caller)
+	c.Locals(usecase_audit.ActorKey, auditEntity.Actor{ID: "client:" + account.ClientID, IP: c.IP()})
+
 	return c.Next()
 }
 
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
 	usecase_account "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/account"
@@ -33,9 +34,14 @@ func (h *AccountHandler) Register(app fiber.Router) {
 	// Define your routes here
 	app.Get("/accounts/:id", h.GetAccountByID)
 	app.Get("/accounts/:id/merchants", h.GetAccountMerchants)
+}
+
+// RegisterSigned registers the routes that must come after the signature check
+func (h *AccountHandler) RegisterSigned(app fiber.Router) {
 	app.Post("/accounts", h.CreateAccount)
 	app.Put("/accounts/:id", h.UpdateAccount)
 	app.Delete("/accounts/:id", h.DeleteAccount)
+	app.Post("/accounts/:id/restore", h.RestoreAccount)
 }
 
 func (h *AccountHandler) GetAccountByID(c *fiber.Ctx) error {
@@ -49,6 +55,7 @@ func (h *AccountHandler) GetAccountByID(c *fiber.Ctx) error {
 		return h.presenter.BuildError(c, err)
 	}
 
+	c.Set(fiber.HeaderETag, helper.FormatETag(account.Version))
 	return h.presenter.BuildSuccess(c, account, "Account successfully retrieved", http.StatusOK)
 }
 
@@ -58,15 +65,18 @@ func (h *AccountHandler) GetAccountMerchants(c *fiber.Ctx) error {
 		return h.presenter.BuildError(c, err)
 	}
 
-	account, err := h.usecase.GetAccountByMerchantID(c.Context(), uint64(id))
+	merchants, err := h.usecase.ListAccountMerchants(c.Context(), uint64(id))
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
 
-	return h.presenter.BuildSuccess(c, account, "Merchants in account sucessfully retrieved", http.StatusOK)
+	return h.presenter.BuildSuccess(c, merchants, "Merchants in account sucessfully retrieved", http.StatusOK)
 }
 
 func (h *AccountHandler) CreateAccount(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
 	var accountRequest *entity.AccountRequest
 
 	err := h.parser.ParserBodyRequest(c, &accountRequest)
@@ -79,30 +89,44 @@ func (h *AccountHandler) CreateAccount(c *fiber.Ctx) error {
 		return h.presenter.BuildError(c, err)
 	}
 
+	c.Set(fiber.HeaderETag, helper.FormatETag(accountResponse.Version))
 	return h.presenter.BuildSuccess(c, accountResponse, "Account created successfully", http.StatusCreated)
 }
 
 func (h *AccountHandler) UpdateAccount(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
 	id, err := h.parser.ParserIntIDFromPathParams(c)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
 
+	version, err := h.parser.ParserIfMatch(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
 	var accountRequest *entity.AccountRequest
 	err = h.parser.ParserBodyRequest(c, &accountRequest)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
+	accountRequest.Version = version
 
 	accountResponse, err := h.usecase.UpdateAccount(c.Context(), uint64(id), accountRequest)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
 
+	c.Set(fiber.HeaderETag, helper.FormatETag(accountResponse.Version))
 	return h.presenter.BuildSuccess(c, accountResponse, "Account updated successfully", http.StatusOK)
 }
 
 func (h *AccountHandler) DeleteAccount(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
 	id, err := h.parser.ParserIntIDFromPathParams(c)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
@@ -115,3 +139,21 @@ func (h *AccountHandler) DeleteAccount(c *fiber.Ctx) error {
 
 	return h.presenter.BuildSuccess(c, nil, "Account deleted successfully", http.StatusNoContent)
 }
+
+func (h *AccountHandler) RestoreAccount(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	accountResponse, err := h.usecase.RestoreAccount(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, helper.FormatETag(accountResponse.Version))
+	return h.presenter.BuildSuccess(c, accountResponse, "Account restored successfully", http.StatusOK)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+)
+
+type AuditHandler struct {
+	parser       parser.Parser
+	presenter    json.JsonPresenter
+	auditUseCase usecase_audit.IAuditUseCase
+}
+
+func NewAuditHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	auditUseCase usecase_audit.IAuditUseCase,
+) *AuditHandler {
+	return &AuditHandler{parser, presenter, auditUseCase}
+}
+
+func (h *AuditHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/audits", h.ListAudits)
+}
+
+// ListAudits returns the audit trail newest first, filtered by entity, actor, action
+// or date
+func (h *AuditHandler) ListAudits(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	var req entity.AuditListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	audits, meta, err := h.auditUseCase.ListAudits(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, audits, meta, "Audit trail successfully retrieved", http.StatusOK)
+}
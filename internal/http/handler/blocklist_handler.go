@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_blocklist "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist/entity"
+)
+
+type BlocklistHandler struct {
+	parser           parser.Parser
+	presenter        json.JsonPresenter
+	blocklistUseCase usecase_blocklist.IBlocklistUseCase
+}
+
+func NewBlocklistHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	blocklistUseCase usecase_blocklist.IBlocklistUseCase,
+) *BlocklistHandler {
+	return &BlocklistHandler{parser, presenter, blocklistUseCase}
+}
+
+func (h *BlocklistHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/blocklist", h.ListEntries)
+	app.Post("/blocklist", h.AddEntry)
+	app.Get("/blocklist/audits", h.ListAudits)
+	app.Delete("/blocklist/:id", h.RemoveEntry)
+}
+
+func (h *BlocklistHandler) ListEntries(c *fiber.Ctx) error {
+	var req entity.BlocklistListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	entries, meta, err := h.blocklistUseCase.ListEntries(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, entries, meta, "Blocklist entries successfully retrieved", http.StatusOK)
+}
+
+// AddEntry and RemoveEntry are audited under the client ID the signature was verified for
+func (h *BlocklistHandler) AddEntry(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	caller, err := h.parser.ParserCaller(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	var req entity.BlocklistEntryRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.Actor = caller.ClientID
+
+	entry, err := h.blocklistUseCase.AddEntry(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, entry, "Blocklist entry successfully added", http.StatusCreated)
+}
+
+func (h *BlocklistHandler) RemoveEntry(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	caller, err := h.parser.ParserCaller(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	if err := h.blocklistUseCase.RemoveEntry(c.Context(), &entity.BlocklistRemoveRequest{
+		ID:    uint64(id),
+		Actor: caller.ClientID,
+	}); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, nil, "Blocklist entry successfully removed", http.StatusOK)
+}
+
+func (h *BlocklistHandler) ListAudits(c *fiber.Ctx) error {
+	var req entity.BlocklistListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	audits, meta, err := h.blocklistUseCase.ListAudits(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, audits, meta, "Blocklist audit trail successfully retrieved", http.StatusOK)
+}
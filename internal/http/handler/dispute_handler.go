@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_dispute "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/dispute"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/dispute/entity"
+)
+
+type DisputeHandler struct {
+	parser         parser.Parser
+	presenter      json.JsonPresenter
+	disputeUseCase usecase_dispute.IDisputeUseCase
+}
+
+func NewDisputeHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	disputeUseCase usecase_dispute.IDisputeUseCase,
+) *DisputeHandler {
+	return &DisputeHandler{parser, presenter, disputeUseCase}
+}
+
+// Register routes, disputes are raised and resolved by backoffice accounts while
+// the merchant responds to them and uploads evidence
+func (h *DisputeHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Post("/disputes", h.CreateDispute)
+	app.Get("/disputes/:id", h.GetDispute)
+	app.Post("/disputes/:id/response", h.RespondDispute)
+	app.Post("/disputes/:id/resolve", h.ResolveDispute)
+	app.Post("/disputes/:id/evidences", h.AddEvidence)
+	app.Get("/disputes/:id/evidences/:evidence_id", h.DownloadEvidence)
+	app.Get("/merchants/:id/disputes", h.ListMerchantDisputes)
+}
+
+func (h *DisputeHandler) CreateDispute(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.DisputeRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	dispute, err := h.disputeUseCase.CreateDispute(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, dispute, "Dispute successfully created", http.StatusCreated)
+}
+
+func (h *DisputeHandler) GetDispute(c *fiber.Ctx) error {
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	dispute, err := h.disputeUseCase.GetDispute(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	if err := h.parser.ParserMerchantScope(c, dispute.MerchantID); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, dispute, "Dispute successfully retrieved", http.StatusOK)
+}
+
+func (h *DisputeHandler) RespondDispute(c *fiber.Ctx) error {
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	if err := h.authorizeDispute(c, uint64(id)); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.DisputeResponseRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.ID = uint64(id)
+
+	dispute, err := h.disputeUseCase.RespondDispute(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, dispute, "Dispute response successfully submitted", http.StatusOK)
+}
+
+func (h *DisputeHandler) ResolveDispute(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.DisputeResolveRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.ID = uint64(id)
+
+	dispute, err := h.disputeUseCase.ResolveDispute(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, dispute, "Dispute successfully resolved", http.StatusOK)
+}
+
+// AddEvidence takes the evidence as the multipart field "file" with an optional "description"
+func (h *DisputeHandler) AddEvidence(c *fiber.Ctx) error {
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	if err := h.authorizeDispute(c, uint64(id)); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return h.presenter.BuildError(c, apperr.ErrInvalidRequest())
+	}
+	file, err := header.Open()
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	defer file.Close()
+
+	req := entity.EvidenceRequest{
+		DisputeID:   uint64(id),
+		FileName:    header.Filename,
+		ContentType: header.Header.Get(fiber.HeaderContentType),
+		Size:        header.Size,
+		Description: c.FormValue("description"),
+	}
+
+	evidence, err := h.disputeUseCase.AddEvidence(c.Context(), &req, file)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, evidence, "Dispute evidence successfully uploaded", http.StatusCreated)
+}
+
+func (h *DisputeHandler) DownloadEvidence(c *fiber.Ctx) error {
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	if err := h.authorizeDispute(c, uint64(id)); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	evidenceID, err := h.parser.ParserIntFromPathParams(c, "evidence_id")
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	file, err := h.disputeUseCase.GetEvidenceFile(c.Context(), uint64(id), uint64(evidenceID))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return c.Download(file.Path, file.Filename)
+}
+
+func (h *DisputeHandler) ListMerchantDisputes(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.DisputeListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+
+	disputes, meta, err := h.disputeUseCase.ListMerchantDisputes(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, disputes, meta, "Merchant disputes successfully retrieved", http.StatusOK)
+}
+
+// authorizeDispute checks the signed account may act on the merchant the dispute is against
+func (h *DisputeHandler) authorizeDispute(c *fiber.Ctx, id uint64) error {
+	dispute, err := h.disputeUseCase.GetDispute(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return h.parser.ParserMerchantScope(c, dispute.MerchantID)
+}
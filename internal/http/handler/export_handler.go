@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_export "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/export"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/export/entity"
+)
+
+type ExportHandler struct {
+	parser        parser.Parser
+	presenter     json.JsonPresenter
+	exportUseCase usecase_export.IExportUseCase
+}
+
+func NewExportHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	exportUseCase usecase_export.IExportUseCase,
+) *ExportHandler {
+	return &ExportHandler{parser, presenter, exportUseCase}
+}
+
+func (h *ExportHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/exports/:id/download", h.DownloadExport)
+}
+
+// RegisterSigned registers the routes that must come after the signature check
+func (h *ExportHandler) RegisterSigned(app fiber.Router) {
+	app.Post("/merchants/:id/exports", h.CreateExport)
+	app.Get("/merchants/:id/exports/:export_id", h.GetExport)
+}
+
+func (h *ExportHandler) CreateExport(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.ExportRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+
+	export, err := h.exportUseCase.CreateExport(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, export, "Export successfully queued", http.StatusAccepted)
+}
+
+func (h *ExportHandler) GetExport(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	exportID, err := h.parser.ParserIntFromPathParams(c, "export_id")
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	export, err := h.exportUseCase.GetExport(c.Context(), uint64(id), uint64(exportID))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, export, "Export successfully retrieved", http.StatusOK)
+}
+
+// DownloadExport serves a finished export file. The link carries its own expiry
+// and signature, so it works without the account signature headers.
+func (h *ExportHandler) DownloadExport(c *fiber.Ctx) error {
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.ExportDownloadRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	file, err := h.exportUseCase.GetDownloadFile(c.Context(), uint64(id), req.Expires, req.Signature)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return c.Download(file.Path, file.Filename)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_fraud "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud/entity"
+)
+
+type FraudHandler struct {
+	parser       parser.Parser
+	presenter    json.JsonPresenter
+	fraudUseCase usecase_fraud.IFraudUseCase
+}
+
+func NewFraudHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	fraudUseCase usecase_fraud.IFraudUseCase,
+) *FraudHandler {
+	return &FraudHandler{parser, presenter, fraudUseCase}
+}
+
+func (h *FraudHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/fraud/rules", h.ListRules)
+	app.Put("/fraud/rules/:id", h.UpdateRule)
+	app.Get("/fraud/decisions", h.ListDecisions)
+	app.Get("/fraud/decisions/:id", h.GetDecision)
+	app.Post("/fraud/decisions/:id/review", h.ReviewDecision)
+}
+
+func (h *FraudHandler) ListRules(c *fiber.Ctx) error {
+	rules, err := h.fraudUseCase.ListRules(c.Context())
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, rules, "Fraud rules successfully retrieved", http.StatusOK)
+}
+
+func (h *FraudHandler) UpdateRule(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.FraudRuleUpdateRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.ID = uint64(id)
+
+	rule, err := h.fraudUseCase.UpdateRule(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, rule, "Fraud rule successfully updated", http.StatusOK)
+}
+
+// ListDecisions with decision=review and review_status=pending is the review queue
+func (h *FraudHandler) ListDecisions(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	var req entity.FraudDecisionListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	decisions, meta, err := h.fraudUseCase.ListDecisions(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, decisions, meta, "Fraud decisions successfully retrieved", http.StatusOK)
+}
+
+func (h *FraudHandler) GetDecision(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	decision, err := h.fraudUseCase.GetDecision(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, decision, "Fraud decision successfully retrieved", http.StatusOK)
+}
+
+func (h *FraudHandler) ReviewDecision(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.FraudReviewRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.ID = uint64(id)
+
+	decision, err := h.fraudUseCase.ReviewDecision(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, decision, "Fraud decision successfully reviewed", http.StatusOK)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_ledger "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/ledger"
+)
+
+type LedgerHandler struct {
+	parser        parser.Parser
+	presenter     json.JsonPresenter
+	ledgerUseCase usecase_ledger.ILedgerUseCase
+}
+
+func NewLedgerHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	ledgerUseCase usecase_ledger.ILedgerUseCase,
+) *LedgerHandler {
+	return &LedgerHandler{parser, presenter, ledgerUseCase}
+}
+
+func (h *LedgerHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/merchants/:id/balance", h.GetMerchantBalance)
+}
+
+func (h *LedgerHandler) GetMerchantBalance(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	balance, err := h.ledgerUseCase.GetMerchantBalance(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, balance, "Merchant balance successfully retrieved", http.StatusOK)
+}
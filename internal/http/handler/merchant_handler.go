@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
 	usecase_merchant "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/merchant"
@@ -11,6 +12,7 @@ import (
 	usecase_qr "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/qr"
 	qrEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/qr/entity"
 	usecase_transaction "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/transaction"
+	transactionEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/transaction/entity"
 )
 
 type MerchantHandler struct {
@@ -34,11 +36,21 @@ func NewMerchantHandler(
 func (h *MerchantHandler) Register(app fiber.Router) {
 	// Define your routes here
 	app.Get("/merchants/:id", h.GetMerchantByID)
+	app.Get("/merchants/:id/transactions", h.GetMerchantTransactions)
+	app.Post("/merchants/:id/qr", h.CreateQRForMerchant)
+}
+
+// RegisterSigned registers the routes that must come after the signature check
+func (h *MerchantHandler) RegisterSigned(app fiber.Router) {
+	app.Get("/merchants", h.ListMerchants)
 	app.Post("/merchants", h.CreateMerchant)
 	app.Put("/merchants/:id", h.UpdateMerchant)
 	app.Delete("/merchants/:id", h.DeleteMerchant)
-	app.Get("/merchants/:id/transactions", h.GetMerchantTransactions)
-	app.Post("/merchants/:id/qr", h.CreateQRForMerchant)
+	app.Post("/merchants/:id/restore", h.RestoreMerchant)
+	app.Get("/merchants/:id/summary", h.GetMerchantSummary)
+	app.Get("/merchants/:id/children", h.ListChildren)
+	app.Put("/merchants/:id/parent", h.SetParent)
+	app.Delete("/merchants/:id/parent", h.RemoveParent)
 }
 
 func (h *MerchantHandler) GetMerchantByID(c *fiber.Ctx) error {
@@ -52,10 +64,38 @@ func (h *MerchantHandler) GetMerchantByID(c *fiber.Ctx) error {
 		return h.presenter.BuildError(c, err)
 	}
 
+	c.Set(fiber.HeaderETag, helper.FormatETag(merchant.Version))
 	return h.presenter.BuildSuccess(c, merchant, "Merchant successfully retrieved", http.StatusOK)
 }
 
+// ListMerchants lists every merchant for a backoffice account, other accounts only see
+// their merchant and its children
+func (h *MerchantHandler) ListMerchants(c *fiber.Ctx) error {
+	caller, err := h.parser.ParserCaller(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.MerchantListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	if !caller.Backoffice {
+		req.MerchantIDs = append([]uint64{caller.MerchantID}, caller.ChildIDs...)
+	}
+
+	merchants, meta, err := h.merchantUseCase.ListMerchants(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, merchants, meta, "Merchants successfully retrieved", http.StatusOK)
+}
+
 func (h *MerchantHandler) CreateMerchant(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
 	var req entity.MerchantRequest
 	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
 		return h.presenter.BuildError(c, err)
@@ -66,11 +106,16 @@ func (h *MerchantHandler) CreateMerchant(c *fiber.Ctx) error {
 		return h.presenter.BuildError(c, err)
 	}
 
+	c.Set(fiber.HeaderETag, helper.FormatETag(merchant.Version))
 	return h.presenter.BuildSuccess(c, merchant, "Merchant successfully created", http.StatusCreated)
 }
 
 func (h *MerchantHandler) UpdateMerchant(c *fiber.Ctx) error {
-	id, err := h.parser.ParserIntIDFromPathParams(c)
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	version, err := h.parser.ParserIfMatch(c)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
@@ -78,17 +123,19 @@ func (h *MerchantHandler) UpdateMerchant(c *fiber.Ctx) error {
 	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
 		return h.presenter.BuildError(c, err)
 	}
+	req.Version = version
 
 	merchant, err := h.merchantUseCase.UpdateMerchant(c.Context(), uint64(id), &req)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
 
+	c.Set(fiber.HeaderETag, helper.FormatETag(merchant.Version))
 	return h.presenter.BuildSuccess(c, merchant, "Merchant successfully updated", http.StatusOK)
 }
 
 func (h *MerchantHandler) DeleteMerchant(c *fiber.Ctx) error {
-	id, err := h.parser.ParserIntIDFromPathParams(c)
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
@@ -100,18 +147,105 @@ func (h *MerchantHandler) DeleteMerchant(c *fiber.Ctx) error {
 	return h.presenter.BuildSuccess(c, nil, "Merchant successfully deleted", http.StatusOK)
 }
 
+func (h *MerchantHandler) RestoreMerchant(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	merchant, err := h.merchantUseCase.RestoreMerchant(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, helper.FormatETag(merchant.Version))
+	return h.presenter.BuildSuccess(c, merchant, "Merchant successfully restored", http.StatusOK)
+}
+
+func (h *MerchantHandler) ListChildren(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	children, err := h.merchantUseCase.ListChildren(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, children, "Child merchants successfully retrieved", http.StatusOK)
+}
+
+func (h *MerchantHandler) SetParent(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	version, err := h.parser.ParserIfMatch(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.MerchantParentRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.ID = uint64(id)
+	req.Version = version
+	// The account must own the parent as well, or it could attach its merchant to any corporate
+	if err := h.parser.ParserMerchantScope(c, req.ParentID); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	merchant, err := h.merchantUseCase.SetParent(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, helper.FormatETag(merchant.Version))
+	return h.presenter.BuildSuccess(c, merchant, "Merchant parent successfully set", http.StatusOK)
+}
+
+func (h *MerchantHandler) RemoveParent(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	version, err := h.parser.ParserIfMatch(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	merchant, err := h.merchantUseCase.RemoveParent(c.Context(), uint64(id), version)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, helper.FormatETag(merchant.Version))
+	return h.presenter.BuildSuccess(c, merchant, "Merchant parent successfully removed", http.StatusOK)
+}
+
 func (h *MerchantHandler) GetMerchantTransactions(c *fiber.Ctx) error {
 	id, err := h.parser.ParserIntIDFromPathParams(c)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
 
-	transactions, err := h.transactionUseCase.GetTransactionsByMerchantID(c.Context(), uint64(id))
+	var req transactionEntity.TransactionSearchRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+
+	transactions, meta, err := h.transactionUseCase.SearchTransactions(c.Context(), &req)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
 
-	return h.presenter.BuildSuccess(c, transactions, "Merchant transactions successfully retrieved", http.StatusOK)
+	return h.presenter.BuildSuccessWithMeta(c, transactions, meta, "Merchant transactions successfully retrieved", http.StatusOK)
 }
 
 func (h *MerchantHandler) CreateQRForMerchant(c *fiber.Ctx) error {
@@ -125,6 +259,7 @@ func (h *MerchantHandler) CreateQRForMerchant(c *fiber.Ctx) error {
 		return h.presenter.BuildError(c, err)
 	}
 	req.MerchantID = uint64(id)
+	req.ClientIP = c.IP()
 	qr, err := h.qrUseCase.GenerateQR(c.Context(), req)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
@@ -132,3 +267,23 @@ func (h *MerchantHandler) CreateQRForMerchant(c *fiber.Ctx) error {
 
 	return h.presenter.BuildSuccess(c, qr, "QR code successfully created", http.StatusCreated)
 }
+
+func (h *MerchantHandler) GetMerchantSummary(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req transactionEntity.TransactionSummaryRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+
+	summary, err := h.transactionUseCase.GetMerchantSummary(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, summary, "Merchant summary successfully retrieved", http.StatusOK)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_importer "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/importer"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/importer/entity"
+)
+
+type MerchantImportHandler struct {
+	parser        parser.Parser
+	presenter     json.JsonPresenter
+	importUseCase usecase_importer.IImportUseCase
+}
+
+func NewMerchantImportHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	importUseCase usecase_importer.IImportUseCase,
+) *MerchantImportHandler {
+	return &MerchantImportHandler{parser, presenter, importUseCase}
+}
+
+func (h *MerchantImportHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Post("/merchants/imports", h.CreateImport)
+	app.Get("/merchants/imports/:id", h.GetImport)
+	app.Get("/merchants/imports/:id/report", h.DownloadReport)
+}
+
+// CreateImport queues the uploaded CSV under the client ID the request was signed with
+func (h *MerchantImportHandler) CreateImport(c *fiber.Ctx) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return h.presenter.BuildError(c, apperr.ErrInvalidRequest())
+	}
+	file, err := header.Open()
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	defer file.Close()
+
+	req := entity.ImportRequest{
+		FileName:  header.Filename,
+		Size:      header.Size,
+		CreatedBy: c.Get("X-Client-ID"),
+	}
+
+	merchantImport, err := h.importUseCase.CreateImport(c.Context(), &req, file)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, merchantImport, "Merchant import successfully queued", http.StatusAccepted)
+}
+
+func (h *MerchantImportHandler) GetImport(c *fiber.Ctx) error {
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	merchantImport, err := h.importUseCase.GetImport(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, merchantImport, "Merchant import successfully retrieved", http.StatusOK)
+}
+
+func (h *MerchantImportHandler) DownloadReport(c *fiber.Ctx) error {
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	file, err := h.importUseCase.GetReportFile(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return c.Download(file.Path, file.Filename)
+}
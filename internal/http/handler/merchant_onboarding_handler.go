@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_onboarding "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/onboarding"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/onboarding/entity"
+)
+
+type MerchantOnboardingHandler struct {
+	parser            parser.Parser
+	presenter         json.JsonPresenter
+	onboardingUseCase usecase_onboarding.IOnboardingUseCase
+}
+
+func NewMerchantOnboardingHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	onboardingUseCase usecase_onboarding.IOnboardingUseCase,
+) *MerchantOnboardingHandler {
+	return &MerchantOnboardingHandler{parser, presenter, onboardingUseCase}
+}
+
+func (h *MerchantOnboardingHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Post("/merchants/:id/documents", h.UploadDocument)
+	app.Get("/merchants/:id/documents", h.ListDocuments)
+	app.Get("/merchants/:id/documents/:document_id", h.DownloadDocument)
+	app.Post("/merchants/:id/status", h.ChangeStatus)
+	app.Get("/merchants/:id/reviews", h.ListReviews)
+}
+
+// UploadDocument takes the document as the multipart field "file" with its "type",
+// one of ktp, npwp or store_photo
+func (h *MerchantOnboardingHandler) UploadDocument(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return h.presenter.BuildError(c, apperr.ErrInvalidRequest())
+	}
+	file, err := header.Open()
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	defer file.Close()
+
+	req := entity.DocumentRequest{
+		MerchantID:  uint64(id),
+		Type:        c.FormValue("type"),
+		FileName:    header.Filename,
+		ContentType: header.Header.Get(fiber.HeaderContentType),
+		Size:        header.Size,
+	}
+
+	document, err := h.onboardingUseCase.UploadDocument(c.Context(), &req, file)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, document, "Merchant document successfully uploaded", http.StatusCreated)
+}
+
+func (h *MerchantOnboardingHandler) ListDocuments(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	documents, err := h.onboardingUseCase.ListDocuments(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, documents, "Merchant documents successfully retrieved", http.StatusOK)
+}
+
+func (h *MerchantOnboardingHandler) DownloadDocument(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	documentID, err := h.parser.ParserIntFromPathParams(c, "document_id")
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	file, err := h.onboardingUseCase.GetDocumentFile(c.Context(), uint64(id), uint64(documentID))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return c.Download(file.Path, file.Filename)
+}
+
+// ChangeStatus is recorded under the client ID of the verified account, so approvals
+// can be traced to the backoffice user who made them. A merchant's own account may
+// only submit it for review.
+func (h *MerchantOnboardingHandler) ChangeStatus(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	caller, err := h.parser.ParserCaller(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.StatusRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+	req.Actor = caller.ClientID
+	req.Backoffice = caller.Backoffice
+
+	status, err := h.onboardingUseCase.ChangeStatus(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, status, "Merchant status successfully changed", http.StatusOK)
+}
+
+func (h *MerchantOnboardingHandler) ListReviews(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	reviews, err := h.onboardingUseCase.ListReviews(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, reviews, "Merchant reviews successfully retrieved", http.StatusOK)
+}
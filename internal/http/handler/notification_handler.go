@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_transaction "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/transaction"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/transaction/entity"
+)
+
+// NotificationHandler receives the callbacks of network participants. The switch reads
+// the response code of the body, so responses skip the usual presenter envelope.
+type NotificationHandler struct {
+	parser    parser.Parser
+	presenter json.JsonPresenter
+	usecase   usecase_transaction.ITransactionUseCase
+}
+
+func NewNotificationHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	usecase usecase_transaction.ITransactionUseCase,
+) *NotificationHandler {
+	return &NotificationHandler{parser, presenter, usecase}
+}
+
+func (h *NotificationHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Post("/payment", h.NotifyPayment)
+}
+
+func (h *NotificationHandler) NotifyPayment(c *fiber.Ctx) error {
+	var req entity.PaymentNotificationRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(&entity.PaymentNotificationResponse{
+			ResponseCode:    entity.NotificationFormatError,
+			ResponseMessage: "Format error",
+		})
+	}
+
+	response, err := h.usecase.NotifyPayment(c.Context(), &req)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(&entity.PaymentNotificationResponse{
+			ResponseCode:    entity.NotificationSystemError,
+			ResponseMessage: "System malfunction",
+			RefID:           req.RefID,
+			BillingID:       req.BillingID,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
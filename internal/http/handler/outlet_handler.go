@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_outlet "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/outlet"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/outlet/entity"
+)
+
+type OutletHandler struct {
+	parser        parser.Parser
+	presenter     json.JsonPresenter
+	outletUseCase usecase_outlet.IOutletUseCase
+}
+
+func NewOutletHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	outletUseCase usecase_outlet.IOutletUseCase,
+) *OutletHandler {
+	return &OutletHandler{parser, presenter, outletUseCase}
+}
+
+func (h *OutletHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/merchants/:id/outlets", h.ListOutlets)
+	app.Post("/merchants/:id/outlets", h.CreateOutlet)
+	app.Put("/merchants/:id/outlets/:outlet_id", h.UpdateOutlet)
+	app.Post("/merchants/:id/outlets/:outlet_id/terminals", h.CreateTerminal)
+	app.Get("/merchants/:id/terminals", h.ListTerminals)
+	app.Post("/merchants/:id/terminals/:terminal_id/bind", h.BindTerminal)
+	app.Post("/merchants/:id/terminals/:terminal_id/unbind", h.UnbindTerminal)
+	app.Post("/merchants/:id/terminals/:terminal_id/block", h.BlockTerminal)
+}
+
+func (h *OutletHandler) ListOutlets(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	outlets, err := h.outletUseCase.ListOutlets(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, outlets, "Outlets successfully retrieved", http.StatusOK)
+}
+
+func (h *OutletHandler) CreateOutlet(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.OutletRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+
+	outlet, err := h.outletUseCase.CreateOutlet(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, outlet, "Outlet successfully created", http.StatusCreated)
+}
+
+func (h *OutletHandler) UpdateOutlet(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	outletID, err := h.parser.ParserIntFromPathParams(c, "outlet_id")
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.OutletRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.ID = uint64(outletID)
+	req.MerchantID = uint64(id)
+
+	outlet, err := h.outletUseCase.UpdateOutlet(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, outlet, "Outlet successfully updated", http.StatusOK)
+}
+
+func (h *OutletHandler) CreateTerminal(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	outletID, err := h.parser.ParserIntFromPathParams(c, "outlet_id")
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.TerminalRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+	req.OutletID = uint64(outletID)
+
+	terminal, err := h.outletUseCase.CreateTerminal(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, terminal, "Terminal successfully created", http.StatusCreated)
+}
+
+func (h *OutletHandler) ListTerminals(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.TerminalListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+
+	terminals, err := h.outletUseCase.ListTerminals(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, terminals, "Terminals successfully retrieved", http.StatusOK)
+}
+
+// BindTerminal, UnbindTerminal and BlockTerminal take the TID of the terminal as :terminal_id
+func (h *OutletHandler) BindTerminal(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.TerminalBindRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+	req.TerminalID = c.Params("terminal_id")
+
+	terminal, err := h.outletUseCase.BindTerminal(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, terminal, "Terminal successfully bound", http.StatusOK)
+}
+
+func (h *OutletHandler) UnbindTerminal(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	terminal, err := h.outletUseCase.UnbindTerminal(c.Context(), uint64(id), c.Params("terminal_id"))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, terminal, "Terminal successfully unbound", http.StatusOK)
+}
+
+func (h *OutletHandler) BlockTerminal(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	terminal, err := h.outletUseCase.BlockTerminal(c.Context(), uint64(id), c.Params("terminal_id"))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, terminal, "Terminal successfully blocked", http.StatusOK)
+}
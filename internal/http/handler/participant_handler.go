@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_participant "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/participant"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/participant/entity"
+)
+
+type ParticipantHandler struct {
+	parser             parser.Parser
+	presenter          json.JsonPresenter
+	participantUseCase usecase_participant.IParticipantUseCase
+}
+
+func NewParticipantHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	participantUseCase usecase_participant.IParticipantUseCase,
+) *ParticipantHandler {
+	return &ParticipantHandler{parser, presenter, participantUseCase}
+}
+
+func (h *ParticipantHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/participants", h.ListParticipants)
+	app.Get("/participants/:id", h.GetParticipant)
+	app.Post("/participants", h.CreateParticipant)
+	app.Put("/participants/:id", h.UpdateParticipant)
+	app.Delete("/participants/:id", h.DeleteParticipant)
+	app.Post("/participants/:id/credentials", h.RotateCredentials)
+}
+
+func (h *ParticipantHandler) ListParticipants(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	var req entity.ParticipantListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	participants, meta, err := h.participantUseCase.ListParticipants(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, participants, meta, "Participants successfully retrieved", http.StatusOK)
+}
+
+func (h *ParticipantHandler) GetParticipant(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	participant, err := h.participantUseCase.GetParticipant(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, participant, "Participant successfully retrieved", http.StatusOK)
+}
+
+func (h *ParticipantHandler) CreateParticipant(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	var req entity.ParticipantRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	participant, err := h.participantUseCase.CreateParticipant(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, participant, "Participant successfully created", http.StatusCreated)
+}
+
+func (h *ParticipantHandler) UpdateParticipant(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.ParticipantUpdateRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.ID = uint64(id)
+
+	participant, err := h.participantUseCase.UpdateParticipant(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, participant, "Participant successfully updated", http.StatusOK)
+}
+
+func (h *ParticipantHandler) DeleteParticipant(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	if err := h.participantUseCase.DeleteParticipant(c.Context(), uint64(id)); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, nil, "Participant successfully deleted", http.StatusOK)
+}
+
+func (h *ParticipantHandler) RotateCredentials(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	participant, err := h.participantUseCase.RotateCredentials(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, participant, "Participant credentials successfully rotated", http.StatusOK)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_payout "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/payout"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/payout/entity"
+)
+
+type PayoutHandler struct {
+	parser        parser.Parser
+	presenter     json.JsonPresenter
+	payoutUseCase usecase_payout.IPayoutUseCase
+}
+
+func NewPayoutHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	payoutUseCase usecase_payout.IPayoutUseCase,
+) *PayoutHandler {
+	return &PayoutHandler{parser, presenter, payoutUseCase}
+}
+
+func (h *PayoutHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Post("/payout-batches", h.CreateBatch)
+	app.Get("/payout-batches/:id", h.GetBatch)
+	app.Get("/payout-batches/:id/file", h.DownloadBatchFile)
+	app.Post("/payout-batches/:id/send", h.MarkBatchSent)
+	app.Post("/payout-batches/:id/returns", h.ImportReturnFile)
+	app.Get("/merchants/:id/payouts", h.ListMerchantPayouts)
+}
+
+// CreateBatch builds the batch of the day given in the body, an empty body means today
+func (h *PayoutHandler) CreateBatch(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	var req entity.PayoutBatchRequest
+	if len(c.Body()) > 0 {
+		if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+			return h.presenter.BuildError(c, err)
+		}
+	}
+
+	batch, err := h.payoutUseCase.CreateBatch(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, batch, "Payout batch successfully created", http.StatusCreated)
+}
+
+func (h *PayoutHandler) GetBatch(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	batch, err := h.payoutUseCase.GetBatch(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, batch, "Payout batch successfully retrieved", http.StatusOK)
+}
+
+func (h *PayoutHandler) DownloadBatchFile(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	file, err := h.payoutUseCase.GetBatchFile(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return c.Download(file.Path, file.Filename)
+}
+
+func (h *PayoutHandler) MarkBatchSent(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	batch, err := h.payoutUseCase.MarkBatchSent(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, batch, "Payout batch successfully marked as sent", http.StatusOK)
+}
+
+// ImportReturnFile takes the bank return file as the multipart field "file"
+func (h *PayoutHandler) ImportReturnFile(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return h.presenter.BuildError(c, apperr.ErrInvalidRequest())
+	}
+	file, err := header.Open()
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	defer file.Close()
+
+	result, err := h.payoutUseCase.ImportReturnFile(c.Context(), uint64(id), file)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, result, "Payout return file successfully imported", http.StatusOK)
+}
+
+func (h *PayoutHandler) ListMerchantPayouts(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.PayoutListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+
+	payouts, meta, err := h.payoutUseCase.ListMerchantPayouts(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, payouts, meta, "Merchant payouts successfully retrieved", http.StatusOK)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	presenterJson "github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_qr "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/qr"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/qr/entity"
+)
+
+// sseHeartbeatInterval keeps idle streams alive behind proxies and detects closed clients
+const sseHeartbeatInterval = 15 * time.Second
+
+type QRHandler struct {
+	parser    parser.Parser
+	presenter presenterJson.JsonPresenter
+	qrUseCase usecase_qr.IQRUseCase
+}
+
+func NewQRHandler(
+	parser parser.Parser,
+	presenter presenterJson.JsonPresenter,
+	qrUseCase usecase_qr.IQRUseCase,
+) *QRHandler {
+	return &QRHandler{parser, presenter, qrUseCase}
+}
+
+func (h *QRHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/qr/:billing_id/events", h.StreamQREvents)
+}
+
+// RegisterSigned registers the routes that must come after the signature check
+func (h *QRHandler) RegisterSigned(app fiber.Router) {
+	app.Post("/qr/:billing_id/cancel", h.CancelQR)
+}
+
+// CancelQR invalidates an unpaid QR, repeating the call returns the same result
+func (h *QRHandler) CancelQR(c *fiber.Ctx) error {
+	caller, err := h.parser.ParserCaller(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	qr, err := h.qrUseCase.CancelQR(c.Context(), caller, c.Params("billing_id"))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, qr, "QR successfully cancelled", http.StatusOK)
+}
+
+// StreamQREvents pushes the payment status of a QR as Server-Sent Events.
+// The first event reports the current (pending) state, the stream closes after
+// the QR is paid, expired or cancelled.
+func (h *QRHandler) StreamQREvents(c *fiber.Ctx) error {
+	billingID := c.Params("billing_id")
+
+	// The stream outlives the request handler, so it must not use the request context
+	subscription, err := h.qrUseCase.SubscribeEvents(context.Background(), billingID)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+
+		heartbeat := time.NewTicker(sseHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		if err := writeSSEEvent(w, subscription.Current); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event, ok := <-subscription.Events:
+				if !ok {
+					return
+				}
+				if err := writeSSEEvent(w, event); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeSSEEvent(w *bufio.Writer, event *entity.QREvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, data); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
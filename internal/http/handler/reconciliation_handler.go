@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_reconciliation "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reconciliation"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reconciliation/entity"
+)
+
+type ReconciliationHandler struct {
+	parser                parser.Parser
+	presenter             json.JsonPresenter
+	reconciliationUseCase usecase_reconciliation.IReconciliationUseCase
+}
+
+func NewReconciliationHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	reconciliationUseCase usecase_reconciliation.IReconciliationUseCase,
+) *ReconciliationHandler {
+	return &ReconciliationHandler{parser, presenter, reconciliationUseCase}
+}
+
+func (h *ReconciliationHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Post("/reconciliations", h.Reconcile)
+	app.Get("/reconciliations/:id", h.GetRun)
+	app.Get("/reconciliations/:id/exceptions", h.ListExceptions)
+	app.Post("/reconciliation-exceptions/:id/resolve", h.ResolveException)
+}
+
+// Reconcile takes the settlement file as the multipart field "file" together with
+// the "format" and "settlement_date" fields
+func (h *ReconciliationHandler) Reconcile(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		return h.presenter.BuildError(c, apperr.ErrInvalidRequest())
+	}
+	file, err := header.Open()
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	defer file.Close()
+
+	req := entity.ReconciliationRequest{
+		Format:         c.FormValue("format"),
+		SettlementDate: c.FormValue("settlement_date"),
+		FileName:       header.Filename,
+	}
+
+	run, err := h.reconciliationUseCase.Reconcile(c.Context(), &req, file)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, run, "Reconciliation successfully completed", http.StatusCreated)
+}
+
+func (h *ReconciliationHandler) GetRun(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	run, err := h.reconciliationUseCase.GetRun(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, run, "Reconciliation successfully retrieved", http.StatusOK)
+}
+
+func (h *ReconciliationHandler) ListExceptions(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.ReconciliationExceptionListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.RunID = uint64(id)
+
+	exceptions, meta, err := h.reconciliationUseCase.ListExceptions(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, exceptions, meta, "Reconciliation exceptions successfully retrieved", http.StatusOK)
+}
+
+func (h *ReconciliationHandler) ResolveException(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.ResolveExceptionRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.ID = uint64(id)
+
+	exception, err := h.reconciliationUseCase.ResolveException(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, exception, "Reconciliation exception successfully resolved", http.StatusOK)
+}
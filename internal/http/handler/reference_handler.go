@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_reference "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference/entity"
+)
+
+type ReferenceHandler struct {
+	parser           parser.Parser
+	presenter        json.JsonPresenter
+	referenceUseCase usecase_reference.IReferenceUseCase
+}
+
+func NewReferenceHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	referenceUseCase usecase_reference.IReferenceUseCase,
+) *ReferenceHandler {
+	return &ReferenceHandler{parser, presenter, referenceUseCase}
+}
+
+func (h *ReferenceHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/references/mccs", h.ListMCCs)
+	app.Get("/references/regions", h.ListRegions)
+	app.Get("/references/postal-codes/:postal_code", h.LookupPostalCode)
+}
+
+func (h *ReferenceHandler) ListMCCs(c *fiber.Ctx) error {
+	var req entity.MCCListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	mccs, meta, err := h.referenceUseCase.ListMCCs(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccessWithMeta(c, mccs, meta, "MCCs successfully retrieved", http.StatusOK)
+}
+
+// ListRegions drives the cascading region dropdowns, provinces first and then the
+// children of the picked region by parent_code
+func (h *ReferenceHandler) ListRegions(c *fiber.Ctx) error {
+	var req entity.RegionListRequest
+	if err := h.parser.ParseQueryParams(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	regions, err := h.referenceUseCase.ListRegions(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, regions, "Regions successfully retrieved", http.StatusOK)
+}
+
+func (h *ReferenceHandler) LookupPostalCode(c *fiber.Ctx) error {
+	areas, err := h.referenceUseCase.LookupPostalCode(c.Context(), c.Params("postal_code"))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, areas, "Postal code successfully retrieved", http.StatusOK)
+}
@@ -32,6 +32,7 @@ func (h *TransactionHandler) Register(app fiber.Router) {
 	// Define your routes here
 	app.Get("/transactions/:id", h.GetTransactionByID)
 	app.Post("/transactions", h.CreateTransaction)
+	app.Post("/transactions/:ref_id/void", h.VoidTransaction)
 }
 
 func (h *TransactionHandler) GetTransactionByID(c *fiber.Ctx) error {
@@ -43,6 +44,9 @@ func (h *TransactionHandler) GetTransactionByID(c *fiber.Ctx) error {
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
+	if err := h.parser.ParserMerchantScope(c, transaction.MerchantID); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
 
 	return h.presenter.BuildSuccess(c, transaction, "Transaction successfully retrieved", http.StatusOK)
 }
@@ -54,6 +58,16 @@ func (h *TransactionHandler) CreateTransaction(c *fiber.Ctx) error {
 		return h.presenter.BuildError(c, err)
 	}
 
+	req.ClientIP = c.IP()
+	// Without a merchant in the body the transaction is for the merchant the
+	// credentials act on, a merchant in the body must be one they may act on
+	if merchantID, ok := c.Locals("merchant_id").(uint64); ok && req.MerchantID == 0 {
+		req.MerchantID = merchantID
+	}
+	if err := h.parser.ParserMerchantScope(c, req.MerchantID); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
 	transaction, err := h.usecase.CreateTransaction(c.Context(), &req)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
@@ -61,3 +75,22 @@ func (h *TransactionHandler) CreateTransaction(c *fiber.Ctx) error {
 
 	return h.presenter.BuildSuccess(c, transaction, "Transaction successfully created", http.StatusCreated)
 }
+
+func (h *TransactionHandler) VoidTransaction(c *fiber.Ctx) error {
+	refID := c.Params("ref_id")
+	// A transaction never moves to another merchant, so it is checked before the void
+	found, err := h.usecase.GetTransactionsByRefID(c.Context(), refID)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	if err := h.parser.ParserMerchantScope(c, found.MerchantID); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	transaction, err := h.usecase.VoidTransaction(c.Context(), refID)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, transaction, "Transaction successfully voided", http.StatusOK)
+}
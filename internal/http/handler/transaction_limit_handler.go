@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/parser"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/presenter/json"
+	usecase_limit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/limit"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/limit/entity"
+)
+
+type TransactionLimitHandler struct {
+	parser       parser.Parser
+	presenter    json.JsonPresenter
+	limitUseCase usecase_limit.ILimitUseCase
+}
+
+func NewTransactionLimitHandler(
+	parser parser.Parser,
+	presenter json.JsonPresenter,
+	limitUseCase usecase_limit.ILimitUseCase,
+) *TransactionLimitHandler {
+	return &TransactionLimitHandler{parser, presenter, limitUseCase}
+}
+
+// Register routes, a merchant may read its limits but only backoffice accounts
+// change them
+func (h *TransactionLimitHandler) Register(app fiber.Router) {
+	// Define your routes here
+	app.Get("/merchants/:id/limits", h.GetMerchantLimit)
+	app.Put("/merchants/:id/limits", h.SetMerchantLimit)
+	app.Delete("/merchants/:id/limits", h.DeleteMerchantLimit)
+	app.Get("/merchant-categories/:mcc/limits", h.GetCategoryLimit)
+	app.Put("/merchant-categories/:mcc/limits", h.SetCategoryLimit)
+	app.Delete("/merchant-categories/:mcc/limits", h.DeleteCategoryLimit)
+}
+
+func (h *TransactionLimitHandler) GetMerchantLimit(c *fiber.Ctx) error {
+	id, err := h.parser.ParserScopedIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	limit, err := h.limitUseCase.GetMerchantLimit(c.Context(), uint64(id))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, limit, "Merchant limits successfully retrieved", http.StatusOK)
+}
+
+func (h *TransactionLimitHandler) SetMerchantLimit(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var req entity.TransactionLimitRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MerchantID = uint64(id)
+
+	limit, err := h.limitUseCase.SetMerchantLimit(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, limit, "Merchant limits successfully saved", http.StatusOK)
+}
+
+func (h *TransactionLimitHandler) DeleteMerchantLimit(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	id, err := h.parser.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	if err := h.limitUseCase.DeleteMerchantLimit(c.Context(), uint64(id)); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, nil, "Merchant limits successfully deleted", http.StatusOK)
+}
+
+func (h *TransactionLimitHandler) GetCategoryLimit(c *fiber.Ctx) error {
+	limit, err := h.limitUseCase.GetCategoryLimit(c.Context(), c.Params("mcc"))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, limit, "Merchant category limits successfully retrieved", http.StatusOK)
+}
+
+func (h *TransactionLimitHandler) SetCategoryLimit(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	var req entity.TransactionLimitRequest
+	if err := h.parser.ParserBodyRequest(c, &req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.MCC = c.Params("mcc")
+
+	limit, err := h.limitUseCase.SetCategoryLimit(c.Context(), &req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, limit, "Merchant category limits successfully saved", http.StatusOK)
+}
+
+func (h *TransactionLimitHandler) DeleteCategoryLimit(c *fiber.Ctx) error {
+	if err := h.parser.ParserBackoffice(c); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	if err := h.limitUseCase.DeleteCategoryLimit(c.Context(), c.Params("mcc")); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, nil, "Merchant category limits successfully deleted", http.StatusOK)
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+)
+
+// AuditActor keeps who makes the request for the audit trail. Requests start as
+// anonymous; on signed routes the signature check replaces it with the verified
+// client. A caller-supplied name is never trusted.
+func AuditActor(c *fiber.Ctx) error {
+	c.Locals(usecase_audit.ActorKey, entity.Actor{ID: anonymousActor, IP: c.IP()})
+	return c.Next()
+}
+
+// anonymousActor is recorded for requests made without credentials
+const anonymousActor = "anonymous"
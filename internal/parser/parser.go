@@ -3,7 +3,9 @@ package parser
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/kharisma-wardhana/final-project-spe-academy/entity"
 	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
 
@@ -26,6 +28,9 @@ type WithMerchantID interface {
 	SetMerchantID(string)
 }
 
+// CallerKey is the local the signature check keeps the verified account under
+const CallerKey = "caller"
+
 type BodyRequest interface{}
 type QueryParamsRequest interface{}
 
@@ -37,6 +42,9 @@ type Parser interface {
 	// ParserIntIDFromPathParams extracts an integer ID from the request path parameters
 	ParserIntIDFromPathParams(c *fiber.Ctx) (int64, error)
 
+	// ParserIntFromPathParams extracts an integer from the named path parameter
+	ParserIntFromPathParams(c *fiber.Ctx, key string) (int64, error)
+
 	// ParserBodyRequest parses the request body into the provided struct and returns an error if parsing fails.
 	ParserBodyRequest(c *fiber.Ctx, req BodyRequest) error
 
@@ -56,6 +64,23 @@ type Parser interface {
 
 	// ParserMerchantID extracts the merchant ID from the request context
 	ParserMerchantID(c *fiber.Ctx) (string, error)
+
+	// ParserCaller returns the account a signed request was made with
+	ParserCaller(c *fiber.Ctx) (*entity.Caller, error)
+
+	// ParserMerchantScope returns an error unless the signed account may act on the merchant
+	ParserMerchantScope(c *fiber.Ctx, merchantID uint64) error
+
+	// ParserBackoffice returns an error unless the request was signed by a backoffice account
+	ParserBackoffice(c *fiber.Ctx) error
+
+	// ParserScopedIDFromPathParams extracts the merchant ID from the request path parameters
+	// and checks the signed account may act on it
+	ParserScopedIDFromPathParams(c *fiber.Ctx) (int64, error)
+
+	// ParserIfMatch extracts the version an update is made against from the If-Match header.
+	// "*" matches any version and is returned as zero.
+	ParserIfMatch(c *fiber.Ctx) (uint64, error)
 }
 
 type RequestParser struct {
@@ -90,6 +115,17 @@ func (p *RequestParser) ParserIntIDFromPathParams(c *fiber.Ctx) (int64, error) {
 	return helper.ToInt64(ID), nil
 }
 
+// Get int64 from a named path param
+func (p *RequestParser) ParserIntFromPathParams(c *fiber.Ctx, key string) (int64, error) {
+	value := c.Params(key)
+
+	if value == "" {
+		return 0, fmt.Errorf("PATH PARAM %s EMPTY", strings.ToUpper(key))
+	}
+
+	return helper.ToInt64(value), nil
+}
+
 // Get request body and parse to struct
 func (p *RequestParser) ParserBodyRequest(c *fiber.Ctx, req BodyRequest) error {
 	body := c.Body()
@@ -160,3 +196,75 @@ func (p *RequestParser) ParserMerchantID(c *fiber.Ctx) (string, error) {
 
 	return merchantID, nil
 }
+
+// ParserIfMatch extracts the version from the If-Match header, an update without one
+// could silently overwrite a concurrent change so the header is required
+func (p *RequestParser) ParserIfMatch(c *fiber.Ctx) (uint64, error) {
+	ifMatch := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+
+	if ifMatch == "" {
+		return 0, apperr.ErrVersionRequired()
+	}
+	if ifMatch == "*" {
+		return 0, nil
+	}
+
+	version, ok := helper.ParseETag(ifMatch)
+	if !ok {
+		return 0, apperr.ErrStaleVersion()
+	}
+
+	return version, nil
+}
+
+// ParserCaller returns the account the signature check verified the request for.
+// Routes registered before the check have none.
+func (p *RequestParser) ParserCaller(c *fiber.Ctx) (*entity.Caller, error) {
+	caller, ok := c.Locals(CallerKey).(*entity.Caller)
+	if !ok {
+		return nil, apperr.ErrInvalidSignature()
+	}
+
+	return caller, nil
+}
+
+// ParserMerchantScope checks the signed account owns the merchant, or is a backoffice
+// account or the corporate parent of it
+func (p *RequestParser) ParserMerchantScope(c *fiber.Ctx, merchantID uint64) error {
+	caller, err := p.ParserCaller(c)
+	if err != nil {
+		return err
+	}
+	if !caller.CanActOn(merchantID) {
+		return apperr.ErrForbidden()
+	}
+
+	return nil
+}
+
+// ParserBackoffice checks the signed account is a backoffice account, for changes
+// that are not the merchant's to make
+func (p *RequestParser) ParserBackoffice(c *fiber.Ctx) error {
+	caller, err := p.ParserCaller(c)
+	if err != nil {
+		return err
+	}
+	if !caller.Backoffice {
+		return apperr.ErrForbidden()
+	}
+
+	return nil
+}
+
+// Get merchant ID int64 from Path param, scoped to the signed account
+func (p *RequestParser) ParserScopedIDFromPathParams(c *fiber.Ctx) (int64, error) {
+	ID, err := p.ParserIntIDFromPathParams(c)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.ParserMerchantScope(c, uint64(ID)); err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
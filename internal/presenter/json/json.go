@@ -21,12 +21,14 @@ func NewJsonPresenter() *Json {
 
 type JsonPresenter interface {
 	BuildSuccess(c *fiber.Ctx, data interface{}, message string, code int) error
+	BuildSuccessWithMeta(c *fiber.Ctx, data interface{}, meta interface{}, message string, code int) error
 	BuildError(c *fiber.Ctx, err error) error
 }
 
 // SuccessBody is used to define success response body data structure
 type ResponseBody struct {
 	Data    interface{} `json:"data,omitempty"`
+	Meta    interface{} `json:"meta,omitempty"`
 	Message string      `json:"message,omitempty"`
 	Code    string      `json:"code"`
 }
@@ -41,6 +43,18 @@ func (p *Json) BuildSuccess(c *fiber.Ctx, data interface{}, message string, code
 	return c.JSON(response)
 }
 
+// BuildSuccessWithMeta is BuildSuccess for list responses, meta holds the pagination details
+func (p *Json) BuildSuccessWithMeta(c *fiber.Ctx, data interface{}, meta interface{}, message string, code int) error {
+	response := &ResponseBody{
+		Data:    data,
+		Meta:    meta,
+		Message: message,
+		Code:    entity.SUCCESS_CODE,
+	}
+
+	return c.JSON(response)
+}
+
 func (p *Json) BuildError(c *fiber.Ctx, err error) error {
 	unwrappedErr := errors.Unwrap(err)
 
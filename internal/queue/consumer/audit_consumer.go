@@ -0,0 +1,56 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+
+	mongoRepo "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mongodb"
+	moentity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mongodb/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+)
+
+type AuditQueue struct {
+	ctx            context.Context
+	auditMongoRepo mongoRepo.AuditRepository
+}
+
+type AuditConsumer interface {
+	ProcessSyncAudit(payload map[string]interface{}) error
+}
+
+func NewAuditConsumer(
+	ctx context.Context,
+	auditMongoRepo mongoRepo.AuditRepository,
+) AuditConsumer {
+	return &AuditQueue{ctx, auditMongoRepo}
+}
+
+func (a *AuditQueue) ProcessSyncAudit(payload map[string]interface{}) error {
+	var event entity.AuditEvent
+	if err := event.LoadFromMap(payload); err != nil {
+		return err
+	}
+
+	changes := make(map[string]moentity.AuditChange, len(event.Changes))
+	for name, change := range event.Changes {
+		changes[name] = moentity.AuditChange{Before: change.Before, After: change.After}
+	}
+
+	// The time of the change is kept, not the time the worker got to it
+	err := a.auditMongoRepo.Create(a.ctx, moentity.AuditCollection{
+		Actor:      event.Actor,
+		IP:         event.IP,
+		Action:     event.Action,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		Changes:    changes,
+		Created:    event.CreatedAt,
+	})
+	if err != nil {
+		fmt.Println("FAILED CREATE AUDIT TO MONGODB")
+		return err
+	}
+
+	fmt.Printf("AUDIT %s %s %d SYNCED!\n", event.Action, event.EntityType, event.EntityID)
+	return nil
+}
@@ -0,0 +1,40 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	usecase_export "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/export"
+)
+
+type ExportQueue struct {
+	ctx           context.Context
+	exportUseCase usecase_export.IExportUseCase
+}
+
+type ExportConsumer interface {
+	ProcessTransactionExport(payload map[string]interface{}) error
+}
+
+func NewExportConsumer(
+	ctx context.Context,
+	exportUseCase usecase_export.IExportUseCase,
+) ExportConsumer {
+	return &ExportQueue{ctx, exportUseCase}
+}
+
+func (e *ExportQueue) ProcessTransactionExport(payload map[string]interface{}) error {
+	exportID := uint64(helper.ToInt64(payload["export_id"]))
+	if exportID == 0 {
+		return fmt.Errorf("export_id is missing from payload")
+	}
+
+	if err := e.exportUseCase.ProcessExport(e.ctx, exportID); err != nil {
+		fmt.Printf("FAILED PROCESS EXPORT %d: %s\n", exportID, err.Error())
+		return err
+	}
+
+	fmt.Printf("EXPORT %d COMPLETED!\n", exportID)
+	return nil
+}
@@ -0,0 +1,40 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	usecase_importer "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/importer"
+)
+
+type MerchantImportQueue struct {
+	ctx           context.Context
+	importUseCase usecase_importer.IImportUseCase
+}
+
+type MerchantImportConsumer interface {
+	ProcessMerchantImport(payload map[string]interface{}) error
+}
+
+func NewMerchantImportConsumer(
+	ctx context.Context,
+	importUseCase usecase_importer.IImportUseCase,
+) MerchantImportConsumer {
+	return &MerchantImportQueue{ctx, importUseCase}
+}
+
+func (m *MerchantImportQueue) ProcessMerchantImport(payload map[string]interface{}) error {
+	importID := uint64(helper.ToInt64(payload["import_id"]))
+	if importID == 0 {
+		return fmt.Errorf("import_id is missing from payload")
+	}
+
+	if err := m.importUseCase.ProcessImport(m.ctx, importID); err != nil {
+		fmt.Printf("FAILED PROCESS MERCHANT IMPORT %d: %s\n", importID, err.Error())
+		return err
+	}
+
+	fmt.Printf("MERCHANT IMPORT %d COMPLETED!\n", importID)
+	return nil
+}
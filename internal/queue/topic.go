@@ -1,6 +1,11 @@
 package queue
 
 var (
-	ProcessSyncLog = "log.insert"
-	ProcessExample = "example.consumer"
+	ProcessSyncLog   = "log.insert"
+	ProcessSyncAudit = "audit.insert"
+	ProcessExample   = "example.consumer"
+
+	ProcessTransactionExport        = "transaction.export"
+	ProcessTransactionStatusChanged = "transaction.status_changed"
+	ProcessMerchantImport           = "merchant.import"
 )
@@ -0,0 +1,106 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mongodb/entity"
+
+	errwrap "github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, params entity.AuditCollection) error
+	FindAll(ctx context.Context, filter *entity.AuditFilter) ([]entity.AuditCollection, int64, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type Audit struct {
+	collection *mongo.Collection
+}
+
+func NewAuditRepository(db *mongo.Database) *Audit {
+	return &Audit{collection: db.Collection(AuditCollection)}
+}
+
+func (r *Audit) Create(ctx context.Context, params entity.AuditCollection) error {
+	funcName := "[AuditRepositoryMongo.Create]"
+
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	_, err := r.collection.InsertOne(ctx, params)
+	return err
+}
+
+// FindAll returns a page of audit records matching the filter, newest first, along
+// with the number of records matching it
+func (r *Audit) FindAll(ctx context.Context, filter *entity.AuditFilter) ([]entity.AuditCollection, int64, error) {
+	funcName := "[AuditRepositoryMongo.FindAll]"
+
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := bson.M{}
+	if filter.EntityType != "" {
+		query["entity_type"] = filter.EntityType
+	}
+	if filter.EntityID != 0 {
+		query["entity_id"] = filter.EntityID
+	}
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.CreatedFrom != nil || filter.CreatedTo != nil {
+		created := bson.M{}
+		if filter.CreatedFrom != nil {
+			created["$gte"] = *filter.CreatedFrom
+		}
+		if filter.CreatedTo != nil {
+			created["$lt"] = *filter.CreatedTo
+		}
+		query["created"] = created
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	cursor, err := r.collection.Find(ctx, query, options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(filter.Offset)).
+		SetLimit(int64(filter.Limit)))
+	if err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	audits := make([]entity.AuditCollection, 0)
+	if err := cursor.All(ctx, &audits); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+	return audits, total, nil
+}
+
+// EnsureIndexes creates the indexes behind the lookups by entity and by actor, it is
+// safe to call on every start
+func (r *Audit) EnsureIndexes(ctx context.Context) error {
+	funcName := "[AuditRepositoryMongo.EnsureIndexes]"
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "created", Value: -1}}},
+		{Keys: bson.D{{Key: "actor", Value: 1}, {Key: "created", Value: -1}}},
+	})
+	if err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
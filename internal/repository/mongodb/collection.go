@@ -2,3 +2,4 @@ package mongodb
 
 const SampleCollection = "sample_meta"
 const LogCollection = "logs"
+const AuditCollection = "audits"
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditCollection struct {
+	ID         primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Actor      string                 `bson:"actor" json:"actor"`
+	IP         string                 `bson:"ip" json:"ip"`
+	Action     string                 `bson:"action" json:"action"`
+	EntityType string                 `bson:"entity_type" json:"entity_type"`
+	EntityID   uint64                 `bson:"entity_id" json:"entity_id"`
+	Changes    map[string]AuditChange `bson:"changes" json:"changes"`
+	Created    time.Time              `bson:"created" json:"created"`
+}
+
+// AuditChange is the value of a field before and after the change, nil when the
+// entity did not exist before or no longer exists after
+type AuditChange struct {
+	Before interface{} `bson:"before" json:"before"`
+	After  interface{} `bson:"after" json:"after"`
+}
+
+type AuditFilter struct {
+	EntityType  string
+	EntityID    uint64
+	Actor       string
+	Action      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Limit       int
+	Offset      int
+}
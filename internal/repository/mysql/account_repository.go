@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"time"
 
 	"github.com/kharisma-wardhana/final-project-spe-academy/config"
 	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
@@ -20,6 +21,11 @@ type IAccountRepository interface {
 	Create(ctx context.Context, dbTrx TrxObj, params *entity.AccountEntity, nonZeroVal bool) error
 	Update(ctx context.Context, dbTrx TrxObj, params *entity.AccountEntity, changes *entity.AccountEntity) (err error)
 	DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64) error
+	FindDeletedByID(ctx context.Context, id uint64) (*entity.AccountEntity, error)
+	Restore(ctx context.Context, dbTrx TrxObj, id uint64) error
+	DeleteByMerchantID(ctx context.Context, dbTrx TrxObj, merchantID uint64, deletedAt time.Time) error
+	RestoreByMerchantID(ctx context.Context, dbTrx TrxObj, merchantID uint64, deletedAt time.Time) error
+	PurgeByMerchantID(ctx context.Context, dbTrx TrxObj, merchantID uint64) error
 }
 
 type AccountRepository struct {
@@ -38,7 +44,7 @@ func (r *AccountRepository) FindByID(ctx context.Context, id uint64) (*entity.Ac
 
 	var account entity.AccountEntity
 	if err := r.db.
-		Raw("SELECT * FROM accounts WHERE id = ?", id).
+		Raw("SELECT * FROM accounts WHERE id = ? AND deleted_at IS NULL", id).
 		First(&account).
 		Error; err != nil {
 		if errwrap.Is(err, gorm.ErrRecordNotFound) {
@@ -57,9 +63,12 @@ func (r *AccountRepository) FindByMerchantID(ctx context.Context, id uint64) (*e
 
 	var account entity.AccountEntity
 	if err := r.db.
-		Raw("SELECT * FROM merchants WHERE account_id = ?", id).
-		Scan(&account).
+		Raw("SELECT * FROM accounts WHERE merchant_id = ? AND deleted_at IS NULL", id).
+		First(&account).
 		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
 		return nil, err
 	}
 	return &account, nil
@@ -72,7 +81,7 @@ func (r *AccountRepository) FindByClientID(ctx context.Context, clientID string)
 	}
 	var account entity.AccountEntity
 	if err := r.db.
-		Raw("SELECT * FROM accounts WHERE client_id = ?", clientID).
+		Raw("SELECT * FROM accounts WHERE client_id = ? AND deleted_at IS NULL", clientID).
 		First(&account).
 		Error; err != nil {
 		if errwrap.Is(err, gorm.ErrRecordNotFound) {
@@ -100,16 +109,25 @@ func (r *AccountRepository) Update(ctx context.Context, dbTrx TrxObj, params *en
 		return errwrap.Wrap(err, funcName)
 	}
 
-	db := r.Trx(dbTrx).Model(params)
+	// The row only changes while it is still at the version params was read with
+	version := params.Version
+	db := r.Trx(dbTrx).Model(params).Where("version = ?", version)
+	var result *gorm.DB
 	if changes != nil {
-		err = db.Updates(*changes).Error
+		changes.Version = version + 1
+		result = db.Updates(*changes)
 	} else {
-		err = db.Updates(helper.StructToMap(params, false)).Error
+		params.Version = version + 1
+		result = db.Updates(helper.StructToMap(params, false))
 	}
 
-	if err != nil {
-		return errwrap.Wrap(err, funcName)
+	if result.Error != nil {
+		return errwrap.Wrap(result.Error, funcName)
+	}
+	if result.RowsAffected == 0 {
+		return appErr.ErrStaleVersion()
 	}
+	params.Version = version + 1
 
 	return nil
 }
@@ -120,11 +138,104 @@ func (r *AccountRepository) DeleteByID(ctx context.Context, dbTrx TrxObj, id uin
 		return errwrap.Wrap(err, funcName)
 	}
 
-	err := r.Trx(dbTrx).Where("id = ?", id).Delete(&entity.AccountEntity{}).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return appErr.ErrRecordNotFound()
+	// AccountEntity has a DeletedAt field, GORM soft deletes it
+	result := r.Trx(dbTrx).Where("id = ?", id).Delete(&entity.AccountEntity{})
+	if result.Error != nil {
+		return errwrap.Wrap(result.Error, funcName)
+	}
+	if result.RowsAffected == 0 {
+		return appErr.ErrRecordNotFound()
+	}
+	return nil
+}
+
+func (r *AccountRepository) FindDeletedByID(ctx context.Context, id uint64) (*entity.AccountEntity, error) {
+	funcName := "AccountRepository.FindDeletedByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var account entity.AccountEntity
+	if err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&account).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
 		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &account, nil
+}
+
+func (r *AccountRepository) Restore(ctx context.Context, dbTrx TrxObj, id uint64) error {
+	funcName := "AccountRepository.Restore"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	result := r.Trx(dbTrx).Unscoped().
+		Model(&entity.AccountEntity{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return errwrap.Wrap(result.Error, funcName)
+	}
+	if result.RowsAffected == 0 {
+		return appErr.ErrRecordNotFound()
+	}
+	return nil
+}
+
+// DeleteByMerchantID soft deletes the merchant's accounts with the merchant's deletedAt
+func (r *AccountRepository) DeleteByMerchantID(ctx context.Context, dbTrx TrxObj, merchantID uint64, deletedAt time.Time) error {
+	funcName := "AccountRepository.DeleteByMerchantID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).
+		Model(&entity.AccountEntity{}).
+		Where("merchant_id = ?", merchantID).
+		Update("deleted_at", deletedAt).
+		Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+// RestoreByMerchantID restores the accounts deleted along with the merchant, accounts
+// deleted on their own before stay deleted
+func (r *AccountRepository) RestoreByMerchantID(ctx context.Context, dbTrx TrxObj, merchantID uint64, deletedAt time.Time) error {
+	funcName := "AccountRepository.RestoreByMerchantID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).
+		Unscoped().
+		Model(&entity.AccountEntity{}).
+		Where("merchant_id = ? AND deleted_at = ?", merchantID, deletedAt).
+		Update("deleted_at", nil).
+		Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *AccountRepository) PurgeByMerchantID(ctx context.Context, dbTrx TrxObj, merchantID uint64) error {
+	funcName := "AccountRepository.PurgeByMerchantID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).
+		Unscoped().
+		Where("merchant_id = ?", merchantID).
+		Delete(&entity.AccountEntity{}).
+		Error; err != nil {
+		return errwrap.Wrap(err, funcName)
 	}
 	return nil
 }
@@ -137,7 +248,7 @@ func (r *AccountRepository) LockByID(ctx context.Context, dbTrx TrxObj, id uint6
 
 	var account entity.AccountEntity
 	err := r.Trx(dbTrx).
-		Raw("SELECT * FROM accounts WHERE id = ? FOR UPDATE", id).
+		Raw("SELECT * FROM accounts WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id).
 		First(&account).Error
 
 	if errwrap.Is(err, gorm.ErrRecordNotFound) {
@@ -0,0 +1,202 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IBlocklistRepository interface {
+	TrxSupportRepo
+	Create(ctx context.Context, dbTrx TrxObj, params *entity.BlocklistEntryEntity) error
+	LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.BlocklistEntryEntity, error)
+	LockByTypeValue(ctx context.Context, dbTrx TrxObj, entryType string, value string) (*entity.BlocklistEntryEntity, error)
+	FindActive(ctx context.Context, now time.Time) ([]entity.BlocklistEntryEntity, error)
+	FindEntries(ctx context.Context, filter *entity.BlocklistFilter) ([]entity.BlocklistEntryEntity, int64, error)
+	Update(ctx context.Context, dbTrx TrxObj, params *entity.BlocklistEntryEntity, changes map[string]interface{}) error
+	DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64) error
+	CreateAudit(ctx context.Context, dbTrx TrxObj, params *entity.BlocklistAuditEntity) error
+	FindAudits(ctx context.Context, filter *entity.BlocklistFilter) ([]entity.BlocklistAuditEntity, int64, error)
+}
+
+type BlocklistRepository struct {
+	GormTrxSupport
+}
+
+func NewBlocklistRepository(mysql *config.Mysql) *BlocklistRepository {
+	return &BlocklistRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *BlocklistRepository) Create(ctx context.Context, dbTrx TrxObj, params *entity.BlocklistEntryEntity) error {
+	funcName := "BlocklistRepository.Create"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *BlocklistRepository) LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.BlocklistEntryEntity, error) {
+	funcName := "BlocklistRepository.LockByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var entry entity.BlocklistEntryEntity
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&entry, id).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &entry, nil
+}
+
+// LockByTypeValue locks the entry of a value, or the gap it would be inserted in when
+// there is none, so concurrent adds of the same value run one after the other
+func (r *BlocklistRepository) LockByTypeValue(ctx context.Context, dbTrx TrxObj, entryType string, value string) (*entity.BlocklistEntryEntity, error) {
+	funcName := "BlocklistRepository.LockByTypeValue"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var entry entity.BlocklistEntryEntity
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("type = ? AND value = ?", entryType, value).
+		First(&entry).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &entry, nil
+}
+
+// FindActive returns every entry that has not expired at now
+func (r *BlocklistRepository) FindActive(ctx context.Context, now time.Time) ([]entity.BlocklistEntryEntity, error) {
+	funcName := "BlocklistRepository.FindActive"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var entries []entity.BlocklistEntryEntity
+	if err := r.db.WithContext(ctx).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Find(&entries).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return entries, nil
+}
+
+func (r *BlocklistRepository) FindEntries(ctx context.Context, filter *entity.BlocklistFilter) ([]entity.BlocklistEntryEntity, int64, error) {
+	funcName := "BlocklistRepository.FindEntries"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := filterBlocklist(r.db.WithContext(ctx).Model(&entity.BlocklistEntryEntity{}), filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	var entries []entity.BlocklistEntryEntity
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&entries).
+		Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+	return entries, total, nil
+}
+
+func (r *BlocklistRepository) Update(ctx context.Context, dbTrx TrxObj, params *entity.BlocklistEntryEntity, changes map[string]interface{}) error {
+	funcName := "BlocklistRepository.Update"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *BlocklistRepository) DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64) error {
+	funcName := "BlocklistRepository.DeleteByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Delete(&entity.BlocklistEntryEntity{}, id).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *BlocklistRepository) CreateAudit(ctx context.Context, dbTrx TrxObj, params *entity.BlocklistAuditEntity) error {
+	funcName := "BlocklistRepository.CreateAudit"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+// FindAudits returns one page of the audit trail, newest first
+func (r *BlocklistRepository) FindAudits(ctx context.Context, filter *entity.BlocklistFilter) ([]entity.BlocklistAuditEntity, int64, error) {
+	funcName := "BlocklistRepository.FindAudits"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := filterBlocklist(r.db.WithContext(ctx).Model(&entity.BlocklistAuditEntity{}), filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	var audits []entity.BlocklistAuditEntity
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&audits).
+		Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+	return audits, total, nil
+}
+
+func filterBlocklist(query *gorm.DB, filter *entity.BlocklistFilter) *gorm.DB {
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Value != "" {
+		query = query.Where("value = ?", filter.Value)
+	}
+	return query
+}
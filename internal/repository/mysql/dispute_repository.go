@@ -0,0 +1,204 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IDisputeRepository interface {
+	TrxSupportRepo
+	Create(ctx context.Context, dbTrx TrxObj, params *entity.DisputeEntity) error
+	FindByID(ctx context.Context, id uint64) (*entity.DisputeEntity, error)
+	LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.DisputeEntity, error)
+	ExistsByTransactionID(ctx context.Context, transactionID uint64) (bool, error)
+	Update(ctx context.Context, dbTrx TrxObj, params *entity.DisputeEntity, changes map[string]interface{}) error
+	FindByMerchantID(ctx context.Context, filter *entity.DisputeFilter) ([]entity.DisputeEntity, int64, error)
+	FindOverdue(ctx context.Context, now time.Time, limit int) ([]entity.DisputeEntity, error)
+	CreateEvidence(ctx context.Context, dbTrx TrxObj, params *entity.DisputeEvidenceEntity) error
+	FindEvidenceByID(ctx context.Context, id uint64) (*entity.DisputeEvidenceEntity, error)
+	FindEvidenceByDisputeID(ctx context.Context, disputeID uint64) ([]entity.DisputeEvidenceEntity, error)
+}
+
+type DisputeRepository struct {
+	GormTrxSupport
+}
+
+func NewDisputeRepository(mysql *config.Mysql) *DisputeRepository {
+	return &DisputeRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *DisputeRepository) Create(ctx context.Context, dbTrx TrxObj, params *entity.DisputeEntity) error {
+	funcName := "DisputeRepository.Create"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *DisputeRepository) FindByID(ctx context.Context, id uint64) (*entity.DisputeEntity, error) {
+	funcName := "DisputeRepository.FindByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var dispute entity.DisputeEntity
+	if err := r.db.WithContext(ctx).First(&dispute, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &dispute, nil
+}
+
+func (r *DisputeRepository) LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.DisputeEntity, error) {
+	funcName := "DisputeRepository.LockByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var dispute entity.DisputeEntity
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&dispute, id).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &dispute, nil
+}
+
+func (r *DisputeRepository) ExistsByTransactionID(ctx context.Context, transactionID uint64) (bool, error) {
+	funcName := "DisputeRepository.ExistsByTransactionID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return false, errwrap.Wrap(err, funcName)
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&entity.DisputeEntity{}).
+		Where("transaction_id = ?", transactionID).
+		Count(&count).
+		Error; err != nil {
+		return false, errwrap.Wrap(err, funcName)
+	}
+	return count > 0, nil
+}
+
+func (r *DisputeRepository) Update(ctx context.Context, dbTrx TrxObj, params *entity.DisputeEntity, changes map[string]interface{}) error {
+	funcName := "DisputeRepository.Update"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *DisputeRepository) FindByMerchantID(ctx context.Context, filter *entity.DisputeFilter) ([]entity.DisputeEntity, int64, error) {
+	funcName := "DisputeRepository.FindByMerchantID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).Model(&entity.DisputeEntity{}).Where("merchant_id = ?", filter.MerchantID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	var disputes []entity.DisputeEntity
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&disputes).
+		Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+	return disputes, total, nil
+}
+
+// FindOverdue returns open disputes whose response deadline has passed without a merchant response
+func (r *DisputeRepository) FindOverdue(ctx context.Context, now time.Time, limit int) ([]entity.DisputeEntity, error) {
+	funcName := "DisputeRepository.FindOverdue"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var disputes []entity.DisputeEntity
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND response_due_at < ?", entity.DisputeStatusOpen, now).
+		Order("response_due_at ASC, id ASC").
+		Limit(limit).
+		Find(&disputes).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return disputes, nil
+}
+
+func (r *DisputeRepository) CreateEvidence(ctx context.Context, dbTrx TrxObj, params *entity.DisputeEvidenceEntity) error {
+	funcName := "DisputeRepository.CreateEvidence"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *DisputeRepository) FindEvidenceByID(ctx context.Context, id uint64) (*entity.DisputeEvidenceEntity, error) {
+	funcName := "DisputeRepository.FindEvidenceByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var evidence entity.DisputeEvidenceEntity
+	if err := r.db.WithContext(ctx).First(&evidence, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &evidence, nil
+}
+
+func (r *DisputeRepository) FindEvidenceByDisputeID(ctx context.Context, disputeID uint64) ([]entity.DisputeEvidenceEntity, error) {
+	funcName := "DisputeRepository.FindEvidenceByDisputeID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var evidences []entity.DisputeEvidenceEntity
+	if err := r.db.WithContext(ctx).
+		Where("dispute_id = ?", disputeID).
+		Order("id ASC").
+		Find(&evidences).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return evidences, nil
+}
@@ -1,6 +1,17 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Merchant accounts act on their own merchant, backoffice accounts run the review
+// of every merchant
+const (
+	AccountRoleMerchant   = "merchant"
+	AccountRoleBackoffice = "backoffice"
+)
 
 type AccountEntity struct {
 	ID           uint64 `gorm:"primaryKey"`
@@ -10,10 +21,17 @@ type AccountEntity struct {
 	PrivateKey   string
 	PublicKey    string
 	Status       string
+	Role         string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	Version      uint64
+	DeletedAt    gorm.DeletedAt
 }
 
 func (AccountEntity) TableName() string {
 	return "accounts"
 }
+
+func (a *AccountEntity) IsBackoffice() bool {
+	return a.Role == AccountRoleBackoffice
+}
@@ -0,0 +1,57 @@
+package entity
+
+import "time"
+
+const (
+	BlocklistTypeCustomerMPAN = "customer_mpan"
+	BlocklistTypeMerchantID   = "merchant_id"
+	BlocklistTypeNMID         = "nmid"
+	BlocklistTypeIP           = "ip"
+)
+
+const (
+	BlocklistActionAdd    = "add"
+	BlocklistActionRemove = "remove"
+)
+
+// BlocklistEntryEntity blocks one customer MPAN, merchant ID, NMID or IP address until
+// ExpiresAt, an entry without ExpiresAt never expires
+type BlocklistEntryEntity struct {
+	ID        uint64 `gorm:"primaryKey"`
+	Type      string
+	Value     string
+	Reason    string
+	ExpiresAt *time.Time
+	CreatedBy string
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (BlocklistEntryEntity) TableName() string {
+	return "blocklist_entries"
+}
+
+// BlocklistAuditEntity records who added or removed an entry. It copies the entry so
+// it stays readable after the entry is removed.
+type BlocklistAuditEntity struct {
+	ID        uint64 `gorm:"primaryKey"`
+	EntryID   uint64
+	Action    string
+	Type      string
+	Value     string
+	Reason    string
+	ExpiresAt *time.Time
+	Actor     string
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (BlocklistAuditEntity) TableName() string {
+	return "blocklist_audits"
+}
+
+type BlocklistFilter struct {
+	Type   string
+	Value  string
+	Limit  int
+	Offset int
+}
@@ -14,3 +14,22 @@ const (
 	TransactionTypePayment TransactionType = 1
 	TransactionTypeRefund  TransactionType = 2
 )
+
+// Name is how the API refers to the merchant type
+func (t MerchantType) Name() string {
+	switch t {
+	case MerchantTypeIndividual:
+		return "individual"
+	case MerchantTypeCorporate:
+		return "corporate"
+	}
+	return ""
+}
+
+const (
+	TransactionStatusPending   = "pending"
+	TransactionStatusCompleted = "completed"
+	TransactionStatusSettled   = "settled"
+	TransactionStatusFailed    = "failed"
+	TransactionStatusVoided    = "voided"
+)
@@ -0,0 +1,54 @@
+package entity
+
+import "time"
+
+const (
+	DisputeStatusOpen             = "open"
+	DisputeStatusMerchantResponse = "merchant_response"
+	DisputeStatusWon              = "won"
+	DisputeStatusLost             = "lost"
+)
+
+type DisputeEntity struct {
+	ID                  uint64 `gorm:"primaryKey"`
+	TransactionID       uint64
+	MerchantID          uint64
+	ReasonCode          string
+	Reason              string
+	Amount              float64
+	Status              string
+	MerchantResponse    string
+	ResolutionNote      string
+	RefundTransactionID *uint64
+	ResponseDueAt       time.Time
+	RespondedAt         *time.Time
+	ResolvedAt          *time.Time
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime"`
+}
+
+func (DisputeEntity) TableName() string {
+	return "disputes"
+}
+
+type DisputeEvidenceEntity struct {
+	ID          uint64 `gorm:"primaryKey"`
+	DisputeID   uint64
+	FileName    string
+	FilePath    string
+	ContentType string
+	Size        int64
+	Description string
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+func (DisputeEvidenceEntity) TableName() string {
+	return "dispute_evidences"
+}
+
+type DisputeFilter struct {
+	MerchantID uint64
+	Status     string
+	Limit      int
+	Offset     int
+}
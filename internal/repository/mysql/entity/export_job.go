@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+
+	ExportStatusQueued     = "queued"
+	ExportStatusProcessing = "processing"
+	ExportStatusCompleted  = "completed"
+	ExportStatusFailed     = "failed"
+)
+
+type ExportJobEntity struct {
+	ID                uint64 `gorm:"primaryKey"`
+	MerchantID        uint64
+	Format            string
+	DateFrom          time.Time
+	DateTo            time.Time
+	TransactionStatus string
+	Status            string
+	FilePath          string
+	RowCount          int64
+	ErrorMessage      string
+	StartedAt         *time.Time
+	CompletedAt       *time.Time
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ExportJobEntity) TableName() string {
+	return "export_jobs"
+}
@@ -0,0 +1,74 @@
+package entity
+
+import "time"
+
+const (
+	FraudDecisionAllow  = "allow"
+	FraudDecisionReview = "review"
+	FraudDecisionBlock  = "block"
+)
+
+const (
+	FraudReviewPending  = "pending"
+	FraudReviewApproved = "approved"
+	FraudReviewRejected = "rejected"
+)
+
+// FraudRuleEntity configures one rule of the fraud engine. Type selects the rule
+// implementation and Params is its JSON configuration.
+type FraudRuleEntity struct {
+	ID        uint64 `gorm:"primaryKey"`
+	Code      string
+	Name      string
+	Type      string
+	Params    string
+	Weight    int
+	IsActive  bool
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (FraudRuleEntity) TableName() string {
+	return "fraud_rules"
+}
+
+// FraudRuleHit is a rule that matched a transaction
+type FraudRuleHit struct {
+	Code   string `json:"code"`
+	Weight int    `json:"weight"`
+	Reason string `json:"reason"`
+}
+
+// FraudDecisionEntity is the outcome of scoring one transaction. TransactionID is
+// empty for blocked transactions, which are never created. Only review decisions
+// have a ReviewStatus.
+type FraudDecisionEntity struct {
+	ID             uint64 `gorm:"primaryKey"`
+	TransactionID  *uint64
+	RefID          string `gorm:"column:reference_id"`
+	BillingID      string
+	MerchantID     uint64
+	CustomerMPAN   string
+	Amount         float64
+	Score          int
+	Decision       string
+	TriggeredRules []FraudRuleHit `gorm:"serializer:json"`
+	ReviewStatus   *string
+	ReviewedBy     *string
+	ReviewNote     *string
+	ReviewedAt     *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (FraudDecisionEntity) TableName() string {
+	return "fraud_decisions"
+}
+
+type FraudDecisionFilter struct {
+	Decision     string
+	ReviewStatus string
+	MerchantID   uint64
+	Limit        int
+	Offset       int
+}
@@ -0,0 +1,86 @@
+package entity
+
+import "time"
+
+// Ledger account codes. Merchant accounts exist once per merchant, system accounts
+// have no merchant and are created by the migrations.
+const (
+	LedgerAccountMerchantPending    = "MERCHANT_PENDING"
+	LedgerAccountMerchantAvailable  = "MERCHANT_AVAILABLE"
+	LedgerAccountSettlementClearing = "SETTLEMENT_CLEARING"
+	LedgerAccountMDRRevenue         = "MDR_REVENUE"
+	LedgerAccountPayoutClearing     = "PAYOUT_CLEARING"
+
+	LedgerAccountTypeAsset     = "asset"
+	LedgerAccountTypeLiability = "liability"
+	LedgerAccountTypeRevenue   = "revenue"
+
+	LedgerDebit  = "debit"
+	LedgerCredit = "credit"
+
+	JournalEntryPayment    = "payment"
+	JournalEntryMDR        = "mdr"
+	JournalEntryRefund     = "refund"
+	JournalEntrySettlement = "settlement"
+	JournalEntryPayout     = "payout"
+	// Payout confirmations clear the money once the bank has paid it out, reversals
+	// give it back to the merchant when the bank rejects the transfer
+	JournalEntryPayoutConfirmation = "payout_confirmation"
+	JournalEntryPayoutReversal     = "payout_reversal"
+
+	JournalReferenceTransaction = "transaction"
+	JournalReferencePayout      = "payout"
+)
+
+type LedgerAccountEntity struct {
+	ID            uint64 `gorm:"primaryKey"`
+	MerchantID    *uint64
+	Code          string
+	Type          string
+	NormalBalance string
+	Currency      string
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (LedgerAccountEntity) TableName() string {
+	return "ledger_accounts"
+}
+
+type JournalEntryEntity struct {
+	ID            uint64 `gorm:"primaryKey"`
+	MerchantID    *uint64
+	EntryType     string
+	ReferenceType string
+	ReferenceID   uint64
+	Description   string
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (JournalEntryEntity) TableName() string {
+	return "journal_entries"
+}
+
+type LedgerPostingEntity struct {
+	ID              uint64 `gorm:"primaryKey"`
+	JournalEntryID  uint64
+	LedgerAccountID uint64
+	Direction       string
+	Amount          float64
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+func (LedgerPostingEntity) TableName() string {
+	return "ledger_postings"
+}
+
+// LedgerAccountBalance is the balance of one account in its normal direction,
+// a liability with more credits than debits has a positive balance
+type LedgerAccountBalance struct {
+	Code    string
+	Balance float64
+}
+
+type LedgerMerchantBalance struct {
+	MerchantID uint64
+	Balance    float64
+}
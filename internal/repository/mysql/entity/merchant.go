@@ -1,27 +1,72 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Merchants go through onboarding before they may take payments, only approved
+// merchants can generate QRs and receive transactions
+const (
+	MerchantStatusDraft       = "draft"
+	MerchantStatusSubmitted   = "submitted"
+	MerchantStatusUnderReview = "under_review"
+	MerchantStatusApproved    = "approved"
+	MerchantStatusRejected    = "rejected"
+	MerchantStatusSuspended   = "suspended"
+)
 
 type MerchantEntity struct {
-	ID            uint64    `gorm:"primaryKey"`
-	Name          string    `gorm:"column:name"`
-	Phone         string    `gorm:"unique"`
-	Email         string    `gorm:"unique"`
-	AccountNumber string    `gorm:"column:account_number"`
-	MID           string    `gorm:"column:mid"`
-	NMID          string    `gorm:"column:nmid"`
-	MPAN          string    `gorm:"column:mpan"`
-	MCC           string    `gorm:"column:mcc"`
-	PostalCode    string    `gorm:"column:postal_code"`
-	Province      string    `gorm:"column:province"`
-	District      string    `gorm:"column:district"`
-	SubDistrict   string    `gorm:"column:subdistrict"`
-	City          string    `gorm:"column:city"`
-	Status        string    `gorm:"column:status"`
-	CreatedAt     time.Time `gorm:"autoCreateTime"`
-	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+	ID             uint64       `gorm:"primaryKey"`
+	Name           string       `gorm:"column:name"`
+	Type           MerchantType `gorm:"column:type"`
+	ParentID       *uint64      `gorm:"column:parent_id"`
+	SettleAtParent bool         `gorm:"column:settle_at_parent"`
+	Phone          string       `gorm:"unique"`
+	Email          string       `gorm:"unique"`
+	AccountNumber  string       `gorm:"column:account_number"`
+	MID            string       `gorm:"column:mid"`
+	NMID           string       `gorm:"column:nmid"`
+	MPAN           string       `gorm:"column:mpan"`
+	MCC            string       `gorm:"column:mcc"`
+	MDRPercent     float64      `gorm:"column:mdr_percentage"`
+	PostalCode     string       `gorm:"column:postal_code"`
+	Province       string       `gorm:"column:province"`
+	District       string       `gorm:"column:district"`
+	SubDistrict    string       `gorm:"column:subdistrict"`
+	City           string       `gorm:"column:city"`
+	Status         string       `gorm:"column:status"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `gorm:"autoUpdateTime"`
+	// Version is bumped by every update, an update carrying an older version is stale
+	Version uint64 `gorm:"column:version"`
+	// DeletedAt soft deletes the merchant, GORM leaves deleted merchants out of its
+	// queries and raw queries have to filter on deleted_at themselves
+	DeletedAt gorm.DeletedAt
+}
+
+// IsCorporate reports whether the merchant may own child merchants
+func (m *MerchantEntity) IsCorporate() bool {
+	return m.Type == MerchantTypeCorporate
 }
 
 func (MerchantEntity) TableName() string {
 	return "merchants"
 }
+
+type MerchantFilter struct {
+	// IDs limits the list to these merchants, nil lists every merchant
+	IDs         []uint64
+	Status      string
+	City        string
+	Province    string
+	MCC         string
+	Search      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	SortBy      string
+	SortDir     string
+	Limit       int
+	Offset      int
+}
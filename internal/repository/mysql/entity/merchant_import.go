@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+const (
+	MerchantImportStatusQueued     = "queued"
+	MerchantImportStatusProcessing = "processing"
+	MerchantImportStatusCompleted  = "completed"
+	MerchantImportStatusFailed     = "failed"
+)
+
+type MerchantImportEntity struct {
+	ID           uint64 `gorm:"primaryKey"`
+	FileName     string
+	FilePath     string
+	ReportPath   string
+	Status       string
+	TotalRows    int64
+	SuccessCount int64
+	FailedCount  int64
+	ErrorMessage string
+	CreatedBy    string
+	StartedAt    *time.Time
+	CompletedAt  *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (MerchantImportEntity) TableName() string {
+	return "merchant_imports"
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+const (
+	MerchantDocumentKTP        = "ktp"
+	MerchantDocumentNPWP       = "npwp"
+	MerchantDocumentStorePhoto = "store_photo"
+)
+
+// MerchantDocumentEntity is a KYC document uploaded during onboarding. A document
+// uploaded again for the same type replaces the older one in the review.
+type MerchantDocumentEntity struct {
+	ID          uint64 `gorm:"primaryKey"`
+	MerchantID  uint64
+	Type        string
+	FileName    string
+	FilePath    string
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+func (MerchantDocumentEntity) TableName() string {
+	return "merchant_documents"
+}
+
+// MerchantReviewEntity records one onboarding status change with who made it and why
+type MerchantReviewEntity struct {
+	ID         uint64 `gorm:"primaryKey"`
+	MerchantID uint64
+	FromStatus string
+	ToStatus   string
+	Actor      string
+	Comment    string
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (MerchantReviewEntity) TableName() string {
+	return "merchant_reviews"
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+const (
+	OutletStatusActive   = "active"
+	OutletStatusInactive = "inactive"
+)
+
+// A terminal takes payments once a device is bound to it, a blocked terminal stays
+// unusable until it is unbound
+const (
+	TerminalBindingUnbound = "unbound"
+	TerminalBindingBound   = "bound"
+	TerminalBindingBlocked = "blocked"
+)
+
+// OutletEntity is a store of a merchant, a chain has one merchant with many outlets
+type OutletEntity struct {
+	ID          uint64 `gorm:"primaryKey"`
+	MerchantID  uint64
+	Name        string
+	Address     string
+	PostalCode  string
+	Province    string
+	District    string
+	SubDistrict string `gorm:"column:subdistrict"`
+	City        string
+	Latitude    *float64
+	Longitude   *float64
+	Status      string
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (OutletEntity) TableName() string {
+	return "outlets"
+}
+
+// TerminalEntity is a point of sale of an outlet. TerminalID is the TID printed in
+// its QRs, DeviceSerial the device bound to it.
+type TerminalEntity struct {
+	ID            uint64 `gorm:"primaryKey"`
+	MerchantID    uint64
+	OutletID      uint64
+	TerminalID    string
+	DeviceSerial  *string
+	BindingStatus string
+	BoundAt       *time.Time
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (TerminalEntity) TableName() string {
+	return "terminals"
+}
+
+type TerminalFilter struct {
+	MerchantID uint64
+	OutletID   uint64
+}
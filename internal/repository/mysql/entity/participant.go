@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	ParticipantRoleIssuer   = "issuer"
+	ParticipantRoleAcquirer = "acquirer"
+	ParticipantRoleSwitch   = "switch"
+)
+
+// ParticipantEntity is a network institution. Code is the institution code stored in
+// transactions.issuer and transactions.acquirer, Roles is the comma separated SET column.
+type ParticipantEntity struct {
+	ID           uint64 `gorm:"primaryKey"`
+	Code         string
+	Name         string
+	Roles        string
+	ClientID     string
+	ClientSecret string
+	IsActive     bool
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ParticipantEntity) TableName() string {
+	return "participants"
+}
+
+func (p *ParticipantEntity) HasRole(role string) bool {
+	for _, r := range strings.Split(p.Roles, ",") {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type ParticipantFilter struct {
+	Role     string
+	IsActive *bool
+	Search   string
+	Limit    int
+	Offset   int
+}
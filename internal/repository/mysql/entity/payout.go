@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+const (
+	PayoutFileFormatCSV        = "csv"
+	PayoutFileFormatFixedWidth = "fixed_width"
+
+	PayoutBatchStatusCreated   = "created"
+	PayoutBatchStatusSent      = "sent"
+	PayoutBatchStatusCompleted = "completed"
+
+	PayoutStatusCreated   = "created"
+	PayoutStatusSent      = "sent"
+	PayoutStatusConfirmed = "confirmed"
+	PayoutStatusRejected  = "rejected"
+)
+
+type PayoutBatchEntity struct {
+	ID          uint64 `gorm:"primaryKey"`
+	BatchDate   time.Time
+	FileFormat  string
+	FilePath    string
+	PayoutCount int64
+	TotalAmount float64
+	Status      string
+	SentAt      *time.Time
+	CompletedAt *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (PayoutBatchEntity) TableName() string {
+	return "payout_batches"
+}
+
+type PayoutEntity struct {
+	ID              uint64 `gorm:"primaryKey"`
+	BatchID         uint64
+	MerchantID      uint64
+	Reference       string
+	AccountNumber   string
+	AccountName     string
+	Amount          float64
+	Status          string
+	RejectionReason string
+	SentAt          *time.Time
+	ResolvedAt      *time.Time
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+func (PayoutEntity) TableName() string {
+	return "payouts"
+}
@@ -0,0 +1,72 @@
+package entity
+
+import "time"
+
+const (
+	ReconciliationRunProcessing = "processing"
+	ReconciliationRunCompleted  = "completed"
+	ReconciliationRunFailed     = "failed"
+
+	ReconciliationMatched        = "matched"
+	ReconciliationMissingOurs    = "missing_ours"
+	ReconciliationMissingTheirs  = "missing_theirs"
+	ReconciliationAmountMismatch = "amount_mismatch"
+	ReconciliationDetailMismatch = "detail_mismatch"
+
+	ReconciliationItemOpen     = "open"
+	ReconciliationItemResolved = "resolved"
+)
+
+type ReconciliationRunEntity struct {
+	ID                  uint64 `gorm:"primaryKey"`
+	SettlementDate      time.Time
+	FileFormat          string
+	FileName            string
+	Status              string
+	TheirCount          int64
+	OurCount            int64
+	MatchedCount        int64
+	MissingOursCount    int64
+	MissingTheirsCount  int64
+	AmountMismatchCount int64
+	DetailMismatchCount int64
+	ErrorMessage        string
+	CompletedAt         *time.Time
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ReconciliationRunEntity) TableName() string {
+	return "reconciliation_runs"
+}
+
+// ReconciliationItemEntity is one row of a run. Rows missing on our side have no
+// transaction and no amount of ours, rows missing on theirs have no amount of theirs.
+type ReconciliationItemEntity struct {
+	ID              uint64 `gorm:"primaryKey"`
+	RunID           uint64
+	TransactionID   *uint64
+	RefID           string `gorm:"column:reference_id"`
+	BillingID       string
+	OurAmount       *float64
+	TheirAmount     *float64
+	TransactionDate *time.Time
+	Result          string
+	Status          string
+	ResolutionNote  string
+	ResolvedAt      *time.Time
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ReconciliationItemEntity) TableName() string {
+	return "reconciliation_items"
+}
+
+type ReconciliationItemFilter struct {
+	RunID  uint64
+	Result string
+	Status string
+	Limit  int
+	Offset int
+}
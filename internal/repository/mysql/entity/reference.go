@@ -0,0 +1,54 @@
+package entity
+
+import "time"
+
+// Region levels of the Indonesian administrative hierarchy, from the widest down
+const (
+	RegionLevelProvince    = "province"
+	RegionLevelCity        = "city"
+	RegionLevelDistrict    = "district"
+	RegionLevelSubDistrict = "subdistrict"
+)
+
+// MCCEntity is an ISO 18245 merchant category code
+type MCCEntity struct {
+	Code        string `gorm:"primaryKey"`
+	Description string
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (MCCEntity) TableName() string {
+	return "mccs"
+}
+
+type MCCFilter struct {
+	Search string
+	Limit  int
+	Offset int
+}
+
+// RegionEntity is a province, city, district or subdistrict keyed by its Kemendagri
+// code, only subdistricts carry a postal code
+type RegionEntity struct {
+	Code       string `gorm:"primaryKey"`
+	ParentCode *string
+	Level      string
+	Name       string
+	PostalCode *string
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (RegionEntity) TableName() string {
+	return "regions"
+}
+
+// RegionFilter lists the regions of a level, the children of ParentCode or the
+// subdistricts of a postal code
+type RegionFilter struct {
+	Level      string
+	ParentCode string
+	PostalCode string
+	Search     string
+}
@@ -4,13 +4,15 @@ import "time"
 
 type TransactionEntity struct {
 	ID              uint64 `gorm:"primaryKey"`
-	RefID           string
+	RefID           string `gorm:"column:reference_id"`
 	BillingID       string
 	MerchantID      uint64
+	OutletID        *uint64
+	TerminalID      *uint64
 	Amount          float64
 	FeeAmount       float64
 	TotalAmount     float64
-	MDRPercent      float64
+	MDRPercent      float64 `gorm:"column:mdr_percentage"`
 	MDRAmount       float64
 	PaymentMethod   string
 	Currency        string
@@ -28,3 +30,47 @@ type TransactionEntity struct {
 func (TransactionEntity) TableName() string {
 	return "transactions"
 }
+
+type TransactionStatusHistoryEntity struct {
+	ID            uint64 `gorm:"primaryKey"`
+	TransactionID uint64
+	FromStatus    string
+	ToStatus      string
+	Reason        string
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (TransactionStatusHistoryEntity) TableName() string {
+	return "transaction_status_histories"
+}
+
+// PendingTransactionFilter selects pending transactions made before a cutoff. An
+// empty PaymentMethod matches every method except those in ExcludePaymentMethods.
+type PendingTransactionFilter struct {
+	Before                time.Time
+	PaymentMethod         string
+	ExcludePaymentMethods []string
+	Limit                 int
+}
+
+// TransactionCursor is the keyset position of the last row of a page, transactions
+// are ordered by (transaction_date, id) descending
+type TransactionCursor struct {
+	TransactionDate time.Time
+	ID              uint64
+}
+
+type TransactionFilter struct {
+	MerchantID    uint64
+	OutletID      uint64
+	TerminalID    uint64
+	Status        string
+	Type          string
+	PaymentMethod string
+	MinAmount     float64
+	MaxAmount     float64
+	DateFrom      *time.Time
+	DateTo        *time.Time
+	Cursor        *TransactionCursor
+	Limit         int
+}
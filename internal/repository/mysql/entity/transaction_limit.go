@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// TransactionLimitEntity holds the limits of one merchant or of every merchant in one
+// category, exactly one of MerchantID and MCC is set. A nil limit is not enforced.
+type TransactionLimitEntity struct {
+	ID               uint64 `gorm:"primaryKey"`
+	MerchantID       *uint64
+	MCC              *string `gorm:"column:mcc"`
+	MaxSingleAmount  *float64
+	MaxDailyVolume   *float64
+	MaxMonthlyVolume *float64
+	MaxPerMinute     *int
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
+
+func (TransactionLimitEntity) TableName() string {
+	return "transaction_limits"
+}
@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+const (
+	SummaryGranularityDay   = "day"
+	SummaryGranularityWeek  = "week"
+	SummaryGranularityMonth = "month"
+)
+
+// TransactionSummaryFilter covers a single merchant, or all of MerchantIDs when set
+// to consolidate a corporate merchant with its children
+type TransactionSummaryFilter struct {
+	MerchantID  uint64
+	MerchantIDs []uint64
+	DateFrom    time.Time
+	DateTo      time.Time
+	Granularity string
+}
+
+// TransactionAggregate holds the totals of a set of transactions. Volumes only
+// count payments and refunds that went through (completed or settled).
+type TransactionAggregate struct {
+	TransactionCount int64
+	PaymentCount     int64
+	RefundCount      int64
+	FailedCount      int64
+	GrossVolume      float64
+	MDRAmount        float64
+	RefundVolume     float64
+	NetAmount        float64
+}
+
+type TransactionPeriodAggregate struct {
+	Period string
+	TransactionAggregate
+}
+
+type TransactionBreakdownAggregate struct {
+	Key              string
+	TransactionCount int64
+	Volume           float64
+	MDRAmount        float64
+}
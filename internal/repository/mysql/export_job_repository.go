@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type IExportJobRepository interface {
+	TrxSupportRepo
+	FindByID(ctx context.Context, id uint64) (*entity.ExportJobEntity, error)
+	Create(ctx context.Context, dbTrx TrxObj, params *entity.ExportJobEntity, nonZeroVal bool) error
+	Update(ctx context.Context, dbTrx TrxObj, params *entity.ExportJobEntity, changes map[string]interface{}) error
+}
+
+type ExportJobRepository struct {
+	GormTrxSupport
+}
+
+func NewExportJobRepository(mysql *config.Mysql) *ExportJobRepository {
+	return &ExportJobRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *ExportJobRepository) FindByID(ctx context.Context, id uint64) (*entity.ExportJobEntity, error) {
+	funcName := "ExportJobRepository.FindByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var job entity.ExportJobEntity
+	if err := r.db.
+		Raw("SELECT * FROM export_jobs WHERE id = ?", id).
+		First(&job).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ExportJobRepository) Create(ctx context.Context, dbTrx TrxObj, params *entity.ExportJobEntity, nonZeroVal bool) error {
+	funcName := "ExportJobRepository.Create"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	cols := helper.NonZeroCols(params, nonZeroVal)
+	return r.Trx(dbTrx).Select(cols).Create(&params).Error
+}
+
+// Update takes a column map because progress updates need to write zero values
+// such as an empty error message or a row count of 0
+func (r *ExportJobRepository) Update(ctx context.Context, dbTrx TrxObj, params *entity.ExportJobEntity, changes map[string]interface{}) error {
+	funcName := "ExportJobRepository.Update"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IFraudRepository interface {
+	TrxSupportRepo
+	FindRules(ctx context.Context, activeOnly bool) ([]entity.FraudRuleEntity, error)
+	FindRuleByID(ctx context.Context, id uint64) (*entity.FraudRuleEntity, error)
+	UpdateRule(ctx context.Context, dbTrx TrxObj, params *entity.FraudRuleEntity, changes map[string]interface{}) error
+	CreateDecision(ctx context.Context, dbTrx TrxObj, params *entity.FraudDecisionEntity) error
+	FindDecisionByID(ctx context.Context, id uint64) (*entity.FraudDecisionEntity, error)
+	FindDecisions(ctx context.Context, filter *entity.FraudDecisionFilter) ([]entity.FraudDecisionEntity, int64, error)
+	LockDecisionByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.FraudDecisionEntity, error)
+	UpdateDecision(ctx context.Context, dbTrx TrxObj, params *entity.FraudDecisionEntity, changes map[string]interface{}) error
+}
+
+type FraudRepository struct {
+	GormTrxSupport
+}
+
+func NewFraudRepository(mysql *config.Mysql) *FraudRepository {
+	return &FraudRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *FraudRepository) FindRules(ctx context.Context, activeOnly bool) ([]entity.FraudRuleEntity, error) {
+	funcName := "FraudRepository.FindRules"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var rules []entity.FraudRuleEntity
+	if err := query.Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return rules, nil
+}
+
+func (r *FraudRepository) FindRuleByID(ctx context.Context, id uint64) (*entity.FraudRuleEntity, error) {
+	funcName := "FraudRepository.FindRuleByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var rule entity.FraudRuleEntity
+	if err := r.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &rule, nil
+}
+
+func (r *FraudRepository) UpdateRule(ctx context.Context, dbTrx TrxObj, params *entity.FraudRuleEntity, changes map[string]interface{}) error {
+	funcName := "FraudRepository.UpdateRule"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *FraudRepository) CreateDecision(ctx context.Context, dbTrx TrxObj, params *entity.FraudDecisionEntity) error {
+	funcName := "FraudRepository.CreateDecision"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *FraudRepository) FindDecisionByID(ctx context.Context, id uint64) (*entity.FraudDecisionEntity, error) {
+	funcName := "FraudRepository.FindDecisionByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var decision entity.FraudDecisionEntity
+	if err := r.db.WithContext(ctx).First(&decision, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &decision, nil
+}
+
+// FindDecisions returns one page of decisions, oldest first so the review queue is
+// worked in arrival order
+func (r *FraudRepository) FindDecisions(ctx context.Context, filter *entity.FraudDecisionFilter) ([]entity.FraudDecisionEntity, int64, error) {
+	funcName := "FraudRepository.FindDecisions"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).Model(&entity.FraudDecisionEntity{})
+	if filter.Decision != "" {
+		query = query.Where("decision = ?", filter.Decision)
+	}
+	if filter.ReviewStatus != "" {
+		query = query.Where("review_status = ?", filter.ReviewStatus)
+	}
+	if filter.MerchantID != 0 {
+		query = query.Where("merchant_id = ?", filter.MerchantID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	var decisions []entity.FraudDecisionEntity
+	if err := query.
+		Order("created_at ASC, id ASC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&decisions).
+		Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+	return decisions, total, nil
+}
+
+func (r *FraudRepository) LockDecisionByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.FraudDecisionEntity, error) {
+	funcName := "FraudRepository.LockDecisionByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var decision entity.FraudDecisionEntity
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&decision, id).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &decision, nil
+}
+
+func (r *FraudRepository) UpdateDecision(ctx context.Context, dbTrx TrxObj, params *entity.FraudDecisionEntity, changes map[string]interface{}) error {
+	funcName := "FraudRepository.UpdateDecision"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
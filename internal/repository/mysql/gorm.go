@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"database/sql"
+	"strings"
 
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
@@ -88,3 +89,8 @@ func DBTransaction(repo TrxSupportRepo, callback func(TrxObj) error) (err error)
 
 	return err
 }
+
+// escapeLike escapes the LIKE wildcards of a user supplied keyword
+func escapeLike(keyword string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(keyword)
+}
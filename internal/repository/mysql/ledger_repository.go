@@ -0,0 +1,194 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ILedgerRepository interface {
+	TrxSupportRepo
+	FindOrCreateAccount(ctx context.Context, dbTrx TrxObj, account *entity.LedgerAccountEntity) (*entity.LedgerAccountEntity, error)
+	ExistsJournalEntry(ctx context.Context, dbTrx TrxObj, entryType string, referenceType string, referenceID uint64) (bool, error)
+	CreateJournalEntry(ctx context.Context, dbTrx TrxObj, entry *entity.JournalEntryEntity, postings []entity.LedgerPostingEntity) error
+	GetMerchantBalances(ctx context.Context, merchantID uint64) ([]entity.LedgerAccountBalance, error)
+	FindMerchantBalancesAbove(ctx context.Context, code string, minBalance float64) ([]entity.LedgerMerchantBalance, error)
+}
+
+type LedgerRepository struct {
+	GormTrxSupport
+}
+
+func NewLedgerRepository(mysql *config.Mysql) *LedgerRepository {
+	return &LedgerRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+// FindOrCreateAccount returns the account with the code and merchant of the given
+// account, creating it from the given values the first time it is used
+func (r *LedgerRepository) FindOrCreateAccount(ctx context.Context, dbTrx TrxObj, account *entity.LedgerAccountEntity) (*entity.LedgerAccountEntity, error) {
+	funcName := "LedgerRepository.FindOrCreateAccount"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	found, err := r.findAccount(ctx, dbTrx, account.Code, account.MerchantID)
+	if err == nil {
+		return found, nil
+	}
+	if !errwrap.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	// A concurrent request may create the same account, the unique index keeps a single row
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(account).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	found, err = r.findAccount(ctx, dbTrx, account.Code, account.MerchantID)
+	if err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return found, nil
+}
+
+func (r *LedgerRepository) findAccount(ctx context.Context, dbTrx TrxObj, code string, merchantID *uint64) (*entity.LedgerAccountEntity, error) {
+	query := r.Trx(dbTrx).WithContext(ctx).Where("code = ?", code)
+	if merchantID == nil {
+		query = query.Where("merchant_id IS NULL")
+	} else {
+		query = query.Where("merchant_id = ?", *merchantID)
+	}
+
+	var account entity.LedgerAccountEntity
+	if err := query.First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *LedgerRepository) ExistsJournalEntry(ctx context.Context, dbTrx TrxObj, entryType string, referenceType string, referenceID uint64) (bool, error) {
+	funcName := "LedgerRepository.ExistsJournalEntry"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return false, errwrap.Wrap(err, funcName)
+	}
+
+	var count int64
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Model(&entity.JournalEntryEntity{}).
+		Where("entry_type = ? AND reference_type = ? AND reference_id = ?", entryType, referenceType, referenceID).
+		Count(&count).
+		Error; err != nil {
+		return false, errwrap.Wrap(err, funcName)
+	}
+	return count > 0, nil
+}
+
+// CreateJournalEntry writes an entry with its postings. Entries that do not balance
+// are refused before anything is written.
+func (r *LedgerRepository) CreateJournalEntry(ctx context.Context, dbTrx TrxObj, entry *entity.JournalEntryEntity, postings []entity.LedgerPostingEntity) error {
+	funcName := "LedgerRepository.CreateJournalEntry"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := validatePostings(postings); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	db := r.Trx(dbTrx).WithContext(ctx)
+	if err := db.Create(entry).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	for i := range postings {
+		postings[i].JournalEntryID = entry.ID
+	}
+	if err := db.Create(&postings).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	return nil
+}
+
+// validatePostings checks that an entry has positive amounts and that its debits equal its credits
+func validatePostings(postings []entity.LedgerPostingEntity) error {
+	if len(postings) < 2 {
+		return fmt.Errorf("journal entry needs at least two postings")
+	}
+
+	var debit, credit float64
+	for _, posting := range postings {
+		if posting.Amount <= 0 {
+			return fmt.Errorf("posting amount must be positive, got %.2f", posting.Amount)
+		}
+		switch posting.Direction {
+		case entity.LedgerDebit:
+			debit += posting.Amount
+		case entity.LedgerCredit:
+			credit += posting.Amount
+		default:
+			return fmt.Errorf("unknown posting direction %q", posting.Direction)
+		}
+	}
+
+	// Amounts are DECIMAL(15, 2), compare in cents to avoid float noise
+	if math.Round(debit*100) != math.Round(credit*100) {
+		return fmt.Errorf("journal entry is not balanced: debit %.2f, credit %.2f", debit, credit)
+	}
+	return nil
+}
+
+func (r *LedgerRepository) GetMerchantBalances(ctx context.Context, merchantID uint64) ([]entity.LedgerAccountBalance, error) {
+	funcName := "LedgerRepository.GetMerchantBalances"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var balances []entity.LedgerAccountBalance
+	if err := r.db.WithContext(ctx).
+		Raw(`SELECT a.code,
+			COALESCE(SUM(CASE WHEN p.direction = a.normal_balance THEN p.amount ELSE -p.amount END), 0) AS balance
+		FROM ledger_accounts a
+		LEFT JOIN ledger_postings p ON p.ledger_account_id = a.id
+		WHERE a.merchant_id = ?
+		GROUP BY a.code`, merchantID).
+		Scan(&balances).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return balances, nil
+}
+
+// FindMerchantBalancesAbove lists the merchants whose account with the given code holds at least minBalance
+func (r *LedgerRepository) FindMerchantBalancesAbove(ctx context.Context, code string, minBalance float64) ([]entity.LedgerMerchantBalance, error) {
+	funcName := "LedgerRepository.FindMerchantBalancesAbove"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var balances []entity.LedgerMerchantBalance
+	if err := r.db.WithContext(ctx).
+		Raw(`SELECT a.merchant_id,
+			SUM(CASE WHEN p.direction = a.normal_balance THEN p.amount ELSE -p.amount END) AS balance
+		FROM ledger_accounts a
+		JOIN ledger_postings p ON p.ledger_account_id = a.id
+		WHERE a.code = ? AND a.merchant_id IS NOT NULL
+		GROUP BY a.merchant_id
+		HAVING balance >= ?
+		ORDER BY a.merchant_id`, code, minBalance).
+		Scan(&balances).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return balances, nil
+}
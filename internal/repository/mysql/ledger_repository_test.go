@@ -0,0 +1,107 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	gmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type LedgerRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	db   *sql.DB
+	repo *mysql.LedgerRepository
+}
+
+func TestLedgerRepository(t *testing.T) {
+	suite.Run(t, new(LedgerRepositoryTestSuite))
+}
+
+func (s *LedgerRepositoryTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	if err != nil {
+		s.Failf("an error '%s' was not expected when opening a stub database connection", err.Error())
+	}
+
+	dialector := gmysql.New(gmysql.Config{Conn: s.db, SkipInitializeWithVersion: true})
+	gormDB, _ := gorm.Open(dialector, &gorm.Config{})
+	s.repo = mysql.NewLedgerRepository(&config.Mysql{DB: gormDB})
+}
+
+func (s *LedgerRepositoryTestSuite) TearDownTest() {
+	s.db.Close()
+}
+
+func (s *LedgerRepositoryTestSuite) TestCreateJournalEntryRejectsInvalidPostings() {
+	testcases := []struct {
+		name     string
+		postings []entity.LedgerPostingEntity
+	}{
+		{
+			name: "unbalanced",
+			postings: []entity.LedgerPostingEntity{
+				{LedgerAccountID: 1, Direction: entity.LedgerDebit, Amount: 100},
+				{LedgerAccountID: 2, Direction: entity.LedgerCredit, Amount: 99.99},
+			},
+		},
+		{
+			name: "single posting",
+			postings: []entity.LedgerPostingEntity{
+				{LedgerAccountID: 1, Direction: entity.LedgerDebit, Amount: 100},
+			},
+		},
+		{
+			name: "negative amount",
+			postings: []entity.LedgerPostingEntity{
+				{LedgerAccountID: 1, Direction: entity.LedgerDebit, Amount: -100},
+				{LedgerAccountID: 2, Direction: entity.LedgerCredit, Amount: -100},
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		s.T().Run(tt.name, func(t *testing.T) {
+			err := s.repo.CreateJournalEntry(context.Background(), nil, &entity.JournalEntryEntity{}, tt.postings)
+			s.Error(err)
+		})
+	}
+	// Nothing may reach the database when the entry is refused
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *LedgerRepositoryTestSuite) TestCreateJournalEntry() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `journal_entries`")).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	s.mock.ExpectCommit()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `ledger_postings`")).
+		WillReturnResult(sqlmock.NewResult(9, 2))
+	s.mock.ExpectCommit()
+
+	postings := []entity.LedgerPostingEntity{
+		{LedgerAccountID: 1, Direction: entity.LedgerDebit, Amount: 0.1 + 0.2},
+		{LedgerAccountID: 2, Direction: entity.LedgerCredit, Amount: 0.3},
+	}
+	err := s.repo.CreateJournalEntry(context.Background(), nil, &entity.JournalEntryEntity{
+		EntryType:     entity.JournalEntryPayment,
+		ReferenceType: entity.JournalReferenceTransaction,
+		ReferenceID:   10,
+	}, postings)
+
+	s.NoError(err)
+	s.Equal(uint64(5), postings[0].JournalEntryID)
+	s.Equal(uint64(5), postings[1].JournalEntryID)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,69 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type IMerchantImportRepository interface {
+	TrxSupportRepo
+	FindByID(ctx context.Context, id uint64) (*entity.MerchantImportEntity, error)
+	Create(ctx context.Context, dbTrx TrxObj, params *entity.MerchantImportEntity) error
+	Update(ctx context.Context, dbTrx TrxObj, params *entity.MerchantImportEntity, changes map[string]interface{}) error
+}
+
+type MerchantImportRepository struct {
+	GormTrxSupport
+}
+
+func NewMerchantImportRepository(mysql *config.Mysql) *MerchantImportRepository {
+	return &MerchantImportRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *MerchantImportRepository) FindByID(ctx context.Context, id uint64) (*entity.MerchantImportEntity, error) {
+	funcName := "MerchantImportRepository.FindByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var merchantImport entity.MerchantImportEntity
+	if err := r.db.WithContext(ctx).First(&merchantImport, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &merchantImport, nil
+}
+
+func (r *MerchantImportRepository) Create(ctx context.Context, dbTrx TrxObj, params *entity.MerchantImportEntity) error {
+	funcName := "MerchantImportRepository.Create"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+// Update takes a column map because progress updates need to write zero values
+// such as an empty error message
+func (r *MerchantImportRepository) Update(ctx context.Context, dbTrx TrxObj, params *entity.MerchantImportEntity, changes map[string]interface{}) error {
+	funcName := "MerchantImportRepository.Update"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type IMerchantOnboardingRepository interface {
+	TrxSupportRepo
+	CreateDocument(ctx context.Context, dbTrx TrxObj, params *entity.MerchantDocumentEntity) error
+	FindDocumentByID(ctx context.Context, id uint64) (*entity.MerchantDocumentEntity, error)
+	FindDocumentsByMerchantID(ctx context.Context, merchantID uint64) ([]entity.MerchantDocumentEntity, error)
+	CreateReview(ctx context.Context, dbTrx TrxObj, params *entity.MerchantReviewEntity) error
+	FindReviewsByMerchantID(ctx context.Context, merchantID uint64) ([]entity.MerchantReviewEntity, error)
+}
+
+type MerchantOnboardingRepository struct {
+	GormTrxSupport
+}
+
+func NewMerchantOnboardingRepository(mysql *config.Mysql) *MerchantOnboardingRepository {
+	return &MerchantOnboardingRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *MerchantOnboardingRepository) CreateDocument(ctx context.Context, dbTrx TrxObj, params *entity.MerchantDocumentEntity) error {
+	funcName := "MerchantOnboardingRepository.CreateDocument"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *MerchantOnboardingRepository) FindDocumentByID(ctx context.Context, id uint64) (*entity.MerchantDocumentEntity, error) {
+	funcName := "MerchantOnboardingRepository.FindDocumentByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var document entity.MerchantDocumentEntity
+	if err := r.db.WithContext(ctx).First(&document, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &document, nil
+}
+
+// FindDocumentsByMerchantID returns every document uploaded by the merchant, oldest first
+func (r *MerchantOnboardingRepository) FindDocumentsByMerchantID(ctx context.Context, merchantID uint64) ([]entity.MerchantDocumentEntity, error) {
+	funcName := "MerchantOnboardingRepository.FindDocumentsByMerchantID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var documents []entity.MerchantDocumentEntity
+	if err := r.db.WithContext(ctx).
+		Where("merchant_id = ?", merchantID).
+		Order("id ASC").
+		Find(&documents).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return documents, nil
+}
+
+func (r *MerchantOnboardingRepository) CreateReview(ctx context.Context, dbTrx TrxObj, params *entity.MerchantReviewEntity) error {
+	funcName := "MerchantOnboardingRepository.CreateReview"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+// FindReviewsByMerchantID returns the onboarding history of the merchant, oldest first
+func (r *MerchantOnboardingRepository) FindReviewsByMerchantID(ctx context.Context, merchantID uint64) ([]entity.MerchantReviewEntity, error) {
+	funcName := "MerchantOnboardingRepository.FindReviewsByMerchantID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var reviews []entity.MerchantReviewEntity
+	if err := r.db.WithContext(ctx).
+		Where("merchant_id = ?", merchantID).
+		Order("created_at ASC, id ASC").
+		Find(&reviews).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return reviews, nil
+}
@@ -2,6 +2,9 @@ package mysql
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/kharisma-wardhana/final-project-spe-academy/config"
 	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
@@ -14,11 +17,20 @@ import (
 type IMerchantRepository interface {
 	TrxSupportRepo
 	FindByID(ctx context.Context, id uint64) (*entity.MerchantEntity, error)
+	FindByIDWithDeleted(ctx context.Context, id uint64) (*entity.MerchantEntity, error)
 	FindByMID(ctx context.Context, mid string) (*entity.MerchantEntity, error)
+	FindAll(ctx context.Context, filter *entity.MerchantFilter) ([]entity.MerchantEntity, int64, error)
+	FindChildren(ctx context.Context, parentID uint64) ([]entity.MerchantEntity, error)
 	LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (result *entity.MerchantEntity, err error)
 	Create(ctx context.Context, dbTrx TrxObj, params *entity.MerchantEntity, nonZeroVal bool) error
+	CreateBatch(ctx context.Context, dbTrx TrxObj, merchants []*entity.MerchantEntity) error
 	Update(ctx context.Context, dbTrx TrxObj, params *entity.MerchantEntity, changes *entity.MerchantEntity) (err error)
-	DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64) error
+	UpdateParent(ctx context.Context, dbTrx TrxObj, params *entity.MerchantEntity, parentID *uint64, settleAtParent bool) error
+	DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64, deletedAt time.Time) error
+	Restore(ctx context.Context, dbTrx TrxObj, id uint64) error
+	FindDeletedBefore(ctx context.Context, deletedBefore time.Time, afterID uint64, limit int) ([]entity.MerchantEntity, error)
+	HasFinancialHistory(ctx context.Context, id uint64) (bool, error)
+	Purge(ctx context.Context, dbTrx TrxObj, id uint64) error
 }
 
 type MerchantRepository struct {
@@ -37,7 +49,7 @@ func (r *MerchantRepository) FindByID(ctx context.Context, id uint64) (*entity.M
 
 	var merchant entity.MerchantEntity
 	if err := r.db.
-		Raw("SELECT * FROM merchants WHERE id = ?", id).
+		Raw("SELECT * FROM merchants WHERE id = ? AND deleted_at IS NULL", id).
 		First(&merchant).
 		Error; err != nil {
 		if errwrap.Is(err, gorm.ErrRecordNotFound) {
@@ -48,6 +60,24 @@ func (r *MerchantRepository) FindByID(ctx context.Context, id uint64) (*entity.M
 	return &merchant, nil
 }
 
+// FindByIDWithDeleted also finds a soft deleted merchant, for the records that outlive
+// the merchant such as the payout of its remaining balance
+func (r *MerchantRepository) FindByIDWithDeleted(ctx context.Context, id uint64) (*entity.MerchantEntity, error) {
+	funcName := "MerchantRepository.FindByIDWithDeleted"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var merchant entity.MerchantEntity
+	if err := r.db.WithContext(ctx).Unscoped().First(&merchant, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &merchant, nil
+}
+
 func (r *MerchantRepository) FindByMID(ctx context.Context, mid string) (*entity.MerchantEntity, error) {
 	funcName := "MerchantRepository.FindByMID"
 	if err := helper.CheckDeadline(ctx); err != nil {
@@ -56,7 +86,7 @@ func (r *MerchantRepository) FindByMID(ctx context.Context, mid string) (*entity
 
 	var merchant entity.MerchantEntity
 	if err := r.db.
-		Raw("SELECT * FROM merchants WHERE mid = ?", mid).
+		Raw("SELECT * FROM merchants WHERE mid = ? AND deleted_at IS NULL", mid).
 		First(&merchant).
 		Error; err != nil {
 
@@ -69,6 +99,92 @@ func (r *MerchantRepository) FindByMID(ctx context.Context, mid string) (*entity
 	return &merchant, nil
 }
 
+// merchantSortColumns whitelists the columns a merchant list can be ordered by
+var merchantSortColumns = map[string]string{
+	"name":       "name",
+	"mid":        "mid",
+	"city":       "city",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func (r *MerchantRepository) FindAll(ctx context.Context, filter *entity.MerchantFilter) ([]entity.MerchantEntity, int64, error) {
+	funcName := "MerchantRepository.FindAll"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).Model(&entity.MerchantEntity{})
+	if filter.IDs != nil {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.City != "" {
+		query = query.Where("city = ?", filter.City)
+	}
+	if filter.Province != "" {
+		query = query.Where("province = ?", filter.Province)
+	}
+	if filter.MCC != "" {
+		query = query.Where("mcc = ?", filter.MCC)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	if filter.Search != "" {
+		keyword := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("name LIKE ? OR email LIKE ?", keyword, keyword)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	sortColumn, ok := merchantSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = "created_at"
+	}
+	sortDir := "DESC"
+	if strings.EqualFold(filter.SortDir, "asc") {
+		sortDir = "ASC"
+	}
+
+	var merchants []entity.MerchantEntity
+	if err := query.
+		Order(fmt.Sprintf("%s %s, id %s", sortColumn, sortDir, sortDir)).
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&merchants).
+		Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	return merchants, total, nil
+}
+
+func (r *MerchantRepository) FindChildren(ctx context.Context, parentID uint64) ([]entity.MerchantEntity, error) {
+	funcName := "MerchantRepository.FindChildren"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var merchants []entity.MerchantEntity
+	if err := r.db.WithContext(ctx).
+		Where("parent_id = ?", parentID).
+		Order("id ASC").
+		Find(&merchants).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return merchants, nil
+}
+
 func (r *MerchantRepository) LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (result *entity.MerchantEntity, err error) {
 	funcName := "MerchantRepository.LockByID"
 	if err := helper.CheckDeadline(ctx); err != nil {
@@ -76,7 +192,7 @@ func (r *MerchantRepository) LockByID(ctx context.Context, dbTrx TrxObj, id uint
 	}
 
 	err = r.Trx(dbTrx).
-		Raw("SELECT * FROM merchants WHERE id = ? FOR UPDATE", id).
+		Raw("SELECT * FROM merchants WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id).
 		Scan(&result).Error
 
 	if errwrap.Is(err, gorm.ErrRecordNotFound) {
@@ -96,34 +212,177 @@ func (r *MerchantRepository) Create(ctx context.Context, dbTrx TrxObj, params *e
 	return r.Trx(dbTrx).Select(cols).Create(&params).Error
 }
 
+// CreateBatch inserts all merchants in one statement, either all of them are stored
+// or none is
+func (r *MerchantRepository) CreateBatch(ctx context.Context, dbTrx TrxObj, merchants []*entity.MerchantEntity) error {
+	funcName := "MerchantRepository.CreateBatch"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).Create(merchants).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
 func (r *MerchantRepository) Update(ctx context.Context, dbTrx TrxObj, params *entity.MerchantEntity, changes *entity.MerchantEntity) (err error) {
 	funcName := "MerchantRepository.Update"
 	if err := helper.CheckDeadline(ctx); err != nil {
 		return errwrap.Wrap(err, funcName)
 	}
 
-	db := r.Trx(dbTrx).Model(params)
+	// The row only changes while it is still at the version params was read with
+	version := params.Version
+	db := r.Trx(dbTrx).Model(params).Where("version = ?", version)
+	var result *gorm.DB
 	if changes != nil {
-		err = db.Updates(*changes).Error
+		changes.Version = version + 1
+		result = db.Updates(*changes)
 	} else {
-		err = db.Updates(helper.StructToMap(params, false)).Error
+		params.Version = version + 1
+		result = db.Updates(helper.StructToMap(params, false))
 	}
 
-	if err != nil {
+	if result.Error != nil {
+		return errwrap.Wrap(result.Error, funcName)
+	}
+	if result.RowsAffected == 0 {
+		return appErr.ErrStaleVersion()
+	}
+	params.Version = version + 1
+
+	return nil
+}
+
+// UpdateParent sets or, with a nil parentID, clears the parent of a merchant. Update
+// cannot do it since it skips zero values.
+func (r *MerchantRepository) UpdateParent(ctx context.Context, dbTrx TrxObj, params *entity.MerchantEntity, parentID *uint64, settleAtParent bool) error {
+	funcName := "MerchantRepository.UpdateParent"
+	if err := helper.CheckDeadline(ctx); err != nil {
 		return errwrap.Wrap(err, funcName)
 	}
 
+	version := params.Version
+	result := r.Trx(dbTrx).Model(params).Where("version = ?", version).Updates(map[string]interface{}{
+		"parent_id":        parentID,
+		"settle_at_parent": settleAtParent,
+		"version":          version + 1,
+	})
+	if result.Error != nil {
+		return errwrap.Wrap(result.Error, funcName)
+	}
+	if result.RowsAffected == 0 {
+		return appErr.ErrStaleVersion()
+	}
+	params.Version = version + 1
 	return nil
 }
 
-func (r *MerchantRepository) DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64) error {
+// DeleteByID soft deletes the merchant. The caller passes deletedAt so the accounts
+// deleted along with the merchant carry the same time and can be restored with it.
+func (r *MerchantRepository) DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64, deletedAt time.Time) error {
 	funcName := "MerchantRepository.DeleteByID"
 	if err := helper.CheckDeadline(ctx); err != nil {
 		return errwrap.Wrap(err, funcName)
 	}
 
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.MerchantEntity{}).Error; err != nil {
-		return err
+	result := r.Trx(dbTrx).Model(&entity.MerchantEntity{}).Where("id = ?", id).Update("deleted_at", deletedAt)
+	if result.Error != nil {
+		return errwrap.Wrap(result.Error, funcName)
+	}
+	if result.RowsAffected == 0 {
+		return appErr.ErrRecordNotFound()
+	}
+	return nil
+}
+
+func (r *MerchantRepository) Restore(ctx context.Context, dbTrx TrxObj, id uint64) error {
+	funcName := "MerchantRepository.Restore"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	result := r.Trx(dbTrx).Unscoped().
+		Model(&entity.MerchantEntity{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return errwrap.Wrap(result.Error, funcName)
+	}
+	if result.RowsAffected == 0 {
+		return appErr.ErrRecordNotFound()
+	}
+	return nil
+}
+
+// FindDeletedBefore pages through the merchants soft deleted before the given time by
+// id, afterID is the last id of the previous page
+func (r *MerchantRepository) FindDeletedBefore(ctx context.Context, deletedBefore time.Time, afterID uint64, limit int) ([]entity.MerchantEntity, error) {
+	funcName := "MerchantRepository.FindDeletedBefore"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var merchants []entity.MerchantEntity
+	if err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND id > ?", deletedBefore, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&merchants).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return merchants, nil
+}
+
+// financialTables hold the records a merchant may never lose, a merchant with a row
+// in any of them is kept soft deleted for good
+var financialTables = []string{
+	"transactions",
+	"journal_entries",
+	"ledger_accounts",
+	"payouts",
+	"disputes",
+	"fraud_decisions",
+}
+
+func (r *MerchantRepository) HasFinancialHistory(ctx context.Context, id uint64) (bool, error) {
+	funcName := "MerchantRepository.HasFinancialHistory"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return false, errwrap.Wrap(err, funcName)
+	}
+
+	for _, table := range financialTables {
+		var found int64
+		if err := r.db.WithContext(ctx).
+			Raw(fmt.Sprintf("SELECT 1 FROM %s WHERE merchant_id = ? LIMIT 1", table), id).
+			Scan(&found).
+			Error; err != nil {
+			return false, errwrap.Wrap(err, funcName)
+		}
+		if found == 1 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Purge removes a soft deleted merchant for good. The merchant's accounts have to be
+// purged first, its outlets, terminals, limits and onboarding records go with it.
+func (r *MerchantRepository) Purge(ctx context.Context, dbTrx TrxObj, id uint64) error {
+	funcName := "MerchantRepository.Purge"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).
+		Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Delete(&entity.MerchantEntity{}).
+		Error; err != nil {
+		return errwrap.Wrap(err, funcName)
 	}
 	return nil
 }
@@ -0,0 +1,138 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	gmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type MerchantRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	db   *sql.DB
+	repo *mysql.MerchantRepository
+}
+
+func TestMerchantRepository(t *testing.T) {
+	suite.Run(t, new(MerchantRepositoryTestSuite))
+}
+
+func (s *MerchantRepositoryTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	if err != nil {
+		s.Failf("an error '%s' was not expected when opening a stub database connection", err.Error())
+	}
+
+	dialector := gmysql.New(gmysql.Config{Conn: s.db, SkipInitializeWithVersion: true})
+	gormDB, _ := gorm.Open(dialector, &gorm.Config{})
+	s.repo = mysql.NewMerchantRepository(&config.Mysql{DB: gormDB})
+}
+
+func (s *MerchantRepositoryTestSuite) TearDownTest() {
+	s.db.Close()
+}
+
+func (s *MerchantRepositoryTestSuite) TestFindAllWithFilter() {
+	createdFrom := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	filter := &entity.MerchantFilter{
+		Status:      "active",
+		City:        "Jakarta",
+		Search:      "50%_off",
+		CreatedFrom: &createdFrom,
+		SortBy:      "name",
+		SortDir:     "asc",
+		Limit:       10,
+		Offset:      20,
+	}
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `merchants` WHERE status = ? AND city = ? AND created_at >= ? AND (name LIKE ? OR email LIKE ?) AND `merchants`.`deleted_at` IS NULL",
+	)).
+		WithArgs("active", "Jakarta", createdFrom, `%50\%\_off%`, `%50\%\_off%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `merchants` WHERE status = ? AND city = ? AND created_at >= ? AND (name LIKE ? OR email LIKE ?) AND `merchants`.`deleted_at` IS NULL ORDER BY name ASC, id ASC LIMIT ? OFFSET ?",
+	)).
+		WithArgs("active", "Jakarta", createdFrom, `%50\%\_off%`, `%50\%\_off%`, 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(21, "Kopi 50% Off"))
+
+	merchants, total, err := s.repo.FindAll(context.Background(), filter)
+
+	s.NoError(err)
+	s.Equal(int64(21), total)
+	s.Require().Len(merchants, 1)
+	s.Equal("Kopi 50% Off", merchants[0].Name)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *MerchantRepositoryTestSuite) TestFindAllFallsBackToDefaultSort() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `merchants` WHERE `merchants`.`deleted_at` IS NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `merchants` WHERE `merchants`.`deleted_at` IS NULL ORDER BY created_at DESC, id DESC LIMIT ?")).
+		WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, _, err := s.repo.FindAll(context.Background(), &entity.MerchantFilter{SortBy: "password; DROP TABLE", Limit: 20})
+
+	s.NoError(err)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *MerchantRepositoryTestSuite) TestFindAllLimitedToIDs() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `merchants` WHERE id IN (?,?) AND `merchants`.`deleted_at` IS NULL")).
+		WithArgs(3, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `merchants` WHERE id IN (?,?) AND `merchants`.`deleted_at` IS NULL ORDER BY created_at DESC, id DESC LIMIT ?")).
+		WithArgs(3, 8, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8).AddRow(3))
+
+	merchants, total, err := s.repo.FindAll(context.Background(), &entity.MerchantFilter{IDs: []uint64{3, 8}, Limit: 20})
+
+	s.NoError(err)
+	s.Equal(int64(2), total)
+	s.Len(merchants, 2)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *MerchantRepositoryTestSuite) TestUpdateBumpsVersion() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `merchants` SET `name`=?,`updated_at`=?,`version`=? WHERE version = ? AND `merchants`.`deleted_at` IS NULL AND `id` = ?")).
+		WithArgs("Kopi Senja", sqlmock.AnyArg(), 4, 3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	merchant := &entity.MerchantEntity{ID: 7, Name: "Kopi Pagi", Version: 3}
+	err := s.repo.Update(context.Background(), nil, merchant, &entity.MerchantEntity{Name: "Kopi Senja"})
+
+	s.NoError(err)
+	s.Equal(uint64(4), merchant.Version)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *MerchantRepositoryTestSuite) TestUpdateStaleVersion() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `merchants` SET `name`=?,`updated_at`=?,`version`=? WHERE version = ? AND `merchants`.`deleted_at` IS NULL AND `id` = ?")).
+		WithArgs("Kopi Senja", sqlmock.AnyArg(), 4, 3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	merchant := &entity.MerchantEntity{ID: 7, Name: "Kopi Pagi", Version: 3}
+	err := s.repo.Update(context.Background(), nil, merchant, &entity.MerchantEntity{Name: "Kopi Senja"})
+
+	s.Equal(appErr.ErrStaleVersion(), err)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
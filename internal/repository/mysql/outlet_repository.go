@@ -0,0 +1,202 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IOutletRepository interface {
+	TrxSupportRepo
+	CreateOutlet(ctx context.Context, dbTrx TrxObj, params *entity.OutletEntity) error
+	FindOutletByID(ctx context.Context, id uint64) (*entity.OutletEntity, error)
+	FindOutletsByMerchantID(ctx context.Context, merchantID uint64) ([]entity.OutletEntity, error)
+	FindOutletsByIDs(ctx context.Context, ids []uint64) ([]entity.OutletEntity, error)
+	UpdateOutlet(ctx context.Context, dbTrx TrxObj, params *entity.OutletEntity, changes map[string]interface{}) error
+	CreateTerminal(ctx context.Context, dbTrx TrxObj, params *entity.TerminalEntity) error
+	FindTerminalByTerminalID(ctx context.Context, terminalID string) (*entity.TerminalEntity, error)
+	FindTerminalByDeviceSerial(ctx context.Context, deviceSerial string) (*entity.TerminalEntity, error)
+	FindTerminals(ctx context.Context, filter *entity.TerminalFilter) ([]entity.TerminalEntity, error)
+	LockTerminalByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.TerminalEntity, error)
+	UpdateTerminal(ctx context.Context, dbTrx TrxObj, params *entity.TerminalEntity, changes map[string]interface{}) error
+}
+
+type OutletRepository struct {
+	GormTrxSupport
+}
+
+func NewOutletRepository(mysql *config.Mysql) *OutletRepository {
+	return &OutletRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *OutletRepository) CreateOutlet(ctx context.Context, dbTrx TrxObj, params *entity.OutletEntity) error {
+	funcName := "OutletRepository.CreateOutlet"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *OutletRepository) FindOutletByID(ctx context.Context, id uint64) (*entity.OutletEntity, error) {
+	funcName := "OutletRepository.FindOutletByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var outlet entity.OutletEntity
+	if err := r.db.WithContext(ctx).First(&outlet, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &outlet, nil
+}
+
+func (r *OutletRepository) FindOutletsByMerchantID(ctx context.Context, merchantID uint64) ([]entity.OutletEntity, error) {
+	funcName := "OutletRepository.FindOutletsByMerchantID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var outlets []entity.OutletEntity
+	if err := r.db.WithContext(ctx).
+		Where("merchant_id = ?", merchantID).
+		Order("name ASC, id ASC").
+		Find(&outlets).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return outlets, nil
+}
+
+func (r *OutletRepository) FindOutletsByIDs(ctx context.Context, ids []uint64) ([]entity.OutletEntity, error) {
+	funcName := "OutletRepository.FindOutletsByIDs"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var outlets []entity.OutletEntity
+	if len(ids) == 0 {
+		return outlets, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&outlets).Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return outlets, nil
+}
+
+func (r *OutletRepository) UpdateOutlet(ctx context.Context, dbTrx TrxObj, params *entity.OutletEntity, changes map[string]interface{}) error {
+	funcName := "OutletRepository.UpdateOutlet"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *OutletRepository) CreateTerminal(ctx context.Context, dbTrx TrxObj, params *entity.TerminalEntity) error {
+	funcName := "OutletRepository.CreateTerminal"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *OutletRepository) FindTerminalByTerminalID(ctx context.Context, terminalID string) (*entity.TerminalEntity, error) {
+	funcName := "OutletRepository.FindTerminalByTerminalID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var terminal entity.TerminalEntity
+	if err := r.db.WithContext(ctx).Where("terminal_id = ?", terminalID).First(&terminal).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &terminal, nil
+}
+
+func (r *OutletRepository) FindTerminalByDeviceSerial(ctx context.Context, deviceSerial string) (*entity.TerminalEntity, error) {
+	funcName := "OutletRepository.FindTerminalByDeviceSerial"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var terminal entity.TerminalEntity
+	if err := r.db.WithContext(ctx).Where("device_serial = ?", deviceSerial).First(&terminal).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &terminal, nil
+}
+
+func (r *OutletRepository) FindTerminals(ctx context.Context, filter *entity.TerminalFilter) ([]entity.TerminalEntity, error) {
+	funcName := "OutletRepository.FindTerminals"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).Where("merchant_id = ?", filter.MerchantID)
+	if filter.OutletID != 0 {
+		query = query.Where("outlet_id = ?", filter.OutletID)
+	}
+
+	var terminals []entity.TerminalEntity
+	if err := query.Order("outlet_id ASC, terminal_id ASC").Find(&terminals).Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return terminals, nil
+}
+
+func (r *OutletRepository) LockTerminalByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.TerminalEntity, error) {
+	funcName := "OutletRepository.LockTerminalByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var terminal entity.TerminalEntity
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&terminal, id).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &terminal, nil
+}
+
+func (r *OutletRepository) UpdateTerminal(ctx context.Context, dbTrx TrxObj, params *entity.TerminalEntity, changes map[string]interface{}) error {
+	funcName := "OutletRepository.UpdateTerminal"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IParticipantRepository interface {
+	TrxSupportRepo
+	Create(ctx context.Context, dbTrx TrxObj, params *entity.ParticipantEntity) error
+	FindByID(ctx context.Context, id uint64) (*entity.ParticipantEntity, error)
+	FindByCode(ctx context.Context, code string) (*entity.ParticipantEntity, error)
+	FindByCodes(ctx context.Context, codes []string) ([]entity.ParticipantEntity, error)
+	FindByClientID(ctx context.Context, clientID string) (*entity.ParticipantEntity, error)
+	FindAll(ctx context.Context, filter *entity.ParticipantFilter) ([]entity.ParticipantEntity, int64, error)
+	LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.ParticipantEntity, error)
+	Update(ctx context.Context, dbTrx TrxObj, params *entity.ParticipantEntity, changes map[string]interface{}) error
+	DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64) error
+}
+
+type ParticipantRepository struct {
+	GormTrxSupport
+}
+
+func NewParticipantRepository(mysql *config.Mysql) *ParticipantRepository {
+	return &ParticipantRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *ParticipantRepository) Create(ctx context.Context, dbTrx TrxObj, params *entity.ParticipantEntity) error {
+	funcName := "ParticipantRepository.Create"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *ParticipantRepository) FindByID(ctx context.Context, id uint64) (*entity.ParticipantEntity, error) {
+	funcName := "ParticipantRepository.FindByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var participant entity.ParticipantEntity
+	if err := r.db.WithContext(ctx).First(&participant, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &participant, nil
+}
+
+func (r *ParticipantRepository) FindByCode(ctx context.Context, code string) (*entity.ParticipantEntity, error) {
+	funcName := "ParticipantRepository.FindByCode"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var participant entity.ParticipantEntity
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&participant).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &participant, nil
+}
+
+func (r *ParticipantRepository) FindByCodes(ctx context.Context, codes []string) ([]entity.ParticipantEntity, error) {
+	funcName := "ParticipantRepository.FindByCodes"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var participants []entity.ParticipantEntity
+	if len(codes) == 0 {
+		return participants, nil
+	}
+	if err := r.db.WithContext(ctx).Where("code IN ?", codes).Find(&participants).Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return participants, nil
+}
+
+func (r *ParticipantRepository) FindByClientID(ctx context.Context, clientID string) (*entity.ParticipantEntity, error) {
+	funcName := "ParticipantRepository.FindByClientID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var participant entity.ParticipantEntity
+	if err := r.db.WithContext(ctx).Where("client_id = ?", clientID).First(&participant).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &participant, nil
+}
+
+func (r *ParticipantRepository) FindAll(ctx context.Context, filter *entity.ParticipantFilter) ([]entity.ParticipantEntity, int64, error) {
+	funcName := "ParticipantRepository.FindAll"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).Model(&entity.ParticipantEntity{})
+	if filter.Role != "" {
+		query = query.Where("FIND_IN_SET(?, roles) > 0", filter.Role)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.Search != "" {
+		keyword := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("code LIKE ? OR name LIKE ?", keyword, keyword)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	var participants []entity.ParticipantEntity
+	if err := query.
+		Order("code ASC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&participants).
+		Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+	return participants, total, nil
+}
+
+func (r *ParticipantRepository) LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.ParticipantEntity, error) {
+	funcName := "ParticipantRepository.LockByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var participant entity.ParticipantEntity
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&participant, id).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &participant, nil
+}
+
+func (r *ParticipantRepository) Update(ctx context.Context, dbTrx TrxObj, params *entity.ParticipantEntity, changes map[string]interface{}) error {
+	funcName := "ParticipantRepository.Update"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *ParticipantRepository) DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64) error {
+	funcName := "ParticipantRepository.DeleteByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Delete(&entity.ParticipantEntity{}, id).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
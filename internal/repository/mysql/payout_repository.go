@@ -0,0 +1,214 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IPayoutRepository interface {
+	TrxSupportRepo
+	FindBatchByID(ctx context.Context, id uint64) (*entity.PayoutBatchEntity, error)
+	LockBatchByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.PayoutBatchEntity, error)
+	FindOrCreateBatch(ctx context.Context, batchDate time.Time, fileFormat string) (*entity.PayoutBatchEntity, error)
+	UpdateBatch(ctx context.Context, dbTrx TrxObj, params *entity.PayoutBatchEntity, changes map[string]interface{}) error
+	CreatePayout(ctx context.Context, dbTrx TrxObj, params *entity.PayoutEntity) error
+	UpdatePayout(ctx context.Context, dbTrx TrxObj, params *entity.PayoutEntity, changes map[string]interface{}) error
+	UpdatePayoutsByBatch(ctx context.Context, dbTrx TrxObj, batchID uint64, fromStatus string, changes map[string]interface{}) error
+	LockPayoutByReference(ctx context.Context, dbTrx TrxObj, reference string) (*entity.PayoutEntity, error)
+	FindPayoutsByBatchID(ctx context.Context, dbTrx TrxObj, batchID uint64) ([]entity.PayoutEntity, error)
+	FindPayoutsByMerchantID(ctx context.Context, merchantID uint64, limit int, offset int) ([]entity.PayoutEntity, int64, error)
+}
+
+type PayoutRepository struct {
+	GormTrxSupport
+}
+
+func NewPayoutRepository(mysql *config.Mysql) *PayoutRepository {
+	return &PayoutRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *PayoutRepository) FindBatchByID(ctx context.Context, id uint64) (*entity.PayoutBatchEntity, error) {
+	funcName := "PayoutRepository.FindBatchByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var batch entity.PayoutBatchEntity
+	if err := r.db.WithContext(ctx).First(&batch, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &batch, nil
+}
+
+func (r *PayoutRepository) LockBatchByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.PayoutBatchEntity, error) {
+	funcName := "PayoutRepository.LockBatchByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var batch entity.PayoutBatchEntity
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&batch, id).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &batch, nil
+}
+
+// FindOrCreateBatch returns the batch of the given day, there is at most one batch per day
+func (r *PayoutRepository) FindOrCreateBatch(ctx context.Context, batchDate time.Time, fileFormat string) (*entity.PayoutBatchEntity, error) {
+	funcName := "PayoutRepository.FindOrCreateBatch"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	batch := &entity.PayoutBatchEntity{
+		BatchDate:  batchDate,
+		FileFormat: fileFormat,
+		Status:     entity.PayoutBatchStatusCreated,
+	}
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(batch).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var found entity.PayoutBatchEntity
+	if err := r.db.WithContext(ctx).
+		Where("batch_date = ?", batchDate.Format("2006-01-02")).
+		First(&found).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &found, nil
+}
+
+func (r *PayoutRepository) UpdateBatch(ctx context.Context, dbTrx TrxObj, params *entity.PayoutBatchEntity, changes map[string]interface{}) error {
+	funcName := "PayoutRepository.UpdateBatch"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *PayoutRepository) CreatePayout(ctx context.Context, dbTrx TrxObj, params *entity.PayoutEntity) error {
+	funcName := "PayoutRepository.CreatePayout"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *PayoutRepository) UpdatePayout(ctx context.Context, dbTrx TrxObj, params *entity.PayoutEntity, changes map[string]interface{}) error {
+	funcName := "PayoutRepository.UpdatePayout"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *PayoutRepository) UpdatePayoutsByBatch(ctx context.Context, dbTrx TrxObj, batchID uint64, fromStatus string, changes map[string]interface{}) error {
+	funcName := "PayoutRepository.UpdatePayoutsByBatch"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).
+		Model(&entity.PayoutEntity{}).
+		Where("batch_id = ? AND status = ?", batchID, fromStatus).
+		Updates(changes).
+		Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *PayoutRepository) LockPayoutByReference(ctx context.Context, dbTrx TrxObj, reference string) (*entity.PayoutEntity, error) {
+	funcName := "PayoutRepository.LockPayoutByReference"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var payout entity.PayoutEntity
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("reference = ?", reference).
+		First(&payout).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &payout, nil
+}
+
+func (r *PayoutRepository) FindPayoutsByBatchID(ctx context.Context, dbTrx TrxObj, batchID uint64) ([]entity.PayoutEntity, error) {
+	funcName := "PayoutRepository.FindPayoutsByBatchID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var payouts []entity.PayoutEntity
+	if err := r.Trx(dbTrx).WithContext(ctx).
+		Where("batch_id = ?", batchID).
+		Order("id ASC").
+		Find(&payouts).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return payouts, nil
+}
+
+func (r *PayoutRepository) FindPayoutsByMerchantID(ctx context.Context, merchantID uint64, limit int, offset int) ([]entity.PayoutEntity, int64, error) {
+	funcName := "PayoutRepository.FindPayoutsByMerchantID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).Model(&entity.PayoutEntity{}).Where("merchant_id = ?", merchantID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	var payouts []entity.PayoutEntity
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&payouts).
+		Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+	return payouts, total, nil
+}
@@ -0,0 +1,151 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type IReconciliationRepository interface {
+	TrxSupportRepo
+	CreateRun(ctx context.Context, dbTrx TrxObj, params *entity.ReconciliationRunEntity) error
+	FindRunByID(ctx context.Context, id uint64) (*entity.ReconciliationRunEntity, error)
+	UpdateRun(ctx context.Context, dbTrx TrxObj, params *entity.ReconciliationRunEntity, changes map[string]interface{}) error
+	CreateItems(ctx context.Context, dbTrx TrxObj, items []entity.ReconciliationItemEntity) error
+	FindExceptions(ctx context.Context, filter *entity.ReconciliationItemFilter) ([]entity.ReconciliationItemEntity, int64, error)
+	FindItemByID(ctx context.Context, id uint64) (*entity.ReconciliationItemEntity, error)
+	UpdateItem(ctx context.Context, dbTrx TrxObj, params *entity.ReconciliationItemEntity, changes map[string]interface{}) error
+}
+
+type ReconciliationRepository struct {
+	GormTrxSupport
+}
+
+func NewReconciliationRepository(mysql *config.Mysql) *ReconciliationRepository {
+	return &ReconciliationRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+// reconciliationItemBatchSize keeps the INSERT of a large settlement file within packet limits
+const reconciliationItemBatchSize = 500
+
+func (r *ReconciliationRepository) CreateRun(ctx context.Context, dbTrx TrxObj, params *entity.ReconciliationRunEntity) error {
+	funcName := "ReconciliationRepository.CreateRun"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *ReconciliationRepository) FindRunByID(ctx context.Context, id uint64) (*entity.ReconciliationRunEntity, error) {
+	funcName := "ReconciliationRepository.FindRunByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var run entity.ReconciliationRunEntity
+	if err := r.db.WithContext(ctx).First(&run, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &run, nil
+}
+
+func (r *ReconciliationRepository) UpdateRun(ctx context.Context, dbTrx TrxObj, params *entity.ReconciliationRunEntity, changes map[string]interface{}) error {
+	funcName := "ReconciliationRepository.UpdateRun"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *ReconciliationRepository) CreateItems(ctx context.Context, dbTrx TrxObj, items []entity.ReconciliationItemEntity) error {
+	funcName := "ReconciliationRepository.CreateItems"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).CreateInBatches(&items, reconciliationItemBatchSize).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+// FindExceptions lists the items of a run that did not match, matched items are never returned
+func (r *ReconciliationRepository) FindExceptions(ctx context.Context, filter *entity.ReconciliationItemFilter) ([]entity.ReconciliationItemEntity, int64, error) {
+	funcName := "ReconciliationRepository.FindExceptions"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).
+		Model(&entity.ReconciliationItemEntity{}).
+		Where("run_id = ? AND result <> ?", filter.RunID, entity.ReconciliationMatched)
+	if filter.Result != "" {
+		query = query.Where("result = ?", filter.Result)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	var items []entity.ReconciliationItemEntity
+	if err := query.
+		Order("id ASC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&items).
+		Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+	return items, total, nil
+}
+
+func (r *ReconciliationRepository) FindItemByID(ctx context.Context, id uint64) (*entity.ReconciliationItemEntity, error) {
+	funcName := "ReconciliationRepository.FindItemByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var item entity.ReconciliationItemEntity
+	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &item, nil
+}
+
+func (r *ReconciliationRepository) UpdateItem(ctx context.Context, dbTrx TrxObj, params *entity.ReconciliationItemEntity, changes map[string]interface{}) error {
+	funcName := "ReconciliationRepository.UpdateItem"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
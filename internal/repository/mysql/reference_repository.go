@@ -0,0 +1,179 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IReferenceRepository interface {
+	TrxSupportRepo
+	FindMCC(ctx context.Context, code string) (*entity.MCCEntity, error)
+	FindMCCs(ctx context.Context, filter *entity.MCCFilter) ([]entity.MCCEntity, int64, error)
+	FindRegion(ctx context.Context, level string, parentCode string, name string) (*entity.RegionEntity, error)
+	FindRegions(ctx context.Context, filter *entity.RegionFilter) ([]entity.RegionEntity, error)
+	FindRegionsByCodes(ctx context.Context, codes []string) ([]entity.RegionEntity, error)
+	UpsertMCCs(ctx context.Context, dbTrx TrxObj, mccs []entity.MCCEntity) error
+	UpsertRegions(ctx context.Context, dbTrx TrxObj, regions []entity.RegionEntity) error
+}
+
+type ReferenceRepository struct {
+	GormTrxSupport
+}
+
+func NewReferenceRepository(mysql *config.Mysql) *ReferenceRepository {
+	return &ReferenceRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *ReferenceRepository) FindMCC(ctx context.Context, code string) (*entity.MCCEntity, error) {
+	funcName := "ReferenceRepository.FindMCC"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var mcc entity.MCCEntity
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&mcc).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &mcc, nil
+}
+
+func (r *ReferenceRepository) FindMCCs(ctx context.Context, filter *entity.MCCFilter) ([]entity.MCCEntity, int64, error) {
+	funcName := "ReferenceRepository.FindMCCs"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).Model(&entity.MCCEntity{})
+	if filter.Search != "" {
+		keyword := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("code LIKE ? OR description LIKE ?", keyword, keyword)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+
+	var mccs []entity.MCCEntity
+	if err := query.
+		Order("code ASC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&mccs).
+		Error; err != nil {
+		return nil, 0, errwrap.Wrap(err, funcName)
+	}
+	return mccs, total, nil
+}
+
+// FindRegion looks a region up by name under its parent, provinces have no parent.
+// The name comparison follows the column collation, so it is case insensitive.
+func (r *ReferenceRepository) FindRegion(ctx context.Context, level string, parentCode string, name string) (*entity.RegionEntity, error) {
+	funcName := "ReferenceRepository.FindRegion"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).Where("level = ? AND name = ?", level, name)
+	if parentCode == "" {
+		query = query.Where("parent_code IS NULL")
+	} else {
+		query = query.Where("parent_code = ?", parentCode)
+	}
+
+	var region entity.RegionEntity
+	if err := query.First(&region).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &region, nil
+}
+
+func (r *ReferenceRepository) FindRegions(ctx context.Context, filter *entity.RegionFilter) ([]entity.RegionEntity, error) {
+	funcName := "ReferenceRepository.FindRegions"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).Model(&entity.RegionEntity{})
+	if filter.Level != "" {
+		query = query.Where("level = ?", filter.Level)
+	}
+	if filter.ParentCode != "" {
+		query = query.Where("parent_code = ?", filter.ParentCode)
+	}
+	if filter.PostalCode != "" {
+		query = query.Where("postal_code = ?", filter.PostalCode)
+	}
+	if filter.Search != "" {
+		query = query.Where("name LIKE ?", "%"+escapeLike(filter.Search)+"%")
+	}
+
+	var regions []entity.RegionEntity
+	if err := query.Order("name ASC, code ASC").Find(&regions).Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return regions, nil
+}
+
+func (r *ReferenceRepository) FindRegionsByCodes(ctx context.Context, codes []string) ([]entity.RegionEntity, error) {
+	funcName := "ReferenceRepository.FindRegionsByCodes"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var regions []entity.RegionEntity
+	if len(codes) == 0 {
+		return regions, nil
+	}
+	if err := r.db.WithContext(ctx).Where("code IN ?", codes).Find(&regions).Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return regions, nil
+}
+
+// UpsertMCCs inserts new codes and refreshes the description of existing ones, so
+// the seed can be run again after the reference file changes
+func (r *ReferenceRepository) UpsertMCCs(ctx context.Context, dbTrx TrxObj, mccs []entity.MCCEntity) error {
+	funcName := "ReferenceRepository.UpsertMCCs"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"description", "updated_at"})}).
+		Create(&mccs).
+		Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+// UpsertRegions expects parents to come before their children, parent_code is a
+// foreign key
+func (r *ReferenceRepository) UpsertRegions(ctx context.Context, dbTrx TrxObj, regions []entity.RegionEntity) error {
+	funcName := "ReferenceRepository.UpsertRegions"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"parent_code", "level", "name", "postal_code", "updated_at"})}).
+		Create(&regions).
+		Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
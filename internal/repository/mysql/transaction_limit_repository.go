@@ -0,0 +1,120 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	errwrap "github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type ITransactionLimitRepository interface {
+	TrxSupportRepo
+	Create(ctx context.Context, dbTrx TrxObj, params *entity.TransactionLimitEntity) error
+	FindByMerchantID(ctx context.Context, merchantID uint64) (*entity.TransactionLimitEntity, error)
+	FindByMCC(ctx context.Context, mcc string) (*entity.TransactionLimitEntity, error)
+	FindApplicable(ctx context.Context, merchantID uint64, mcc string) (*entity.TransactionLimitEntity, error)
+	Update(ctx context.Context, dbTrx TrxObj, params *entity.TransactionLimitEntity, changes map[string]interface{}) error
+	DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64) error
+}
+
+type TransactionLimitRepository struct {
+	GormTrxSupport
+}
+
+func NewTransactionLimitRepository(mysql *config.Mysql) *TransactionLimitRepository {
+	return &TransactionLimitRepository{GormTrxSupport{db: mysql.DB}}
+}
+
+func (r *TransactionLimitRepository) Create(ctx context.Context, dbTrx TrxObj, params *entity.TransactionLimitEntity) error {
+	funcName := "TransactionLimitRepository.Create"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *TransactionLimitRepository) FindByMerchantID(ctx context.Context, merchantID uint64) (*entity.TransactionLimitEntity, error) {
+	funcName := "TransactionLimitRepository.FindByMerchantID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var limit entity.TransactionLimitEntity
+	if err := r.db.WithContext(ctx).Where("merchant_id = ?", merchantID).First(&limit).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &limit, nil
+}
+
+func (r *TransactionLimitRepository) FindByMCC(ctx context.Context, mcc string) (*entity.TransactionLimitEntity, error) {
+	funcName := "TransactionLimitRepository.FindByMCC"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var limit entity.TransactionLimitEntity
+	if err := r.db.WithContext(ctx).Where("mcc = ?", mcc).First(&limit).Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &limit, nil
+}
+
+// FindApplicable returns the limits of the merchant, or those of its category when
+// the merchant has none of its own
+func (r *TransactionLimitRepository) FindApplicable(ctx context.Context, merchantID uint64, mcc string) (*entity.TransactionLimitEntity, error) {
+	funcName := "TransactionLimitRepository.FindApplicable"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var limit entity.TransactionLimitEntity
+	if err := r.db.WithContext(ctx).
+		Where("merchant_id = ? OR mcc = ?", merchantID, mcc).
+		Order("merchant_id IS NULL").
+		First(&limit).
+		Error; err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErr.ErrRecordNotFound()
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &limit, nil
+}
+
+func (r *TransactionLimitRepository) Update(ctx context.Context, dbTrx TrxObj, params *entity.TransactionLimitEntity, changes map[string]interface{}) error {
+	funcName := "TransactionLimitRepository.Update"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+func (r *TransactionLimitRepository) DeleteByID(ctx context.Context, dbTrx TrxObj, id uint64) error {
+	funcName := "TransactionLimitRepository.DeleteByID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Delete(&entity.TransactionLimitEntity{}, id).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
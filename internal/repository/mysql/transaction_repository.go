@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"time"
 
 	"github.com/kharisma-wardhana/final-project-spe-academy/config"
 	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
@@ -15,9 +16,22 @@ type ITransactionRepository interface {
 	TrxSupportRepo
 	FindByID(ctx context.Context, id uint64) (*entity.TransactionEntity, error)
 	FindByRefID(ctx context.Context, refID string) (*entity.TransactionEntity, error)
-	FindByMerchantID(ctx context.Context, merchantID uint64) ([]entity.TransactionEntity, error)
+	ExistsByBillingID(ctx context.Context, billingID string, statuses []string) (bool, error)
+	FindByBillingID(ctx context.Context, billingID string, statuses []string) (*entity.TransactionEntity, error)
+	ExistsByParticipantCode(ctx context.Context, code string) (bool, error)
+	CountMerchantsByCustomerMPAN(ctx context.Context, customerMPAN string, since time.Time, excludeMerchantID uint64) (int64, error)
+	Search(ctx context.Context, filter *entity.TransactionFilter) ([]entity.TransactionEntity, error)
+	Stream(ctx context.Context, filter *entity.TransactionFilter, fn func(*entity.TransactionEntity) error) error
+	StreamByDate(ctx context.Context, dateFrom time.Time, dateTo time.Time, statuses []string, fn func(*entity.TransactionEntity) error) error
+	Summarize(ctx context.Context, filter *entity.TransactionSummaryFilter) (*entity.TransactionAggregate, error)
+	SummarizeByPeriod(ctx context.Context, filter *entity.TransactionSummaryFilter) ([]entity.TransactionPeriodAggregate, error)
+	SummarizeBy(ctx context.Context, filter *entity.TransactionSummaryFilter, dimension string) ([]entity.TransactionBreakdownAggregate, error)
 	LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.TransactionEntity, error)
 	Create(ctx context.Context, dbTrx TrxObj, params *entity.TransactionEntity, nonZeroVal bool) error
+	Update(ctx context.Context, dbTrx TrxObj, params *entity.TransactionEntity, changes map[string]interface{}) error
+	FindSettleable(ctx context.Context, cutoff time.Time, limit int) ([]entity.TransactionEntity, error)
+	FindPendingBefore(ctx context.Context, filter *entity.PendingTransactionFilter) ([]entity.TransactionEntity, error)
+	CreateStatusHistory(ctx context.Context, dbTrx TrxObj, params *entity.TransactionStatusHistoryEntity) error
 }
 
 type TransactionRepository struct {
@@ -61,21 +75,297 @@ func (r *TransactionRepository) FindByRefID(ctx context.Context, refID string) (
 	return &transaction, nil
 }
 
-func (r *TransactionRepository) FindByMerchantID(ctx context.Context, merchantID uint64) ([]entity.TransactionEntity, error) {
-	funcName := "TransactionRepository.FindByMerchantID"
+// ExistsByBillingID reports whether the QR with the billing ID has a transaction in one of the statuses
+func (r *TransactionRepository) ExistsByBillingID(ctx context.Context, billingID string, statuses []string) (bool, error) {
+	funcName := "TransactionRepository.ExistsByBillingID"
 	if err := helper.CheckDeadline(ctx); err != nil {
+		return false, errwrap.Wrap(err, funcName)
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&entity.TransactionEntity{}).
+		Where("billing_id = ? AND status IN ?", billingID, statuses).
+		Count(&count).
+		Error; err != nil {
+		return false, errwrap.Wrap(err, funcName)
+	}
+	return count > 0, nil
+}
+
+// ExistsByParticipantCode reports whether a transaction names the participant as issuer or acquirer
+func (r *TransactionRepository) ExistsByParticipantCode(ctx context.Context, code string) (bool, error) {
+	funcName := "TransactionRepository.ExistsByParticipantCode"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return false, errwrap.Wrap(err, funcName)
+	}
+
+	var ids []uint64
+	if err := r.db.WithContext(ctx).
+		Model(&entity.TransactionEntity{}).
+		Where("issuer = ? OR acquirer = ?", code, code).
+		Limit(1).
+		Pluck("id", &ids).
+		Error; err != nil {
+		return false, errwrap.Wrap(err, funcName)
+	}
+	return len(ids) > 0, nil
+}
+
+// CountMerchantsByCustomerMPAN counts the other merchants the customer paid since the given time
+func (r *TransactionRepository) CountMerchantsByCustomerMPAN(ctx context.Context, customerMPAN string, since time.Time, excludeMerchantID uint64) (int64, error) {
+	funcName := "TransactionRepository.CountMerchantsByCustomerMPAN"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return 0, errwrap.Wrap(err, funcName)
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&entity.TransactionEntity{}).
+		Where("customer_mpan = ? AND transaction_date >= ? AND merchant_id <> ?", customerMPAN, since, excludeMerchantID).
+		Distinct("merchant_id").
+		Count(&count).
+		Error; err != nil {
+		return 0, errwrap.Wrap(err, funcName)
+	}
+	return count, nil
+}
+
+// FindByBillingID returns the latest transaction of the QR with the billing ID in one of the statuses
+func (r *TransactionRepository) FindByBillingID(ctx context.Context, billingID string, statuses []string) (*entity.TransactionEntity, error) {
+	funcName := "TransactionRepository.FindByBillingID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var transaction entity.TransactionEntity
+	if err := r.db.WithContext(ctx).
+		Where("billing_id = ? AND status IN ?", billingID, statuses).
+		Order("id DESC").
+		First(&transaction).
+		Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, appErr.ErrRecordNotFound()
+		}
 		return nil, errwrap.Wrap(err, funcName)
 	}
+	return &transaction, nil
+}
+
+// Search returns one page of a merchant's transactions, newest first. Pages are
+// addressed by keyset on (transaction_date, id) so deep pages cost the same as the first.
+func (r *TransactionRepository) Search(ctx context.Context, filter *entity.TransactionFilter) ([]entity.TransactionEntity, error) {
+	funcName := "TransactionRepository.Search"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	query := applyTransactionFilter(r.db.WithContext(ctx).Model(&entity.TransactionEntity{}), filter)
+	if filter.Cursor != nil {
+		query = query.Where(
+			"transaction_date < ? OR (transaction_date = ? AND id < ?)",
+			filter.Cursor.TransactionDate, filter.Cursor.TransactionDate, filter.Cursor.ID,
+		)
+	}
+
 	var transactions []entity.TransactionEntity
-	if err := r.db.
-		Raw("SELECT * FROM transactions WHERE merchant_id = ?", merchantID).
-		Scan(&transactions).
+	if err := query.
+		Order("transaction_date DESC, id DESC").
+		Limit(filter.Limit).
+		Find(&transactions).
 		Error; err != nil {
-		return nil, err
+		return nil, errwrap.Wrap(err, funcName)
 	}
 	return transactions, nil
 }
 
+// Stream walks every transaction matching the filter in chronological order without
+// loading them all in memory, fn is called once per row
+func (r *TransactionRepository) Stream(ctx context.Context, filter *entity.TransactionFilter, fn func(*entity.TransactionEntity) error) error {
+	funcName := "TransactionRepository.Stream"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	query := applyTransactionFilter(r.db.WithContext(ctx).Model(&entity.TransactionEntity{}), filter)
+	if err := r.stream(query, fn); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+// StreamByDate walks the transactions of all merchants made between dateFrom
+// (inclusive) and dateTo (exclusive) with one of the given statuses
+func (r *TransactionRepository) StreamByDate(ctx context.Context, dateFrom time.Time, dateTo time.Time, statuses []string, fn func(*entity.TransactionEntity) error) error {
+	funcName := "TransactionRepository.StreamByDate"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).
+		Model(&entity.TransactionEntity{}).
+		Where("transaction_date >= ? AND transaction_date < ?", dateFrom, dateTo).
+		Where("status IN ?", statuses)
+	if err := r.stream(query, fn); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
+
+// stream scans the rows of the query one at a time, oldest first
+func (r *TransactionRepository) stream(query *gorm.DB, fn func(*entity.TransactionEntity) error) error {
+	rows, err := query.Order("transaction_date ASC, id ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transaction entity.TransactionEntity
+		if err := r.db.ScanRows(rows, &transaction); err != nil {
+			return err
+		}
+		if err := fn(&transaction); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func applyTransactionFilter(query *gorm.DB, filter *entity.TransactionFilter) *gorm.DB {
+	query = query.Where("merchant_id = ?", filter.MerchantID)
+	if filter.OutletID != 0 {
+		query = query.Where("outlet_id = ?", filter.OutletID)
+	}
+	if filter.TerminalID != 0 {
+		query = query.Where("terminal_id = ?", filter.TerminalID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.PaymentMethod != "" {
+		query = query.Where("payment_method = ?", filter.PaymentMethod)
+	}
+	if filter.MinAmount > 0 {
+		query = query.Where("total_amount >= ?", filter.MinAmount)
+	}
+	if filter.MaxAmount > 0 {
+		query = query.Where("total_amount <= ?", filter.MaxAmount)
+	}
+	if filter.DateFrom != nil {
+		query = query.Where("transaction_date >= ?", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		query = query.Where("transaction_date < ?", *filter.DateTo)
+	}
+	return query
+}
+
+// transactionAggregateColumns only counts money that actually moved, pending and
+// failed transactions are visible in the counts but not in the volumes
+const transactionAggregateColumns = `COUNT(*) AS transaction_count,
+	COALESCE(SUM(CASE WHEN type = 'payment' AND status IN ('completed', 'settled') THEN 1 ELSE 0 END), 0) AS payment_count,
+	COALESCE(SUM(CASE WHEN type = 'refund' AND status IN ('completed', 'settled') THEN 1 ELSE 0 END), 0) AS refund_count,
+	COALESCE(SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END), 0) AS failed_count,
+	COALESCE(SUM(CASE WHEN type = 'payment' AND status IN ('completed', 'settled') THEN total_amount ELSE 0 END), 0) AS gross_volume,
+	COALESCE(SUM(CASE WHEN type = 'payment' AND status IN ('completed', 'settled') THEN mdr_amount ELSE 0 END), 0) AS mdr_amount,
+	COALESCE(SUM(CASE WHEN type = 'refund' AND status IN ('completed', 'settled') THEN total_amount ELSE 0 END), 0) AS refund_volume,
+	COALESCE(SUM(CASE WHEN status IN ('completed', 'settled') THEN
+		CASE WHEN type = 'payment' THEN total_amount - mdr_amount ELSE -total_amount END
+	ELSE 0 END), 0) AS net_amount`
+
+// summaryPeriodColumns buckets transaction_date per granularity, weeks start on Monday
+var summaryPeriodColumns = map[string]string{
+	entity.SummaryGranularityDay:   "DATE_FORMAT(transaction_date, '%Y-%m-%d')",
+	entity.SummaryGranularityWeek:  "DATE_FORMAT(DATE_SUB(transaction_date, INTERVAL WEEKDAY(transaction_date) DAY), '%Y-%m-%d')",
+	entity.SummaryGranularityMonth: "DATE_FORMAT(transaction_date, '%Y-%m')",
+}
+
+// summaryDimensions whitelists the columns a breakdown may group by with the key
+// selected for each, transactions without an outlet are grouped under an empty key
+var summaryDimensions = map[string]string{
+	"payment_method": "payment_method",
+	"issuer":         "issuer",
+	"outlet_id":      "COALESCE(CAST(outlet_id AS CHAR), '')",
+	"merchant_id":    "CAST(merchant_id AS CHAR)",
+}
+
+func (r *TransactionRepository) Summarize(ctx context.Context, filter *entity.TransactionSummaryFilter) (*entity.TransactionAggregate, error) {
+	funcName := "TransactionRepository.Summarize"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var aggregate entity.TransactionAggregate
+	if err := r.summaryQuery(ctx, filter).
+		Select(transactionAggregateColumns).
+		Scan(&aggregate).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &aggregate, nil
+}
+
+func (r *TransactionRepository) SummarizeByPeriod(ctx context.Context, filter *entity.TransactionSummaryFilter) ([]entity.TransactionPeriodAggregate, error) {
+	funcName := "TransactionRepository.SummarizeByPeriod"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	period, ok := summaryPeriodColumns[filter.Granularity]
+	if !ok {
+		return nil, errwrap.Wrapf(appErr.ErrInvalidRequest(), "%s: unknown granularity %q", funcName, filter.Granularity)
+	}
+
+	var aggregates []entity.TransactionPeriodAggregate
+	if err := r.summaryQuery(ctx, filter).
+		Select(period + " AS period, " + transactionAggregateColumns).
+		Group("period").
+		Order("period ASC").
+		Scan(&aggregates).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return aggregates, nil
+}
+
+// SummarizeBy breaks the successful payments down by a dimension such as payment_method, issuer or outlet_id
+func (r *TransactionRepository) SummarizeBy(ctx context.Context, filter *entity.TransactionSummaryFilter, dimension string) ([]entity.TransactionBreakdownAggregate, error) {
+	funcName := "TransactionRepository.SummarizeBy"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	key, ok := summaryDimensions[dimension]
+	if !ok {
+		return nil, errwrap.Wrapf(appErr.ErrInvalidRequest(), "%s: unknown dimension %q", funcName, dimension)
+	}
+
+	var aggregates []entity.TransactionBreakdownAggregate
+	if err := r.summaryQuery(ctx, filter).
+		Select(key+" AS `key`, COUNT(*) AS transaction_count, SUM(total_amount) AS volume, SUM(mdr_amount) AS mdr_amount").
+		Where("type = ? AND status IN ?", "payment", []string{entity.TransactionStatusCompleted, entity.TransactionStatusSettled}).
+		Group(dimension).
+		Order("volume DESC").
+		Scan(&aggregates).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return aggregates, nil
+}
+
+func (r *TransactionRepository) summaryQuery(ctx context.Context, filter *entity.TransactionSummaryFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&entity.TransactionEntity{})
+	if len(filter.MerchantIDs) > 0 {
+		return query.Where("merchant_id IN ? AND transaction_date >= ? AND transaction_date < ?", filter.MerchantIDs, filter.DateFrom, filter.DateTo)
+	}
+	return query.Where("merchant_id = ? AND transaction_date >= ? AND transaction_date < ?", filter.MerchantID, filter.DateFrom, filter.DateTo)
+}
+
 func (r *TransactionRepository) LockByID(ctx context.Context, dbTrx TrxObj, id uint64) (*entity.TransactionEntity, error) {
 	funcName := "TransactionRepository.LockByID"
 	if err := helper.CheckDeadline(ctx); err != nil {
@@ -104,3 +394,73 @@ func (r *TransactionRepository) Create(ctx context.Context, dbTrx TrxObj, params
 	cols := helper.NonZeroCols(params, nonZeroVal)
 	return r.Trx(dbTrx).Select(cols).Create(&params).Error
 }
+
+func (r *TransactionRepository) Update(ctx context.Context, dbTrx TrxObj, params *entity.TransactionEntity, changes map[string]interface{}) error {
+	funcName := "TransactionRepository.Update"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).Model(params).Updates(changes).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	return nil
+}
+
+// FindSettleable returns completed transactions made before the cutoff, oldest first
+func (r *TransactionRepository) FindSettleable(ctx context.Context, cutoff time.Time, limit int) ([]entity.TransactionEntity, error) {
+	funcName := "TransactionRepository.FindSettleable"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var transactions []entity.TransactionEntity
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND transaction_date < ?", entity.TransactionStatusCompleted, cutoff).
+		Order("transaction_date ASC, id ASC").
+		Limit(limit).
+		Find(&transactions).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return transactions, nil
+}
+
+// FindPendingBefore returns pending transactions made before the filter cutoff, oldest first
+func (r *TransactionRepository) FindPendingBefore(ctx context.Context, filter *entity.PendingTransactionFilter) ([]entity.TransactionEntity, error) {
+	funcName := "TransactionRepository.FindPendingBefore"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	query := r.db.WithContext(ctx).
+		Where("status = ? AND transaction_date < ?", entity.TransactionStatusPending, filter.Before)
+	if filter.PaymentMethod != "" {
+		query = query.Where("payment_method = ?", filter.PaymentMethod)
+	} else if len(filter.ExcludePaymentMethods) > 0 {
+		query = query.Where("payment_method NOT IN ?", filter.ExcludePaymentMethods)
+	}
+
+	var transactions []entity.TransactionEntity
+	if err := query.
+		Order("transaction_date ASC, id ASC").
+		Limit(filter.Limit).
+		Find(&transactions).
+		Error; err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return transactions, nil
+}
+
+func (r *TransactionRepository) CreateStatusHistory(ctx context.Context, dbTrx TrxObj, params *entity.TransactionStatusHistoryEntity) error {
+	funcName := "TransactionRepository.CreateStatusHistory"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	if err := r.Trx(dbTrx).WithContext(ctx).Create(params).Error; err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
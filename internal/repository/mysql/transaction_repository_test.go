@@ -0,0 +1,170 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	gmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type TransactionRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	db   *sql.DB
+	repo *mysql.TransactionRepository
+}
+
+func TestTransactionRepository(t *testing.T) {
+	suite.Run(t, new(TransactionRepositoryTestSuite))
+}
+
+func (s *TransactionRepositoryTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	if err != nil {
+		s.Failf("an error '%s' was not expected when opening a stub database connection", err.Error())
+	}
+
+	dialector := gmysql.New(gmysql.Config{Conn: s.db, SkipInitializeWithVersion: true})
+	gormDB, _ := gorm.Open(dialector, &gorm.Config{})
+	s.repo = mysql.NewTransactionRepository(&config.Mysql{DB: gormDB})
+}
+
+func (s *TransactionRepositoryTestSuite) TearDownTest() {
+	s.db.Close()
+}
+
+func (s *TransactionRepositoryTestSuite) TestSearchFirstPage() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `transactions` WHERE merchant_id = ? AND status = ? ORDER BY transaction_date DESC, id DESC LIMIT ?",
+	)).
+		WithArgs(uint64(7), "completed", 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reference_id"}).AddRow(10, "REF-10"))
+
+	transactions, err := s.repo.Search(context.Background(), &entity.TransactionFilter{
+		MerchantID: 7,
+		Status:     "completed",
+		Limit:      21,
+	})
+
+	s.NoError(err)
+	s.Require().Len(transactions, 1)
+	s.Equal("REF-10", transactions[0].RefID)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactionRepositoryTestSuite) TestSearchAfterCursor() {
+	cursorDate := time.Date(2025, 7, 15, 10, 0, 0, 0, time.UTC)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `transactions` WHERE merchant_id = ? AND total_amount >= ? AND (transaction_date < ? OR (transaction_date = ? AND id < ?)) ORDER BY transaction_date DESC, id DESC LIMIT ?",
+	)).
+		WithArgs(uint64(7), 1000.0, cursorDate, cursorDate, uint64(42), 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := s.repo.Search(context.Background(), &entity.TransactionFilter{
+		MerchantID: 7,
+		MinAmount:  1000,
+		Cursor:     &entity.TransactionCursor{TransactionDate: cursorDate, ID: 42},
+		Limit:      11,
+	})
+
+	s.NoError(err)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactionRepositoryTestSuite) TestSummarizeByPeriod() {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	s.mock.ExpectQuery(
+		"SELECT DATE_FORMAT\\(transaction_date, '%Y-%m'\\) AS period, COUNT\\(\\*\\) AS transaction_count,.+ "+
+			regexp.QuoteMeta("FROM `transactions` WHERE merchant_id = ? AND transaction_date >= ? AND transaction_date < ? GROUP BY `period` ORDER BY period ASC"),
+	).
+		WithArgs(uint64(7), from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"period", "transaction_count", "gross_volume", "net_amount"}).
+			AddRow("2025-07", 3, 30000.0, 29790.0))
+
+	aggregates, err := s.repo.SummarizeByPeriod(context.Background(), &entity.TransactionSummaryFilter{
+		MerchantID:  7,
+		DateFrom:    from,
+		DateTo:      to,
+		Granularity: entity.SummaryGranularityMonth,
+	})
+
+	s.NoError(err)
+	s.Require().Len(aggregates, 1)
+	s.Equal("2025-07", aggregates[0].Period)
+	s.Equal(int64(3), aggregates[0].TransactionCount)
+	s.Equal(29790.0, aggregates[0].NetAmount)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactionRepositoryTestSuite) TestSummarizeByOutlet() {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT COALESCE(CAST(outlet_id AS CHAR), '') AS `key`, COUNT(*) AS transaction_count, SUM(total_amount) AS volume, SUM(mdr_amount) AS mdr_amount "+
+			"FROM `transactions` WHERE (merchant_id = ? AND transaction_date >= ? AND transaction_date < ?) AND (type = ? AND status IN (?,?)) GROUP BY `outlet_id` ORDER BY volume DESC",
+	)).
+		WithArgs(uint64(7), from, to, "payment", "completed", "settled").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "transaction_count", "volume", "mdr_amount"}).
+			AddRow("3", 2, 20000.0, 140.0).
+			AddRow("", 1, 5000.0, 35.0))
+
+	aggregates, err := s.repo.SummarizeBy(context.Background(), &entity.TransactionSummaryFilter{
+		MerchantID: 7,
+		DateFrom:   from,
+		DateTo:     to,
+	}, "outlet_id")
+
+	s.NoError(err)
+	s.Require().Len(aggregates, 2)
+	s.Equal("3", aggregates[0].Key)
+	s.Equal("", aggregates[1].Key)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactionRepositoryTestSuite) TestSummarizeByMerchantConsolidated() {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT CAST(merchant_id AS CHAR) AS `key`, COUNT(*) AS transaction_count, SUM(total_amount) AS volume, SUM(mdr_amount) AS mdr_amount "+
+			"FROM `transactions` WHERE (merchant_id IN (?,?,?) AND transaction_date >= ? AND transaction_date < ?) AND (type = ? AND status IN (?,?)) GROUP BY `merchant_id` ORDER BY volume DESC",
+	)).
+		WithArgs(uint64(7), uint64(8), uint64(9), from, to, "payment", "completed", "settled").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "transaction_count", "volume", "mdr_amount"}).
+			AddRow("8", 4, 40000.0, 280.0).
+			AddRow("7", 1, 10000.0, 70.0))
+
+	aggregates, err := s.repo.SummarizeBy(context.Background(), &entity.TransactionSummaryFilter{
+		MerchantID:  7,
+		MerchantIDs: []uint64{7, 8, 9},
+		DateFrom:    from,
+		DateTo:      to,
+	}, "merchant_id")
+
+	s.NoError(err)
+	s.Require().Len(aggregates, 2)
+	s.Equal("8", aggregates[0].Key)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactionRepositoryTestSuite) TestSummarizeByUnknownDimension() {
+	_, err := s.repo.SummarizeBy(context.Background(), &entity.TransactionSummaryFilter{MerchantID: 7}, "customer_mpan")
+
+	s.Error(err)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
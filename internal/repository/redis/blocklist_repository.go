@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+type IBlocklistRepository interface {
+	Match(ctx context.Context, values map[string]string) ([]entity.BlocklistCacheEntity, bool, error)
+	Put(ctx context.Context, entry *entity.BlocklistCacheEntity) error
+	Remove(ctx context.Context, entryType string, value string) error
+	Load(ctx context.Context, entries []entity.BlocklistCacheEntity, ttl time.Duration) error
+}
+
+type BlocklistRepository struct {
+	redisClient *redis.Client
+}
+
+func NewBlocklistRepository(redisClient *redis.Client) *BlocklistRepository {
+	return &BlocklistRepository{redisClient}
+}
+
+// Every entry lives in one hash keyed by type and value, so the whole cache can be
+// replaced in a single step. The loaded marker expires to have the cache rebuilt from
+// the database now and then.
+const (
+	blocklistKey       = "blocklist:entries"
+	blocklistLoadedKey = "blocklist:loaded"
+)
+
+func blocklistField(entryType string, value string) string {
+	return entryType + ":" + value
+}
+
+// Match looks up the value of each type and returns the cached entries found, expired
+// ones included. The bool is false when the cache has not been loaded, the entries
+// are then incomplete.
+func (r *BlocklistRepository) Match(ctx context.Context, values map[string]string) ([]entity.BlocklistCacheEntity, bool, error) {
+	funcName := "BlocklistRepository.Match"
+	captureFieldError := generalEntity.CaptureFields{
+		"values": helper.ToString(values),
+	}
+
+	fields := make([]string, 0, len(values))
+	for entryType, value := range values {
+		fields = append(fields, blocklistField(entryType, value))
+	}
+
+	var found *redis.SliceCmd
+	var loaded *redis.IntCmd
+	if _, err := r.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		found = pipe.HMGet(ctx, blocklistKey, fields...)
+		loaded = pipe.Exists(ctx, blocklistLoadedKey)
+		return nil
+	}); err != nil {
+		helper.LogError("redisClient.Pipelined", funcName, err, captureFieldError, "")
+		return nil, false, err
+	}
+
+	var entries []entity.BlocklistCacheEntity
+	for _, data := range found.Val() {
+		if data == nil {
+			continue
+		}
+		var entry entity.BlocklistCacheEntity
+		if err := json.Unmarshal([]byte(data.(string)), &entry); err != nil {
+			helper.LogError("json.Unmarshal", funcName, err, captureFieldError, "")
+			return nil, false, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, loaded.Val() == 1, nil
+}
+
+func (r *BlocklistRepository) Put(ctx context.Context, entry *entity.BlocklistCacheEntity) error {
+	funcName := "BlocklistRepository.Put"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(entry),
+	}
+
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		helper.LogError("json.Marshal", funcName, err, captureFieldError, "")
+		return err
+	}
+
+	if err := r.redisClient.HSet(ctx, blocklistKey, blocklistField(entry.Type, entry.Value), entryJSON).Err(); err != nil {
+		helper.LogError("redisClient.HSet", funcName, err, captureFieldError, "")
+		return err
+	}
+
+	return nil
+}
+
+func (r *BlocklistRepository) Remove(ctx context.Context, entryType string, value string) error {
+	funcName := "BlocklistRepository.Remove"
+	captureFieldError := generalEntity.CaptureFields{
+		"type":  entryType,
+		"value": value,
+	}
+
+	if err := r.redisClient.HDel(ctx, blocklistKey, blocklistField(entryType, value)).Err(); err != nil {
+		helper.LogError("redisClient.HDel", funcName, err, captureFieldError, "")
+		return err
+	}
+
+	return nil
+}
+
+// Load replaces the cached entries with entries and marks the cache loaded for ttl
+func (r *BlocklistRepository) Load(ctx context.Context, entries []entity.BlocklistCacheEntity, ttl time.Duration) error {
+	funcName := "BlocklistRepository.Load"
+	captureFieldError := generalEntity.CaptureFields{
+		"entries": helper.ToString(len(entries)),
+	}
+
+	fields := make(map[string]interface{}, len(entries))
+	for i := range entries {
+		entryJSON, err := json.Marshal(&entries[i])
+		if err != nil {
+			helper.LogError("json.Marshal", funcName, err, captureFieldError, "")
+			return err
+		}
+		fields[blocklistField(entries[i].Type, entries[i].Value)] = entryJSON
+	}
+
+	if _, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, blocklistKey)
+		if len(fields) > 0 {
+			pipe.HSet(ctx, blocklistKey, fields)
+		}
+		pipe.Set(ctx, blocklistLoadedKey, helper.DatetimeNowJakartaString(), ttl)
+		return nil
+	}); err != nil {
+		helper.LogError("redisClient.TxPipelined", funcName, err, captureFieldError, "")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+package entity
+
+// BlocklistCacheEntity is a blocklist entry as cached, ExpiresAt is a unix time and
+// zero when the entry never expires
+type BlocklistCacheEntity struct {
+	ID        uint64
+	Type      string
+	Value     string
+	ExpiresAt int64
+}
@@ -0,0 +1,18 @@
+package entity
+
+// LimitWindow names the counter a limit check stopped at
+type LimitWindow string
+
+const (
+	LimitWindowDaily   LimitWindow = "daily"
+	LimitWindowMonthly LimitWindow = "monthly"
+	LimitWindowMinute  LimitWindow = "minute"
+)
+
+// LimitCaps are the ceilings checked against the counters, zero means no ceiling.
+// Volumes are in cents so the counters stay integers.
+type LimitCaps struct {
+	DailyVolume   int64
+	MonthlyVolume int64
+	PerMinute     int64
+}
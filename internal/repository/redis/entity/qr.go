@@ -2,8 +2,11 @@ package entity
 
 type QREntity struct {
 	MerchantID uint64
+	OutletID   uint64
+	TerminalID uint64
 	BillingID  string
 	Amount     float64
 	QRCode     string
 	Expiration int64
+	ExpiredAt  int64
 }
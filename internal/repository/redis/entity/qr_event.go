@@ -0,0 +1,18 @@
+package entity
+
+type QREventType string
+
+const (
+	QREventPaid      QREventType = "paid"
+	QREventExpired   QREventType = "expired"
+	QREventCancelled QREventType = "cancelled"
+)
+
+type QREventEntity struct {
+	Event      QREventType
+	BillingID  string
+	RefID      string
+	MerchantID uint64
+	Amount     float64
+	OccurredAt int64
+}
@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+type ILimitCounterRepository interface {
+	Apply(ctx context.Context, merchantID uint64, at time.Time, amount int64, caps entity.LimitCaps, commit bool) (entity.LimitWindow, error)
+	Release(ctx context.Context, merchantID uint64, at time.Time, amount int64) error
+}
+
+type LimitCounterRepository struct {
+	redisClient *redis.Client
+}
+
+func NewLimitCounterRepository(redisClient *redis.Client) *LimitCounterRepository {
+	return &LimitCounterRepository{redisClient}
+}
+
+// Counters outlive their window a little so a request at the boundary still finds them
+const (
+	dailyCounterTTL   = 48 * time.Hour
+	monthlyCounterTTL = 32 * 24 * time.Hour
+	minuteCounterTTL  = 2 * time.Minute
+)
+
+// applyLimitScript checks every window before counting anything, so a rejected
+// request leaves the counters untouched. It returns the first window that would be
+// exceeded, or an empty string.
+var applyLimitScript = redis.NewScript(`
+local amount = tonumber(ARGV[1])
+local daily = tonumber(redis.call('GET', KEYS[1]) or '0')
+local monthly = tonumber(redis.call('GET', KEYS[2]) or '0')
+local minute = tonumber(redis.call('GET', KEYS[3]) or '0')
+if tonumber(ARGV[2]) > 0 and daily + amount > tonumber(ARGV[2]) then
+	return 'daily'
+end
+if tonumber(ARGV[3]) > 0 and monthly + amount > tonumber(ARGV[3]) then
+	return 'monthly'
+end
+if tonumber(ARGV[4]) > 0 and minute + 1 > tonumber(ARGV[4]) then
+	return 'minute'
+end
+if ARGV[5] == '1' then
+	redis.call('INCRBY', KEYS[1], amount)
+	redis.call('EXPIRE', KEYS[1], ARGV[6])
+	redis.call('INCRBY', KEYS[2], amount)
+	redis.call('EXPIRE', KEYS[2], ARGV[7])
+	redis.call('INCR', KEYS[3])
+	redis.call('EXPIRE', KEYS[3], ARGV[8])
+end
+return ''
+`)
+
+// releaseLimitScript takes back an amount and one transaction, a counter that has
+// expired or was reset in the meantime is not taken below zero
+var releaseLimitScript = redis.NewScript(`
+local amounts = {tonumber(ARGV[1]), tonumber(ARGV[1]), 1}
+for i, key in ipairs(KEYS) do
+	local value = tonumber(redis.call('GET', key) or '0')
+	local release = math.min(value, amounts[i])
+	if release > 0 then
+		redis.call('DECRBY', key, release)
+	end
+end
+return 1
+`)
+
+func limitCounterKeys(merchantID uint64, at time.Time) []string {
+	return []string{
+		fmt.Sprintf("limit:%d:day:%s", merchantID, at.Format("20060102")),
+		fmt.Sprintf("limit:%d:month:%s", merchantID, at.Format("200601")),
+		fmt.Sprintf("limit:%d:minute:%s", merchantID, at.Format("200601021504")),
+	}
+}
+
+// Apply checks the merchant's counters for the windows containing at against caps.
+// With commit the amount (in cents) and one transaction are counted when no cap is
+// exceeded, without it the counters are only read.
+func (r *LimitCounterRepository) Apply(ctx context.Context, merchantID uint64, at time.Time, amount int64, caps entity.LimitCaps, commit bool) (entity.LimitWindow, error) {
+	funcName := "LimitCounterRepository.Apply"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+		"amount":     helper.ToString(amount),
+	}
+
+	commitFlag := "0"
+	if commit {
+		commitFlag = "1"
+	}
+
+	window, err := applyLimitScript.Run(ctx, r.redisClient, limitCounterKeys(merchantID, at),
+		amount,
+		caps.DailyVolume,
+		caps.MonthlyVolume,
+		caps.PerMinute,
+		commitFlag,
+		int64(dailyCounterTTL.Seconds()),
+		int64(monthlyCounterTTL.Seconds()),
+		int64(minuteCounterTTL.Seconds()),
+	).Text()
+	if err != nil {
+		helper.LogError("applyLimitScript.Run", funcName, err, captureFieldError, "")
+		return "", err
+	}
+
+	return entity.LimitWindow(window), nil
+}
+
+// Release takes the amount (in cents) and one transaction back from the merchant's
+// counters for the windows containing at
+func (r *LimitCounterRepository) Release(ctx context.Context, merchantID uint64, at time.Time, amount int64) error {
+	funcName := "LimitCounterRepository.Release"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+		"amount":     helper.ToString(amount),
+	}
+
+	if err := releaseLimitScript.Run(ctx, r.redisClient, limitCounterKeys(merchantID, at), amount).Err(); err != nil {
+		helper.LogError("releaseLimitScript.Run", funcName, err, captureFieldError, "")
+		return err
+	}
+
+	return nil
+}
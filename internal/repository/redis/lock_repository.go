@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/redis/go-redis/v9"
+)
+
+type ILockRepository interface {
+	Acquire(ctx context.Context, name string, ttl time.Duration) (string, bool, error)
+	Release(ctx context.Context, name string, token string) error
+}
+
+type LockRepository struct {
+	redisClient *redis.Client
+}
+
+func NewLockRepository(redisClient *redis.Client) *LockRepository {
+	return &LockRepository{redisClient}
+}
+
+func lockKey(name string) string {
+	return fmt.Sprintf("lock:%s", name)
+}
+
+// releaseLockScript deletes the lock only while it still holds the caller's token,
+// so a holder whose lock already expired cannot release the next holder's lock
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Acquire takes the named lock for ttl. It returns the token needed to release the
+// lock and false when another holder has it.
+func (r *LockRepository) Acquire(ctx context.Context, name string, ttl time.Duration) (string, bool, error) {
+	funcName := "LockRepository.Acquire"
+	captureFieldError := generalEntity.CaptureFields{
+		"name": name,
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		helper.LogError("rand.Read", funcName, err, captureFieldError, "")
+		return "", false, err
+	}
+	token := hex.EncodeToString(buf)
+
+	acquired, err := r.redisClient.SetNX(ctx, lockKey(name), token, ttl).Result()
+	if err != nil {
+		helper.LogError("redisClient.SetNX", funcName, err, captureFieldError, "")
+		return "", false, err
+	}
+
+	return token, acquired, nil
+}
+
+func (r *LockRepository) Release(ctx context.Context, name string, token string) error {
+	funcName := "LockRepository.Release"
+	captureFieldError := generalEntity.CaptureFields{
+		"name": name,
+	}
+
+	if err := releaseLockScript.Run(ctx, r.redisClient, []string{lockKey(name)}, token).Err(); err != nil {
+		helper.LogError("releaseLockScript.Run", funcName, err, captureFieldError, "")
+		return err
+	}
+
+	return nil
+}
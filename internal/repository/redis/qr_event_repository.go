@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+type IQREventRepository interface {
+	Publish(ctx context.Context, event *entity.QREventEntity) error
+	Subscribe(ctx context.Context, billingID string) (<-chan *entity.QREventEntity, func() error, error)
+}
+
+type QREventRepository struct {
+	redisClient *redis.Client
+}
+
+func NewQREventRepository(redisClient *redis.Client) *QREventRepository {
+	return &QREventRepository{redisClient}
+}
+
+// qrEventChannel is the pub/sub channel every API instance listens on for a billing ID,
+// so an event published by one instance reaches subscribers connected to any other.
+func qrEventChannel(billingID string) string {
+	return fmt.Sprintf("qr:events:%s", billingID)
+}
+
+func (r *QREventRepository) Publish(ctx context.Context, event *entity.QREventEntity) error {
+	funcName := "QREventRepository.Publish"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(event),
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		helper.LogError("json.Marshal", funcName, err, captureFieldError, "")
+		return err
+	}
+
+	if err := r.redisClient.Publish(ctx, qrEventChannel(event.BillingID), payload).Err(); err != nil {
+		helper.LogError("redisClient.Publish", funcName, err, captureFieldError, "")
+		return err
+	}
+
+	return nil
+}
+
+// Subscribe listens for events of a single billing ID. The returned channel is closed
+// once the subscription is closed through the returned func or ctx is cancelled.
+func (r *QREventRepository) Subscribe(ctx context.Context, billingID string) (<-chan *entity.QREventEntity, func() error, error) {
+	funcName := "QREventRepository.Subscribe"
+	captureFieldError := generalEntity.CaptureFields{
+		"billingID": billingID,
+	}
+
+	sub := r.redisClient.Subscribe(ctx, qrEventChannel(billingID))
+	// Wait for the subscription confirmation so no event published afterwards is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		helper.LogError("redisClient.Subscribe", funcName, err, captureFieldError, "")
+		sub.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan *entity.QREventEntity)
+	go func() {
+		defer close(events)
+		for msg := range sub.Channel() {
+			var event entity.QREventEntity
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				helper.LogError("json.Unmarshal", funcName, err, captureFieldError, "")
+				continue
+			}
+
+			select {
+			case events <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, sub.Close, nil
+}
@@ -3,8 +3,11 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	appErr "github.com/kharisma-wardhana/final-project-spe-academy/error"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
 	"github.com/redis/go-redis/v9"
@@ -13,6 +16,8 @@ import (
 type IQRRepository interface {
 	Create(ctx context.Context, qr *entity.QREntity) error
 	GetByBillingID(ctx context.Context, billingID string) (*entity.QREntity, error)
+	Cancel(ctx context.Context, billingID string) (*entity.QREntity, bool, error)
+	Consume(ctx context.Context, billingID string) (*entity.QREntity, error)
 }
 
 type QRRepository struct {
@@ -36,7 +41,9 @@ func (r *QRRepository) Create(ctx context.Context, qr *entity.QREntity) error {
 		return err
 	}
 
-	if err := r.redisClient.Set(ctx, qr.BillingID, qrJSON, 0).Err(); err != nil {
+	// The QR is only payable until it expires, so let Redis drop it on its own
+	ttl := time.Duration(qr.Expiration) * time.Second
+	if err := r.redisClient.Set(ctx, qr.BillingID, qrJSON, ttl).Err(); err != nil {
 		helper.LogError("redisClient.Set", funcName, err, captureFieldError, "")
 		return err
 	}
@@ -51,7 +58,9 @@ func (r *QRRepository) GetByBillingID(ctx context.Context, billingID string) (*e
 	}
 
 	data, err := r.redisClient.Get(ctx, billingID).Result()
-	if err != nil {
+	if err == redis.Nil {
+		return nil, appErr.ErrRecordNotFound()
+	} else if err != nil {
 		helper.LogError("redisClient.Get", funcName, err, captureFieldError, "")
 		return nil, err
 	}
@@ -64,3 +73,81 @@ func (r *QRRepository) GetByBillingID(ctx context.Context, billingID string) (*e
 
 	return &qr, nil
 }
+
+// Consume reads and removes the QR in one step, of concurrent payments for the same
+// QR only one gets it and the others get not found
+func (r *QRRepository) Consume(ctx context.Context, billingID string) (*entity.QREntity, error) {
+	funcName := "QRRepository.Consume"
+	captureFieldError := generalEntity.CaptureFields{
+		"billingID": billingID,
+	}
+
+	data, err := r.redisClient.GetDel(ctx, billingID).Result()
+	if err == redis.Nil {
+		return nil, appErr.ErrRecordNotFound()
+	} else if err != nil {
+		helper.LogError("redisClient.GetDel", funcName, err, captureFieldError, "")
+		return nil, err
+	}
+
+	var qr entity.QREntity
+	if err := json.Unmarshal([]byte(data), &qr); err != nil {
+		helper.LogError("json.Unmarshal", funcName, err, captureFieldError, "")
+		return nil, err
+	}
+
+	return &qr, nil
+}
+
+// qrTombstoneTTL is how long a cancelled QR is remembered, repeated cancels within
+// that time answer with the same QR instead of not found
+const qrTombstoneTTL = 24 * time.Hour
+
+func qrTombstoneKey(billingID string) string {
+	return fmt.Sprintf("qr:cancelled:%s", billingID)
+}
+
+// cancelQRScript swaps the QR for its tombstone in a single step, so a payment
+// reading the QR either finds it payable or not at all. It returns the QR and 1 on
+// the first cancel, the tombstone and 0 on a repeated cancel, nil when neither exists.
+var cancelQRScript = redis.NewScript(`
+local qr = redis.call('GET', KEYS[1])
+if qr then
+	redis.call('DEL', KEYS[1])
+	redis.call('SET', KEYS[2], qr, 'PX', ARGV[1])
+	return {qr, 1}
+end
+local tombstone = redis.call('GET', KEYS[2])
+if tombstone then
+	return {tombstone, 0}
+end
+return false
+`)
+
+// Cancel removes a QR so it can no longer be paid. The bool is true only for the
+// call that cancelled it, a QR that was already cancelled is returned with false.
+func (r *QRRepository) Cancel(ctx context.Context, billingID string) (*entity.QREntity, bool, error) {
+	funcName := "QRRepository.Cancel"
+	captureFieldError := generalEntity.CaptureFields{
+		"billingID": billingID,
+	}
+
+	result, err := cancelQRScript.Run(ctx, r.redisClient,
+		[]string{billingID, qrTombstoneKey(billingID)},
+		qrTombstoneTTL.Milliseconds(),
+	).Slice()
+	if err == redis.Nil {
+		return nil, false, appErr.ErrRecordNotFound()
+	} else if err != nil {
+		helper.LogError("cancelQRScript.Run", funcName, err, captureFieldError, "")
+		return nil, false, err
+	}
+
+	var qr entity.QREntity
+	if err := json.Unmarshal([]byte(result[0].(string)), &qr); err != nil {
+		helper.LogError("json.Unmarshal", funcName, err, captureFieldError, "")
+		return nil, false, err
+	}
+
+	return &qr, result[1].(int64) == 1, nil
+}
@@ -3,37 +3,49 @@ package usecase_account
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
 	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/account/entity"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
 	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
 	errWrap "github.com/pkg/errors"
 )
 
 type AccountUseCase struct {
 	// Add any dependencies needed for the use case here
-	logUseCase  usecase_log.ILogUseCase
-	accountRepo mysql.IAccountRepository
+	logUseCase   usecase_log.ILogUseCase
+	accountRepo  mysql.IAccountRepository
+	merchantRepo mysql.IMerchantRepository
+	auditUseCase usecase_audit.IAuditUseCase
 }
 
-func NewAccountUseCase(logUseCase usecase_log.ILogUseCase, accountRepo mysql.IAccountRepository) *AccountUseCase {
+func NewAccountUseCase(logUseCase usecase_log.ILogUseCase, accountRepo mysql.IAccountRepository, merchantRepo mysql.IMerchantRepository, auditUseCase usecase_audit.IAuditUseCase) *AccountUseCase {
 	return &AccountUseCase{
-		logUseCase:  logUseCase,
-		accountRepo: accountRepo,
+		logUseCase:   logUseCase,
+		accountRepo:  accountRepo,
+		merchantRepo: merchantRepo,
+		auditUseCase: auditUseCase,
 	}
 }
 
+// IAccountUseCase manages merchant accounts. The account routes take no credentials,
+// so backoffice accounts are provisioned in the database and stay out of reach here.
 type IAccountUseCase interface {
 	GetAccountByID(ctx context.Context, id uint64) (*entity.AccountResponse, error)
 	GetAccountByMerchantID(ctx context.Context, merchantID uint64) (*entity.AccountResponse, error)
+	ListAccountMerchants(ctx context.Context, id uint64) ([]*entity.AccountMerchantResponse, error)
 	CreateAccount(ctx context.Context, req *entity.AccountRequest) (*entity.AccountResponse, error)
 	UpdateAccount(ctx context.Context, id uint64, req *entity.AccountRequest) (result *entity.AccountResponse, err error)
 	DeleteAccount(ctx context.Context, id uint64) error
+	RestoreAccount(ctx context.Context, id uint64) (*entity.AccountResponse, error)
 }
 
 func (u *AccountUseCase) GetAccountByID(ctx context.Context, id uint64) (*entity.AccountResponse, error) {
@@ -45,18 +57,11 @@ func (u *AccountUseCase) GetAccountByID(ctx context.Context, id uint64) (*entity
 		u.logUseCase.Error("accountRepo.FindByID", funcName, err, captureFieldError)
 		return nil, err
 	}
+	if account.IsBackoffice() {
+		return nil, apperr.ErrRecordNotFound()
+	}
 
-	return &entity.AccountResponse{
-		ID:           account.ID,
-		MerchantID:   account.MerchantID,
-		ClientID:     account.ClientID,
-		ClientSecret: account.ClientSecret,
-		PrivateKey:   account.PrivateKey,
-		PublicKey:    account.PublicKey,
-		Status:       account.Status,
-		CreatedAt:    helper.ConvertToJakartaDate(account.CreatedAt),
-		UpdatedAt:    helper.ConvertToJakartaDate(account.UpdatedAt),
-	}, nil
+	return toAccountResponse(account), nil
 }
 
 func (u *AccountUseCase) GetAccountByMerchantID(ctx context.Context, merchantID uint64) (*entity.AccountResponse, error) {
@@ -70,6 +75,9 @@ func (u *AccountUseCase) GetAccountByMerchantID(ctx context.Context, merchantID
 		u.logUseCase.Error("accountRepo.FindByMerchantID", funcName, err, captureFieldError)
 		return nil, err
 	}
+	if account.IsBackoffice() {
+		return nil, apperr.ErrRecordNotFound()
+	}
 
 	return &entity.AccountResponse{
 		ID:           account.ID,
@@ -84,6 +92,51 @@ func (u *AccountUseCase) GetAccountByMerchantID(ctx context.Context, merchantID
 	}, nil
 }
 
+// ListAccountMerchants lists the merchants the account may act on: its own merchant
+// and, when that merchant is corporate, all of its children
+func (u *AccountUseCase) ListAccountMerchants(ctx context.Context, id uint64) ([]*entity.AccountMerchantResponse, error) {
+	funcName := "AccountUseCase.ListAccountMerchants"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	account, err := u.accountRepo.FindByID(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("accountRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if account.IsBackoffice() {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	merchant, err := u.merchantRepo.FindByID(ctx, account.MerchantID)
+	if err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	merchants := []mEntity.MerchantEntity{*merchant}
+	if merchant.IsCorporate() {
+		children, err := u.merchantRepo.FindChildren(ctx, merchant.ID)
+		if err != nil {
+			u.logUseCase.Error("merchantRepo.FindChildren", funcName, err, captureFieldError)
+			return nil, err
+		}
+		merchants = append(merchants, children...)
+	}
+
+	response := make([]*entity.AccountMerchantResponse, 0, len(merchants))
+	for _, merchant := range merchants {
+		response = append(response, &entity.AccountMerchantResponse{
+			ID:       merchant.ID,
+			Name:     merchant.Name,
+			MID:      merchant.MID,
+			Type:     merchant.Type.Name(),
+			ParentID: merchant.ParentID,
+			Status:   merchant.Status,
+		})
+	}
+	return response, nil
+}
+
 func (u *AccountUseCase) CreateAccount(ctx context.Context, req *entity.AccountRequest) (*entity.AccountResponse, error) {
 	funcName := "AccountUseCase.CreateAccount"
 	captureFieldError := generalEntity.CaptureFields{
@@ -100,6 +153,8 @@ func (u *AccountUseCase) CreateAccount(ctx context.Context, req *entity.AccountR
 		PrivateKey:   req.PrivateKey,
 		PublicKey:    req.PublicKey,
 		Status:       req.Status,
+		Role:         mEntity.AccountRoleMerchant,
+		Version:      1,
 	}
 
 	err := u.accountRepo.Create(ctx, nil, accountEntity, true)
@@ -108,17 +163,9 @@ func (u *AccountUseCase) CreateAccount(ctx context.Context, req *entity.AccountR
 		return nil, err
 	}
 
-	return &entity.AccountResponse{
-		ID:           accountEntity.ID,
-		MerchantID:   accountEntity.MerchantID,
-		ClientID:     accountEntity.ClientID,
-		ClientSecret: accountEntity.ClientSecret,
-		PrivateKey:   accountEntity.PrivateKey,
-		PublicKey:    accountEntity.PublicKey,
-		Status:       accountEntity.Status,
-		CreatedAt:    helper.ConvertToJakartaDate(accountEntity.CreatedAt),
-		UpdatedAt:    helper.ConvertToJakartaDate(accountEntity.UpdatedAt),
-	}, nil
+	result := toAccountResponse(accountEntity)
+	u.auditUseCase.Record(ctx, auditEntity.ActionCreate, auditEntity.EntityAccount, accountEntity.ID, nil, result)
+	return result, nil
 }
 
 func (u *AccountUseCase) UpdateAccount(ctx context.Context, id uint64, req *entity.AccountRequest) (result *entity.AccountResponse, err error) {
@@ -131,6 +178,7 @@ func (u *AccountUseCase) UpdateAccount(ctx context.Context, id uint64, req *enti
 		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
 	}
 
+	var before, after *entity.AccountResponse
 	if err := mysql.DBTransaction(u.accountRepo, func(dbTrx mysql.TrxObj) error {
 		accountEntity, err := u.accountRepo.LockByID(ctx, dbTrx, id)
 		if err != nil {
@@ -142,6 +190,14 @@ func (u *AccountUseCase) UpdateAccount(ctx context.Context, id uint64, req *enti
 			u.logUseCase.Error("accountRepo.LockByID", funcName, err, captureFieldError)
 			return err
 		}
+		if accountEntity.IsBackoffice() {
+			return apperr.ErrRecordNotFound()
+		}
+		if req.Version != 0 && req.Version != accountEntity.Version {
+			return apperr.ErrStaleVersion()
+		}
+
+		before = toAccountResponse(accountEntity)
 
 		// Process the changes
 		changes := &mEntity.AccountEntity{
@@ -165,13 +221,20 @@ func (u *AccountUseCase) UpdateAccount(ctx context.Context, id uint64, req *enti
 			Status:       accountEntity.Status,
 			CreatedAt:    helper.ConvertToJakartaDate(accountEntity.CreatedAt),
 			UpdatedAt:    helper.ConvertToJakartaDate(accountEntity.UpdatedAt),
+			Version:      accountEntity.Version,
 		}
+		after = toAccountResponse(accountEntity)
 		return nil
 	}); err != nil {
 		u.logUseCase.Error("accountRepo.DBTransaction", funcName, err, captureFieldError)
+		// Errors with their own status are passed on as is, the presenter only maps them unwrapped
+		if _, ok := err.(apperr.CustomErrorResponse); ok {
+			return nil, err
+		}
 		return nil, errWrap.Wrap(err, funcName)
 	}
 
+	u.auditUseCase.Record(ctx, auditEntity.ActionUpdate, auditEntity.EntityAccount, id, before, after)
 	return result, nil
 }
 
@@ -181,9 +244,67 @@ func (u *AccountUseCase) DeleteAccount(ctx context.Context, id uint64) error {
 		"id": helper.ToString(id),
 	}
 
+	account, err := u.accountRepo.FindByID(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("accountRepo.FindByID", funcName, err, captureFieldError)
+		return err
+	}
+	if account.IsBackoffice() {
+		return apperr.ErrRecordNotFound()
+	}
 	if err := u.accountRepo.DeleteByID(ctx, nil, id); err != nil {
 		u.logUseCase.Error("accountRepo.DeleteByID", funcName, err, captureFieldError)
 		return err
 	}
+
+	u.auditUseCase.Record(ctx, auditEntity.ActionDelete, auditEntity.EntityAccount, id, toAccountResponse(account), nil)
 	return nil
 }
+
+// RestoreAccount undoes the soft delete of an account, the account of a deleted
+// merchant comes back by restoring the merchant
+func (u *AccountUseCase) RestoreAccount(ctx context.Context, id uint64) (*entity.AccountResponse, error) {
+	funcName := "AccountUseCase.RestoreAccount"
+	captureFieldError := generalEntity.CaptureFields{
+		"id": helper.ToString(id),
+	}
+
+	account, err := u.accountRepo.FindDeletedByID(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("accountRepo.FindDeletedByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if account.IsBackoffice() {
+		return nil, apperr.ErrRecordNotFound()
+	}
+	if _, err := u.merchantRepo.FindByID(ctx, account.MerchantID); errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		return nil, apperr.CustomError("Merchant of the account is deleted, restore the merchant instead", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	} else if err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	if err := u.accountRepo.Restore(ctx, nil, id); err != nil {
+		u.logUseCase.Error("accountRepo.Restore", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	result := toAccountResponse(account)
+	u.auditUseCase.Record(ctx, auditEntity.ActionRestore, auditEntity.EntityAccount, id, nil, result)
+	return result, nil
+}
+
+func toAccountResponse(account *mEntity.AccountEntity) *entity.AccountResponse {
+	return &entity.AccountResponse{
+		ID:           account.ID,
+		MerchantID:   account.MerchantID,
+		ClientID:     account.ClientID,
+		ClientSecret: account.ClientSecret,
+		PrivateKey:   account.PrivateKey,
+		PublicKey:    account.PublicKey,
+		Status:       account.Status,
+		CreatedAt:    helper.ConvertToJakartaDate(account.CreatedAt),
+		UpdatedAt:    helper.ConvertToJakartaDate(account.UpdatedAt),
+		Version:      account.Version,
+	}
+}
@@ -7,6 +7,9 @@ type AccountRequest struct {
 	PrivateKey   string `json:"private_key"`
 	PublicKey    string `json:"public_key"`
 	Status       string `json:"status"`
+	// Version is the version the update was made against, taken from If-Match. Zero
+	// updates whatever version is current.
+	Version uint64 `json:"-"`
 }
 
 type AccountResponse struct {
@@ -19,4 +22,16 @@ type AccountResponse struct {
 	Status       string `json:"status"`
 	CreatedAt    string `json:"created_at"`
 	UpdatedAt    string `json:"updated_at"`
+	Version      uint64 `json:"version"`
+}
+
+// AccountMerchantResponse is a merchant the account's credentials may act on, its own
+// merchant first and, for a corporate merchant, each of its children after it
+type AccountMerchantResponse struct {
+	ID       uint64  `json:"id"`
+	Name     string  `json:"name"`
+	MID      string  `json:"mid"`
+	Type     string  `json:"type"`
+	ParentID *uint64 `json:"parent_id"`
+	Status   string  `json:"status"`
 }
@@ -0,0 +1,238 @@
+package usecase_audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/queue"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mongodb"
+	moentity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mongodb/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	errWrap "github.com/pkg/errors"
+)
+
+type actorKey struct{}
+
+// ActorKey is the key the actor of a request is kept under. Fiber locals are fasthttp
+// user values, which the request context's Value reads back, so an actor stored with
+// c.Locals(ActorKey, actor) reaches the usecases through c.Context().
+var ActorKey = actorKey{}
+
+// SystemActor is recorded for the changes no request asked for, e.g. the purge job's
+var SystemActor = entity.Actor{ID: "system"}
+
+// WithActor attaches the actor to a context outside of a request
+func WithActor(ctx context.Context, actor entity.Actor) context.Context {
+	return context.WithValue(ctx, ActorKey, actor)
+}
+
+// ActorFromContext returns the actor of the request the context belongs to
+func ActorFromContext(ctx context.Context) entity.Actor {
+	if actor, ok := ctx.Value(ActorKey).(entity.Actor); ok {
+		return actor
+	}
+	return SystemActor
+}
+
+// redactedFields are recorded as changed without their values
+var redactedFields = map[string]bool{
+	"client_secret": true,
+	"private_key":   true,
+}
+
+// ignoredFields change on every write and say nothing about the change
+var ignoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"version":    true,
+}
+
+const redacted = "[REDACTED]"
+
+type AuditUseCase struct {
+	logUseCase usecase_log.ILogUseCase
+	queue      queue.Queue
+	auditRepo  mongodb.AuditRepository
+}
+
+func NewAuditUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	queue queue.Queue,
+	auditRepo mongodb.AuditRepository,
+) *AuditUseCase {
+	return &AuditUseCase{
+		logUseCase: logUseCase,
+		queue:      queue,
+		auditRepo:  auditRepo,
+	}
+}
+
+type IAuditUseCase interface {
+	Record(ctx context.Context, action string, entityType string, entityID uint64, before interface{}, after interface{})
+	ListAudits(ctx context.Context, req *entity.AuditListRequest) ([]*entity.AuditResponse, *generalEntity.PaginationMeta, error)
+}
+
+// Record publishes the change to the audit queue, the worker stores it. before is nil
+// for a create and after is nil for a delete. The change is already committed, so a
+// failure to publish is logged with the whole event instead of failing the request.
+func (u *AuditUseCase) Record(ctx context.Context, action string, entityType string, entityID uint64, before interface{}, after interface{}) {
+	funcName := "AuditUseCase.Record"
+	captureFieldError := generalEntity.CaptureFields{
+		"action":     action,
+		"entityType": entityType,
+		"entityID":   helper.ToString(entityID),
+	}
+
+	changes, err := diff(before, after)
+	if err != nil {
+		u.logUseCase.Error("audit.diff", funcName, err, captureFieldError)
+		return
+	}
+
+	actor := ActorFromContext(ctx)
+	event := entity.AuditEvent{
+		Actor:      actor.ID,
+		IP:         actor.IP,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    changes,
+		CreatedAt:  time.Now(),
+	}
+	payload, err := helper.Serialize(event)
+	if err != nil {
+		u.logUseCase.Error("helper.Serialize", funcName, err, captureFieldError)
+		return
+	}
+	if err := u.queue.Publish(queue.ProcessSyncAudit, payload, 1); err != nil {
+		captureFieldError["event"] = string(payload)
+		u.logUseCase.Error("queue.Publish", funcName, err, captureFieldError)
+	}
+}
+
+// diff compares the JSON fields of before and after and returns the ones that differ
+func diff(before interface{}, after interface{}) (map[string]entity.FieldChange, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]entity.FieldChange)
+	record := func(name string) {
+		if ignoredFields[name] {
+			return
+		}
+		if _, done := changes[name]; done {
+			return
+		}
+		beforeValue, afterValue := beforeFields[name], afterFields[name]
+		if reflect.DeepEqual(beforeValue, afterValue) {
+			return
+		}
+		if redactedFields[name] {
+			beforeValue, afterValue = redact(beforeValue), redact(afterValue)
+		}
+		changes[name] = entity.FieldChange{Before: beforeValue, After: afterValue}
+	}
+	for name := range beforeFields {
+		record(name)
+	}
+	for name := range afterFields {
+		record(name)
+	}
+	return changes, nil
+}
+
+func toFields(value interface{}) (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	if value == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// redact hides a value but keeps telling an empty or missing value from a set one
+func redact(value interface{}) interface{} {
+	if value == nil || value == "" {
+		return value
+	}
+	return redacted
+}
+
+func (u *AuditUseCase) ListAudits(ctx context.Context, req *entity.AuditListRequest) ([]*entity.AuditResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "AuditUseCase.ListAudits"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	filter := &moentity.AuditFilter{
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		Actor:      req.Actor,
+		Action:     req.Action,
+		Limit:      limit,
+		Offset:     (page - 1) * limit,
+	}
+	if req.CreatedFrom != "" {
+		createdFrom, _ := helper.ParseDate(req.CreatedFrom)
+		filter.CreatedFrom = &createdFrom
+	}
+	if req.CreatedTo != "" {
+		// The end date is inclusive, so filter up to the start of the next day
+		createdTo, _ := helper.ParseDate(req.CreatedTo)
+		createdTo = createdTo.AddDate(0, 0, 1)
+		filter.CreatedTo = &createdTo
+	}
+
+	audits, total, err := u.auditRepo.FindAll(ctx, filter)
+	if err != nil {
+		u.logUseCase.Error("auditRepo.FindAll", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	response := make([]*entity.AuditResponse, 0, len(audits))
+	for i := range audits {
+		response = append(response, toAuditResponse(&audits[i]))
+	}
+	return response, generalEntity.NewPaginationMeta(page, limit, total), nil
+}
+
+func toAuditResponse(audit *moentity.AuditCollection) *entity.AuditResponse {
+	changes := make(map[string]entity.FieldChange, len(audit.Changes))
+	for name, change := range audit.Changes {
+		changes[name] = entity.FieldChange{Before: change.Before, After: change.After}
+	}
+	return &entity.AuditResponse{
+		ID:         audit.ID.Hex(),
+		Actor:      audit.Actor,
+		IP:         audit.IP,
+		Action:     audit.Action,
+		EntityType: audit.EntityType,
+		EntityID:   audit.EntityID,
+		Changes:    changes,
+		CreatedAt:  helper.ConvertToJakartaTime(audit.Created),
+	}
+}
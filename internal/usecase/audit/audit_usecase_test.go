@@ -0,0 +1,86 @@
+package usecase_audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/queue"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type queueStub struct {
+	queue.Queue
+	key     string
+	message []byte
+}
+
+func (q *queueStub) Publish(key string, message []byte, attempts int32) error {
+	q.key = key
+	q.message = message
+	return nil
+}
+
+type account struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Status       string `json:"status"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+type AuditUsecaseTestSuite struct {
+	suite.Suite
+
+	queue   *queueStub
+	usecase *AuditUseCase
+}
+
+func (s *AuditUsecaseTestSuite) SetupTest() {
+	s.queue = &queueStub{}
+	s.usecase = NewAuditUseCase(nil, s.queue, nil)
+}
+
+func TestAuditUsecase(t *testing.T) {
+	suite.Run(t, new(AuditUsecaseTestSuite))
+}
+
+func (s *AuditUsecaseTestSuite) published() entity.AuditEvent {
+	var event entity.AuditEvent
+	s.Require().NoError(json.Unmarshal(s.queue.message, &event))
+	return event
+}
+
+func (s *AuditUsecaseTestSuite) TestRecordUpdate() {
+	ctx := WithActor(context.Background(), entity.Actor{ID: "ops@example.com", IP: "10.0.0.1"})
+	before := &account{ClientID: "client-1", ClientSecret: "old", Status: "active", UpdatedAt: "2025-07-01"}
+	after := &account{ClientID: "client-1", ClientSecret: "new", Status: "inactive", UpdatedAt: "2025-07-02"}
+
+	s.usecase.Record(ctx, entity.ActionUpdate, entity.EntityAccount, 7, before, after)
+
+	s.Equal(queue.ProcessSyncAudit, s.queue.key)
+	event := s.published()
+	s.Equal("ops@example.com", event.Actor)
+	s.Equal("10.0.0.1", event.IP)
+	s.Equal(uint64(7), event.EntityID)
+	// Unchanged and timestamp fields are left out, secrets are recorded without values
+	s.Equal(map[string]entity.FieldChange{
+		"client_secret": {Before: redacted, After: redacted},
+		"status":        {Before: "active", After: "inactive"},
+	}, event.Changes)
+}
+
+func (s *AuditUsecaseTestSuite) TestRecordCreate() {
+	after := &account{ClientID: "client-1", ClientSecret: "secret", Status: "active"}
+
+	s.usecase.Record(context.Background(), entity.ActionCreate, entity.EntityAccount, 7, nil, after)
+
+	event := s.published()
+	s.Equal(SystemActor.ID, event.Actor)
+	s.Equal(map[string]entity.FieldChange{
+		"client_id":     {Before: nil, After: "client-1"},
+		"client_secret": {Before: nil, After: redacted},
+		"status":        {Before: nil, After: "active"},
+	}, event.Changes)
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ActionCreate            = "create"
+	ActionUpdate            = "update"
+	ActionDelete            = "delete"
+	ActionRestore           = "restore"
+	ActionPurge             = "purge"
+	ActionSetParent         = "set_parent"
+	ActionRemoveParent      = "remove_parent"
+	ActionRotateCredentials = "rotate_credentials"
+	ActionChangeStatus      = "change_status"
+	ActionImport            = "import"
+)
+
+const (
+	EntityMerchant         = "merchant"
+	EntityAccount          = "account"
+	EntityParticipant      = "participant"
+	EntityTransactionLimit = "transaction_limit"
+	EntityFraudRule        = "fraud_rule"
+)
+
+// Actor is who made a change and the address the request came from, IP is empty for
+// changes made by the system itself
+type Actor struct {
+	ID string
+	IP string
+}
+
+// AuditEvent is published to the audit queue for every change and stored as is
+type AuditEvent struct {
+	Actor      string                 `json:"actor"`
+	IP         string                 `json:"ip"`
+	Action     string                 `json:"action"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   uint64                 `json:"entity_id"`
+	Changes    map[string]FieldChange `json:"changes"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+func (e *AuditEvent) LoadFromMap(m map[string]interface{}) error {
+	data, err := json.Marshal(m)
+	if err == nil {
+		err = json.Unmarshal(data, e)
+	}
+	return err
+}
+
+type AuditListRequest struct {
+	EntityType  string `query:"entity_type" validate:"required_with=EntityID,omitempty,oneof=merchant account participant transaction_limit fraud_rule"`
+	EntityID    uint64 `query:"entity_id"`
+	Actor       string `query:"actor"`
+	Action      string `query:"action" validate:"omitempty,oneof=create update delete restore purge set_parent remove_parent rotate_credentials change_status import"`
+	CreatedFrom string `query:"created_from" validate:"omitempty,datetime=2006-01-02"`
+	CreatedTo   string `query:"created_to" validate:"omitempty,datetime=2006-01-02"`
+	Page        int    `query:"page" validate:"omitempty,min=1"`
+	Limit       int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type AuditResponse struct {
+	ID         string                 `json:"id"`
+	Actor      string                 `json:"actor"`
+	IP         string                 `json:"ip"`
+	Action     string                 `json:"action"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   uint64                 `json:"entity_id"`
+	Changes    map[string]FieldChange `json:"changes"`
+	CreatedAt  string                 `json:"created_at"`
+}
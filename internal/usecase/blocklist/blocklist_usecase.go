@@ -0,0 +1,403 @@
+package usecase_blocklist
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis"
+	rEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	errWrap "github.com/pkg/errors"
+)
+
+// blocklistHitProcess is the log pipeline process blocked requests are reported under
+const blocklistHitProcess = "BlocklistHit"
+
+type BlocklistUseCase struct {
+	logUseCase         usecase_log.ILogUseCase
+	blocklistRepo      mysql.IBlocklistRepository
+	blocklistCacheRepo redis.IBlocklistRepository
+	merchantRepo       mysql.IMerchantRepository
+	option             *config.BlocklistOption
+
+	// loadMu lets one request per instance rebuild the cache while the others wait
+	loadMu sync.Mutex
+}
+
+func NewBlocklistUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	blocklistRepo mysql.IBlocklistRepository,
+	blocklistCacheRepo redis.IBlocklistRepository,
+	merchantRepo mysql.IMerchantRepository,
+	option *config.BlocklistOption,
+) *BlocklistUseCase {
+	return &BlocklistUseCase{
+		logUseCase:         logUseCase,
+		blocklistRepo:      blocklistRepo,
+		blocklistCacheRepo: blocklistCacheRepo,
+		merchantRepo:       merchantRepo,
+		option:             option,
+	}
+}
+
+type IBlocklistUseCase interface {
+	Check(ctx context.Context, subject *entity.BlocklistSubject) error
+	AddEntry(ctx context.Context, req *entity.BlocklistEntryRequest) (*entity.BlocklistEntryResponse, error)
+	RemoveEntry(ctx context.Context, req *entity.BlocklistRemoveRequest) error
+	ListEntries(ctx context.Context, req *entity.BlocklistListRequest) ([]*entity.BlocklistEntryResponse, *generalEntity.PaginationMeta, error)
+	ListAudits(ctx context.Context, req *entity.BlocklistListRequest) ([]*entity.BlocklistAuditResponse, *generalEntity.PaginationMeta, error)
+}
+
+// Check rejects the subject when any of its values is blocklisted. The merchant's
+// NMID is looked up when only its ID is given, so transactions on QRs generated
+// before the NMID was blocked are stopped as well.
+func (u *BlocklistUseCase) Check(ctx context.Context, subject *entity.BlocklistSubject) error {
+	funcName := "BlocklistUseCase.Check"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(subject),
+	}
+
+	nmid := subject.NMID
+	if nmid == "" && subject.MerchantID != 0 {
+		merchant, err := u.merchantRepo.FindByID(ctx, subject.MerchantID)
+		if err != nil {
+			u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+			return err
+		}
+		nmid = merchant.NMID
+	}
+
+	values := map[string]string{}
+	if subject.CustomerMPAN != "" {
+		values[mEntity.BlocklistTypeCustomerMPAN] = subject.CustomerMPAN
+	}
+	if subject.MerchantID != 0 {
+		values[mEntity.BlocklistTypeMerchantID] = strconv.FormatUint(subject.MerchantID, 10)
+	}
+	if nmid != "" {
+		values[mEntity.BlocklistTypeNMID] = nmid
+	}
+	if subject.IP != "" {
+		values[mEntity.BlocklistTypeIP] = subject.IP
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	entries, err := u.match(ctx, values)
+	if err != nil {
+		u.logUseCase.Error("BlocklistUseCase.match", funcName, err, captureFieldError)
+		return err
+	}
+
+	now := time.Now().Unix()
+	for _, entry := range entries {
+		if entry.ExpiresAt != 0 && entry.ExpiresAt <= now {
+			continue
+		}
+		err := apperr.ErrBlocklisted()
+		captureFieldError["entryID"] = helper.ToString(entry.ID)
+		captureFieldError["type"] = entry.Type
+		u.logUseCase.Log(generalEntity.LogWarning, "blocklisted value matched", funcName, err, captureFieldError, blocklistHitProcess)
+		return err
+	}
+	return nil
+}
+
+// match reads the entries of values from the cache, rebuilding it from the database
+// when it has not been loaded or its refresh interval has passed
+func (u *BlocklistUseCase) match(ctx context.Context, values map[string]string) ([]rEntity.BlocklistCacheEntity, error) {
+	entries, loaded, err := u.blocklistCacheRepo.Match(ctx, values)
+	if err != nil || loaded {
+		return entries, err
+	}
+
+	u.loadMu.Lock()
+	defer u.loadMu.Unlock()
+
+	// Another request may have rebuilt it while this one waited
+	entries, loaded, err = u.blocklistCacheRepo.Match(ctx, values)
+	if err != nil || loaded {
+		return entries, err
+	}
+
+	active, err := u.blocklistRepo.FindActive(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	cached := make([]rEntity.BlocklistCacheEntity, 0, len(active))
+	for i := range active {
+		cached = append(cached, toBlocklistCacheEntity(&active[i]))
+	}
+	if err := u.blocklistCacheRepo.Load(ctx, cached, time.Duration(u.option.CacheRefreshSeconds)*time.Second); err != nil {
+		return nil, err
+	}
+
+	entries, _, err = u.blocklistCacheRepo.Match(ctx, values)
+	return entries, err
+}
+
+// AddEntry blocks a value and audits it. An expired entry for the same value is
+// replaced, an active one has to be removed first.
+func (u *BlocklistUseCase) AddEntry(ctx context.Context, req *entity.BlocklistEntryRequest) (*entity.BlocklistEntryResponse, error) {
+	funcName := "BlocklistUseCase.AddEntry"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	if req.Type == mEntity.BlocklistTypeMerchantID {
+		if _, err := strconv.ParseUint(req.Value, 10, 64); err != nil {
+			return nil, apperr.CustomError("value must be a merchant ID", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+		}
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != "" {
+		loc, _ := time.LoadLocation("Asia/Jakarta")
+		at, err := time.ParseInLocation("2006-01-02 15:04:05", req.ExpiresAt, loc)
+		if err != nil {
+			return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err.Error())
+		}
+		if !at.After(time.Now()) {
+			return nil, apperr.CustomError("expires_at must be in the future", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+		}
+		expiresAt = &at
+	}
+
+	var entry *mEntity.BlocklistEntryEntity
+	if err := mysql.DBTransaction(u.blocklistRepo, func(dbTrx mysql.TrxObj) error {
+		var err error
+		entry, err = u.blocklistRepo.LockByTypeValue(ctx, dbTrx, req.Type, req.Value)
+		switch {
+		case errWrap.Is(err, apperr.ErrRecordNotFound()):
+			entry = &mEntity.BlocklistEntryEntity{
+				Type:      req.Type,
+				Value:     req.Value,
+				Reason:    req.Reason,
+				ExpiresAt: expiresAt,
+				CreatedBy: req.Actor,
+			}
+			if err := u.blocklistRepo.Create(ctx, dbTrx, entry); err != nil {
+				u.logUseCase.Error("blocklistRepo.Create", funcName, err, captureFieldError)
+				return err
+			}
+		case err != nil:
+			u.logUseCase.Error("blocklistRepo.LockByTypeValue", funcName, err, captureFieldError)
+			return err
+		case isActive(entry, time.Now()):
+			return apperr.CustomError("value is already blocklisted", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+		default:
+			changes := map[string]interface{}{
+				"reason":     req.Reason,
+				"expires_at": expiresAt,
+				"created_by": req.Actor,
+			}
+			if err := u.blocklistRepo.Update(ctx, dbTrx, entry, changes); err != nil {
+				u.logUseCase.Error("blocklistRepo.Update", funcName, err, captureFieldError)
+				return err
+			}
+			entry.Reason = req.Reason
+			entry.ExpiresAt = expiresAt
+			entry.CreatedBy = req.Actor
+		}
+
+		return u.audit(ctx, dbTrx, mEntity.BlocklistActionAdd, entry, req.Actor)
+	}); err != nil {
+		return nil, err
+	}
+
+	// The entry is stored, a cache that missed it catches up on its next rebuild
+	cached := toBlocklistCacheEntity(entry)
+	if err := u.blocklistCacheRepo.Put(ctx, &cached); err != nil {
+		u.logUseCase.Error("blocklistCacheRepo.Put", funcName, err, captureFieldError)
+	}
+
+	return toBlocklistEntryResponse(entry), nil
+}
+
+// RemoveEntry unblocks a value, the audit trail keeps a copy of the removed entry
+func (u *BlocklistUseCase) RemoveEntry(ctx context.Context, req *entity.BlocklistRemoveRequest) error {
+	funcName := "BlocklistUseCase.RemoveEntry"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	var entry *mEntity.BlocklistEntryEntity
+	if err := mysql.DBTransaction(u.blocklistRepo, func(dbTrx mysql.TrxObj) error {
+		var err error
+		entry, err = u.blocklistRepo.LockByID(ctx, dbTrx, req.ID)
+		if err != nil {
+			u.logUseCase.Error("blocklistRepo.LockByID", funcName, err, captureFieldError)
+			return err
+		}
+		if err := u.blocklistRepo.DeleteByID(ctx, dbTrx, entry.ID); err != nil {
+			u.logUseCase.Error("blocklistRepo.DeleteByID", funcName, err, captureFieldError)
+			return err
+		}
+		return u.audit(ctx, dbTrx, mEntity.BlocklistActionRemove, entry, req.Actor)
+	}); err != nil {
+		return err
+	}
+
+	if err := u.blocklistCacheRepo.Remove(ctx, entry.Type, entry.Value); err != nil {
+		u.logUseCase.Error("blocklistCacheRepo.Remove", funcName, err, captureFieldError)
+	}
+	return nil
+}
+
+func (u *BlocklistUseCase) audit(ctx context.Context, dbTrx mysql.TrxObj, action string, entry *mEntity.BlocklistEntryEntity, actor string) error {
+	if err := u.blocklistRepo.CreateAudit(ctx, dbTrx, &mEntity.BlocklistAuditEntity{
+		EntryID:   entry.ID,
+		Action:    action,
+		Type:      entry.Type,
+		Value:     entry.Value,
+		Reason:    entry.Reason,
+		ExpiresAt: entry.ExpiresAt,
+		Actor:     actor,
+	}); err != nil {
+		u.logUseCase.Error("blocklistRepo.CreateAudit", "BlocklistUseCase.audit", err, generalEntity.CaptureFields{
+			"entryID": helper.ToString(entry.ID),
+			"action":  action,
+		})
+		return err
+	}
+	return nil
+}
+
+// ListEntries pages through the entries newest first, expired ones included
+func (u *BlocklistUseCase) ListEntries(ctx context.Context, req *entity.BlocklistListRequest) ([]*entity.BlocklistEntryResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "BlocklistUseCase.ListEntries"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	entries, total, err := u.blocklistRepo.FindEntries(ctx, &mEntity.BlocklistFilter{
+		Type:   req.Type,
+		Value:  req.Value,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		u.logUseCase.Error("blocklistRepo.FindEntries", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	response := make([]*entity.BlocklistEntryResponse, 0, len(entries))
+	for i := range entries {
+		response = append(response, toBlocklistEntryResponse(&entries[i]))
+	}
+
+	return response, generalEntity.NewPaginationMeta(page, limit, total), nil
+}
+
+// ListAudits pages through the audit trail newest first
+func (u *BlocklistUseCase) ListAudits(ctx context.Context, req *entity.BlocklistListRequest) ([]*entity.BlocklistAuditResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "BlocklistUseCase.ListAudits"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	audits, total, err := u.blocklistRepo.FindAudits(ctx, &mEntity.BlocklistFilter{
+		Type:   req.Type,
+		Value:  req.Value,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		u.logUseCase.Error("blocklistRepo.FindAudits", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	response := make([]*entity.BlocklistAuditResponse, 0, len(audits))
+	for i := range audits {
+		response = append(response, toBlocklistAuditResponse(&audits[i]))
+	}
+
+	return response, generalEntity.NewPaginationMeta(page, limit, total), nil
+}
+
+func isActive(entry *mEntity.BlocklistEntryEntity, now time.Time) bool {
+	return entry.ExpiresAt == nil || entry.ExpiresAt.After(now)
+}
+
+func toBlocklistCacheEntity(entry *mEntity.BlocklistEntryEntity) rEntity.BlocklistCacheEntity {
+	cached := rEntity.BlocklistCacheEntity{
+		ID:    entry.ID,
+		Type:  entry.Type,
+		Value: entry.Value,
+	}
+	if entry.ExpiresAt != nil {
+		cached.ExpiresAt = entry.ExpiresAt.Unix()
+	}
+	return cached
+}
+
+func toBlocklistEntryResponse(entry *mEntity.BlocklistEntryEntity) *entity.BlocklistEntryResponse {
+	response := &entity.BlocklistEntryResponse{
+		ID:        entry.ID,
+		Type:      entry.Type,
+		Value:     entry.Value,
+		Reason:    entry.Reason,
+		Active:    isActive(entry, time.Now()),
+		CreatedBy: entry.CreatedBy,
+		CreatedAt: helper.ConvertToJakartaTime(entry.CreatedAt),
+		UpdatedAt: helper.ConvertToJakartaTime(entry.UpdatedAt),
+	}
+	if entry.ExpiresAt != nil {
+		expiresAt := helper.ConvertToJakartaTime(*entry.ExpiresAt)
+		response.ExpiresAt = &expiresAt
+	}
+	return response
+}
+
+func toBlocklistAuditResponse(audit *mEntity.BlocklistAuditEntity) *entity.BlocklistAuditResponse {
+	response := &entity.BlocklistAuditResponse{
+		ID:        audit.ID,
+		EntryID:   audit.EntryID,
+		Action:    audit.Action,
+		Type:      audit.Type,
+		Value:     audit.Value,
+		Reason:    audit.Reason,
+		Actor:     audit.Actor,
+		CreatedAt: helper.ConvertToJakartaTime(audit.CreatedAt),
+	}
+	if audit.ExpiresAt != nil {
+		expiresAt := helper.ConvertToJakartaTime(*audit.ExpiresAt)
+		response.ExpiresAt = &expiresAt
+	}
+	return response
+}
@@ -0,0 +1,141 @@
+package usecase_blocklist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	rEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type blocklistRepoStub struct {
+	mysql.IBlocklistRepository
+	entries    []mEntity.BlocklistEntryEntity
+	loadedRows int
+}
+
+func (r *blocklistRepoStub) FindActive(ctx context.Context, now time.Time) ([]mEntity.BlocklistEntryEntity, error) {
+	var active []mEntity.BlocklistEntryEntity
+	for _, entry := range r.entries {
+		if isActive(&entry, now) {
+			active = append(active, entry)
+		}
+	}
+	r.loadedRows += len(active)
+	return active, nil
+}
+
+// blocklistCacheStub keeps the cache in memory like the Redis hash
+type blocklistCacheStub struct {
+	entries map[string]rEntity.BlocklistCacheEntity
+	loaded  bool
+}
+
+func (c *blocklistCacheStub) Match(ctx context.Context, values map[string]string) ([]rEntity.BlocklistCacheEntity, bool, error) {
+	var found []rEntity.BlocklistCacheEntity
+	for entryType, value := range values {
+		if entry, ok := c.entries[entryType+":"+value]; ok {
+			found = append(found, entry)
+		}
+	}
+	return found, c.loaded, nil
+}
+
+func (c *blocklistCacheStub) Put(ctx context.Context, entry *rEntity.BlocklistCacheEntity) error {
+	c.entries[entry.Type+":"+entry.Value] = *entry
+	return nil
+}
+
+func (c *blocklistCacheStub) Remove(ctx context.Context, entryType string, value string) error {
+	delete(c.entries, entryType+":"+value)
+	return nil
+}
+
+func (c *blocklistCacheStub) Load(ctx context.Context, entries []rEntity.BlocklistCacheEntity, ttl time.Duration) error {
+	c.entries = map[string]rEntity.BlocklistCacheEntity{}
+	for _, entry := range entries {
+		c.entries[entry.Type+":"+entry.Value] = entry
+	}
+	c.loaded = true
+	return nil
+}
+
+type merchantRepoStub struct {
+	mysql.IMerchantRepository
+}
+
+func (merchantRepoStub) FindByID(ctx context.Context, id uint64) (*mEntity.MerchantEntity, error) {
+	return &mEntity.MerchantEntity{ID: id, NMID: "ID1020000000001"}, nil
+}
+
+type logStub struct {
+	usecase_log.ILogUseCase
+	warnings int
+}
+
+func (l *logStub) Error(process string, funcName string, err error, logFields map[string]string) {}
+
+func (l *logStub) Log(status generalEntity.LogType, message string, funcName string, err error, logFields map[string]string, process string) {
+	l.warnings++
+}
+
+type BlocklistUseCaseTestSuite struct {
+	suite.Suite
+
+	repo    *blocklistRepoStub
+	cache   *blocklistCacheStub
+	log     *logStub
+	usecase *BlocklistUseCase
+}
+
+func (s *BlocklistUseCaseTestSuite) SetupTest() {
+	past := time.Now().Add(-time.Hour)
+	s.repo = &blocklistRepoStub{entries: []mEntity.BlocklistEntryEntity{
+		{ID: 1, Type: mEntity.BlocklistTypeCustomerMPAN, Value: "9360000000000000001"},
+		{ID: 2, Type: mEntity.BlocklistTypeNMID, Value: "ID1020000000001"},
+		{ID: 3, Type: mEntity.BlocklistTypeIP, Value: "10.0.0.1", ExpiresAt: &past},
+	}}
+	s.cache = &blocklistCacheStub{entries: map[string]rEntity.BlocklistCacheEntity{}}
+	s.log = &logStub{}
+	s.usecase = NewBlocklistUseCase(s.log, s.repo, s.cache, merchantRepoStub{}, &config.BlocklistOption{CacheRefreshSeconds: 300})
+}
+
+func TestBlocklistUseCase(t *testing.T) {
+	suite.Run(t, new(BlocklistUseCaseTestSuite))
+}
+
+func (s *BlocklistUseCaseTestSuite) TestCheckLoadsCacheOnce() {
+	err := s.usecase.Check(context.Background(), &entity.BlocklistSubject{CustomerMPAN: "9360000000000000001"})
+	s.Equal(apperr.ErrBlocklisted(), err)
+	s.True(s.cache.loaded)
+	s.Equal(2, s.repo.loadedRows)
+
+	err = s.usecase.Check(context.Background(), &entity.BlocklistSubject{CustomerMPAN: "9360000000000000002"})
+	s.NoError(err)
+	s.Equal(2, s.repo.loadedRows)
+	s.Equal(1, s.log.warnings)
+}
+
+func (s *BlocklistUseCaseTestSuite) TestCheckLooksUpMerchantNMID() {
+	err := s.usecase.Check(context.Background(), &entity.BlocklistSubject{MerchantID: 7})
+	s.Equal(apperr.ErrBlocklisted(), err)
+}
+
+func (s *BlocklistUseCaseTestSuite) TestCheckSkipsExpiredEntries() {
+	// Cached before it expired, the cache still holds it until the next rebuild
+	s.cache.loaded = true
+	s.cache.entries["ip:10.0.0.1"] = rEntity.BlocklistCacheEntity{ID: 3, Type: mEntity.BlocklistTypeIP, Value: "10.0.0.1", ExpiresAt: time.Now().Add(-time.Minute).Unix()}
+
+	err := s.usecase.Check(context.Background(), &entity.BlocklistSubject{IP: "10.0.0.1"})
+	s.NoError(err)
+	s.Zero(s.log.warnings)
+}
@@ -0,0 +1,56 @@
+package entity
+
+// BlocklistSubject is who takes part in a QR or transaction, empty fields are not
+// checked
+type BlocklistSubject struct {
+	CustomerMPAN string
+	MerchantID   uint64
+	NMID         string
+	IP           string
+}
+
+// BlocklistEntryRequest blocks a value until ExpiresAt, Jakarta time. Without
+// ExpiresAt the value stays blocked until it is removed.
+type BlocklistEntryRequest struct {
+	Type      string `json:"type" validate:"required,oneof=customer_mpan merchant_id nmid ip"`
+	Value     string `json:"value" validate:"required,max=100"`
+	Reason    string `json:"reason" validate:"required,max=255"`
+	ExpiresAt string `json:"expires_at" validate:"omitempty,datetime=2006-01-02 15:04:05"`
+	Actor     string `json:"-" validate:"required,max=100"`
+}
+
+type BlocklistRemoveRequest struct {
+	ID    uint64 `validate:"required"`
+	Actor string `validate:"required,max=100"`
+}
+
+type BlocklistListRequest struct {
+	Type  string `query:"type" validate:"omitempty,oneof=customer_mpan merchant_id nmid ip"`
+	Value string `query:"value" validate:"omitempty,max=100"`
+	Page  int    `query:"page" validate:"omitempty,min=1"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type BlocklistEntryResponse struct {
+	ID        uint64  `json:"id"`
+	Type      string  `json:"type"`
+	Value     string  `json:"value"`
+	Reason    string  `json:"reason"`
+	ExpiresAt *string `json:"expires_at"`
+	Active    bool    `json:"active"`
+	CreatedBy string  `json:"created_by"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+type BlocklistAuditResponse struct {
+	ID        uint64  `json:"id"`
+	EntryID   uint64  `json:"entry_id"`
+	Action    string  `json:"action"`
+	Type      string  `json:"type"`
+	Value     string  `json:"value"`
+	Reason    string  `json:"reason"`
+	ExpiresAt *string `json:"expires_at"`
+	Actor     string  `json:"actor"`
+	CreatedAt string  `json:"created_at"`
+}
@@ -0,0 +1,493 @@
+package usecase_dispute
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/dispute/entity"
+	usecase_ledger "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/ledger"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	errWrap "github.com/pkg/errors"
+)
+
+// disputeDirectory is where evidence files are kept, relative to config.StorageDirectory
+const disputeDirectory = "disputes"
+
+// evidenceContentTypes are the evidence files accepted, receipts and screenshots
+var evidenceContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type DisputeUseCase struct {
+	logUseCase      usecase_log.ILogUseCase
+	disputeRepo     mysql.IDisputeRepository
+	transactionRepo mysql.ITransactionRepository
+	merchantRepo    mysql.IMerchantRepository
+	ledgerUseCase   usecase_ledger.ILedgerUseCase
+	option          *config.DisputeOption
+}
+
+func NewDisputeUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	disputeRepo mysql.IDisputeRepository,
+	transactionRepo mysql.ITransactionRepository,
+	merchantRepo mysql.IMerchantRepository,
+	ledgerUseCase usecase_ledger.ILedgerUseCase,
+	option *config.DisputeOption,
+) *DisputeUseCase {
+	return &DisputeUseCase{
+		logUseCase:      logUseCase,
+		disputeRepo:     disputeRepo,
+		transactionRepo: transactionRepo,
+		merchantRepo:    merchantRepo,
+		ledgerUseCase:   ledgerUseCase,
+		option:          option,
+	}
+}
+
+// IDisputeUseCase manages disputes raised on payments. A dispute starts open, moves
+// to merchant_response once the merchant answers and ends won or lost. A lost
+// dispute refunds the disputed amount to the customer.
+type IDisputeUseCase interface {
+	CreateDispute(ctx context.Context, req *entity.DisputeRequest) (*entity.DisputeResponse, error)
+	GetDispute(ctx context.Context, disputeID uint64) (*entity.DisputeResponse, error)
+	ListMerchantDisputes(ctx context.Context, req *entity.DisputeListRequest) ([]*entity.DisputeResponse, *generalEntity.PaginationMeta, error)
+	RespondDispute(ctx context.Context, req *entity.DisputeResponseRequest) (*entity.DisputeResponse, error)
+	ResolveDispute(ctx context.Context, req *entity.DisputeResolveRequest) (*entity.DisputeResponse, error)
+	AddEvidence(ctx context.Context, req *entity.EvidenceRequest, file io.Reader) (*entity.EvidenceResponse, error)
+	GetEvidenceFile(ctx context.Context, disputeID uint64, evidenceID uint64) (*entity.EvidenceFile, error)
+	ExpireDisputes(ctx context.Context, now time.Time, batchSize int) (int, error)
+}
+
+func (u *DisputeUseCase) CreateDispute(ctx context.Context, req *entity.DisputeRequest) (*entity.DisputeResponse, error) {
+	funcName := "DisputeUseCase.CreateDispute"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	transaction, err := u.transactionRepo.FindByRefID(ctx, req.RefID)
+	if err != nil {
+		u.logUseCase.Error("transactionRepo.FindByRefID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	// Only money the customer actually paid can be disputed
+	if transaction.Type != "payment" ||
+		(transaction.Status != mEntity.TransactionStatusCompleted && transaction.Status != mEntity.TransactionStatusSettled) {
+		return nil, apperr.CustomError("Only completed payments can be disputed", generalEntity.BAD_REQUEST_CODE, http.StatusUnprocessableEntity)
+	}
+
+	amount := transaction.TotalAmount
+	if req.Amount > 0 {
+		if req.Amount > transaction.TotalAmount {
+			return nil, apperr.CustomError("Dispute amount must not exceed the transaction amount", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+		}
+		amount = req.Amount
+	}
+
+	exists, err := u.disputeRepo.ExistsByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		u.logUseCase.Error("disputeRepo.ExistsByTransactionID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if exists {
+		return nil, apperr.CustomError("Transaction is already disputed", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	}
+
+	dispute := &mEntity.DisputeEntity{
+		TransactionID: transaction.ID,
+		MerchantID:    transaction.MerchantID,
+		ReasonCode:    req.ReasonCode,
+		Reason:        req.Reason,
+		Amount:        amount,
+		Status:        mEntity.DisputeStatusOpen,
+		ResponseDueAt: time.Now().AddDate(0, 0, u.option.ResponseDays),
+	}
+	if err := u.disputeRepo.Create(ctx, nil, dispute); err != nil {
+		u.logUseCase.Error("disputeRepo.Create", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toDisputeResponse(dispute), nil
+}
+
+func (u *DisputeUseCase) GetDispute(ctx context.Context, disputeID uint64) (*entity.DisputeResponse, error) {
+	funcName := "DisputeUseCase.GetDispute"
+	captureFieldError := generalEntity.CaptureFields{
+		"disputeID": helper.ToString(disputeID),
+	}
+
+	dispute, err := u.disputeRepo.FindByID(ctx, disputeID)
+	if err != nil {
+		u.logUseCase.Error("disputeRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	evidences, err := u.disputeRepo.FindEvidenceByDisputeID(ctx, disputeID)
+	if err != nil {
+		u.logUseCase.Error("disputeRepo.FindEvidenceByDisputeID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	response := toDisputeResponse(dispute)
+	response.Evidences = make([]*entity.EvidenceResponse, 0, len(evidences))
+	for i := range evidences {
+		response.Evidences = append(response.Evidences, toEvidenceResponse(&evidences[i]))
+	}
+	return response, nil
+}
+
+func (u *DisputeUseCase) ListMerchantDisputes(ctx context.Context, req *entity.DisputeListRequest) ([]*entity.DisputeResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "DisputeUseCase.ListMerchantDisputes"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, req.MerchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	disputes, total, err := u.disputeRepo.FindByMerchantID(ctx, &mEntity.DisputeFilter{
+		MerchantID: req.MerchantID,
+		Status:     req.Status,
+		Limit:      limit,
+		Offset:     (page - 1) * limit,
+	})
+	if err != nil {
+		u.logUseCase.Error("disputeRepo.FindByMerchantID", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	responses := make([]*entity.DisputeResponse, 0, len(disputes))
+	for i := range disputes {
+		responses = append(responses, toDisputeResponse(&disputes[i]))
+	}
+	return responses, generalEntity.NewPaginationMeta(page, limit, total), nil
+}
+
+// RespondDispute records the merchant's answer, it is only accepted before the response deadline
+func (u *DisputeUseCase) RespondDispute(ctx context.Context, req *entity.DisputeResponseRequest) (*entity.DisputeResponse, error) {
+	funcName := "DisputeUseCase.RespondDispute"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	if err := mysql.DBTransaction(u.disputeRepo, func(dbTrx mysql.TrxObj) error {
+		dispute, err := u.disputeRepo.LockByID(ctx, dbTrx, req.ID)
+		if err != nil {
+			return err
+		}
+		if dispute.Status != mEntity.DisputeStatusOpen {
+			return apperr.CustomError("Dispute is no longer waiting for a merchant response", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+		}
+
+		now := time.Now()
+		if now.After(dispute.ResponseDueAt) {
+			return apperr.CustomError("Dispute response deadline has passed", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+		}
+
+		return u.disputeRepo.Update(ctx, dbTrx, dispute, map[string]interface{}{
+			"status":            mEntity.DisputeStatusMerchantResponse,
+			"merchant_response": req.Response,
+			"responded_at":      now,
+		})
+	}); err != nil {
+		u.logUseCase.Error("DisputeUseCase.RespondDispute", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return u.GetDispute(ctx, req.ID)
+}
+
+// ResolveDispute records the scheme's decision. A lost dispute is refunded in the
+// same DB transaction.
+func (u *DisputeUseCase) ResolveDispute(ctx context.Context, req *entity.DisputeResolveRequest) (*entity.DisputeResponse, error) {
+	funcName := "DisputeUseCase.ResolveDispute"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	if err := mysql.DBTransaction(u.disputeRepo, func(dbTrx mysql.TrxObj) error {
+		dispute, err := u.disputeRepo.LockByID(ctx, dbTrx, req.ID)
+		if err != nil {
+			return err
+		}
+		if dispute.Status == mEntity.DisputeStatusWon || dispute.Status == mEntity.DisputeStatusLost {
+			return apperr.CustomError("Dispute has already been resolved", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+		}
+
+		if req.Outcome == mEntity.DisputeStatusLost {
+			return u.loseDispute(ctx, dbTrx, dispute, req.Note)
+		}
+		return u.disputeRepo.Update(ctx, dbTrx, dispute, map[string]interface{}{
+			"status":          mEntity.DisputeStatusWon,
+			"resolution_note": req.Note,
+			"resolved_at":     time.Now(),
+		})
+	}); err != nil {
+		u.logUseCase.Error("DisputeUseCase.ResolveDispute", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return u.GetDispute(ctx, req.ID)
+}
+
+// loseDispute closes a dispute as lost and creates a completed refund for the
+// disputed amount. The refund is posted to the ledger like any other refund and
+// settles against the merchant's balance in the next settlement run.
+func (u *DisputeUseCase) loseDispute(ctx context.Context, dbTrx mysql.TrxObj, dispute *mEntity.DisputeEntity, note string) error {
+	original, err := u.transactionRepo.LockByID(ctx, dbTrx, dispute.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	refund := &mEntity.TransactionEntity{
+		RefID:           fmt.Sprintf("CHB%010d", dispute.ID),
+		BillingID:       original.BillingID,
+		MerchantID:      original.MerchantID,
+		Amount:          dispute.Amount,
+		TotalAmount:     dispute.Amount,
+		PaymentMethod:   original.PaymentMethod,
+		Currency:        original.Currency,
+		Type:            "refund",
+		Issuer:          original.Issuer,
+		Acquirer:        original.Acquirer,
+		CustomerMPAN:    original.CustomerMPAN,
+		TransactionDate: now,
+		Status:          mEntity.TransactionStatusCompleted,
+	}
+	if err := u.transactionRepo.Create(ctx, dbTrx, refund, true); err != nil {
+		return err
+	}
+	if err := u.ledgerUseCase.PostTransaction(ctx, dbTrx, refund); err != nil {
+		return err
+	}
+
+	return u.disputeRepo.Update(ctx, dbTrx, dispute, map[string]interface{}{
+		"status":                mEntity.DisputeStatusLost,
+		"resolution_note":       note,
+		"refund_transaction_id": refund.ID,
+		"resolved_at":           now,
+	})
+}
+
+// AddEvidence stores an evidence file next to the dispute, evidence can be added
+// until the dispute is resolved
+func (u *DisputeUseCase) AddEvidence(ctx context.Context, req *entity.EvidenceRequest, file io.Reader) (*entity.EvidenceResponse, error) {
+	funcName := "DisputeUseCase.AddEvidence"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+	if !evidenceContentTypes[req.ContentType] {
+		return nil, apperr.CustomError("Evidence must be a PDF, JPEG or PNG file", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+	if req.Size > int64(u.option.MaxEvidenceSizeMB)<<20 {
+		return nil, apperr.CustomError(
+			fmt.Sprintf("Evidence file must not exceed %d MB", u.option.MaxEvidenceSizeMB),
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	dispute, err := u.disputeRepo.FindByID(ctx, req.DisputeID)
+	if err != nil {
+		u.logUseCase.Error("disputeRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if dispute.Status == mEntity.DisputeStatusWon || dispute.Status == mEntity.DisputeStatusLost {
+		return nil, apperr.CustomError("Dispute has already been resolved", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	}
+
+	filePath, err := u.saveEvidenceFile(dispute.ID, req.FileName, file)
+	if err != nil {
+		u.logUseCase.Error("DisputeUseCase.saveEvidenceFile", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	evidence := &mEntity.DisputeEvidenceEntity{
+		DisputeID:   dispute.ID,
+		FileName:    req.FileName,
+		FilePath:    filePath,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		Description: req.Description,
+	}
+	if err := u.disputeRepo.CreateEvidence(ctx, nil, evidence); err != nil {
+		u.logUseCase.Error("disputeRepo.CreateEvidence", funcName, err, captureFieldError)
+		os.Remove(filePath)
+		return nil, err
+	}
+
+	return toEvidenceResponse(evidence), nil
+}
+
+// saveEvidenceFile writes the upload under a name made unique by its upload time,
+// the original name is only kept as a hint and stripped of path characters
+func (u *DisputeUseCase) saveEvidenceFile(disputeID uint64, fileName string, file io.Reader) (string, error) {
+	directory := filepath.Join(config.StorageDirectory, disputeDirectory, helper.ToString(disputeID))
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return "", err
+	}
+
+	safeName := unsafeFileNameChars.ReplaceAllString(filepath.Base(fileName), "_")
+	filePath := filepath.Join(directory, fmt.Sprintf("%d_%s", time.Now().UnixNano(), safeName))
+
+	out, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, file); err != nil {
+		out.Close()
+		os.Remove(filePath)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+func (u *DisputeUseCase) GetEvidenceFile(ctx context.Context, disputeID uint64, evidenceID uint64) (*entity.EvidenceFile, error) {
+	funcName := "DisputeUseCase.GetEvidenceFile"
+	captureFieldError := generalEntity.CaptureFields{
+		"disputeID":  helper.ToString(disputeID),
+		"evidenceID": helper.ToString(evidenceID),
+	}
+
+	evidence, err := u.disputeRepo.FindEvidenceByID(ctx, evidenceID)
+	if err != nil {
+		u.logUseCase.Error("disputeRepo.FindEvidenceByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if evidence.DisputeID != disputeID {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	return &entity.EvidenceFile{
+		Path:     evidence.FilePath,
+		Filename: evidence.FileName,
+	}, nil
+}
+
+// ExpireDisputes closes as lost the open disputes the merchant did not answer
+// before the deadline. It returns how many were closed.
+func (u *DisputeUseCase) ExpireDisputes(ctx context.Context, now time.Time, batchSize int) (int, error) {
+	funcName := "DisputeUseCase.ExpireDisputes"
+	captureFieldError := generalEntity.CaptureFields{
+		"now": now.Format(time.RFC3339),
+	}
+
+	disputes, err := u.disputeRepo.FindOverdue(ctx, now, batchSize)
+	if err != nil {
+		u.logUseCase.Error("disputeRepo.FindOverdue", funcName, err, captureFieldError)
+		return 0, err
+	}
+
+	expired := 0
+	for _, overdue := range disputes {
+		lost := false
+		if err := mysql.DBTransaction(u.disputeRepo, func(dbTrx mysql.TrxObj) error {
+			dispute, err := u.disputeRepo.LockByID(ctx, dbTrx, overdue.ID)
+			if err != nil {
+				return err
+			}
+			// The merchant may have answered between the lookup and the lock
+			if dispute.Status != mEntity.DisputeStatusOpen {
+				return nil
+			}
+			lost = true
+			return u.loseDispute(ctx, dbTrx, dispute, "No merchant response before the deadline")
+		}); err != nil {
+			captureFieldError["disputeID"] = helper.ToString(overdue.ID)
+			u.logUseCase.Error("DisputeUseCase.loseDispute", funcName, err, captureFieldError)
+			return expired, err
+		}
+		if lost {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+func toDisputeResponse(dispute *mEntity.DisputeEntity) *entity.DisputeResponse {
+	response := &entity.DisputeResponse{
+		ID:                  dispute.ID,
+		TransactionID:       dispute.TransactionID,
+		MerchantID:          dispute.MerchantID,
+		ReasonCode:          dispute.ReasonCode,
+		Reason:              dispute.Reason,
+		Amount:              dispute.Amount,
+		Status:              dispute.Status,
+		MerchantResponse:    dispute.MerchantResponse,
+		ResolutionNote:      dispute.ResolutionNote,
+		RefundTransactionID: dispute.RefundTransactionID,
+		ResponseDueAt:       helper.ConvertToJakartaTime(dispute.ResponseDueAt),
+		CreatedAt:           helper.ConvertToJakartaTime(dispute.CreatedAt),
+	}
+	if dispute.RespondedAt != nil {
+		response.RespondedAt = helper.ConvertToJakartaTime(*dispute.RespondedAt)
+	}
+	if dispute.ResolvedAt != nil {
+		response.ResolvedAt = helper.ConvertToJakartaTime(*dispute.ResolvedAt)
+	}
+	return response
+}
+
+func toEvidenceResponse(evidence *mEntity.DisputeEvidenceEntity) *entity.EvidenceResponse {
+	return &entity.EvidenceResponse{
+		ID:          evidence.ID,
+		DisputeID:   evidence.DisputeID,
+		FileName:    evidence.FileName,
+		ContentType: evidence.ContentType,
+		Size:        evidence.Size,
+		Description: evidence.Description,
+		CreatedAt:   helper.ConvertToJakartaTime(evidence.CreatedAt),
+	}
+}
@@ -0,0 +1,240 @@
+package usecase_dispute
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/dispute/entity"
+	usecase_ledger "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/ledger"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type trxStub struct{}
+
+func (trxStub) Commit() error   { return nil }
+func (trxStub) Rollback() error { return nil }
+
+type disputeRepoStub struct {
+	mysql.IDisputeRepository
+	disputes map[uint64]*mEntity.DisputeEntity
+	// answeredBeforeLock has the merchant answer between the overdue lookup and the
+	// lock, like a concurrent response would
+	answeredBeforeLock map[uint64]bool
+}
+
+func (r *disputeRepoStub) Begin() (mysql.TrxObj, error) {
+	return trxStub{}, nil
+}
+
+func (r *disputeRepoStub) FindByID(ctx context.Context, id uint64) (*mEntity.DisputeEntity, error) {
+	dispute, ok := r.disputes[id]
+	if !ok {
+		return nil, apperr.ErrRecordNotFound()
+	}
+	found := *dispute
+	return &found, nil
+}
+
+func (r *disputeRepoStub) LockByID(ctx context.Context, dbTrx mysql.TrxObj, id uint64) (*mEntity.DisputeEntity, error) {
+	if r.answeredBeforeLock[id] {
+		r.disputes[id].Status = mEntity.DisputeStatusMerchantResponse
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *disputeRepoStub) FindEvidenceByDisputeID(ctx context.Context, disputeID uint64) ([]mEntity.DisputeEvidenceEntity, error) {
+	return nil, nil
+}
+
+func (r *disputeRepoStub) FindOverdue(ctx context.Context, now time.Time, limit int) ([]mEntity.DisputeEntity, error) {
+	disputes := []mEntity.DisputeEntity{}
+	for id := uint64(1); id <= uint64(len(r.disputes)); id++ {
+		dispute := r.disputes[id]
+		if dispute.Status == mEntity.DisputeStatusOpen && dispute.ResponseDueAt.Before(now) && len(disputes) < limit {
+			disputes = append(disputes, *dispute)
+		}
+	}
+	return disputes, nil
+}
+
+func (r *disputeRepoStub) Update(ctx context.Context, dbTrx mysql.TrxObj, params *mEntity.DisputeEntity, changes map[string]interface{}) error {
+	dispute := r.disputes[params.ID]
+	for column, value := range changes {
+		switch column {
+		case "status":
+			dispute.Status = value.(string)
+		case "merchant_response":
+			dispute.MerchantResponse = value.(string)
+		case "resolution_note":
+			dispute.ResolutionNote = value.(string)
+		case "refund_transaction_id":
+			refundID := value.(uint64)
+			dispute.RefundTransactionID = &refundID
+		case "responded_at":
+			respondedAt := value.(time.Time)
+			dispute.RespondedAt = &respondedAt
+		case "resolved_at":
+			resolvedAt := value.(time.Time)
+			dispute.ResolvedAt = &resolvedAt
+		}
+	}
+	return nil
+}
+
+type transactionRepoStub struct {
+	mysql.ITransactionRepository
+	transactions map[uint64]*mEntity.TransactionEntity
+	refunds      []mEntity.TransactionEntity
+}
+
+func (r *transactionRepoStub) LockByID(ctx context.Context, dbTrx mysql.TrxObj, id uint64) (*mEntity.TransactionEntity, error) {
+	found := *r.transactions[id]
+	return &found, nil
+}
+
+func (r *transactionRepoStub) Create(ctx context.Context, dbTrx mysql.TrxObj, params *mEntity.TransactionEntity, nonZeroVal bool) error {
+	params.ID = uint64(100 + len(r.refunds))
+	r.refunds = append(r.refunds, *params)
+	return nil
+}
+
+type ledgerUseCaseStub struct {
+	usecase_ledger.ILedgerUseCase
+	posted []string
+}
+
+func (u *ledgerUseCaseStub) PostTransaction(ctx context.Context, dbTrx mysql.TrxObj, transaction *mEntity.TransactionEntity) error {
+	u.posted = append(u.posted, transaction.RefID)
+	return nil
+}
+
+type logStub struct {
+	usecase_log.ILogUseCase
+}
+
+func (logStub) Error(process string, funcName string, err error, logFields map[string]string) {}
+
+type DisputeUseCaseTestSuite struct {
+	suite.Suite
+
+	repo            *disputeRepoStub
+	transactionRepo *transactionRepoStub
+	ledgerUseCase   *ledgerUseCaseStub
+	usecase         *DisputeUseCase
+}
+
+func (s *DisputeUseCaseTestSuite) SetupTest() {
+	s.repo = &disputeRepoStub{disputes: map[uint64]*mEntity.DisputeEntity{
+		1: {ID: 1, TransactionID: 10, MerchantID: 1, Amount: 4000, Status: mEntity.DisputeStatusOpen, ResponseDueAt: time.Now().Add(time.Hour)},
+	}}
+	s.transactionRepo = &transactionRepoStub{transactions: map[uint64]*mEntity.TransactionEntity{
+		10: {ID: 10, RefID: "REF10", BillingID: "BILL10", MerchantID: 1, TotalAmount: 10000, Type: "payment", Status: mEntity.TransactionStatusSettled},
+	}}
+	s.ledgerUseCase = &ledgerUseCaseStub{}
+	s.usecase = NewDisputeUseCase(logStub{}, s.repo, s.transactionRepo, nil, s.ledgerUseCase, &config.DisputeOption{ResponseDays: 7})
+}
+
+func TestDisputeUseCase(t *testing.T) {
+	suite.Run(t, new(DisputeUseCaseTestSuite))
+}
+
+func (s *DisputeUseCaseTestSuite) resolve(outcome string) error {
+	_, err := s.usecase.ResolveDispute(context.Background(), &entity.DisputeResolveRequest{
+		ID:      1,
+		Outcome: outcome,
+		Note:    "Scheme decision",
+	})
+	return err
+}
+
+func (s *DisputeUseCaseTestSuite) TestRespondOnlyWhileOpen() {
+	dispute, err := s.usecase.RespondDispute(context.Background(), &entity.DisputeResponseRequest{ID: 1, Response: "Goods were delivered"})
+	s.Require().NoError(err)
+	s.Equal(mEntity.DisputeStatusMerchantResponse, dispute.Status)
+	s.Equal("Goods were delivered", dispute.MerchantResponse)
+	s.NotEmpty(dispute.RespondedAt)
+
+	_, err = s.usecase.RespondDispute(context.Background(), &entity.DisputeResponseRequest{ID: 1, Response: "Again"})
+	s.Require().Error(err)
+	s.Equal(http.StatusConflict, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.Equal("Goods were delivered", s.repo.disputes[1].MerchantResponse)
+}
+
+func (s *DisputeUseCaseTestSuite) TestRespondAfterDeadline() {
+	s.repo.disputes[1].ResponseDueAt = time.Now().Add(-time.Minute)
+
+	_, err := s.usecase.RespondDispute(context.Background(), &entity.DisputeResponseRequest{ID: 1, Response: "Too late"})
+	s.Require().Error(err)
+	s.Equal(http.StatusConflict, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.Equal(mEntity.DisputeStatusOpen, s.repo.disputes[1].Status)
+}
+
+func (s *DisputeUseCaseTestSuite) TestWonDisputeIsNotRefunded() {
+	s.repo.disputes[1].Status = mEntity.DisputeStatusMerchantResponse
+
+	s.Require().NoError(s.resolve(mEntity.DisputeStatusWon))
+	s.Equal(mEntity.DisputeStatusWon, s.repo.disputes[1].Status)
+	s.Nil(s.repo.disputes[1].RefundTransactionID)
+	s.Empty(s.transactionRepo.refunds)
+	s.Empty(s.ledgerUseCase.posted)
+
+	// A resolved dispute cannot be resolved again
+	err := s.resolve(mEntity.DisputeStatusLost)
+	s.Require().Error(err)
+	s.Equal(http.StatusConflict, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.Equal(mEntity.DisputeStatusWon, s.repo.disputes[1].Status)
+	s.Empty(s.transactionRepo.refunds)
+}
+
+func (s *DisputeUseCaseTestSuite) TestLostDisputeRefundsDisputedAmount() {
+	s.Require().NoError(s.resolve(mEntity.DisputeStatusLost))
+
+	// The refund covers the disputed amount, not the whole payment
+	s.Require().Len(s.transactionRepo.refunds, 1)
+	refund := s.transactionRepo.refunds[0]
+	s.Equal("CHB0000000001", refund.RefID)
+	s.Equal("refund", refund.Type)
+	s.Equal(mEntity.TransactionStatusCompleted, refund.Status)
+	s.Equal("BILL10", refund.BillingID)
+	s.Equal(uint64(1), refund.MerchantID)
+	s.Equal(4000.0, refund.TotalAmount)
+	s.Equal([]string{"CHB0000000001"}, s.ledgerUseCase.posted)
+
+	dispute := s.repo.disputes[1]
+	s.Equal(mEntity.DisputeStatusLost, dispute.Status)
+	s.Equal("Scheme decision", dispute.ResolutionNote)
+	s.Require().NotNil(dispute.RefundTransactionID)
+	s.Equal(refund.ID, *dispute.RefundTransactionID)
+	s.NotNil(dispute.ResolvedAt)
+}
+
+func (s *DisputeUseCaseTestSuite) TestExpireDisputes() {
+	now := time.Now()
+	s.repo.disputes = map[uint64]*mEntity.DisputeEntity{
+		1: {ID: 1, TransactionID: 10, Amount: 4000, Status: mEntity.DisputeStatusOpen, ResponseDueAt: now.Add(-time.Hour)},
+		2: {ID: 2, TransactionID: 10, Amount: 5000, Status: mEntity.DisputeStatusOpen, ResponseDueAt: now.Add(time.Hour)},
+		3: {ID: 3, TransactionID: 10, Amount: 6000, Status: mEntity.DisputeStatusMerchantResponse, ResponseDueAt: now.Add(-time.Hour)},
+		4: {ID: 4, TransactionID: 10, Amount: 7000, Status: mEntity.DisputeStatusOpen, ResponseDueAt: now.Add(-time.Hour)},
+	}
+	s.repo.answeredBeforeLock = map[uint64]bool{4: true}
+
+	expired, err := s.usecase.ExpireDisputes(context.Background(), now, 10)
+	s.Require().NoError(err)
+
+	// Only the open dispute past its deadline is lost, the one answered while the job
+	// ran is left for the backoffice
+	s.Equal(1, expired)
+	s.Equal(mEntity.DisputeStatusLost, s.repo.disputes[1].Status)
+	s.Equal(mEntity.DisputeStatusOpen, s.repo.disputes[2].Status)
+	s.Equal(mEntity.DisputeStatusMerchantResponse, s.repo.disputes[3].Status)
+	s.Equal(mEntity.DisputeStatusMerchantResponse, s.repo.disputes[4].Status)
+	s.Equal([]string{"CHB0000000001"}, s.ledgerUseCase.posted)
+}
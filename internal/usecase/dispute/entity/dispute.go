@@ -0,0 +1,68 @@
+package entity
+
+type DisputeRequest struct {
+	RefID      string  `json:"reference_id" validate:"required"`
+	ReasonCode string  `json:"reason_code" validate:"required,max=20"`
+	Reason     string  `json:"reason" validate:"required,max=255"`
+	Amount     float64 `json:"amount" validate:"omitempty,gt=0"`
+}
+
+type DisputeResponseRequest struct {
+	ID       uint64 `json:"-"`
+	Response string `json:"response" validate:"required,max=2000"`
+}
+
+type DisputeResolveRequest struct {
+	ID      uint64 `json:"-"`
+	Outcome string `json:"outcome" validate:"required,oneof=won lost"`
+	Note    string `json:"note" validate:"omitempty,max=255"`
+}
+
+type DisputeListRequest struct {
+	MerchantID uint64 `query:"-"`
+	Status     string `query:"status" validate:"omitempty,oneof=open merchant_response won lost"`
+	Page       int    `query:"page" validate:"omitempty,min=1"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type EvidenceRequest struct {
+	DisputeID   uint64
+	FileName    string `validate:"required,max=255"`
+	ContentType string `validate:"required"`
+	Size        int64  `validate:"gt=0"`
+	Description string `validate:"omitempty,max=255"`
+}
+
+type DisputeResponse struct {
+	ID                  uint64              `json:"id"`
+	TransactionID       uint64              `json:"transaction_id"`
+	MerchantID          uint64              `json:"merchant_id"`
+	ReasonCode          string              `json:"reason_code"`
+	Reason              string              `json:"reason"`
+	Amount              float64             `json:"amount"`
+	Status              string              `json:"status"`
+	MerchantResponse    string              `json:"merchant_response,omitempty"`
+	ResolutionNote      string              `json:"resolution_note,omitempty"`
+	RefundTransactionID *uint64             `json:"refund_transaction_id,omitempty"`
+	ResponseDueAt       string              `json:"response_due_at"`
+	RespondedAt         string              `json:"responded_at,omitempty"`
+	ResolvedAt          string              `json:"resolved_at,omitempty"`
+	CreatedAt           string              `json:"created_at"`
+	Evidences           []*EvidenceResponse `json:"evidences,omitempty"`
+}
+
+type EvidenceResponse struct {
+	ID          uint64 `json:"id"`
+	DisputeID   uint64 `json:"dispute_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// EvidenceFile is an evidence file on disk
+type EvidenceFile struct {
+	Path     string
+	Filename string
+}
@@ -0,0 +1,36 @@
+package entity
+
+type ExportRequest struct {
+	MerchantID uint64 `json:"-"`
+	Format     string `json:"format" validate:"required,oneof=csv xlsx"`
+	DateFrom   string `json:"date_from" validate:"required,datetime=2006-01-02"`
+	DateTo     string `json:"date_to" validate:"required,datetime=2006-01-02"`
+	Status     string `json:"status" validate:"omitempty,oneof=pending completed settled failed voided"`
+}
+
+type ExportResponse struct {
+	ID                uint64 `json:"id"`
+	MerchantID        uint64 `json:"merchant_id"`
+	Format            string `json:"format"`
+	DateFrom          string `json:"date_from"`
+	DateTo            string `json:"date_to"`
+	TransactionStatus string `json:"transaction_status,omitempty"`
+	Status            string `json:"status"`
+	RowCount          int64  `json:"row_count"`
+	ErrorMessage      string `json:"error_message,omitempty"`
+	DownloadURL       string `json:"download_url,omitempty"`
+	DownloadExpiresAt string `json:"download_expires_at,omitempty"`
+	CreatedAt         string `json:"created_at"`
+	CompletedAt       string `json:"completed_at,omitempty"`
+}
+
+// ExportFile is a finished export resolved from a download link
+type ExportFile struct {
+	Path     string
+	Filename string
+}
+
+type ExportDownloadRequest struct {
+	Expires   int64  `query:"expires"`
+	Signature string `query:"signature"`
+}
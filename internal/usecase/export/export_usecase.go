@@ -0,0 +1,330 @@
+package usecase_export
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/queue"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/export/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	errWrap "github.com/pkg/errors"
+)
+
+// exportDirectory is where finished exports are kept, relative to config.StorageDirectory
+const exportDirectory = "exports"
+
+type ExportUseCase struct {
+	logUseCase      usecase_log.ILogUseCase
+	queue           queue.Queue
+	exportJobRepo   mysql.IExportJobRepository
+	transactionRepo mysql.ITransactionRepository
+	merchantRepo    mysql.IMerchantRepository
+	option          *config.ExportOption
+}
+
+func NewExportUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	queue queue.Queue,
+	exportJobRepo mysql.IExportJobRepository,
+	transactionRepo mysql.ITransactionRepository,
+	merchantRepo mysql.IMerchantRepository,
+	option *config.ExportOption,
+) *ExportUseCase {
+	return &ExportUseCase{
+		logUseCase:      logUseCase,
+		queue:           queue,
+		exportJobRepo:   exportJobRepo,
+		transactionRepo: transactionRepo,
+		merchantRepo:    merchantRepo,
+		option:          option,
+	}
+}
+
+type IExportUseCase interface {
+	CreateExport(ctx context.Context, req *entity.ExportRequest) (*entity.ExportResponse, error)
+	GetExport(ctx context.Context, merchantID uint64, exportID uint64) (*entity.ExportResponse, error)
+	GetDownloadFile(ctx context.Context, exportID uint64, expires int64, signature string) (*entity.ExportFile, error)
+	ProcessExport(ctx context.Context, exportID uint64) error
+}
+
+// CreateExport stores a queued export job and hands it to the worker, the
+// response only carries the job status
+func (u *ExportUseCase) CreateExport(ctx context.Context, req *entity.ExportRequest) (*entity.ExportResponse, error) {
+	funcName := "ExportUseCase.CreateExport"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(req.MerchantID),
+		"payload":    helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	dateFrom, _ := helper.ParseDate(req.DateFrom)
+	dateTo, _ := helper.ParseDate(req.DateTo)
+	if dateTo.Before(dateFrom) {
+		return nil, apperr.CustomError("date_to must not be before date_from", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+	// Both ends are inclusive, a single day export spans one day
+	if days := int(dateTo.Sub(dateFrom).Hours()/24) + 1; days > u.option.MaxRangeDays {
+		return nil, apperr.CustomError(
+			fmt.Sprintf("Export range must not exceed %d days", u.option.MaxRangeDays),
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, req.MerchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	job := &mEntity.ExportJobEntity{
+		MerchantID:        req.MerchantID,
+		Format:            req.Format,
+		DateFrom:          dateFrom,
+		DateTo:            dateTo,
+		TransactionStatus: req.Status,
+		Status:            mEntity.ExportStatusQueued,
+		CreatedAt:         time.Now(),
+	}
+	if err := u.exportJobRepo.Create(ctx, nil, job, true); err != nil {
+		u.logUseCase.Error("exportJobRepo.Create", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	payload, _ := helper.Serialize(map[string]interface{}{"export_id": job.ID})
+	if err := u.queue.Publish(queue.ProcessTransactionExport, payload, 1); err != nil {
+		u.logUseCase.Error("queue.Publish", funcName, err, captureFieldError)
+		u.failJob(ctx, job, err)
+		return nil, err
+	}
+
+	return u.toExportResponse(job), nil
+}
+
+func (u *ExportUseCase) GetExport(ctx context.Context, merchantID uint64, exportID uint64) (*entity.ExportResponse, error) {
+	funcName := "ExportUseCase.GetExport"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+		"exportID":   helper.ToString(exportID),
+	}
+
+	job, err := u.exportJobRepo.FindByID(ctx, exportID)
+	if err != nil {
+		u.logUseCase.Error("exportJobRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	// Exports of other merchants are reported as missing rather than forbidden
+	if job.MerchantID != merchantID {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	return u.toExportResponse(job), nil
+}
+
+// GetDownloadFile resolves a signed download link to the export file on disk
+func (u *ExportUseCase) GetDownloadFile(ctx context.Context, exportID uint64, expires int64, signature string) (*entity.ExportFile, error) {
+	funcName := "ExportUseCase.GetDownloadFile"
+	captureFieldError := generalEntity.CaptureFields{
+		"exportID": helper.ToString(exportID),
+		"expires":  helper.ToString(expires),
+	}
+
+	if !u.verifyDownload(exportID, expires, signature, time.Now()) {
+		return nil, apperr.ErrInvalidLink()
+	}
+
+	job, err := u.exportJobRepo.FindByID(ctx, exportID)
+	if err != nil {
+		u.logUseCase.Error("exportJobRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if job.Status != mEntity.ExportStatusCompleted {
+		return nil, apperr.ErrNotReady()
+	}
+
+	return &entity.ExportFile{
+		Path:     job.FilePath,
+		Filename: filepath.Base(job.FilePath),
+	}, nil
+}
+
+// ProcessExport writes the export file for a job, it is run by the worker. Jobs
+// already completed are skipped so a redelivered message does no work twice.
+func (u *ExportUseCase) ProcessExport(ctx context.Context, exportID uint64) error {
+	funcName := "ExportUseCase.ProcessExport"
+	captureFieldError := generalEntity.CaptureFields{
+		"exportID": helper.ToString(exportID),
+	}
+
+	job, err := u.exportJobRepo.FindByID(ctx, exportID)
+	if err != nil {
+		u.logUseCase.Error("exportJobRepo.FindByID", funcName, err, captureFieldError)
+		return err
+	}
+	if job.Status == mEntity.ExportStatusCompleted {
+		return nil
+	}
+
+	startedAt := time.Now()
+	if err := u.exportJobRepo.Update(ctx, nil, job, map[string]interface{}{
+		"status":        mEntity.ExportStatusProcessing,
+		"started_at":    startedAt,
+		"error_message": "",
+	}); err != nil {
+		u.logUseCase.Error("exportJobRepo.Update", funcName, err, captureFieldError)
+		return err
+	}
+
+	filePath, rowCount, err := u.writeExportFile(ctx, job)
+	if err != nil {
+		u.logUseCase.Error("ExportUseCase.writeExportFile", funcName, err, captureFieldError)
+		u.failJob(ctx, job, err)
+		return err
+	}
+
+	if err := u.exportJobRepo.Update(ctx, nil, job, map[string]interface{}{
+		"status":       mEntity.ExportStatusCompleted,
+		"file_path":    filePath,
+		"row_count":    rowCount,
+		"completed_at": time.Now(),
+	}); err != nil {
+		u.logUseCase.Error("exportJobRepo.Update", funcName, err, captureFieldError)
+		return err
+	}
+
+	return nil
+}
+
+// writeExportFile streams the job's transactions into a temp file and only moves
+// it to its final name once complete, a download never sees a partial file
+func (u *ExportUseCase) writeExportFile(ctx context.Context, job *mEntity.ExportJobEntity) (string, int64, error) {
+	directory := filepath.Join(config.StorageDirectory, exportDirectory)
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return "", 0, err
+	}
+
+	filePath := filepath.Join(directory, fmt.Sprintf(
+		"transactions_%d_%s_%s_%d.%s",
+		job.MerchantID,
+		job.DateFrom.Format("20060102"),
+		job.DateTo.Format("20060102"),
+		job.ID,
+		job.Format,
+	))
+	tmpPath := filePath + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	writer, err := newExportWriter(job.Format, file)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// The stored date_to is inclusive, filter up to the start of the next day
+	dateTo := job.DateTo.AddDate(0, 0, 1)
+	filter := &mEntity.TransactionFilter{
+		MerchantID: job.MerchantID,
+		Status:     job.TransactionStatus,
+		DateFrom:   &job.DateFrom,
+		DateTo:     &dateTo,
+	}
+
+	var rowCount int64
+	if err := u.transactionRepo.Stream(ctx, filter, func(transaction *mEntity.TransactionEntity) error {
+		rowCount++
+		return writer.Write(transaction)
+	}); err != nil {
+		return "", 0, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := file.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return "", 0, err
+	}
+
+	return filePath, rowCount, nil
+}
+
+func (u *ExportUseCase) failJob(ctx context.Context, job *mEntity.ExportJobEntity, cause error) {
+	if err := u.exportJobRepo.Update(ctx, nil, job, map[string]interface{}{
+		"status":        mEntity.ExportStatusFailed,
+		"error_message": cause.Error(),
+	}); err != nil {
+		u.logUseCase.Error("exportJobRepo.Update", "ExportUseCase.failJob", err, generalEntity.CaptureFields{
+			"exportID": helper.ToString(job.ID),
+		})
+	}
+}
+
+// signDownload signs the export ID together with the link expiry, so neither can
+// be changed without invalidating the link
+func (u *ExportUseCase) signDownload(exportID uint64, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(u.option.DownloadSecret))
+	fmt.Fprintf(mac, "%d:%d", exportID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (u *ExportUseCase) verifyDownload(exportID uint64, expires int64, signature string, now time.Time) bool {
+	if now.Unix() > expires {
+		return false
+	}
+	expected := u.signDownload(exportID, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (u *ExportUseCase) toExportResponse(job *mEntity.ExportJobEntity) *entity.ExportResponse {
+	response := &entity.ExportResponse{
+		ID:                job.ID,
+		MerchantID:        job.MerchantID,
+		Format:            job.Format,
+		DateFrom:          job.DateFrom.Format("2006-01-02"),
+		DateTo:            job.DateTo.Format("2006-01-02"),
+		TransactionStatus: job.TransactionStatus,
+		Status:            job.Status,
+		RowCount:          job.RowCount,
+		ErrorMessage:      job.ErrorMessage,
+		CreatedAt:         helper.ConvertToJakartaTime(job.CreatedAt),
+	}
+	if job.CompletedAt != nil {
+		response.CompletedAt = helper.ConvertToJakartaTime(*job.CompletedAt)
+	}
+
+	// A fresh link is issued on every status check, old links simply expire
+	if job.Status == mEntity.ExportStatusCompleted {
+		expiresAt := time.Now().Add(time.Duration(u.option.DownloadTTLMinutes) * time.Minute)
+		response.DownloadURL = fmt.Sprintf(
+			"/api/v1/exports/%d/download?expires=%d&signature=%s",
+			job.ID, expiresAt.Unix(), u.signDownload(job.ID, expiresAt.Unix()),
+		)
+		response.DownloadExpiresAt = helper.ConvertToJakartaTime(expiresAt)
+	}
+
+	return response
+}
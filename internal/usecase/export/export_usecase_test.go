@@ -0,0 +1,112 @@
+package usecase_export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xuri/excelize/v2"
+)
+
+type ExportUsecaseTestSuite struct {
+	suite.Suite
+
+	usecase *ExportUseCase
+}
+
+func (s *ExportUsecaseTestSuite) SetupTest() {
+	s.usecase = NewExportUseCase(nil, nil, nil, nil, nil, &config.ExportOption{
+		DownloadSecret:     "secret",
+		DownloadTTLMinutes: 30,
+		MaxRangeDays:       31,
+	})
+}
+
+func TestExportUsecase(t *testing.T) {
+	suite.Run(t, new(ExportUsecaseTestSuite))
+}
+
+func (s *ExportUsecaseTestSuite) TestVerifyDownload() {
+	now := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	expires := now.Add(time.Minute).Unix()
+	signature := s.usecase.signDownload(10, expires)
+
+	testcases := []struct {
+		name      string
+		exportID  uint64
+		expires   int64
+		signature string
+		now       time.Time
+		want      bool
+	}{
+		{name: "valid link", exportID: 10, expires: expires, signature: signature, now: now, want: true},
+		{name: "expired link", exportID: 10, expires: expires, signature: signature, now: now.Add(2 * time.Minute)},
+		{name: "other export", exportID: 11, expires: expires, signature: signature, now: now},
+		{name: "extended expiry", exportID: 10, expires: expires + 3600, signature: signature, now: now},
+		{name: "empty signature", exportID: 10, expires: expires, now: now},
+	}
+
+	for _, tt := range testcases {
+		s.T().Run(tt.name, func(t *testing.T) {
+			s.Equal(tt.want, s.usecase.verifyDownload(tt.exportID, tt.expires, tt.signature, tt.now))
+		})
+	}
+}
+
+func (s *ExportUsecaseTestSuite) TestCSVWriter() {
+	var buf bytes.Buffer
+	writer, err := newExportWriter(mEntity.ExportFormatCSV, &buf)
+	s.Require().NoError(err)
+
+	s.Require().NoError(writer.Write(&mEntity.TransactionEntity{
+		RefID:           "REF-1",
+		BillingID:       "BILL-1",
+		MerchantID:      7,
+		Type:            "payment",
+		PaymentMethod:   "ewallet",
+		Currency:        "IDR",
+		Amount:          10000,
+		TotalAmount:     10000,
+		MDRPercent:      0.7,
+		MDRAmount:       70,
+		TransactionDate: time.Date(2025, 7, 1, 3, 0, 0, 0, time.UTC),
+		Status:          "completed",
+	}))
+	s.Require().NoError(writer.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	s.Require().Len(lines, 2)
+	s.Equal(strings.Join(exportHeader, ","), lines[0])
+	s.True(strings.HasPrefix(lines[1], "REF-1,BILL-1,7,payment,ewallet,IDR,10000.00,0.00,10000.00,0.70,70.00,"))
+	s.Contains(lines[1], "2025-07-01 10:00:00")
+}
+
+func (s *ExportUsecaseTestSuite) TestXLSXWriter() {
+	var buf bytes.Buffer
+	writer, err := newExportWriter(mEntity.ExportFormatXLSX, &buf)
+	s.Require().NoError(err)
+
+	s.Require().NoError(writer.Write(&mEntity.TransactionEntity{RefID: "REF-1", Amount: 10000}))
+	s.Require().NoError(writer.Close())
+
+	file, err := excelize.OpenReader(&buf)
+	s.Require().NoError(err)
+	defer file.Close()
+
+	rows, err := file.GetRows(xlsxSheet)
+	s.Require().NoError(err)
+	s.Require().Len(rows, 2)
+	s.Equal(exportHeader, rows[0])
+	s.Equal("REF-1", rows[1][0])
+	s.Equal("10000", rows[1][6])
+}
+
+func (s *ExportUsecaseTestSuite) TestUnsupportedFormat() {
+	_, err := newExportWriter("pdf", &bytes.Buffer{})
+	s.Error(err)
+}
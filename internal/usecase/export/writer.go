@@ -0,0 +1,145 @@
+package usecase_export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/xuri/excelize/v2"
+)
+
+var exportHeader = []string{
+	"reference_id", "billing_id", "merchant_id", "type", "payment_method", "currency",
+	"amount", "fee_amount", "total_amount", "mdr_percent", "mdr_amount",
+	"issuer", "acquirer", "customer_mpan", "transaction_date", "settlement_date", "status",
+}
+
+// exportWriter appends transactions to an export file one row at a time
+type exportWriter interface {
+	Write(transaction *mEntity.TransactionEntity) error
+	Close() error
+}
+
+func newExportWriter(format string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case mEntity.ExportFormatCSV:
+		return newCSVWriter(w)
+	case mEntity.ExportFormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// exportRow keeps amounts as numbers so spreadsheets can sum them, the CSV writer formats them
+func exportRow(transaction *mEntity.TransactionEntity) []interface{} {
+	return []interface{}{
+		transaction.RefID,
+		transaction.BillingID,
+		transaction.MerchantID,
+		transaction.Type,
+		transaction.PaymentMethod,
+		transaction.Currency,
+		transaction.Amount,
+		transaction.FeeAmount,
+		transaction.TotalAmount,
+		transaction.MDRPercent,
+		transaction.MDRAmount,
+		transaction.Issuer,
+		transaction.Acquirer,
+		transaction.CustomerMPAN,
+		helper.ConvertToJakartaTime(transaction.TransactionDate),
+		helper.ConvertToJakartaTime(transaction.SettlementDate),
+		transaction.Status,
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func csvRecord(values []interface{}) []string {
+	record := make([]string, len(values))
+	for i, value := range values {
+		if amount, ok := value.(float64); ok {
+			record[i] = fmt.Sprintf("%.2f", amount)
+			continue
+		}
+		record[i] = helper.ToString(value)
+	}
+	return record
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportHeader); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer}, nil
+}
+
+func (w *csvWriter) Write(transaction *mEntity.TransactionEntity) error {
+	return w.writer.Write(csvRecord(exportRow(transaction)))
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// xlsxWriter uses the excelize stream writer so rows are flushed to a temp file
+// instead of being kept in memory
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+const xlsxSheet = "Transactions"
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &xlsxWriter{out: w, file: file, stream: stream}
+	header := make([]interface{}, len(exportHeader))
+	for i, column := range exportHeader {
+		header[i] = column
+	}
+	if err := writer.writeRow(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *xlsxWriter) Write(transaction *mEntity.TransactionEntity) error {
+	return w.writeRow(exportRow(transaction))
+}
+
+func (w *xlsxWriter) writeRow(values []interface{}) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, values)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FraudSubject is the transaction being scored
+type FraudSubject struct {
+	RefID        string
+	BillingID    string
+	MerchantID   uint64
+	CustomerMPAN string
+	Amount       float64
+	At           time.Time
+}
+
+type FraudRuleUpdateRequest struct {
+	ID       uint64          `json:"-"`
+	Name     string          `json:"name" validate:"required,max=255"`
+	Params   json.RawMessage `json:"params" validate:"required"`
+	Weight   *int            `json:"weight" validate:"required,min=0,max=100"`
+	IsActive *bool           `json:"is_active" validate:"required"`
+}
+
+type FraudRuleResponse struct {
+	ID        uint64          `json:"id"`
+	Code      string          `json:"code"`
+	Name      string          `json:"name"`
+	Type      string          `json:"type"`
+	Params    json.RawMessage `json:"params"`
+	Weight    int             `json:"weight"`
+	IsActive  bool            `json:"is_active"`
+	CreatedAt string          `json:"created_at"`
+	UpdatedAt string          `json:"updated_at"`
+}
+
+type FraudDecisionListRequest struct {
+	Decision     string `query:"decision" validate:"omitempty,oneof=allow review block"`
+	ReviewStatus string `query:"review_status" validate:"omitempty,oneof=pending approved rejected"`
+	MerchantID   uint64 `query:"merchant_id"`
+	Page         int    `query:"page" validate:"omitempty,min=1"`
+	Limit        int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type FraudReviewRequest struct {
+	ID         uint64 `json:"-"`
+	Status     string `json:"status" validate:"required,oneof=approved rejected"`
+	ReviewedBy string `json:"reviewed_by" validate:"required,max=100"`
+	Note       string `json:"note" validate:"max=255"`
+}
+
+type FraudRuleHit struct {
+	Code   string `json:"code"`
+	Weight int    `json:"weight"`
+	Reason string `json:"reason"`
+}
+
+type FraudDecisionResponse struct {
+	ID             uint64         `json:"id"`
+	TransactionID  *uint64        `json:"transaction_id"`
+	RefID          string         `json:"reference_id"`
+	BillingID      string         `json:"billing_id"`
+	MerchantID     uint64         `json:"merchant_id"`
+	CustomerMPAN   string         `json:"customer_mpan"`
+	Amount         float64        `json:"amount"`
+	Score          int            `json:"score"`
+	Decision       string         `json:"decision"`
+	TriggeredRules []FraudRuleHit `json:"triggered_rules"`
+	ReviewStatus   *string        `json:"review_status"`
+	ReviewedBy     *string        `json:"reviewed_by"`
+	ReviewNote     *string        `json:"review_note"`
+	ReviewedAt     *string        `json:"reviewed_at"`
+	CreatedAt      string         `json:"created_at"`
+}
@@ -0,0 +1,372 @@
+package usecase_fraud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	errWrap "github.com/pkg/errors"
+)
+
+// maxFraudScore caps the sum of the matched rule weights
+const maxFraudScore = 100
+
+type FraudUseCase struct {
+	logUseCase   usecase_log.ILogUseCase
+	fraudRepo    mysql.IFraudRepository
+	signals      Signals
+	auditUseCase usecase_audit.IAuditUseCase
+	option       *config.FraudOption
+
+	// rules caches the active rules, reloaded once they are older than RulesRefreshSeconds
+	mu       sync.Mutex
+	rules    []loadedRule
+	loadedAt time.Time
+}
+
+type loadedRule struct {
+	entity mEntity.FraudRuleEntity
+	rule   Rule
+}
+
+func NewFraudUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	fraudRepo mysql.IFraudRepository,
+	merchantRepo mysql.IMerchantRepository,
+	transactionRepo mysql.ITransactionRepository,
+	auditUseCase usecase_audit.IAuditUseCase,
+	option *config.FraudOption,
+) *FraudUseCase {
+	return &FraudUseCase{
+		logUseCase:   logUseCase,
+		fraudRepo:    fraudRepo,
+		signals:      &repoSignals{merchantRepo, transactionRepo},
+		auditUseCase: auditUseCase,
+		option:       option,
+	}
+}
+
+type IFraudUseCase interface {
+	Evaluate(ctx context.Context, subject *entity.FraudSubject) (*mEntity.FraudDecisionEntity, error)
+	RecordDecision(ctx context.Context, dbTrx mysql.TrxObj, decision *mEntity.FraudDecisionEntity) error
+	ListRules(ctx context.Context) ([]*entity.FraudRuleResponse, error)
+	UpdateRule(ctx context.Context, req *entity.FraudRuleUpdateRequest) (*entity.FraudRuleResponse, error)
+	ListDecisions(ctx context.Context, req *entity.FraudDecisionListRequest) ([]*entity.FraudDecisionResponse, *generalEntity.PaginationMeta, error)
+	GetDecision(ctx context.Context, id uint64) (*entity.FraudDecisionResponse, error)
+	ReviewDecision(ctx context.Context, req *entity.FraudReviewRequest) (*entity.FraudDecisionResponse, error)
+}
+
+// Evaluate scores the subject against the active rules. The score is the sum of the
+// weights of the matched rules, capped at 100. The decision is returned unsaved so
+// the caller can store it together with the transaction.
+func (u *FraudUseCase) Evaluate(ctx context.Context, subject *entity.FraudSubject) (*mEntity.FraudDecisionEntity, error) {
+	funcName := "FraudUseCase.Evaluate"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(subject),
+	}
+
+	rules, err := u.activeRules(ctx)
+	if err != nil {
+		u.logUseCase.Error("FraudUseCase.activeRules", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	decision := &mEntity.FraudDecisionEntity{
+		RefID:          subject.RefID,
+		BillingID:      subject.BillingID,
+		MerchantID:     subject.MerchantID,
+		CustomerMPAN:   subject.CustomerMPAN,
+		Amount:         subject.Amount,
+		TriggeredRules: []mEntity.FraudRuleHit{},
+	}
+	for _, r := range rules {
+		reason, err := r.rule.Evaluate(ctx, u.signals, subject)
+		if err != nil {
+			captureFieldError["rule"] = r.entity.Code
+			u.logUseCase.Error("Rule.Evaluate", funcName, err, captureFieldError)
+			return nil, err
+		}
+		if reason == "" {
+			continue
+		}
+		decision.TriggeredRules = append(decision.TriggeredRules, mEntity.FraudRuleHit{
+			Code:   r.entity.Code,
+			Weight: r.entity.Weight,
+			Reason: reason,
+		})
+		decision.Score += r.entity.Weight
+	}
+	decision.Score = min(decision.Score, maxFraudScore)
+
+	switch {
+	case decision.Score >= u.option.BlockScore:
+		decision.Decision = mEntity.FraudDecisionBlock
+	case decision.Score >= u.option.ReviewScore:
+		decision.Decision = mEntity.FraudDecisionReview
+		status := mEntity.FraudReviewPending
+		decision.ReviewStatus = &status
+	default:
+		decision.Decision = mEntity.FraudDecisionAllow
+	}
+
+	return decision, nil
+}
+
+func (u *FraudUseCase) RecordDecision(ctx context.Context, dbTrx mysql.TrxObj, decision *mEntity.FraudDecisionEntity) error {
+	if err := u.fraudRepo.CreateDecision(ctx, dbTrx, decision); err != nil {
+		u.logUseCase.Error("fraudRepo.CreateDecision", "FraudUseCase.RecordDecision", err, generalEntity.CaptureFields{
+			"payload": helper.ToString(decision),
+		})
+		return err
+	}
+	return nil
+}
+
+// activeRules returns the cached active rules, reloading them once the cache is older
+// than the refresh interval. A rule with invalid params is skipped so one bad row does
+// not stop every payment.
+func (u *FraudUseCase) activeRules(ctx context.Context) ([]loadedRule, error) {
+	funcName := "FraudUseCase.activeRules"
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.loadedAt.IsZero() && time.Since(u.loadedAt) < time.Duration(u.option.RulesRefreshSeconds)*time.Second {
+		return u.rules, nil
+	}
+
+	ruleEntities, err := u.fraudRepo.FindRules(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]loadedRule, 0, len(ruleEntities))
+	for _, ruleEntity := range ruleEntities {
+		rule, err := buildRule(ruleEntity.Type, []byte(ruleEntity.Params))
+		if err != nil {
+			u.logUseCase.Error("buildRule", funcName, err, generalEntity.CaptureFields{"rule": ruleEntity.Code})
+			continue
+		}
+		rules = append(rules, loadedRule{entity: ruleEntity, rule: rule})
+	}
+
+	u.rules = rules
+	u.loadedAt = time.Now()
+	return rules, nil
+}
+
+func (u *FraudUseCase) ListRules(ctx context.Context) ([]*entity.FraudRuleResponse, error) {
+	funcName := "FraudUseCase.ListRules"
+
+	rules, err := u.fraudRepo.FindRules(ctx, false)
+	if err != nil {
+		u.logUseCase.Error("fraudRepo.FindRules", funcName, err, generalEntity.CaptureFields{})
+		return nil, err
+	}
+
+	response := make([]*entity.FraudRuleResponse, 0, len(rules))
+	for i := range rules {
+		response = append(response, toFraudRuleResponse(&rules[i]))
+	}
+	return response, nil
+}
+
+// UpdateRule changes the params, weight and active flag of a rule. This instance
+// uses the change right away, other instances once their rule cache refreshes.
+func (u *FraudUseCase) UpdateRule(ctx context.Context, req *entity.FraudRuleUpdateRequest) (*entity.FraudRuleResponse, error) {
+	funcName := "FraudUseCase.UpdateRule"
+	captureFieldError := generalEntity.CaptureFields{
+		"id":      helper.ToString(req.ID),
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	rule, err := u.fraudRepo.FindRuleByID(ctx, req.ID)
+	if err != nil {
+		u.logUseCase.Error("fraudRepo.FindRuleByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	if _, err := buildRule(rule.Type, req.Params); err != nil {
+		return nil, apperr.CustomError(fmt.Sprintf("invalid params: %s", err), generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+
+	before := toFraudRuleResponse(rule)
+	changes := map[string]interface{}{
+		"name":      req.Name,
+		"params":    string(req.Params),
+		"weight":    *req.Weight,
+		"is_active": *req.IsActive,
+	}
+	if err := u.fraudRepo.UpdateRule(ctx, nil, rule, changes); err != nil {
+		u.logUseCase.Error("fraudRepo.UpdateRule", funcName, err, captureFieldError)
+		return nil, err
+	}
+	rule.Name = req.Name
+	rule.Params = string(req.Params)
+	rule.Weight = *req.Weight
+	rule.IsActive = *req.IsActive
+
+	u.mu.Lock()
+	u.loadedAt = time.Time{}
+	u.mu.Unlock()
+
+	result := toFraudRuleResponse(rule)
+	u.auditUseCase.Record(ctx, auditEntity.ActionUpdate, auditEntity.EntityFraudRule, rule.ID, before, result)
+	return result, nil
+}
+
+// ListDecisions pages through stored decisions, oldest first. Listing review
+// decisions with review status pending gives the review queue.
+func (u *FraudUseCase) ListDecisions(ctx context.Context, req *entity.FraudDecisionListRequest) ([]*entity.FraudDecisionResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "FraudUseCase.ListDecisions"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	decisions, total, err := u.fraudRepo.FindDecisions(ctx, &mEntity.FraudDecisionFilter{
+		Decision:     req.Decision,
+		ReviewStatus: req.ReviewStatus,
+		MerchantID:   req.MerchantID,
+		Limit:        limit,
+		Offset:       (page - 1) * limit,
+	})
+	if err != nil {
+		u.logUseCase.Error("fraudRepo.FindDecisions", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	response := make([]*entity.FraudDecisionResponse, 0, len(decisions))
+	for i := range decisions {
+		response = append(response, toFraudDecisionResponse(&decisions[i]))
+	}
+
+	return response, generalEntity.NewPaginationMeta(page, limit, total), nil
+}
+
+func (u *FraudUseCase) GetDecision(ctx context.Context, id uint64) (*entity.FraudDecisionResponse, error) {
+	funcName := "FraudUseCase.GetDecision"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	decision, err := u.fraudRepo.FindDecisionByID(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("fraudRepo.FindDecisionByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toFraudDecisionResponse(decision), nil
+}
+
+// ReviewDecision records the analyst's verdict on a decision in the review queue. It
+// does not reverse the payment, a rejected transaction goes through void or dispute.
+func (u *FraudUseCase) ReviewDecision(ctx context.Context, req *entity.FraudReviewRequest) (*entity.FraudDecisionResponse, error) {
+	funcName := "FraudUseCase.ReviewDecision"
+	captureFieldError := generalEntity.CaptureFields{
+		"id":      helper.ToString(req.ID),
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	var decision *mEntity.FraudDecisionEntity
+	if err := mysql.DBTransaction(u.fraudRepo, func(dbTrx mysql.TrxObj) error {
+		var err error
+		decision, err = u.fraudRepo.LockDecisionByID(ctx, dbTrx, req.ID)
+		if err != nil {
+			u.logUseCase.Error("fraudRepo.LockDecisionByID", funcName, err, captureFieldError)
+			return err
+		}
+		if decision.ReviewStatus == nil || *decision.ReviewStatus != mEntity.FraudReviewPending {
+			return apperr.CustomError("decision is not waiting for review", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+		}
+
+		now := time.Now()
+		changes := map[string]interface{}{
+			"review_status": req.Status,
+			"reviewed_by":   req.ReviewedBy,
+			"review_note":   req.Note,
+			"reviewed_at":   now,
+		}
+		if err := u.fraudRepo.UpdateDecision(ctx, dbTrx, decision, changes); err != nil {
+			u.logUseCase.Error("fraudRepo.UpdateDecision", funcName, err, captureFieldError)
+			return err
+		}
+		decision.ReviewStatus = &req.Status
+		decision.ReviewedBy = &req.ReviewedBy
+		decision.ReviewNote = &req.Note
+		decision.ReviewedAt = &now
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return toFraudDecisionResponse(decision), nil
+}
+
+func toFraudRuleResponse(rule *mEntity.FraudRuleEntity) *entity.FraudRuleResponse {
+	return &entity.FraudRuleResponse{
+		ID:        rule.ID,
+		Code:      rule.Code,
+		Name:      rule.Name,
+		Type:      rule.Type,
+		Params:    json.RawMessage(rule.Params),
+		Weight:    rule.Weight,
+		IsActive:  rule.IsActive,
+		CreatedAt: helper.ConvertToJakartaDate(rule.CreatedAt),
+		UpdatedAt: helper.ConvertToJakartaDate(rule.UpdatedAt),
+	}
+}
+
+func toFraudDecisionResponse(decision *mEntity.FraudDecisionEntity) *entity.FraudDecisionResponse {
+	response := &entity.FraudDecisionResponse{
+		ID:             decision.ID,
+		TransactionID:  decision.TransactionID,
+		RefID:          decision.RefID,
+		BillingID:      decision.BillingID,
+		MerchantID:     decision.MerchantID,
+		CustomerMPAN:   decision.CustomerMPAN,
+		Amount:         decision.Amount,
+		Score:          decision.Score,
+		Decision:       decision.Decision,
+		TriggeredRules: make([]entity.FraudRuleHit, 0, len(decision.TriggeredRules)),
+		ReviewStatus:   decision.ReviewStatus,
+		ReviewedBy:     decision.ReviewedBy,
+		ReviewNote:     decision.ReviewNote,
+		CreatedAt:      helper.ConvertToJakartaTime(decision.CreatedAt),
+	}
+	for _, hit := range decision.TriggeredRules {
+		response.TriggeredRules = append(response.TriggeredRules, entity.FraudRuleHit(hit))
+	}
+	if decision.ReviewedAt != nil {
+		reviewedAt := helper.ConvertToJakartaTime(*decision.ReviewedAt)
+		response.ReviewedAt = &reviewedAt
+	}
+	return response
+}
@@ -0,0 +1,221 @@
+package usecase_fraud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud/entity"
+)
+
+// Signals is the history rules may look at besides the transaction itself
+type Signals interface {
+	Merchant(ctx context.Context, merchantID uint64) (*mEntity.MerchantEntity, error)
+	Summarize(ctx context.Context, merchantID uint64, from time.Time, to time.Time) (*mEntity.TransactionAggregate, error)
+	CountMerchantsByCustomerMPAN(ctx context.Context, customerMPAN string, since time.Time, excludeMerchantID uint64) (int64, error)
+}
+
+// repoSignals reads the signals from the database
+type repoSignals struct {
+	merchantRepo    mysql.IMerchantRepository
+	transactionRepo mysql.ITransactionRepository
+}
+
+func (s *repoSignals) Merchant(ctx context.Context, merchantID uint64) (*mEntity.MerchantEntity, error) {
+	return s.merchantRepo.FindByID(ctx, merchantID)
+}
+
+func (s *repoSignals) Summarize(ctx context.Context, merchantID uint64, from time.Time, to time.Time) (*mEntity.TransactionAggregate, error) {
+	return s.transactionRepo.Summarize(ctx, &mEntity.TransactionSummaryFilter{
+		MerchantID: merchantID,
+		DateFrom:   from,
+		DateTo:     to,
+	})
+}
+
+func (s *repoSignals) CountMerchantsByCustomerMPAN(ctx context.Context, customerMPAN string, since time.Time, excludeMerchantID uint64) (int64, error) {
+	return s.transactionRepo.CountMerchantsByCustomerMPAN(ctx, customerMPAN, since, excludeMerchantID)
+}
+
+// Rule scores one risk pattern. Evaluate returns why the subject matched, or an
+// empty string when it did not.
+type Rule interface {
+	Evaluate(ctx context.Context, signals Signals, subject *entity.FraudSubject) (string, error)
+}
+
+// RuleFactory builds a rule from the JSON params stored with it
+type RuleFactory func(params []byte) (Rule, error)
+
+// ruleTypes holds the rule implementations a fraud_rules row can select, keyed by
+// its type column
+var ruleTypes = map[string]RuleFactory{
+	"new_merchant_volume":  newMerchantVolumeRule,
+	"mpan_merchant_spread": newMPANMerchantSpreadRule,
+	"round_amount":         newRoundAmountRule,
+	"night_spike":          newNightSpikeRule,
+}
+
+// RegisterRuleType makes another rule type available, a type already registered
+// under the same name is replaced
+func RegisterRuleType(ruleType string, factory RuleFactory) {
+	ruleTypes[ruleType] = factory
+}
+
+func buildRule(ruleType string, params []byte) (Rule, error) {
+	factory, ok := ruleTypes[ruleType]
+	if !ok {
+		return nil, fmt.Errorf("unknown fraud rule type %q", ruleType)
+	}
+	return factory(params)
+}
+
+// merchantVolumeRule matches merchants onboarded less than MaxAgeDays ago whose
+// volume today, this transaction included, goes over DailyVolume
+type merchantVolumeRule struct {
+	MaxAgeDays  int     `json:"max_age_days"`
+	DailyVolume float64 `json:"daily_volume"`
+}
+
+func newMerchantVolumeRule(params []byte) (Rule, error) {
+	var r merchantVolumeRule
+	if err := json.Unmarshal(params, &r); err != nil {
+		return nil, err
+	}
+	if r.MaxAgeDays <= 0 || r.DailyVolume <= 0 {
+		return nil, fmt.Errorf("max_age_days and daily_volume must be positive")
+	}
+	return &r, nil
+}
+
+func (r *merchantVolumeRule) Evaluate(ctx context.Context, signals Signals, subject *entity.FraudSubject) (string, error) {
+	merchant, err := signals.Merchant(ctx, subject.MerchantID)
+	if err != nil {
+		return "", err
+	}
+	if subject.At.Sub(merchant.CreatedAt) > time.Duration(r.MaxAgeDays)*24*time.Hour {
+		return "", nil
+	}
+
+	dayStart := time.Date(subject.At.Year(), subject.At.Month(), subject.At.Day(), 0, 0, 0, 0, subject.At.Location())
+	aggregate, err := signals.Summarize(ctx, subject.MerchantID, dayStart, subject.At)
+	if err != nil {
+		return "", err
+	}
+
+	volume := aggregate.GrossVolume + subject.Amount
+	if volume <= r.DailyVolume {
+		return "", nil
+	}
+	return fmt.Sprintf("merchant onboarded within %d days reached %.2f today", r.MaxAgeDays, volume), nil
+}
+
+// mpanMerchantSpreadRule matches customers who paid more than MaxMerchants
+// merchants, this one included, within the last WindowMinutes
+type mpanMerchantSpreadRule struct {
+	WindowMinutes int   `json:"window_minutes"`
+	MaxMerchants  int64 `json:"max_merchants"`
+}
+
+func newMPANMerchantSpreadRule(params []byte) (Rule, error) {
+	var r mpanMerchantSpreadRule
+	if err := json.Unmarshal(params, &r); err != nil {
+		return nil, err
+	}
+	if r.WindowMinutes <= 0 || r.MaxMerchants <= 0 {
+		return nil, fmt.Errorf("window_minutes and max_merchants must be positive")
+	}
+	return &r, nil
+}
+
+func (r *mpanMerchantSpreadRule) Evaluate(ctx context.Context, signals Signals, subject *entity.FraudSubject) (string, error) {
+	if subject.CustomerMPAN == "" {
+		return "", nil
+	}
+
+	since := subject.At.Add(-time.Duration(r.WindowMinutes) * time.Minute)
+	others, err := signals.CountMerchantsByCustomerMPAN(ctx, subject.CustomerMPAN, since, subject.MerchantID)
+	if err != nil {
+		return "", err
+	}
+
+	if others+1 <= r.MaxMerchants {
+		return "", nil
+	}
+	return fmt.Sprintf("customer paid %d merchants within %d minutes", others+1, r.WindowMinutes), nil
+}
+
+// roundAmountRule matches amounts of at least MinAmount that are a multiple of Multiple
+type roundAmountRule struct {
+	Multiple  float64 `json:"multiple"`
+	MinAmount float64 `json:"min_amount"`
+}
+
+func newRoundAmountRule(params []byte) (Rule, error) {
+	var r roundAmountRule
+	if err := json.Unmarshal(params, &r); err != nil {
+		return nil, err
+	}
+	if r.Multiple <= 0 {
+		return nil, fmt.Errorf("multiple must be positive")
+	}
+	return &r, nil
+}
+
+func (r *roundAmountRule) Evaluate(ctx context.Context, signals Signals, subject *entity.FraudSubject) (string, error) {
+	// Compared in cents to avoid float noise
+	amount, multiple := math.Round(subject.Amount*100), math.Round(r.Multiple*100)
+	if subject.Amount < r.MinAmount || math.Mod(amount, multiple) != 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("amount %.2f is a multiple of %.2f", subject.Amount, r.Multiple), nil
+}
+
+// nightSpikeRule matches merchants with more than MaxTransactions transactions, this
+// one included, within WindowMinutes between StartHour and EndHour. The hours may
+// wrap around midnight, e.g. 22 to 5.
+type nightSpikeRule struct {
+	StartHour       int   `json:"start_hour"`
+	EndHour         int   `json:"end_hour"`
+	WindowMinutes   int   `json:"window_minutes"`
+	MaxTransactions int64 `json:"max_transactions"`
+}
+
+func newNightSpikeRule(params []byte) (Rule, error) {
+	var r nightSpikeRule
+	if err := json.Unmarshal(params, &r); err != nil {
+		return nil, err
+	}
+	if r.StartHour < 0 || r.StartHour > 23 || r.EndHour < 0 || r.EndHour > 23 || r.StartHour == r.EndHour {
+		return nil, fmt.Errorf("start_hour and end_hour must be different hours between 0 and 23")
+	}
+	if r.WindowMinutes <= 0 || r.MaxTransactions <= 0 {
+		return nil, fmt.Errorf("window_minutes and max_transactions must be positive")
+	}
+	return &r, nil
+}
+
+func (r *nightSpikeRule) Evaluate(ctx context.Context, signals Signals, subject *entity.FraudSubject) (string, error) {
+	hour := subject.At.Hour()
+	night := hour >= r.StartHour && hour < r.EndHour
+	if r.StartHour > r.EndHour {
+		night = hour >= r.StartHour || hour < r.EndHour
+	}
+	if !night {
+		return "", nil
+	}
+
+	aggregate, err := signals.Summarize(ctx, subject.MerchantID, subject.At.Add(-time.Duration(r.WindowMinutes)*time.Minute), subject.At)
+	if err != nil {
+		return "", err
+	}
+
+	count := aggregate.TransactionCount + 1
+	if count <= r.MaxTransactions {
+		return "", nil
+	}
+	return fmt.Sprintf("%d transactions within %d minutes at night", count, r.WindowMinutes), nil
+}
@@ -0,0 +1,196 @@
+package usecase_fraud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type signalsStub struct {
+	merchantCreatedAt time.Time
+	aggregate         mEntity.TransactionAggregate
+	otherMerchants    int64
+}
+
+func (s *signalsStub) Merchant(ctx context.Context, merchantID uint64) (*mEntity.MerchantEntity, error) {
+	return &mEntity.MerchantEntity{ID: merchantID, CreatedAt: s.merchantCreatedAt}, nil
+}
+
+func (s *signalsStub) Summarize(ctx context.Context, merchantID uint64, from time.Time, to time.Time) (*mEntity.TransactionAggregate, error) {
+	return &s.aggregate, nil
+}
+
+func (s *signalsStub) CountMerchantsByCustomerMPAN(ctx context.Context, customerMPAN string, since time.Time, excludeMerchantID uint64) (int64, error) {
+	return s.otherMerchants, nil
+}
+
+type fraudRepoStub struct {
+	mysql.IFraudRepository
+	rules []mEntity.FraudRuleEntity
+}
+
+func (r *fraudRepoStub) FindRules(ctx context.Context, activeOnly bool) ([]mEntity.FraudRuleEntity, error) {
+	return r.rules, nil
+}
+
+type logStub struct {
+	usecase_log.ILogUseCase
+}
+
+func (logStub) Error(process string, funcName string, err error, logFields map[string]string) {}
+
+type RulesTestSuite struct {
+	suite.Suite
+
+	night time.Time
+}
+
+func (s *RulesTestSuite) SetupTest() {
+	s.night = time.Date(2025, 7, 1, 2, 30, 0, 0, time.UTC)
+}
+
+func TestRules(t *testing.T) {
+	suite.Run(t, new(RulesTestSuite))
+}
+
+func (s *RulesTestSuite) TestRules() {
+	testcases := []struct {
+		name    string
+		kind    string
+		params  string
+		signals signalsStub
+		subject entity.FraudSubject
+		matched bool
+	}{
+		{
+			name:    "new merchant over daily volume",
+			kind:    "new_merchant_volume",
+			params:  `{"max_age_days": 30, "daily_volume": 1000000}`,
+			signals: signalsStub{merchantCreatedAt: s.night.AddDate(0, 0, -3), aggregate: mEntity.TransactionAggregate{GrossVolume: 900000}},
+			subject: entity.FraudSubject{Amount: 100001, At: s.night},
+			matched: true,
+		},
+		{
+			name:    "established merchant over daily volume",
+			kind:    "new_merchant_volume",
+			params:  `{"max_age_days": 30, "daily_volume": 1000000}`,
+			signals: signalsStub{merchantCreatedAt: s.night.AddDate(0, -2, 0), aggregate: mEntity.TransactionAggregate{GrossVolume: 900000}},
+			subject: entity.FraudSubject{Amount: 100001, At: s.night},
+		},
+		{
+			name:    "customer across many merchants",
+			kind:    "mpan_merchant_spread",
+			params:  `{"window_minutes": 60, "max_merchants": 3}`,
+			signals: signalsStub{otherMerchants: 3},
+			subject: entity.FraudSubject{CustomerMPAN: "9360000000000000001", At: s.night},
+			matched: true,
+		},
+		{
+			name:    "customer within merchant spread",
+			kind:    "mpan_merchant_spread",
+			params:  `{"window_minutes": 60, "max_merchants": 3}`,
+			signals: signalsStub{otherMerchants: 2},
+			subject: entity.FraudSubject{CustomerMPAN: "9360000000000000001", At: s.night},
+		},
+		{
+			name:    "round amount",
+			kind:    "round_amount",
+			params:  `{"multiple": 1000000, "min_amount": 5000000}`,
+			subject: entity.FraudSubject{Amount: 7000000, At: s.night},
+			matched: true,
+		},
+		{
+			name:    "round amount below minimum",
+			kind:    "round_amount",
+			params:  `{"multiple": 1000000, "min_amount": 5000000}`,
+			subject: entity.FraudSubject{Amount: 3000000, At: s.night},
+		},
+		{
+			name:    "amount not round",
+			kind:    "round_amount",
+			params:  `{"multiple": 1000000, "min_amount": 5000000}`,
+			subject: entity.FraudSubject{Amount: 7000000.5, At: s.night},
+		},
+		{
+			name:    "spike at night",
+			kind:    "night_spike",
+			params:  `{"start_hour": 22, "end_hour": 5, "window_minutes": 10, "max_transactions": 5}`,
+			signals: signalsStub{aggregate: mEntity.TransactionAggregate{TransactionCount: 5}},
+			subject: entity.FraudSubject{At: s.night},
+			matched: true,
+		},
+		{
+			name:    "spike during the day",
+			kind:    "night_spike",
+			params:  `{"start_hour": 22, "end_hour": 5, "window_minutes": 10, "max_transactions": 5}`,
+			signals: signalsStub{aggregate: mEntity.TransactionAggregate{TransactionCount: 5}},
+			subject: entity.FraudSubject{At: s.night.Add(10 * time.Hour)},
+		},
+	}
+
+	for _, tc := range testcases {
+		s.Run(tc.name, func() {
+			rule, err := buildRule(tc.kind, []byte(tc.params))
+			s.Require().NoError(err)
+
+			reason, err := rule.Evaluate(context.Background(), &tc.signals, &tc.subject)
+			s.NoError(err)
+			s.Equal(tc.matched, reason != "", reason)
+		})
+	}
+}
+
+func (s *RulesTestSuite) TestInvalidRules() {
+	_, err := buildRule("unknown", []byte(`{}`))
+	s.Error(err)
+
+	_, err = buildRule("round_amount", []byte(`{"multiple": 0}`))
+	s.Error(err)
+
+	_, err = buildRule("night_spike", []byte(`{"start_hour": 3, "end_hour": 3, "window_minutes": 10, "max_transactions": 5}`))
+	s.Error(err)
+}
+
+func (s *RulesTestSuite) TestEvaluate() {
+	repo := &fraudRepoStub{rules: []mEntity.FraudRuleEntity{
+		{Code: "ROUND_AMOUNT", Type: "round_amount", Params: `{"multiple": 1000000, "min_amount": 5000000}`, Weight: 30},
+		{Code: "NIGHT_SPIKE", Type: "night_spike", Params: `{"start_hour": 0, "end_hour": 5, "window_minutes": 10, "max_transactions": 5}`, Weight: 60},
+		// Skipped instead of failing every transaction
+		{Code: "BROKEN", Type: "round_amount", Params: `{}`, Weight: 100},
+	}}
+	usecase := NewFraudUseCase(logStub{}, repo, nil, nil, nil, &config.FraudOption{ReviewScore: 50, BlockScore: 80, RulesRefreshSeconds: 60})
+	signals := &signalsStub{}
+	usecase.signals = signals
+
+	testcases := []struct {
+		name     string
+		amount   float64
+		count    int64
+		score    int
+		decision string
+	}{
+		{name: "allow", amount: 12345, count: 0, score: 0, decision: mEntity.FraudDecisionAllow},
+		{name: "review", amount: 12345, count: 9, score: 60, decision: mEntity.FraudDecisionReview},
+		{name: "block", amount: 6000000, count: 9, score: 90, decision: mEntity.FraudDecisionBlock},
+	}
+
+	for _, tc := range testcases {
+		s.Run(tc.name, func() {
+			signals.aggregate.TransactionCount = tc.count
+
+			decision, err := usecase.Evaluate(context.Background(), &entity.FraudSubject{MerchantID: 1, Amount: tc.amount, At: s.night})
+			s.Require().NoError(err)
+			s.Equal(tc.score, decision.Score)
+			s.Equal(tc.decision, decision.Decision)
+			s.Equal(tc.decision == mEntity.FraudDecisionReview, decision.ReviewStatus != nil)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package entity
+
+// ImportRequest describes an uploaded merchant CSV, CreatedBy is the client ID that
+// uploaded it
+type ImportRequest struct {
+	FileName  string `validate:"required,max=255"`
+	Size      int64  `validate:"required,min=1"`
+	CreatedBy string `validate:"required"`
+}
+
+type ImportResponse struct {
+	ID           uint64 `json:"id"`
+	FileName     string `json:"file_name"`
+	Status       string `json:"status"`
+	TotalRows    int64  `json:"total_rows"`
+	SuccessCount int64  `json:"success_count"`
+	FailedCount  int64  `json:"failed_count"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	ReportURL    string `json:"report_url,omitempty"`
+	CreatedBy    string `json:"created_by"`
+	CreatedAt    string `json:"created_at"`
+	CompletedAt  string `json:"completed_at,omitempty"`
+}
+
+// ImportReportFile is the per-row results report of a finished import
+type ImportReportFile struct {
+	Path     string
+	Filename string
+}
@@ -0,0 +1,539 @@
+package usecase_importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/queue"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/importer/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	usecase_merchant "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/merchant"
+	merchantEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/merchant/entity"
+	usecase_reference "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference"
+	referenceEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference/entity"
+	errWrap "github.com/pkg/errors"
+)
+
+// importDirectory is where uploaded files and their reports are kept, relative to
+// config.StorageDirectory
+const importDirectory = "imports"
+
+const (
+	rowStatusCreated = "created"
+	rowStatusFailed  = "failed"
+)
+
+// importColumns are the CSV columns a file may have, named like the JSON fields of
+// a merchant request. requiredColumns must be present in the header.
+var (
+	importColumns = map[string]func(req *merchantEntity.MerchantRequest, value string){
+		"name":           func(req *merchantEntity.MerchantRequest, value string) { req.Name = value },
+		"type":           func(req *merchantEntity.MerchantRequest, value string) { req.Type = value },
+		"phone":          func(req *merchantEntity.MerchantRequest, value string) { req.Phone = value },
+		"email":          func(req *merchantEntity.MerchantRequest, value string) { req.Email = value },
+		"account_number": func(req *merchantEntity.MerchantRequest, value string) { req.AccountNumber = value },
+		"mid":            func(req *merchantEntity.MerchantRequest, value string) { req.MID = value },
+		"nmid":           func(req *merchantEntity.MerchantRequest, value string) { req.NMID = value },
+		"mpan":           func(req *merchantEntity.MerchantRequest, value string) { req.MPAN = value },
+		"mcc":            func(req *merchantEntity.MerchantRequest, value string) { req.MCC = value },
+		"postal_code":    func(req *merchantEntity.MerchantRequest, value string) { req.PostalCode = value },
+		"province":       func(req *merchantEntity.MerchantRequest, value string) { req.Province = value },
+		"district":       func(req *merchantEntity.MerchantRequest, value string) { req.District = value },
+		"subdistrict":    func(req *merchantEntity.MerchantRequest, value string) { req.SubDistrict = value },
+		"city":           func(req *merchantEntity.MerchantRequest, value string) { req.City = value },
+	}
+	requiredColumns = []string{"name", "email", "account_number", "mid", "nmid", "mpan", "mcc"}
+)
+
+type ImportUseCase struct {
+	logUseCase       usecase_log.ILogUseCase
+	queue            queue.Queue
+	importRepo       mysql.IMerchantImportRepository
+	merchantRepo     mysql.IMerchantRepository
+	referenceUseCase usecase_reference.IReferenceUseCase
+	auditUseCase     usecase_audit.IAuditUseCase
+	option           *config.MerchantImportOption
+}
+
+func NewImportUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	queue queue.Queue,
+	importRepo mysql.IMerchantImportRepository,
+	merchantRepo mysql.IMerchantRepository,
+	referenceUseCase usecase_reference.IReferenceUseCase,
+	auditUseCase usecase_audit.IAuditUseCase,
+	option *config.MerchantImportOption,
+) *ImportUseCase {
+	return &ImportUseCase{
+		logUseCase:       logUseCase,
+		queue:            queue,
+		importRepo:       importRepo,
+		merchantRepo:     merchantRepo,
+		referenceUseCase: referenceUseCase,
+		auditUseCase:     auditUseCase,
+		option:           option,
+	}
+}
+
+type IImportUseCase interface {
+	CreateImport(ctx context.Context, req *entity.ImportRequest, file io.Reader) (*entity.ImportResponse, error)
+	GetImport(ctx context.Context, id uint64) (*entity.ImportResponse, error)
+	GetReportFile(ctx context.Context, id uint64) (*entity.ImportReportFile, error)
+	ProcessImport(ctx context.Context, id uint64) error
+}
+
+// importRow is the outcome of one CSV row, Line is the line it starts on in the file
+// so it can be found in the partner's spreadsheet
+type importRow struct {
+	Line       int
+	MID        string
+	Email      string
+	Status     string
+	MerchantID uint64
+	Error      string
+	merchant   *mEntity.MerchantEntity
+	reference  *referenceEntity.MerchantReference
+}
+
+// CreateImport stores the uploaded CSV and hands it to the worker, the response only
+// carries the import status
+func (u *ImportUseCase) CreateImport(ctx context.Context, req *entity.ImportRequest, file io.Reader) (*entity.ImportResponse, error) {
+	funcName := "ImportUseCase.CreateImport"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+	if !strings.EqualFold(filepath.Ext(req.FileName), ".csv") {
+		return nil, apperr.CustomError("Import file must be a CSV file", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+	if req.Size > int64(u.option.MaxFileSizeMB)<<20 {
+		return nil, apperr.CustomError(
+			fmt.Sprintf("Import file must not exceed %d MB", u.option.MaxFileSizeMB),
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	merchantImport := &mEntity.MerchantImportEntity{
+		FileName:  filepath.Base(req.FileName),
+		Status:    mEntity.MerchantImportStatusQueued,
+		CreatedBy: req.CreatedBy,
+	}
+	if err := u.importRepo.Create(ctx, nil, merchantImport); err != nil {
+		u.logUseCase.Error("importRepo.Create", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	filePath, err := u.saveImportFile(merchantImport.ID, file)
+	if err != nil {
+		u.logUseCase.Error("ImportUseCase.saveImportFile", funcName, err, captureFieldError)
+		u.failImport(ctx, merchantImport, err)
+		return nil, err
+	}
+	if err := u.importRepo.Update(ctx, nil, merchantImport, map[string]interface{}{"file_path": filePath}); err != nil {
+		u.logUseCase.Error("importRepo.Update", funcName, err, captureFieldError)
+		return nil, err
+	}
+	merchantImport.FilePath = filePath
+
+	payload, _ := helper.Serialize(map[string]interface{}{"import_id": merchantImport.ID})
+	if err := u.queue.Publish(queue.ProcessMerchantImport, payload, 1); err != nil {
+		u.logUseCase.Error("queue.Publish", funcName, err, captureFieldError)
+		u.failImport(ctx, merchantImport, err)
+		return nil, err
+	}
+
+	return toImportResponse(merchantImport), nil
+}
+
+func (u *ImportUseCase) saveImportFile(importID uint64, file io.Reader) (string, error) {
+	directory := filepath.Join(config.StorageDirectory, importDirectory)
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(directory, fmt.Sprintf("merchants_%d.csv", importID))
+	out, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, file); err != nil {
+		out.Close()
+		os.Remove(filePath)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+func (u *ImportUseCase) GetImport(ctx context.Context, id uint64) (*entity.ImportResponse, error) {
+	funcName := "ImportUseCase.GetImport"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	merchantImport, err := u.importRepo.FindByID(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("importRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toImportResponse(merchantImport), nil
+}
+
+// GetReportFile returns the results report, it only exists once the import completed
+func (u *ImportUseCase) GetReportFile(ctx context.Context, id uint64) (*entity.ImportReportFile, error) {
+	funcName := "ImportUseCase.GetReportFile"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	merchantImport, err := u.importRepo.FindByID(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("importRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if merchantImport.Status != mEntity.MerchantImportStatusCompleted {
+		return nil, apperr.ErrNotReady()
+	}
+
+	return &entity.ImportReportFile{
+		Path:     merchantImport.ReportPath,
+		Filename: filepath.Base(merchantImport.ReportPath),
+	}, nil
+}
+
+// ProcessImport validates and inserts the rows of an uploaded file, it is run by the
+// worker. Imports already completed are skipped. An import interrupted halfway and
+// delivered again reports the rows it had already inserted as duplicates.
+func (u *ImportUseCase) ProcessImport(ctx context.Context, id uint64) error {
+	funcName := "ImportUseCase.ProcessImport"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	merchantImport, err := u.importRepo.FindByID(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("importRepo.FindByID", funcName, err, captureFieldError)
+		return err
+	}
+	if merchantImport.Status == mEntity.MerchantImportStatusCompleted {
+		return nil
+	}
+
+	if err := u.importRepo.Update(ctx, nil, merchantImport, map[string]interface{}{
+		"status":        mEntity.MerchantImportStatusProcessing,
+		"started_at":    time.Now(),
+		"error_message": "",
+	}); err != nil {
+		u.logUseCase.Error("importRepo.Update", funcName, err, captureFieldError)
+		return err
+	}
+
+	// The worker has no request, the merchants are audited as created by whoever
+	// uploaded the file
+	ctx = usecase_audit.WithActor(ctx, auditEntity.Actor{ID: merchantImport.CreatedBy})
+	rows, err := u.importRows(ctx, merchantImport)
+	if err != nil {
+		u.logUseCase.Error("ImportUseCase.importRows", funcName, err, captureFieldError)
+		u.failImport(ctx, merchantImport, err)
+		return err
+	}
+
+	reportPath, err := writeReport(merchantImport, rows)
+	if err != nil {
+		u.logUseCase.Error("ImportUseCase.writeReport", funcName, err, captureFieldError)
+		u.failImport(ctx, merchantImport, err)
+		return err
+	}
+
+	var successCount int64
+	for _, row := range rows {
+		if row.Status == rowStatusCreated {
+			successCount++
+		}
+	}
+	if err := u.importRepo.Update(ctx, nil, merchantImport, map[string]interface{}{
+		"status":        mEntity.MerchantImportStatusCompleted,
+		"report_path":   reportPath,
+		"total_rows":    len(rows),
+		"success_count": successCount,
+		"failed_count":  int64(len(rows)) - successCount,
+		"completed_at":  time.Now(),
+	}); err != nil {
+		u.logUseCase.Error("importRepo.Update", funcName, err, captureFieldError)
+		return err
+	}
+
+	return nil
+}
+
+// importRows reads the file row by row. Rows failing validation are reported right
+// away, valid ones are inserted option.ChunkSize at a time.
+func (u *ImportUseCase) importRows(ctx context.Context, merchantImport *mEntity.MerchantImportEntity) ([]*importRow, error) {
+	file, err := os.Open(merchantImport.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("import file is empty")
+	} else if err != nil {
+		return nil, err
+	}
+	columns, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*importRow, 0)
+	pending := make([]*importRow, 0, u.option.ChunkSize)
+	// Rows of one file mostly share a handful of MCCs and regions, each combination
+	// is only looked up once
+	checked := make(map[referenceEntity.MerchantReference][]generalEntity.ErrorResponse)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, &importRow{Line: parseErr.StartLine, Status: rowStatusFailed, Error: parseErr.Err.Error()})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		row := parseRow(line, columns, record)
+		rows = append(rows, row)
+		if row.Status == rowStatusFailed {
+			continue
+		}
+		if err := u.checkReference(ctx, row, checked); err != nil {
+			return nil, err
+		}
+		if row.Status == rowStatusFailed {
+			continue
+		}
+
+		pending = append(pending, row)
+		if len(pending) == u.option.ChunkSize {
+			u.insertChunk(ctx, pending)
+			pending = pending[:0]
+		}
+	}
+	u.insertChunk(ctx, pending)
+
+	return rows, nil
+}
+
+// parseHeader maps each column position to a merchant request field, the header is
+// matched case insensitively and must name every required column
+func parseHeader(header []string) ([]string, error) {
+	columns := make([]string, len(header))
+	present := make(map[string]bool, len(header))
+	for i, column := range header {
+		// Spreadsheets often save CSV with a byte order mark
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if _, ok := importColumns[column]; !ok {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		columns[i] = column
+		present[column] = true
+	}
+
+	for _, column := range requiredColumns {
+		if !present[column] {
+			return nil, fmt.Errorf("missing column %q", column)
+		}
+	}
+	return columns, nil
+}
+
+// parseRow runs a record through the validate tags of a merchant request,
+// checkReference covers the rest of what CreateMerchant validates
+func parseRow(line int, columns []string, record []string) *importRow {
+	if len(record) != len(columns) {
+		return &importRow{
+			Line:   line,
+			Status: rowStatusFailed,
+			Error:  fmt.Sprintf("expected %d columns, got %d", len(columns), len(record)),
+		}
+	}
+
+	var req merchantEntity.MerchantRequest
+	for i, column := range columns {
+		importColumns[column](&req, strings.TrimSpace(record[i]))
+	}
+
+	row := &importRow{Line: line, MID: req.MID, Email: req.Email}
+	if errs := usecase.ValidateStructProcess(req); len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, validationErr := range errs {
+			messages = append(messages, validationErr.Message)
+		}
+		row.Status = rowStatusFailed
+		row.Error = strings.Join(messages, "; ")
+		return row
+	}
+
+	row.merchant = usecase_merchant.NewMerchantEntity(&req)
+	row.reference = req.Reference()
+	return row
+}
+
+// checkReference fails the row when its MCC or region fields do not match the
+// reference data. A failed lookup fails the whole import rather than the row.
+func (u *ImportUseCase) checkReference(ctx context.Context, row *importRow, checked map[referenceEntity.MerchantReference][]generalEntity.ErrorResponse) error {
+	fieldErrors, ok := checked[*row.reference]
+	if !ok {
+		var err error
+		fieldErrors, err = u.referenceUseCase.ValidateMerchantReference(ctx, row.reference)
+		if err != nil {
+			return err
+		}
+		checked[*row.reference] = fieldErrors
+	}
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldErr := range fieldErrors {
+		messages = append(messages, fieldErr.Message)
+	}
+	row.Status = rowStatusFailed
+	row.Error = strings.Join(messages, "; ")
+	return nil
+}
+
+// insertChunk inserts the rows in one statement. When that fails, for example on a
+// duplicate MID, the rows are inserted one by one so only the offending ones fail.
+func (u *ImportUseCase) insertChunk(ctx context.Context, rows []*importRow) {
+	if len(rows) == 0 {
+		return
+	}
+
+	merchants := make([]*mEntity.MerchantEntity, 0, len(rows))
+	for _, row := range rows {
+		merchants = append(merchants, row.merchant)
+	}
+
+	if err := u.merchantRepo.CreateBatch(ctx, nil, merchants); err == nil {
+		for _, row := range rows {
+			u.markCreated(ctx, row)
+		}
+		return
+	}
+
+	for _, row := range rows {
+		row.merchant.ID = 0
+		if err := u.merchantRepo.Create(ctx, nil, row.merchant, true); err != nil {
+			row.Status = rowStatusFailed
+			row.Error = err.Error()
+			continue
+		}
+		u.markCreated(ctx, row)
+	}
+}
+
+func (u *ImportUseCase) markCreated(ctx context.Context, row *importRow) {
+	row.Status = rowStatusCreated
+	row.MerchantID = row.merchant.ID
+	u.auditUseCase.Record(ctx, auditEntity.ActionImport, auditEntity.EntityMerchant, row.merchant.ID, nil, usecase_merchant.ToMerchantResponse(row.merchant))
+}
+
+// writeReport writes the results of every row in file order to a temp file and only
+// moves it to its final name once complete
+func writeReport(merchantImport *mEntity.MerchantImportEntity, rows []*importRow) (string, error) {
+	reportPath := filepath.Join(config.StorageDirectory, importDirectory, fmt.Sprintf("merchants_%d_report.csv", merchantImport.ID))
+	tmpPath := reportPath + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	_ = writer.Write([]string{"line", "mid", "email", "status", "merchant_id", "error"})
+	for _, row := range rows {
+		merchantID := ""
+		if row.MerchantID != 0 {
+			merchantID = helper.ToString(row.MerchantID)
+		}
+		_ = writer.Write([]string{helper.ToString(row.Line), row.MID, row.Email, row.Status, merchantID, row.Error})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, reportPath); err != nil {
+		return "", err
+	}
+	return reportPath, nil
+}
+
+func (u *ImportUseCase) failImport(ctx context.Context, merchantImport *mEntity.MerchantImportEntity, cause error) {
+	if err := u.importRepo.Update(ctx, nil, merchantImport, map[string]interface{}{
+		"status":        mEntity.MerchantImportStatusFailed,
+		"error_message": cause.Error(),
+	}); err != nil {
+		u.logUseCase.Error("importRepo.Update", "ImportUseCase.failImport", err, generalEntity.CaptureFields{
+			"id": helper.ToString(merchantImport.ID),
+		})
+	}
+}
+
+func toImportResponse(merchantImport *mEntity.MerchantImportEntity) *entity.ImportResponse {
+	response := &entity.ImportResponse{
+		ID:           merchantImport.ID,
+		FileName:     merchantImport.FileName,
+		Status:       merchantImport.Status,
+		TotalRows:    merchantImport.TotalRows,
+		SuccessCount: merchantImport.SuccessCount,
+		FailedCount:  merchantImport.FailedCount,
+		ErrorMessage: merchantImport.ErrorMessage,
+		CreatedBy:    merchantImport.CreatedBy,
+		CreatedAt:    helper.ConvertToJakartaTime(merchantImport.CreatedAt),
+	}
+	if merchantImport.CompletedAt != nil {
+		response.CompletedAt = helper.ConvertToJakartaTime(*merchantImport.CompletedAt)
+	}
+	if merchantImport.Status == mEntity.MerchantImportStatusCompleted {
+		response.ReportURL = fmt.Sprintf("/api/v1/merchants/imports/%d/report", merchantImport.ID)
+	}
+	return response
+}
@@ -0,0 +1,155 @@
+package usecase_importer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	usecase_reference "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference"
+	referenceEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference/entity"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type merchantRepoStub struct {
+	mysql.IMerchantRepository
+	existingMIDs map[string]bool
+	batches      int
+	nextID       uint64
+}
+
+func (r *merchantRepoStub) CreateBatch(ctx context.Context, dbTrx mysql.TrxObj, merchants []*mEntity.MerchantEntity) error {
+	r.batches++
+	for _, merchant := range merchants {
+		if r.existingMIDs[merchant.MID] {
+			return errors.New("Error 1062 (23000): Duplicate entry")
+		}
+	}
+	for _, merchant := range merchants {
+		r.nextID++
+		merchant.ID = r.nextID
+	}
+	return nil
+}
+
+func (r *merchantRepoStub) Create(ctx context.Context, dbTrx mysql.TrxObj, params *mEntity.MerchantEntity, nonZeroVal bool) error {
+	if r.existingMIDs[params.MID] {
+		return errors.New("Error 1062 (23000): Duplicate entry")
+	}
+	r.nextID++
+	params.ID = r.nextID
+	return nil
+}
+
+type referenceUseCaseStub struct {
+	usecase_reference.IReferenceUseCase
+	lookups int
+}
+
+func (u *referenceUseCaseStub) ValidateMerchantReference(ctx context.Context, ref *referenceEntity.MerchantReference) ([]generalEntity.ErrorResponse, error) {
+	u.lookups++
+	if ref.MCC == "0000" {
+		return []generalEntity.ErrorResponse{{FailedField: "MCC", Tag: "mcc", Message: "MCC 0000 is not an ISO 18245 merchant category code"}}, nil
+	}
+	return nil, nil
+}
+
+type auditUseCaseStub struct {
+	usecase_audit.IAuditUseCase
+	entityIDs []uint64
+}
+
+func (u *auditUseCaseStub) Record(ctx context.Context, action string, entityType string, entityID uint64, before interface{}, after interface{}) {
+	u.entityIDs = append(u.entityIDs, entityID)
+}
+
+type ImportUsecaseTestSuite struct {
+	suite.Suite
+
+	merchantRepo     *merchantRepoStub
+	referenceUseCase *referenceUseCaseStub
+	auditUseCase     *auditUseCaseStub
+	usecase          *ImportUseCase
+}
+
+func (s *ImportUsecaseTestSuite) SetupTest() {
+	s.merchantRepo = &merchantRepoStub{existingMIDs: map[string]bool{"M-DUP": true}}
+	s.referenceUseCase = &referenceUseCaseStub{}
+	s.auditUseCase = &auditUseCaseStub{}
+	s.usecase = NewImportUseCase(nil, nil, nil, s.merchantRepo, s.referenceUseCase, s.auditUseCase, &config.MerchantImportOption{
+		MaxFileSizeMB: 1,
+		ChunkSize:     2,
+	})
+}
+
+func TestImportUsecase(t *testing.T) {
+	suite.Run(t, new(ImportUsecaseTestSuite))
+}
+
+func (s *ImportUsecaseTestSuite) writeFile(content string) *mEntity.MerchantImportEntity {
+	filePath := filepath.Join(s.T().TempDir(), "merchants.csv")
+	s.Require().NoError(os.WriteFile(filePath, []byte(content), 0o644))
+	return &mEntity.MerchantImportEntity{ID: 1, FilePath: filePath}
+}
+
+func (s *ImportUsecaseTestSuite) TestImportRows() {
+	merchantImport := s.writeFile("\ufeffName,Email,Account_Number,MID,NMID,MPAN,MCC,City\n" +
+		"Kopi A,a@mail.com,0001,M-A,ID-A,9001,5812,Yogyakarta\n" +
+		"Kopi B,not-an-email,0002,M-B,ID-B,9002,5812,Yogyakarta\n" +
+		"Kopi C,c@mail.com,0003,M-C,ID-C,9003,5812,Yogyakarta\n" +
+		"Kopi D,d@mail.com,0004,M-DUP,ID-D,9004,5812,Yogyakarta\n" +
+		"Kopi E,e@mail.com,0005\n")
+
+	rows, err := s.usecase.importRows(context.Background(), merchantImport)
+
+	s.Require().NoError(err)
+	s.Require().Len(rows, 5)
+
+	s.Equal(2, rows[0].Line)
+	s.Equal(rowStatusCreated, rows[0].Status)
+	s.Equal(rowStatusFailed, rows[1].Status)
+	s.NotEmpty(rows[1].Error)
+	// The duplicate fails its chunk, the chunk is retried row by row
+	s.Equal(rowStatusCreated, rows[2].Status)
+	s.NotZero(rows[2].MerchantID)
+	s.Equal(rowStatusFailed, rows[3].Status)
+	s.Contains(rows[3].Error, "Duplicate entry")
+	s.Equal(rowStatusFailed, rows[4].Status)
+	s.Equal("expected 8 columns, got 3", rows[4].Error)
+	s.Equal(2, s.merchantRepo.batches)
+	// Only the merchants actually created are audited
+	s.Equal([]uint64{rows[0].MerchantID, rows[2].MerchantID}, s.auditUseCase.entityIDs)
+}
+
+func (s *ImportUsecaseTestSuite) TestImportRowsMissingColumn() {
+	merchantImport := s.writeFile("name,email,mid\nKopi A,a@mail.com,M-A\n")
+
+	_, err := s.usecase.importRows(context.Background(), merchantImport)
+
+	s.EqualError(err, `missing column "account_number"`)
+}
+
+func (s *ImportUsecaseTestSuite) TestImportRowsUnknownReference() {
+	merchantImport := s.writeFile("name,email,account_number,mid,nmid,mpan,mcc\n" +
+		"Kopi A,a@mail.com,0001,M-A,ID-A,9001,5812\n" +
+		"Kopi B,b@mail.com,0002,M-B,ID-B,9002,0000\n" +
+		"Kopi C,c@mail.com,0003,M-C,ID-C,9003,5812\n")
+
+	rows, err := s.usecase.importRows(context.Background(), merchantImport)
+
+	s.Require().NoError(err)
+	s.Require().Len(rows, 3)
+	s.Equal(rowStatusCreated, rows[0].Status)
+	s.Equal(rowStatusFailed, rows[1].Status)
+	s.Equal("MCC 0000 is not an ISO 18245 merchant category code", rows[1].Error)
+	s.Equal(rowStatusCreated, rows[2].Status)
+	// Rows sharing an MCC and region are checked once
+	s.Equal(2, s.referenceUseCase.lookups)
+}
@@ -0,0 +1,8 @@
+package entity
+
+type BalanceResponse struct {
+	MerchantID uint64  `json:"merchant_id"`
+	Available  float64 `json:"available"`
+	Pending    float64 `json:"pending"`
+	Total      float64 `json:"total"`
+}
@@ -0,0 +1,287 @@
+package usecase_ledger
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/ledger/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+)
+
+type LedgerUseCase struct {
+	logUseCase   usecase_log.ILogUseCase
+	ledgerRepo   mysql.ILedgerRepository
+	merchantRepo mysql.IMerchantRepository
+}
+
+func NewLedgerUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	ledgerRepo mysql.ILedgerRepository,
+	merchantRepo mysql.IMerchantRepository,
+) *LedgerUseCase {
+	return &LedgerUseCase{
+		logUseCase:   logUseCase,
+		ledgerRepo:   ledgerRepo,
+		merchantRepo: merchantRepo,
+	}
+}
+
+// ILedgerUseCase posts money movements as balanced journal entries. The Post methods
+// take the caller's DB transaction so the ledger is written together with the
+// business change, or not at all.
+type ILedgerUseCase interface {
+	PostTransaction(ctx context.Context, dbTrx mysql.TrxObj, transaction *mEntity.TransactionEntity) error
+	PostSettlement(ctx context.Context, dbTrx mysql.TrxObj, transaction *mEntity.TransactionEntity) error
+	PostPayout(ctx context.Context, dbTrx mysql.TrxObj, merchantID uint64, payoutID uint64, amount float64) error
+	PostPayoutConfirmation(ctx context.Context, dbTrx mysql.TrxObj, merchantID uint64, payoutID uint64, amount float64) error
+	PostPayoutReversal(ctx context.Context, dbTrx mysql.TrxObj, merchantID uint64, payoutID uint64, amount float64) error
+	GetMerchantBalance(ctx context.Context, merchantID uint64) (*entity.BalanceResponse, error)
+}
+
+// ledgerAccounts describes how each account is created on first use
+var ledgerAccounts = map[string]mEntity.LedgerAccountEntity{
+	mEntity.LedgerAccountMerchantPending:    {Type: mEntity.LedgerAccountTypeLiability, NormalBalance: mEntity.LedgerCredit},
+	mEntity.LedgerAccountMerchantAvailable:  {Type: mEntity.LedgerAccountTypeLiability, NormalBalance: mEntity.LedgerCredit},
+	mEntity.LedgerAccountSettlementClearing: {Type: mEntity.LedgerAccountTypeAsset, NormalBalance: mEntity.LedgerDebit},
+	mEntity.LedgerAccountMDRRevenue:         {Type: mEntity.LedgerAccountTypeRevenue, NormalBalance: mEntity.LedgerCredit},
+	mEntity.LedgerAccountPayoutClearing:     {Type: mEntity.LedgerAccountTypeLiability, NormalBalance: mEntity.LedgerCredit},
+}
+
+// ledgerCurrency is the only currency transactions are made in (ISO 4217 numeric for IDR)
+const ledgerCurrency = "360"
+
+// PostTransaction records a completed payment or refund:
+//   - payment: Dr SETTLEMENT_CLEARING / Cr MERCHANT_PENDING for the total amount
+//   - MDR:     Dr MERCHANT_PENDING / Cr MDR_REVENUE for the MDR amount
+//   - refund:  Dr MERCHANT_PENDING / Cr SETTLEMENT_CLEARING for the total amount
+func (u *LedgerUseCase) PostTransaction(ctx context.Context, dbTrx mysql.TrxObj, transaction *mEntity.TransactionEntity) error {
+	funcName := "LedgerUseCase.PostTransaction"
+	captureFieldError := generalEntity.CaptureFields{
+		"transactionID": helper.ToString(transaction.ID),
+	}
+
+	merchantID := transaction.MerchantID
+	var err error
+	switch transaction.Type {
+	case "payment":
+		err = u.post(ctx, dbTrx, &postingPair{
+			EntryType:   mEntity.JournalEntryPayment,
+			Reference:   mEntity.JournalReferenceTransaction,
+			ReferenceID: transaction.ID,
+			MerchantID:  merchantID,
+			Debit:       mEntity.LedgerAccountSettlementClearing,
+			Credit:      mEntity.LedgerAccountMerchantPending,
+			Amount:      transaction.TotalAmount,
+			Description: fmt.Sprintf("Payment %s", transaction.RefID),
+		})
+		if err == nil && transaction.MDRAmount > 0 {
+			err = u.post(ctx, dbTrx, &postingPair{
+				EntryType:   mEntity.JournalEntryMDR,
+				Reference:   mEntity.JournalReferenceTransaction,
+				ReferenceID: transaction.ID,
+				MerchantID:  merchantID,
+				Debit:       mEntity.LedgerAccountMerchantPending,
+				Credit:      mEntity.LedgerAccountMDRRevenue,
+				Amount:      transaction.MDRAmount,
+				Description: fmt.Sprintf("MDR %s", transaction.RefID),
+			})
+		}
+	case "refund":
+		err = u.post(ctx, dbTrx, &postingPair{
+			EntryType:   mEntity.JournalEntryRefund,
+			Reference:   mEntity.JournalReferenceTransaction,
+			ReferenceID: transaction.ID,
+			MerchantID:  merchantID,
+			Debit:       mEntity.LedgerAccountMerchantPending,
+			Credit:      mEntity.LedgerAccountSettlementClearing,
+			Amount:      transaction.TotalAmount,
+			Description: fmt.Sprintf("Refund %s", transaction.RefID),
+		})
+	default:
+		err = fmt.Errorf("unknown transaction type %q", transaction.Type)
+	}
+
+	if err != nil {
+		u.logUseCase.Error("LedgerUseCase.post", funcName, err, captureFieldError)
+		return err
+	}
+	return nil
+}
+
+// PostSettlement moves the merchant's net amount of a transaction from pending to
+// available. Refunds settle the other way round.
+func (u *LedgerUseCase) PostSettlement(ctx context.Context, dbTrx mysql.TrxObj, transaction *mEntity.TransactionEntity) error {
+	funcName := "LedgerUseCase.PostSettlement"
+	captureFieldError := generalEntity.CaptureFields{
+		"transactionID": helper.ToString(transaction.ID),
+	}
+
+	pair := &postingPair{
+		EntryType:   mEntity.JournalEntrySettlement,
+		Reference:   mEntity.JournalReferenceTransaction,
+		ReferenceID: transaction.ID,
+		MerchantID:  transaction.MerchantID,
+		Debit:       mEntity.LedgerAccountMerchantPending,
+		Credit:      mEntity.LedgerAccountMerchantAvailable,
+		Amount:      transaction.TotalAmount - transaction.MDRAmount,
+		Description: fmt.Sprintf("Settlement %s", transaction.RefID),
+	}
+	if transaction.Type == "refund" {
+		pair.Debit, pair.Credit = pair.Credit, pair.Debit
+		pair.Amount = transaction.TotalAmount
+	}
+
+	if err := u.post(ctx, dbTrx, pair); err != nil {
+		u.logUseCase.Error("LedgerUseCase.post", funcName, err, captureFieldError)
+		return err
+	}
+	return nil
+}
+
+// PostPayout moves money paid out to the merchant's bank account from available to payout clearing
+func (u *LedgerUseCase) PostPayout(ctx context.Context, dbTrx mysql.TrxObj, merchantID uint64, payoutID uint64, amount float64) error {
+	return u.postPayout(ctx, dbTrx, "LedgerUseCase.PostPayout", &postingPair{
+		EntryType:   mEntity.JournalEntryPayout,
+		Debit:       mEntity.LedgerAccountMerchantAvailable,
+		Credit:      mEntity.LedgerAccountPayoutClearing,
+		Description: fmt.Sprintf("Payout %d", payoutID),
+	}, merchantID, payoutID, amount)
+}
+
+// PostPayoutConfirmation clears a payout the bank has transferred, the money leaves settlement clearing
+func (u *LedgerUseCase) PostPayoutConfirmation(ctx context.Context, dbTrx mysql.TrxObj, merchantID uint64, payoutID uint64, amount float64) error {
+	return u.postPayout(ctx, dbTrx, "LedgerUseCase.PostPayoutConfirmation", &postingPair{
+		EntryType:   mEntity.JournalEntryPayoutConfirmation,
+		Debit:       mEntity.LedgerAccountPayoutClearing,
+		Credit:      mEntity.LedgerAccountSettlementClearing,
+		Description: fmt.Sprintf("Payout %d confirmed", payoutID),
+	}, merchantID, payoutID, amount)
+}
+
+// PostPayoutReversal gives a rejected payout back to the merchant's available balance
+func (u *LedgerUseCase) PostPayoutReversal(ctx context.Context, dbTrx mysql.TrxObj, merchantID uint64, payoutID uint64, amount float64) error {
+	return u.postPayout(ctx, dbTrx, "LedgerUseCase.PostPayoutReversal", &postingPair{
+		EntryType:   mEntity.JournalEntryPayoutReversal,
+		Debit:       mEntity.LedgerAccountPayoutClearing,
+		Credit:      mEntity.LedgerAccountMerchantAvailable,
+		Description: fmt.Sprintf("Payout %d rejected", payoutID),
+	}, merchantID, payoutID, amount)
+}
+
+func (u *LedgerUseCase) postPayout(ctx context.Context, dbTrx mysql.TrxObj, funcName string, pair *postingPair, merchantID uint64, payoutID uint64, amount float64) error {
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+		"payoutID":   helper.ToString(payoutID),
+	}
+
+	pair.Reference = mEntity.JournalReferencePayout
+	pair.ReferenceID = payoutID
+	pair.MerchantID = merchantID
+	pair.Amount = amount
+	if err := u.post(ctx, dbTrx, pair); err != nil {
+		u.logUseCase.Error("LedgerUseCase.post", funcName, err, captureFieldError)
+		return err
+	}
+	return nil
+}
+
+func (u *LedgerUseCase) GetMerchantBalance(ctx context.Context, merchantID uint64) (*entity.BalanceResponse, error) {
+	funcName := "LedgerUseCase.GetMerchantBalance"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, merchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	balances, err := u.ledgerRepo.GetMerchantBalances(ctx, merchantID)
+	if err != nil {
+		u.logUseCase.Error("ledgerRepo.GetMerchantBalances", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	response := &entity.BalanceResponse{MerchantID: merchantID}
+	for _, balance := range balances {
+		switch balance.Code {
+		case mEntity.LedgerAccountMerchantAvailable:
+			response.Available = roundAmount(balance.Balance)
+		case mEntity.LedgerAccountMerchantPending:
+			response.Pending = roundAmount(balance.Balance)
+		}
+	}
+	response.Total = roundAmount(response.Available + response.Pending)
+
+	return response, nil
+}
+
+// postingPair is one balanced movement: the same amount debited from one account and credited to another
+type postingPair struct {
+	EntryType   string
+	Reference   string
+	ReferenceID uint64
+	MerchantID  uint64
+	Debit       string
+	Credit      string
+	Amount      float64
+	Description string
+}
+
+// post writes a pair once, an entry already recorded for the same reference is left as is
+func (u *LedgerUseCase) post(ctx context.Context, dbTrx mysql.TrxObj, pair *postingPair) error {
+	exists, err := u.ledgerRepo.ExistsJournalEntry(ctx, dbTrx, pair.EntryType, pair.Reference, pair.ReferenceID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	debit, err := u.account(ctx, dbTrx, pair.Debit, pair.MerchantID)
+	if err != nil {
+		return err
+	}
+	credit, err := u.account(ctx, dbTrx, pair.Credit, pair.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	amount := roundAmount(pair.Amount)
+	return u.ledgerRepo.CreateJournalEntry(ctx, dbTrx, &mEntity.JournalEntryEntity{
+		MerchantID:    &pair.MerchantID,
+		EntryType:     pair.EntryType,
+		ReferenceType: pair.Reference,
+		ReferenceID:   pair.ReferenceID,
+		Description:   pair.Description,
+	}, []mEntity.LedgerPostingEntity{
+		{LedgerAccountID: debit.ID, Direction: mEntity.LedgerDebit, Amount: amount},
+		{LedgerAccountID: credit.ID, Direction: mEntity.LedgerCredit, Amount: amount},
+	})
+}
+
+// account resolves a ledger account code, merchant accounts belong to the given merchant
+func (u *LedgerUseCase) account(ctx context.Context, dbTrx mysql.TrxObj, code string, merchantID uint64) (*mEntity.LedgerAccountEntity, error) {
+	template, ok := ledgerAccounts[code]
+	if !ok {
+		return nil, fmt.Errorf("unknown ledger account %q", code)
+	}
+
+	account := template
+	account.Code = code
+	account.Currency = ledgerCurrency
+	if code == mEntity.LedgerAccountMerchantPending || code == mEntity.LedgerAccountMerchantAvailable {
+		account.MerchantID = &merchantID
+	}
+
+	return u.ledgerRepo.FindOrCreateAccount(ctx, dbTrx, &account)
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
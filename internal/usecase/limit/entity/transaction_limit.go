@@ -0,0 +1,24 @@
+package entity
+
+// TransactionLimitRequest replaces every limit of a merchant or a merchant category,
+// a limit left out is no longer enforced
+type TransactionLimitRequest struct {
+	MerchantID       uint64   `json:"-"`
+	MCC              string   `json:"-" validate:"omitempty,numeric,max=5"`
+	MaxSingleAmount  *float64 `json:"max_single_amount" validate:"omitempty,gt=0"`
+	MaxDailyVolume   *float64 `json:"max_daily_volume" validate:"omitempty,gt=0"`
+	MaxMonthlyVolume *float64 `json:"max_monthly_volume" validate:"omitempty,gt=0"`
+	MaxPerMinute     *int     `json:"max_per_minute" validate:"omitempty,min=1"`
+}
+
+type TransactionLimitResponse struct {
+	ID               uint64   `json:"id"`
+	MerchantID       *uint64  `json:"merchant_id,omitempty"`
+	MCC              *string  `json:"mcc,omitempty"`
+	MaxSingleAmount  *float64 `json:"max_single_amount"`
+	MaxDailyVolume   *float64 `json:"max_daily_volume"`
+	MaxMonthlyVolume *float64 `json:"max_monthly_volume"`
+	MaxPerMinute     *int     `json:"max_per_minute"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+}
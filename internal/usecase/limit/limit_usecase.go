@@ -0,0 +1,359 @@
+package usecase_limit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis"
+	rEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/limit/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	errWrap "github.com/pkg/errors"
+)
+
+// limitBreachProcess is the log pipeline process breaches are reported under
+const limitBreachProcess = "TransactionLimitBreach"
+
+type LimitUseCase struct {
+	logUseCase       usecase_log.ILogUseCase
+	limitRepo        mysql.ITransactionLimitRepository
+	limitCounterRepo redis.ILimitCounterRepository
+	merchantRepo     mysql.IMerchantRepository
+	auditUseCase     usecase_audit.IAuditUseCase
+}
+
+func NewLimitUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	limitRepo mysql.ITransactionLimitRepository,
+	limitCounterRepo redis.ILimitCounterRepository,
+	merchantRepo mysql.IMerchantRepository,
+	auditUseCase usecase_audit.IAuditUseCase,
+) *LimitUseCase {
+	return &LimitUseCase{
+		logUseCase:       logUseCase,
+		limitRepo:        limitRepo,
+		limitCounterRepo: limitCounterRepo,
+		merchantRepo:     merchantRepo,
+		auditUseCase:     auditUseCase,
+	}
+}
+
+type ILimitUseCase interface {
+	CheckLimits(ctx context.Context, merchantID uint64, amount float64) error
+	ConsumeLimits(ctx context.Context, merchantID uint64, amount float64, at time.Time) error
+	ReleaseLimits(ctx context.Context, merchantID uint64, amount float64, at time.Time) error
+	GetMerchantLimit(ctx context.Context, merchantID uint64) (*entity.TransactionLimitResponse, error)
+	SetMerchantLimit(ctx context.Context, req *entity.TransactionLimitRequest) (*entity.TransactionLimitResponse, error)
+	DeleteMerchantLimit(ctx context.Context, merchantID uint64) error
+	GetCategoryLimit(ctx context.Context, mcc string) (*entity.TransactionLimitResponse, error)
+	SetCategoryLimit(ctx context.Context, req *entity.TransactionLimitRequest) (*entity.TransactionLimitResponse, error)
+	DeleteCategoryLimit(ctx context.Context, mcc string) error
+}
+
+// CheckLimits tells whether the merchant may receive amount right now without counting
+// it, for requests that do not move money yet such as generating a QR
+func (u *LimitUseCase) CheckLimits(ctx context.Context, merchantID uint64, amount float64) error {
+	return u.enforce(ctx, "LimitUseCase.CheckLimits", merchantID, amount, helper.DatetimeNowJakarta(), false)
+}
+
+// ConsumeLimits checks the limits like CheckLimits and counts amount and one
+// transaction against the merchant's daily, monthly and per minute windows containing at
+func (u *LimitUseCase) ConsumeLimits(ctx context.Context, merchantID uint64, amount float64, at time.Time) error {
+	return u.enforce(ctx, "LimitUseCase.ConsumeLimits", merchantID, amount, at, true)
+}
+
+// ReleaseLimits takes back what ConsumeLimits counted with the same amount and time,
+// for a payment that could not be stored after its limits were consumed
+func (u *LimitUseCase) ReleaseLimits(ctx context.Context, merchantID uint64, amount float64, at time.Time) error {
+	funcName := "LimitUseCase.ReleaseLimits"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+		"amount":     helper.ToString(amount),
+	}
+
+	_, caps, err := u.findCaps(ctx, funcName, merchantID, captureFieldError)
+	if err != nil {
+		return err
+	}
+	if caps == (rEntity.LimitCaps{}) {
+		return nil
+	}
+
+	if err := u.limitCounterRepo.Release(ctx, merchantID, at, toCents(amount)); err != nil {
+		u.logUseCase.Error("limitCounterRepo.Release", funcName, err, captureFieldError)
+		return err
+	}
+	return nil
+}
+
+// IsLimitBreach tells whether err is one of the errors returned for an exceeded limit
+func IsLimitBreach(err error) bool {
+	return errWrap.Is(err, apperr.ErrSingleAmountLimit()) ||
+		errWrap.Is(err, apperr.ErrDailyVolumeLimit()) ||
+		errWrap.Is(err, apperr.ErrMonthlyVolumeLimit()) ||
+		errWrap.Is(err, apperr.ErrVelocityLimit())
+}
+
+// enforce applies the merchant's own limits, or its category's when it has none.
+// Category limits apply to each merchant in the category separately.
+func (u *LimitUseCase) enforce(ctx context.Context, funcName string, merchantID uint64, amount float64, at time.Time, commit bool) error {
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+		"amount":     helper.ToString(amount),
+	}
+
+	limit, caps, err := u.findCaps(ctx, funcName, merchantID, captureFieldError)
+	if err != nil || limit == nil {
+		return err
+	}
+
+	if limit.MaxSingleAmount != nil && toCents(amount) > toCents(*limit.MaxSingleAmount) {
+		return u.breach(funcName, apperr.ErrSingleAmountLimit(), captureFieldError)
+	}
+	if caps == (rEntity.LimitCaps{}) {
+		return nil
+	}
+
+	window, err := u.limitCounterRepo.Apply(ctx, merchantID, at, toCents(amount), caps, commit)
+	if err != nil {
+		u.logUseCase.Error("limitCounterRepo.Apply", funcName, err, captureFieldError)
+		return err
+	}
+
+	switch window {
+	case rEntity.LimitWindowDaily:
+		return u.breach(funcName, apperr.ErrDailyVolumeLimit(), captureFieldError)
+	case rEntity.LimitWindowMonthly:
+		return u.breach(funcName, apperr.ErrMonthlyVolumeLimit(), captureFieldError)
+	case rEntity.LimitWindowMinute:
+		return u.breach(funcName, apperr.ErrVelocityLimit(), captureFieldError)
+	}
+	return nil
+}
+
+// breach reports the rejected request to the log pipeline and returns err
+func (u *LimitUseCase) breach(funcName string, err apperr.CustomErrorResponse, captureFieldError generalEntity.CaptureFields) error {
+	captureFieldError["code"] = err.ErrCode
+	u.logUseCase.Log(generalEntity.LogWarning, "transaction limit exceeded", funcName, err, captureFieldError, limitBreachProcess)
+	return err
+}
+
+// findCaps returns the limit that applies to the merchant and the caps its counters
+// are checked against, the limit is nil when the merchant has none
+func (u *LimitUseCase) findCaps(ctx context.Context, funcName string, merchantID uint64, captureFieldError generalEntity.CaptureFields) (*mEntity.TransactionLimitEntity, rEntity.LimitCaps, error) {
+	caps := rEntity.LimitCaps{}
+
+	merchant, err := u.merchantRepo.FindByID(ctx, merchantID)
+	if err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, caps, err
+	}
+
+	limit, err := u.limitRepo.FindApplicable(ctx, merchant.ID, merchant.MCC)
+	if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		return nil, caps, nil
+	} else if err != nil {
+		u.logUseCase.Error("limitRepo.FindApplicable", funcName, err, captureFieldError)
+		return nil, caps, err
+	}
+	captureFieldError["limitID"] = helper.ToString(limit.ID)
+
+	if limit.MaxDailyVolume != nil {
+		caps.DailyVolume = toCents(*limit.MaxDailyVolume)
+	}
+	if limit.MaxMonthlyVolume != nil {
+		caps.MonthlyVolume = toCents(*limit.MaxMonthlyVolume)
+	}
+	if limit.MaxPerMinute != nil {
+		caps.PerMinute = int64(*limit.MaxPerMinute)
+	}
+	return limit, caps, nil
+}
+
+func (u *LimitUseCase) GetMerchantLimit(ctx context.Context, merchantID uint64) (*entity.TransactionLimitResponse, error) {
+	funcName := "LimitUseCase.GetMerchantLimit"
+	captureFieldError := generalEntity.CaptureFields{"merchantID": helper.ToString(merchantID)}
+
+	limit, err := u.limitRepo.FindByMerchantID(ctx, merchantID)
+	if err != nil {
+		u.logUseCase.Error("limitRepo.FindByMerchantID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toTransactionLimitResponse(limit), nil
+}
+
+// SetMerchantLimit creates or replaces the limits of one merchant, they take
+// precedence over the limits of the merchant's category
+func (u *LimitUseCase) SetMerchantLimit(ctx context.Context, req *entity.TransactionLimitRequest) (*entity.TransactionLimitResponse, error) {
+	funcName := "LimitUseCase.SetMerchantLimit"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(req.MerchantID),
+		"payload":    helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, req.MerchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	limit, err := u.limitRepo.FindByMerchantID(ctx, req.MerchantID)
+	if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		limit = &mEntity.TransactionLimitEntity{MerchantID: &req.MerchantID}
+	} else if err != nil {
+		u.logUseCase.Error("limitRepo.FindByMerchantID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	if err := u.saveLimit(ctx, limit, req); err != nil {
+		u.logUseCase.Error("LimitUseCase.saveLimit", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toTransactionLimitResponse(limit), nil
+}
+
+func (u *LimitUseCase) DeleteMerchantLimit(ctx context.Context, merchantID uint64) error {
+	funcName := "LimitUseCase.DeleteMerchantLimit"
+	captureFieldError := generalEntity.CaptureFields{"merchantID": helper.ToString(merchantID)}
+
+	limit, err := u.limitRepo.FindByMerchantID(ctx, merchantID)
+	if err != nil {
+		u.logUseCase.Error("limitRepo.FindByMerchantID", funcName, err, captureFieldError)
+		return err
+	}
+
+	if err := u.limitRepo.DeleteByID(ctx, nil, limit.ID); err != nil {
+		u.logUseCase.Error("limitRepo.DeleteByID", funcName, err, captureFieldError)
+		return err
+	}
+	u.auditUseCase.Record(ctx, auditEntity.ActionDelete, auditEntity.EntityTransactionLimit, limit.ID, toTransactionLimitResponse(limit), nil)
+	return nil
+}
+
+func (u *LimitUseCase) GetCategoryLimit(ctx context.Context, mcc string) (*entity.TransactionLimitResponse, error) {
+	funcName := "LimitUseCase.GetCategoryLimit"
+	captureFieldError := generalEntity.CaptureFields{"mcc": mcc}
+
+	limit, err := u.limitRepo.FindByMCC(ctx, mcc)
+	if err != nil {
+		u.logUseCase.Error("limitRepo.FindByMCC", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toTransactionLimitResponse(limit), nil
+}
+
+// SetCategoryLimit creates or replaces the limits applied to every merchant of the
+// category that has no limits of its own
+func (u *LimitUseCase) SetCategoryLimit(ctx context.Context, req *entity.TransactionLimitRequest) (*entity.TransactionLimitResponse, error) {
+	funcName := "LimitUseCase.SetCategoryLimit"
+	captureFieldError := generalEntity.CaptureFields{
+		"mcc":     req.MCC,
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	limit, err := u.limitRepo.FindByMCC(ctx, req.MCC)
+	if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		limit = &mEntity.TransactionLimitEntity{MCC: &req.MCC}
+	} else if err != nil {
+		u.logUseCase.Error("limitRepo.FindByMCC", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	if err := u.saveLimit(ctx, limit, req); err != nil {
+		u.logUseCase.Error("LimitUseCase.saveLimit", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toTransactionLimitResponse(limit), nil
+}
+
+func (u *LimitUseCase) DeleteCategoryLimit(ctx context.Context, mcc string) error {
+	funcName := "LimitUseCase.DeleteCategoryLimit"
+	captureFieldError := generalEntity.CaptureFields{"mcc": mcc}
+
+	limit, err := u.limitRepo.FindByMCC(ctx, mcc)
+	if err != nil {
+		u.logUseCase.Error("limitRepo.FindByMCC", funcName, err, captureFieldError)
+		return err
+	}
+
+	if err := u.limitRepo.DeleteByID(ctx, nil, limit.ID); err != nil {
+		u.logUseCase.Error("limitRepo.DeleteByID", funcName, err, captureFieldError)
+		return err
+	}
+	u.auditUseCase.Record(ctx, auditEntity.ActionDelete, auditEntity.EntityTransactionLimit, limit.ID, toTransactionLimitResponse(limit), nil)
+	return nil
+}
+
+// saveLimit stores the limits of req on limit, creating it when it has no ID yet
+func (u *LimitUseCase) saveLimit(ctx context.Context, limit *mEntity.TransactionLimitEntity, req *entity.TransactionLimitRequest) error {
+	var before *entity.TransactionLimitResponse
+	if limit.ID != 0 {
+		before = toTransactionLimitResponse(limit)
+	}
+
+	limit.MaxSingleAmount = req.MaxSingleAmount
+	limit.MaxDailyVolume = req.MaxDailyVolume
+	limit.MaxMonthlyVolume = req.MaxMonthlyVolume
+	limit.MaxPerMinute = req.MaxPerMinute
+
+	if limit.ID == 0 {
+		if err := u.limitRepo.Create(ctx, nil, limit); err != nil {
+			return err
+		}
+		u.auditUseCase.Record(ctx, auditEntity.ActionCreate, auditEntity.EntityTransactionLimit, limit.ID, nil, toTransactionLimitResponse(limit))
+		return nil
+	}
+
+	if err := u.limitRepo.Update(ctx, nil, limit, map[string]interface{}{
+		"max_single_amount":  req.MaxSingleAmount,
+		"max_daily_volume":   req.MaxDailyVolume,
+		"max_monthly_volume": req.MaxMonthlyVolume,
+		"max_per_minute":     req.MaxPerMinute,
+	}); err != nil {
+		return err
+	}
+	limit.UpdatedAt = time.Now()
+	u.auditUseCase.Record(ctx, auditEntity.ActionUpdate, auditEntity.EntityTransactionLimit, limit.ID, before, toTransactionLimitResponse(limit))
+	return nil
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func toTransactionLimitResponse(limit *mEntity.TransactionLimitEntity) *entity.TransactionLimitResponse {
+	return &entity.TransactionLimitResponse{
+		ID:               limit.ID,
+		MerchantID:       limit.MerchantID,
+		MCC:              limit.MCC,
+		MaxSingleAmount:  limit.MaxSingleAmount,
+		MaxDailyVolume:   limit.MaxDailyVolume,
+		MaxMonthlyVolume: limit.MaxMonthlyVolume,
+		MaxPerMinute:     limit.MaxPerMinute,
+		CreatedAt:        helper.ConvertToJakartaDate(limit.CreatedAt),
+		UpdatedAt:        helper.ConvertToJakartaDate(limit.UpdatedAt),
+	}
+}
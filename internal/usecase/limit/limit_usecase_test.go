@@ -0,0 +1,165 @@
+package usecase_limit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis"
+	rEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type logStub struct {
+	usecase_log.ILogUseCase
+	warnings []generalEntity.CaptureFields
+}
+
+func (l *logStub) Log(status generalEntity.LogType, message string, funcName string, err error, logFields map[string]string, processName string) {
+	if status == generalEntity.LogWarning {
+		l.warnings = append(l.warnings, logFields)
+	}
+}
+
+func (l *logStub) Error(process string, funcName string, err error, logFields map[string]string) {}
+
+type merchantRepoStub struct {
+	mysql.IMerchantRepository
+}
+
+func (merchantRepoStub) FindByID(ctx context.Context, id uint64) (*mEntity.MerchantEntity, error) {
+	return &mEntity.MerchantEntity{ID: id, MCC: "5812"}, nil
+}
+
+type limitRepoStub struct {
+	mysql.ITransactionLimitRepository
+	limit *mEntity.TransactionLimitEntity
+}
+
+func (r *limitRepoStub) FindApplicable(ctx context.Context, merchantID uint64, mcc string) (*mEntity.TransactionLimitEntity, error) {
+	if r.limit == nil {
+		return nil, apperr.ErrRecordNotFound()
+	}
+	return r.limit, nil
+}
+
+type counterCall struct {
+	amount int64
+	caps   rEntity.LimitCaps
+	commit bool
+}
+
+type releaseCall struct {
+	at     time.Time
+	amount int64
+}
+
+type limitCounterRepoStub struct {
+	redis.ILimitCounterRepository
+	window   rEntity.LimitWindow
+	calls    []counterCall
+	releases []releaseCall
+}
+
+func (r *limitCounterRepoStub) Release(ctx context.Context, merchantID uint64, at time.Time, amount int64) error {
+	r.releases = append(r.releases, releaseCall{at, amount})
+	return nil
+}
+
+func (r *limitCounterRepoStub) Apply(ctx context.Context, merchantID uint64, at time.Time, amount int64, caps rEntity.LimitCaps, commit bool) (rEntity.LimitWindow, error) {
+	r.calls = append(r.calls, counterCall{amount, caps, commit})
+	return r.window, nil
+}
+
+type LimitUseCaseTestSuite struct {
+	suite.Suite
+
+	log      *logStub
+	limits   *limitRepoStub
+	counters *limitCounterRepoStub
+	usecase  *LimitUseCase
+}
+
+func (s *LimitUseCaseTestSuite) SetupTest() {
+	s.log = &logStub{}
+	s.limits = &limitRepoStub{}
+	s.counters = &limitCounterRepoStub{}
+	s.usecase = NewLimitUseCase(s.log, s.limits, s.counters, merchantRepoStub{}, nil)
+}
+
+func TestLimitUseCase(t *testing.T) {
+	suite.Run(t, new(LimitUseCaseTestSuite))
+}
+
+func (s *LimitUseCaseTestSuite) TestNoLimits() {
+	s.NoError(s.usecase.ConsumeLimits(context.Background(), 1, 500000000, time.Now()))
+	s.Empty(s.counters.calls)
+}
+
+func (s *LimitUseCaseTestSuite) TestSingleAmount() {
+	maxSingle := 1000000.0
+	s.limits.limit = &mEntity.TransactionLimitEntity{ID: 7, MaxSingleAmount: &maxSingle}
+
+	s.NoError(s.usecase.ConsumeLimits(context.Background(), 1, 1000000, time.Now()))
+	s.Equal(apperr.ErrSingleAmountLimit(), s.usecase.ConsumeLimits(context.Background(), 1, 1000000.01, time.Now()))
+
+	// Nothing to count without volume or rate limits
+	s.Empty(s.counters.calls)
+	s.Require().Len(s.log.warnings, 1)
+	s.Equal(generalEntity.LIMIT_SINGLE_CODE, s.log.warnings[0]["code"])
+	s.Equal("7", s.log.warnings[0]["limitID"])
+}
+
+func (s *LimitUseCaseTestSuite) TestCounters() {
+	daily, perMinute := 2500000.5, 10
+	s.limits.limit = &mEntity.TransactionLimitEntity{MaxDailyVolume: &daily, MaxPerMinute: &perMinute}
+
+	s.NoError(s.usecase.CheckLimits(context.Background(), 1, 15000.25))
+	s.NoError(s.usecase.ConsumeLimits(context.Background(), 1, 15000.25, time.Now()))
+	s.Equal([]counterCall{
+		{amount: 1500025, caps: rEntity.LimitCaps{DailyVolume: 250000050, PerMinute: 10}, commit: false},
+		{amount: 1500025, caps: rEntity.LimitCaps{DailyVolume: 250000050, PerMinute: 10}, commit: true},
+	}, s.counters.calls)
+	s.Empty(s.log.warnings)
+}
+
+func (s *LimitUseCaseTestSuite) TestBreaches() {
+	monthly := 100000000.0
+	s.limits.limit = &mEntity.TransactionLimitEntity{MaxMonthlyVolume: &monthly}
+
+	testcases := []struct {
+		window   rEntity.LimitWindow
+		expected apperr.CustomErrorResponse
+	}{
+		{window: rEntity.LimitWindowDaily, expected: apperr.ErrDailyVolumeLimit()},
+		{window: rEntity.LimitWindowMonthly, expected: apperr.ErrMonthlyVolumeLimit()},
+		{window: rEntity.LimitWindowMinute, expected: apperr.ErrVelocityLimit()},
+	}
+
+	for _, tc := range testcases {
+		s.Run(string(tc.window), func() {
+			s.counters.window = tc.window
+			s.Equal(tc.expected, s.usecase.ConsumeLimits(context.Background(), 1, 10000, time.Now()))
+		})
+	}
+	s.Len(s.log.warnings, len(testcases))
+}
+
+func (s *LimitUseCaseTestSuite) TestRelease() {
+	at := time.Date(2026, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	// Nothing was counted without limits, so there is nothing to take back
+	s.NoError(s.usecase.ReleaseLimits(context.Background(), 1, 10000, at))
+	s.Empty(s.counters.releases)
+
+	daily := 2500000.0
+	s.limits.limit = &mEntity.TransactionLimitEntity{MaxDailyVolume: &daily}
+	s.NoError(s.usecase.ReleaseLimits(context.Background(), 1, 10000.5, at))
+	s.Equal([]releaseCall{{at: at, amount: 1000050}}, s.counters.releases)
+}
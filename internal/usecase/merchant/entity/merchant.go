@@ -1,38 +1,93 @@
 package entity
 
+import referenceEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference/entity"
+
+// MerchantRequest is validated the same way whether it comes from the API or from a
+// row of a bulk import
 type MerchantRequest struct {
-	Name          string `json:"name"`
-	Phone         string `json:"phone"`
-	Email         string `json:"email"`
-	AccountNumber string `json:"account_number"`
-	MID           string `json:"mid"`
-	NMID          string `json:"nmid"`
-	MPAN          string `json:"mpan"`
-	MCC           string `json:"mcc"`
-	PostalCode    string `json:"postal_code"`
-	Province      string `json:"province"`
-	District      string `json:"district"`
-	SubDistrict   string `json:"subdistrict"`
-	City          string `json:"city"`
-	Status        string `json:"status"`
+	Name          string `json:"name" validate:"required,max=255"`
+	Type          string `json:"type" validate:"omitempty,oneof=individual corporate"`
+	Phone         string `json:"phone" validate:"omitempty,max=20"`
+	Email         string `json:"email" validate:"required,email,max=255"`
+	AccountNumber string `json:"account_number" validate:"required,max=50"`
+	MID           string `json:"mid" validate:"required,max=50"`
+	NMID          string `json:"nmid" validate:"required,max=50"`
+	MPAN          string `json:"mpan" validate:"required,max=50"`
+	MCC           string `json:"mcc" validate:"required,numeric,len=4"`
+	PostalCode    string `json:"postal_code" validate:"omitempty,numeric,len=5"`
+	Province      string `json:"province" validate:"omitempty,max=100"`
+	District      string `json:"district" validate:"omitempty,max=100"`
+	SubDistrict   string `json:"subdistrict" validate:"omitempty,max=100"`
+	City          string `json:"city" validate:"omitempty,max=100"`
+	// MDRPercent is charged on the payments recorded from a switch notification
+	MDRPercent float64 `json:"mdr_percent" validate:"min=0,max=100"`
+	// Version is the version the update was made against, taken from If-Match. Zero
+	// updates whatever version is current.
+	Version uint64 `json:"-"`
+}
+
+// Reference picks the fields that must also match the MCC and region reference data
+func (r *MerchantRequest) Reference() *referenceEntity.MerchantReference {
+	return &referenceEntity.MerchantReference{
+		MCC:         r.MCC,
+		Province:    r.Province,
+		City:        r.City,
+		District:    r.District,
+		SubDistrict: r.SubDistrict,
+		PostalCode:  r.PostalCode,
+	}
 }
 
 type MerchantResponse struct {
-	ID            uint64 `json:"id"`
-	Name          string `json:"name"`
-	Phone         string `json:"phone"`
-	Email         string `json:"email"`
-	AccountNumber string `json:"account_number"`
-	MID           string `json:"mid"`
-	NMID          string `json:"nmid"`
-	MPAN          string `json:"mpan"`
-	MCC           string `json:"mcc"`
-	PostalCode    string `json:"postal_code"`
-	Province      string `json:"province"`
-	District      string `json:"district"`
-	SubDistrict   string `json:"subdistrict"`
-	City          string `json:"city"`
-	Status        string `json:"status"`
-	CreatedAt     string `json:"created_at"`
-	UpdatedAt     string `json:"updated_at"`
+	ID             uint64  `json:"id"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	ParentID       *uint64 `json:"parent_id"`
+	SettleAtParent bool    `json:"settle_at_parent"`
+	Phone          string  `json:"phone"`
+	Email          string  `json:"email"`
+	AccountNumber  string  `json:"account_number"`
+	MID            string  `json:"mid"`
+	NMID           string  `json:"nmid"`
+	MPAN           string  `json:"mpan"`
+	MCC            string  `json:"mcc"`
+	MDRPercent     float64 `json:"mdr_percent"`
+	PostalCode     string  `json:"postal_code"`
+	Province       string  `json:"province"`
+	District       string  `json:"district"`
+	SubDistrict    string  `json:"subdistrict"`
+	City           string  `json:"city"`
+	Status         string  `json:"status"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
+	Version        uint64  `json:"version"`
+}
+
+type MerchantListRequest struct {
+	Status      string `query:"status" validate:"omitempty,oneof=draft submitted under_review approved rejected suspended"`
+	City        string `query:"city"`
+	Province    string `query:"province"`
+	MCC         string `query:"mcc"`
+	Search      string `query:"q"`
+	CreatedFrom string `query:"created_from" validate:"omitempty,datetime=2006-01-02"`
+	CreatedTo   string `query:"created_to" validate:"omitempty,datetime=2006-01-02"`
+	SortBy      string `query:"sort_by" validate:"omitempty,oneof=name mid city created_at updated_at"`
+	SortDir     string `query:"sort_dir" validate:"omitempty,oneof=asc desc"`
+	Page        int    `query:"page" validate:"omitempty,min=1"`
+	Limit       int    `query:"limit" validate:"omitempty,min=1,max=100"`
+
+	// MerchantIDs limits the list to the merchants the caller may act on, nil lists
+	// every merchant
+	MerchantIDs []uint64 `query:"-"`
+}
+
+// MerchantParentRequest attaches a merchant to a corporate parent. With SettleAtParent
+// its payouts go to the parent's bank account instead of its own.
+type MerchantParentRequest struct {
+	ID             uint64 `json:"-"`
+	ParentID       uint64 `json:"parent_id" validate:"required"`
+	SettleAtParent bool   `json:"settle_at_parent"`
+	// Version is the version the change was made against, taken from If-Match. Zero
+	// changes whatever version is current.
+	Version uint64 `json:"-"`
 }
@@ -3,27 +3,45 @@ package usecase_merchant
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
 	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
 	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/merchant/entity"
+	usecase_reference "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference"
 	errWrap "github.com/pkg/errors"
+	"gorm.io/gorm"
 )
 
 type MerchantUseCase struct {
-	logUseCase   usecase_log.ILogUseCase
-	merchantRepo mysql.IMerchantRepository
+	logUseCase       usecase_log.ILogUseCase
+	merchantRepo     mysql.IMerchantRepository
+	referenceUseCase usecase_reference.IReferenceUseCase
+	accountRepo      mysql.IAccountRepository
+	auditUseCase     usecase_audit.IAuditUseCase
 }
 
-func NewMerchantUseCase(logUseCase usecase_log.ILogUseCase, merchantRepo mysql.IMerchantRepository) *MerchantUseCase {
+func NewMerchantUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	merchantRepo mysql.IMerchantRepository,
+	referenceUseCase usecase_reference.IReferenceUseCase,
+	accountRepo mysql.IAccountRepository,
+	auditUseCase usecase_audit.IAuditUseCase,
+) *MerchantUseCase {
 	return &MerchantUseCase{
-		logUseCase:   logUseCase,
-		merchantRepo: merchantRepo,
+		logUseCase:       logUseCase,
+		merchantRepo:     merchantRepo,
+		referenceUseCase: referenceUseCase,
+		accountRepo:      accountRepo,
+		auditUseCase:     auditUseCase,
 	}
 }
 
@@ -31,9 +49,26 @@ type IMerchantUseCase interface {
 	CreateMerchant(ctx context.Context, req *entity.MerchantRequest) (*entity.MerchantResponse, error)
 	UpdateMerchant(ctx context.Context, id uint64, req *entity.MerchantRequest) (*entity.MerchantResponse, error)
 	GetMerchantByMID(ctx context.Context, mid string) (*entity.MerchantResponse, error)
+	ListMerchants(ctx context.Context, req *entity.MerchantListRequest) ([]*entity.MerchantResponse, *generalEntity.PaginationMeta, error)
 	DeleteMerchantByID(ctx context.Context, id uint64) error
+	RestoreMerchant(ctx context.Context, id uint64) (*entity.MerchantResponse, error)
+	PurgeMerchants(ctx context.Context, deletedBefore time.Time, batchSize int) (int, error)
+	SetParent(ctx context.Context, req *entity.MerchantParentRequest) (*entity.MerchantResponse, error)
+	RemoveParent(ctx context.Context, id uint64, version uint64) (*entity.MerchantResponse, error)
+	ListChildren(ctx context.Context, id uint64) ([]*entity.MerchantResponse, error)
 }
 
+// merchantPurgeProcess is the log pipeline process the purge job reports kept merchants under
+const merchantPurgeProcess = "MerchantPurge"
+
+// merchantTypes maps the type names used by the API to the stored MerchantType
+var merchantTypes = map[string]mEntity.MerchantType{
+	mEntity.MerchantTypeIndividual.Name(): mEntity.MerchantTypeIndividual,
+	mEntity.MerchantTypeCorporate.Name():  mEntity.MerchantTypeCorporate,
+}
+
+// CreateMerchant registers the merchant as a draft, it can only take payments once
+// approved through onboarding
 func (u *MerchantUseCase) CreateMerchant(ctx context.Context, req *entity.MerchantRequest) (*entity.MerchantResponse, error) {
 	funcName := "MerchantUseCase.CreateMerchant"
 	captureFieldError := generalEntity.CaptureFields{
@@ -43,46 +78,61 @@ func (u *MerchantUseCase) CreateMerchant(ctx context.Context, req *entity.Mercha
 		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
 		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
 	}
-	merchant := &mEntity.MerchantEntity{
+	if err := u.validateReference(ctx, funcName, req, captureFieldError); err != nil {
+		return nil, err
+	}
+	merchant := NewMerchantEntity(req)
+	if err := u.merchantRepo.Create(ctx, nil, merchant, true); err != nil {
+		u.logUseCase.Error("merchantRepo.Create", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	result := ToMerchantResponse(merchant)
+	u.auditUseCase.Record(ctx, auditEntity.ActionCreate, auditEntity.EntityMerchant, merchant.ID, nil, result)
+	return result, nil
+}
+
+// validateReference rejects an unknown MCC or region fields that do not nest, the
+// mismatches are reported like any other invalid field
+func (u *MerchantUseCase) validateReference(ctx context.Context, funcName string, req *entity.MerchantRequest, captureFieldError generalEntity.CaptureFields) error {
+	fieldErrors, err := u.referenceUseCase.ValidateMerchantReference(ctx, req.Reference())
+	if err != nil {
+		u.logUseCase.Error("referenceUseCase.ValidateMerchantReference", funcName, err, captureFieldError)
+		return err
+	}
+	if message := usecase.FormatValidationErrors(fieldErrors); message != "" {
+		u.logUseCase.Error("referenceUseCase.ValidateMerchantReference", funcName, fmt.Errorf("%s", message), captureFieldError)
+		return errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), message)
+	}
+	return nil
+}
+
+// NewMerchantEntity maps a validated request to a new draft merchant, individual
+// unless the request says otherwise
+func NewMerchantEntity(req *entity.MerchantRequest) *mEntity.MerchantEntity {
+	merchantType := mEntity.MerchantTypeIndividual
+	if req.Type != "" {
+		merchantType = merchantTypes[req.Type]
+	}
+	return &mEntity.MerchantEntity{
 		Name:          req.Name,
+		Type:          merchantType,
 		Phone:         req.Phone,
 		Email:         req.Email,
 		MID:           req.MID,
 		NMID:          req.NMID,
 		MPAN:          req.MPAN,
 		MCC:           req.MCC,
+		MDRPercent:    req.MDRPercent,
 		AccountNumber: req.AccountNumber,
 		PostalCode:    req.PostalCode,
 		Province:      req.Province,
 		District:      req.District,
 		SubDistrict:   req.SubDistrict,
 		City:          req.City,
-		Status:        req.Status,
-	}
-	if err := u.merchantRepo.Create(ctx, nil, merchant, true); err != nil {
-		u.logUseCase.Error("merchantRepo.Create", funcName, err, captureFieldError)
-		return nil, err
+		Status:        mEntity.MerchantStatusDraft,
+		Version:       1,
 	}
-
-	return &entity.MerchantResponse{
-		ID:            merchant.ID,
-		Name:          merchant.Name,
-		Phone:         merchant.Phone,
-		Email:         merchant.Email,
-		MID:           merchant.MID,
-		NMID:          merchant.NMID,
-		MPAN:          merchant.MPAN,
-		MCC:           merchant.MCC,
-		AccountNumber: merchant.AccountNumber,
-		PostalCode:    merchant.PostalCode,
-		Province:      merchant.Province,
-		District:      merchant.District,
-		SubDistrict:   merchant.SubDistrict,
-		City:          merchant.City,
-		Status:        merchant.Status,
-		CreatedAt:     helper.ConvertToJakartaDate(merchant.CreatedAt),
-		UpdatedAt:     helper.ConvertToJakartaDate(merchant.UpdatedAt),
-	}, nil
 }
 
 func (u *MerchantUseCase) GetMerchantByMID(ctx context.Context, mid string) (*entity.MerchantResponse, error) {
@@ -95,25 +145,55 @@ func (u *MerchantUseCase) GetMerchantByMID(ctx context.Context, mid string) (*en
 		return nil, err
 	}
 
-	return &entity.MerchantResponse{
-		ID:            merchant.ID,
-		Name:          merchant.Name,
-		Phone:         merchant.Phone,
-		Email:         merchant.Email,
-		MID:           merchant.MID,
-		NMID:          merchant.NMID,
-		MPAN:          merchant.MPAN,
-		MCC:           merchant.MCC,
-		AccountNumber: merchant.AccountNumber,
-		PostalCode:    merchant.PostalCode,
-		Province:      merchant.Province,
-		District:      merchant.District,
-		SubDistrict:   merchant.SubDistrict,
-		City:          merchant.City,
-		Status:        merchant.Status,
-		CreatedAt:     helper.ConvertToJakartaDate(merchant.CreatedAt),
-		UpdatedAt:     helper.ConvertToJakartaDate(merchant.UpdatedAt),
-	}, nil
+	return ToMerchantResponse(merchant), nil
+}
+
+func (u *MerchantUseCase) ListMerchants(ctx context.Context, req *entity.MerchantListRequest) ([]*entity.MerchantResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "MerchantUseCase.ListMerchants"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	filter := &mEntity.MerchantFilter{
+		IDs:      req.MerchantIDs,
+		Status:   req.Status,
+		City:     req.City,
+		Province: req.Province,
+		MCC:      req.MCC,
+		Search:   req.Search,
+		SortBy:   req.SortBy,
+		SortDir:  req.SortDir,
+		Limit:    limit,
+		Offset:   (page - 1) * limit,
+	}
+	if req.CreatedFrom != "" {
+		createdFrom, _ := helper.ParseDate(req.CreatedFrom)
+		filter.CreatedFrom = &createdFrom
+	}
+	if req.CreatedTo != "" {
+		// The end date is inclusive, so filter up to the start of the next day
+		createdTo, _ := helper.ParseDate(req.CreatedTo)
+		createdTo = createdTo.AddDate(0, 0, 1)
+		filter.CreatedTo = &createdTo
+	}
+
+	merchants, total, err := u.merchantRepo.FindAll(ctx, filter)
+	if err != nil {
+		u.logUseCase.Error("merchantRepo.FindAll", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	response := make([]*entity.MerchantResponse, 0, len(merchants))
+	for i := range merchants {
+		response = append(response, ToMerchantResponse(&merchants[i]))
+	}
+
+	return response, generalEntity.NewPaginationMeta(page, limit, total), nil
 }
 
 func (u *MerchantUseCase) UpdateMerchant(ctx context.Context, id uint64, req *entity.MerchantRequest) (result *entity.MerchantResponse, err error) {
@@ -126,28 +206,43 @@ func (u *MerchantUseCase) UpdateMerchant(ctx context.Context, id uint64, req *en
 		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
 		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
 	}
+	if err := u.validateReference(ctx, funcName, req, captureFieldError); err != nil {
+		return nil, err
+	}
 
+	var before *entity.MerchantResponse
 	if err := mysql.DBTransaction(u.merchantRepo, func(dbTrx mysql.TrxObj) error {
 		merchantEntity, err := u.merchantRepo.LockByID(ctx, dbTrx, id)
 		if err != nil {
 			u.logUseCase.Error("merchantRepo.LockByID", funcName, err, captureFieldError)
 			return err
 		}
+		if merchantEntity == nil {
+			return apperr.ErrRecordNotFound()
+		}
+		if req.Version != 0 && req.Version != merchantEntity.Version {
+			return apperr.ErrStaleVersion()
+		}
+		if err := u.checkTypeChange(ctx, merchantEntity, merchantTypes[req.Type]); err != nil {
+			return err
+		}
+		before = ToMerchantResponse(merchantEntity)
 		changes := &mEntity.MerchantEntity{
 			Name:          req.Name,
+			Type:          merchantTypes[req.Type],
 			Phone:         req.Phone,
 			Email:         req.Email,
 			MID:           req.MID,
 			NMID:          req.NMID,
 			MPAN:          req.MPAN,
 			MCC:           req.MCC,
+			MDRPercent:    req.MDRPercent,
 			AccountNumber: req.AccountNumber,
 			PostalCode:    req.PostalCode,
 			Province:      req.Province,
 			District:      req.District,
 			SubDistrict:   req.SubDistrict,
 			City:          req.City,
-			Status:        req.Status,
 			UpdatedAt:     time.Now(),
 		}
 		err = u.merchantRepo.Update(ctx, dbTrx, merchantEntity, changes)
@@ -155,41 +250,327 @@ func (u *MerchantUseCase) UpdateMerchant(ctx context.Context, id uint64, req *en
 			u.logUseCase.Error("merchantRepo.Update", funcName, err, captureFieldError)
 			return err
 		}
-		result = &entity.MerchantResponse{
-			ID:            merchantEntity.ID,
-			Name:          merchantEntity.Name,
-			Phone:         merchantEntity.Phone,
-			Email:         merchantEntity.Email,
-			MID:           merchantEntity.MID,
-			NMID:          merchantEntity.NMID,
-			MPAN:          merchantEntity.MPAN,
-			MCC:           merchantEntity.MCC,
-			AccountNumber: merchantEntity.AccountNumber,
-			PostalCode:    merchantEntity.PostalCode,
-			Province:      merchantEntity.Province,
-			District:      merchantEntity.District,
-			SubDistrict:   merchantEntity.SubDistrict,
-			City:          merchantEntity.City,
-			Status:        merchantEntity.Status,
-			CreatedAt:     helper.ConvertToJakartaDate(merchantEntity.CreatedAt),
-			UpdatedAt:     helper.ConvertToJakartaDate(merchantEntity.UpdatedAt),
-		}
+		result = ToMerchantResponse(merchantEntity)
 		return nil
 	}); err != nil {
 		return nil, err
 	}
 
+	u.auditUseCase.Record(ctx, auditEntity.ActionUpdate, auditEntity.EntityMerchant, id, before, result)
 	return result, nil
 }
 
+// DeleteMerchantByID soft deletes the merchant together with its accounts, its
+// transactions and ledger stay untouched and the merchant can be restored
 func (u *MerchantUseCase) DeleteMerchantByID(ctx context.Context, id uint64) error {
 	funcName := "MerchantUseCase.DeleteMerchantByID"
 	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
 
-	if err := u.merchantRepo.DeleteByID(ctx, nil, id); err != nil {
-		u.logUseCase.Error("merchantRepo.DeleteByID", funcName, err, captureFieldError)
+	var before *entity.MerchantResponse
+	if err := mysql.DBTransaction(u.merchantRepo, func(dbTrx mysql.TrxObj) error {
+		merchant, err := u.merchantRepo.LockByID(ctx, dbTrx, id)
+		if err != nil {
+			u.logUseCase.Error("merchantRepo.LockByID", funcName, err, captureFieldError)
+			return err
+		}
+		if merchant == nil {
+			return apperr.ErrRecordNotFound()
+		}
+		before = ToMerchantResponse(merchant)
+
+		children, err := u.merchantRepo.FindChildren(ctx, id)
+		if err != nil {
+			u.logUseCase.Error("merchantRepo.FindChildren", funcName, err, captureFieldError)
+			return err
+		}
+		if len(children) > 0 {
+			return apperr.CustomError("Merchant still has child merchants", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+		}
+
+		// The column keeps whole seconds, the accounts get the exact same time so a
+		// restore can tell them from accounts deleted on their own
+		deletedAt := time.Now().Truncate(time.Second)
+		if err := u.merchantRepo.DeleteByID(ctx, dbTrx, id, deletedAt); err != nil {
+			u.logUseCase.Error("merchantRepo.DeleteByID", funcName, err, captureFieldError)
+			return err
+		}
+		if err := u.accountRepo.DeleteByMerchantID(ctx, dbTrx, id, deletedAt); err != nil {
+			u.logUseCase.Error("accountRepo.DeleteByMerchantID", funcName, err, captureFieldError)
+			return err
+		}
+		return nil
+	}); err != nil {
 		return err
 	}
 
+	u.auditUseCase.Record(ctx, auditEntity.ActionDelete, auditEntity.EntityMerchant, id, before, nil)
 	return nil
 }
+
+// RestoreMerchant undoes a soft delete, the accounts deleted along with the merchant
+// come back with it
+func (u *MerchantUseCase) RestoreMerchant(ctx context.Context, id uint64) (*entity.MerchantResponse, error) {
+	funcName := "MerchantUseCase.RestoreMerchant"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	merchant, err := u.merchantRepo.FindByIDWithDeleted(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("merchantRepo.FindByIDWithDeleted", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if !merchant.DeletedAt.Valid {
+		return nil, apperr.CustomError("Merchant is not deleted", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	}
+	if merchant.ParentID != nil {
+		if _, err := u.merchantRepo.FindByID(ctx, *merchant.ParentID); errWrap.Is(err, apperr.ErrRecordNotFound()) {
+			return nil, apperr.CustomError("Parent merchant is deleted, restore it first", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+		} else if err != nil {
+			u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+			return nil, err
+		}
+	}
+
+	if err := mysql.DBTransaction(u.merchantRepo, func(dbTrx mysql.TrxObj) error {
+		if err := u.merchantRepo.Restore(ctx, dbTrx, id); err != nil {
+			u.logUseCase.Error("merchantRepo.Restore", funcName, err, captureFieldError)
+			return err
+		}
+		if err := u.accountRepo.RestoreByMerchantID(ctx, dbTrx, id, merchant.DeletedAt.Time); err != nil {
+			u.logUseCase.Error("accountRepo.RestoreByMerchantID", funcName, err, captureFieldError)
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	merchant.DeletedAt = gorm.DeletedAt{}
+	result := ToMerchantResponse(merchant)
+	u.auditUseCase.Record(ctx, auditEntity.ActionRestore, auditEntity.EntityMerchant, id, nil, result)
+	return result, nil
+}
+
+// PurgeMerchants removes for good the merchants soft deleted before deletedBefore.
+// Merchants with transactions, ledger entries, payouts, disputes or fraud decisions
+// are kept soft deleted, the financial records have to stay resolvable.
+func (u *MerchantUseCase) PurgeMerchants(ctx context.Context, deletedBefore time.Time, batchSize int) (int, error) {
+	funcName := "MerchantUseCase.PurgeMerchants"
+	captureFieldError := generalEntity.CaptureFields{
+		"deletedBefore": helper.ToString(deletedBefore),
+	}
+
+	purged := 0
+	var afterID uint64
+	for {
+		merchants, err := u.merchantRepo.FindDeletedBefore(ctx, deletedBefore, afterID, batchSize)
+		if err != nil {
+			u.logUseCase.Error("merchantRepo.FindDeletedBefore", funcName, err, captureFieldError)
+			return purged, err
+		}
+
+		for i := range merchants {
+			afterID = merchants[i].ID
+			captureFieldError["merchantID"] = helper.ToString(merchants[i].ID)
+
+			hasHistory, err := u.merchantRepo.HasFinancialHistory(ctx, merchants[i].ID)
+			if err != nil {
+				u.logUseCase.Error("merchantRepo.HasFinancialHistory", funcName, err, captureFieldError)
+				return purged, err
+			}
+			if hasHistory {
+				u.logUseCase.Info("merchant has financial history, kept soft deleted", funcName, captureFieldError, merchantPurgeProcess)
+				continue
+			}
+
+			// A purge still failing on a foreign key, e.g. a deleted corporate merchant
+			// whose deleted children are not purged yet, is retried on the next run
+			if err := u.purgeMerchant(ctx, merchants[i].ID); err != nil {
+				u.logUseCase.Error("MerchantUseCase.purgeMerchant", funcName, err, captureFieldError)
+				continue
+			}
+			u.auditUseCase.Record(ctx, auditEntity.ActionPurge, auditEntity.EntityMerchant, merchants[i].ID, ToMerchantResponse(&merchants[i]), nil)
+			purged++
+		}
+
+		if len(merchants) < batchSize {
+			return purged, nil
+		}
+	}
+}
+
+func (u *MerchantUseCase) purgeMerchant(ctx context.Context, id uint64) error {
+	return mysql.DBTransaction(u.merchantRepo, func(dbTrx mysql.TrxObj) error {
+		if err := u.accountRepo.PurgeByMerchantID(ctx, dbTrx, id); err != nil {
+			return err
+		}
+		return u.merchantRepo.Purge(ctx, dbTrx, id)
+	})
+}
+
+// checkTypeChange keeps the hierarchy one level deep: a corporate merchant with
+// children stays corporate and a child merchant cannot become corporate
+func (u *MerchantUseCase) checkTypeChange(ctx context.Context, merchant *mEntity.MerchantEntity, merchantType mEntity.MerchantType) error {
+	switch {
+	case merchantType == 0 || merchantType == merchant.Type:
+		return nil
+	case merchantType == mEntity.MerchantTypeCorporate && merchant.ParentID != nil:
+		return apperr.CustomError("A child merchant cannot be corporate", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+
+	children, err := u.merchantRepo.FindChildren(ctx, merchant.ID)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return apperr.CustomError("Merchant still has child merchants", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	}
+	return nil
+}
+
+// SetParent attaches an individual merchant to a corporate one, replacing its current
+// parent if any
+func (u *MerchantUseCase) SetParent(ctx context.Context, req *entity.MerchantParentRequest) (result *entity.MerchantResponse, err error) {
+	funcName := "MerchantUseCase.SetParent"
+	captureFieldError := generalEntity.CaptureFields{
+		"id":      helper.ToString(req.ID),
+		"payload": helper.ToString(req),
+	}
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+	if req.ParentID == req.ID {
+		return nil, apperr.CustomError("A merchant cannot be its own parent", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+
+	parent, err := u.merchantRepo.FindByID(ctx, req.ParentID)
+	if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		return nil, apperr.CustomError("Unknown parent merchant", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	} else if err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if !parent.IsCorporate() {
+		return nil, apperr.CustomError("Parent merchant must be corporate", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+
+	var before *entity.MerchantResponse
+	if err := mysql.DBTransaction(u.merchantRepo, func(dbTrx mysql.TrxObj) error {
+		merchant, err := u.merchantRepo.LockByID(ctx, dbTrx, req.ID)
+		if err != nil {
+			u.logUseCase.Error("merchantRepo.LockByID", funcName, err, captureFieldError)
+			return err
+		}
+		if merchant == nil {
+			return apperr.ErrRecordNotFound()
+		}
+		if req.Version != 0 && req.Version != merchant.Version {
+			return apperr.ErrStaleVersion()
+		}
+		if merchant.IsCorporate() {
+			return apperr.CustomError("A corporate merchant cannot have a parent", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+		}
+		before = ToMerchantResponse(merchant)
+
+		if err := u.merchantRepo.UpdateParent(ctx, dbTrx, merchant, &req.ParentID, req.SettleAtParent); err != nil {
+			u.logUseCase.Error("merchantRepo.UpdateParent", funcName, err, captureFieldError)
+			return err
+		}
+		merchant.ParentID = &req.ParentID
+		merchant.SettleAtParent = req.SettleAtParent
+		result = ToMerchantResponse(merchant)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	u.auditUseCase.Record(ctx, auditEntity.ActionSetParent, auditEntity.EntityMerchant, req.ID, before, result)
+	return result, nil
+}
+
+// RemoveParent detaches a merchant from its parent, it settles to its own account again.
+// A version of zero removes it from whatever version is current.
+func (u *MerchantUseCase) RemoveParent(ctx context.Context, id uint64, version uint64) (result *entity.MerchantResponse, err error) {
+	funcName := "MerchantUseCase.RemoveParent"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	var before *entity.MerchantResponse
+	if err := mysql.DBTransaction(u.merchantRepo, func(dbTrx mysql.TrxObj) error {
+		merchant, err := u.merchantRepo.LockByID(ctx, dbTrx, id)
+		if err != nil {
+			u.logUseCase.Error("merchantRepo.LockByID", funcName, err, captureFieldError)
+			return err
+		}
+		if merchant == nil {
+			return apperr.ErrRecordNotFound()
+		}
+		if version != 0 && version != merchant.Version {
+			return apperr.ErrStaleVersion()
+		}
+		before = ToMerchantResponse(merchant)
+
+		if err := u.merchantRepo.UpdateParent(ctx, dbTrx, merchant, nil, false); err != nil {
+			u.logUseCase.Error("merchantRepo.UpdateParent", funcName, err, captureFieldError)
+			return err
+		}
+		merchant.ParentID = nil
+		merchant.SettleAtParent = false
+		result = ToMerchantResponse(merchant)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	u.auditUseCase.Record(ctx, auditEntity.ActionRemoveParent, auditEntity.EntityMerchant, id, before, result)
+	return result, nil
+}
+
+func (u *MerchantUseCase) ListChildren(ctx context.Context, id uint64) ([]*entity.MerchantResponse, error) {
+	funcName := "MerchantUseCase.ListChildren"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	if _, err := u.merchantRepo.FindByID(ctx, id); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	children, err := u.merchantRepo.FindChildren(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("merchantRepo.FindChildren", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	response := make([]*entity.MerchantResponse, 0, len(children))
+	for i := range children {
+		response = append(response, ToMerchantResponse(&children[i]))
+	}
+	return response, nil
+}
+
+// ToMerchantResponse maps a stored merchant to its API representation
+func ToMerchantResponse(merchant *mEntity.MerchantEntity) *entity.MerchantResponse {
+	return &entity.MerchantResponse{
+		ID:             merchant.ID,
+		Name:           merchant.Name,
+		Type:           merchant.Type.Name(),
+		ParentID:       merchant.ParentID,
+		SettleAtParent: merchant.SettleAtParent,
+		Phone:          merchant.Phone,
+		Email:          merchant.Email,
+		MID:            merchant.MID,
+		NMID:           merchant.NMID,
+		MPAN:           merchant.MPAN,
+		MCC:            merchant.MCC,
+		MDRPercent:     merchant.MDRPercent,
+		AccountNumber:  merchant.AccountNumber,
+		PostalCode:     merchant.PostalCode,
+		Province:       merchant.Province,
+		District:       merchant.District,
+		SubDistrict:    merchant.SubDistrict,
+		City:           merchant.City,
+		Status:         merchant.Status,
+		CreatedAt:      helper.ConvertToJakartaDate(merchant.CreatedAt),
+		UpdatedAt:      helper.ConvertToJakartaDate(merchant.UpdatedAt),
+		Version:        merchant.Version,
+	}
+}
@@ -0,0 +1,144 @@
+package usecase_merchant_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+	usecase_merchant "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/merchant"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/merchant/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MerchantUsecaseTestSuite struct {
+	suite.Suite
+
+	usecase      usecase_merchant.IMerchantUseCase
+	merchantRepo *mocks.MerchantRepository
+	accountRepo  *mocks.AccountRepository
+	auditUseCase *mocks.AuditUseCase
+	logUseCase   *mocks.LogUsecase
+	trx          *mocks.TrxObj
+}
+
+func (s *MerchantUsecaseTestSuite) SetupTest() {
+	s.merchantRepo = mocks.NewMerchantRepository(s.T())
+	s.accountRepo = mocks.NewAccountRepository(s.T())
+	s.auditUseCase = mocks.NewAuditUseCase(s.T())
+	s.logUseCase = &mocks.LogUsecase{}
+	s.trx = &mocks.TrxObj{}
+
+	s.logUseCase.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	s.logUseCase.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	s.trx.On("Commit").Return(nil).Maybe()
+	s.trx.On("Rollback").Return(nil).Maybe()
+	s.merchantRepo.On("Begin").Return(s.trx, nil).Maybe()
+
+	s.usecase = usecase_merchant.NewMerchantUseCase(s.logUseCase, s.merchantRepo, nil, s.accountRepo, s.auditUseCase)
+}
+
+func TestMerchantUsecase(t *testing.T) {
+	suite.Run(t, new(MerchantUsecaseTestSuite))
+}
+
+func (s *MerchantUsecaseTestSuite) TestPurgeMerchants() {
+	s.merchantRepo.On("FindDeletedBefore", mock.Anything, mock.Anything, uint64(0), 2).
+		Return([]mEntity.MerchantEntity{{ID: 1}, {ID: 2}}, nil).Once()
+	s.merchantRepo.On("FindDeletedBefore", mock.Anything, mock.Anything, uint64(2), 2).
+		Return([]mEntity.MerchantEntity{{ID: 3}, {ID: 4}}, nil).Once()
+	s.merchantRepo.On("FindDeletedBefore", mock.Anything, mock.Anything, uint64(4), 2).
+		Return([]mEntity.MerchantEntity{{ID: 5}}, nil).Once()
+	for _, id := range []uint64{1, 2} {
+		s.merchantRepo.On("HasFinancialHistory", mock.Anything, id).Return(true, nil).Once()
+	}
+	for _, id := range []uint64{3, 4, 5} {
+		s.merchantRepo.On("HasFinancialHistory", mock.Anything, id).Return(false, nil).Once()
+		s.accountRepo.On("PurgeByMerchantID", mock.Anything, s.trx, id).Return(nil).Once()
+	}
+	s.merchantRepo.On("Purge", mock.Anything, s.trx, uint64(3)).Return(nil).Once()
+	s.merchantRepo.On("Purge", mock.Anything, s.trx, uint64(4)).
+		Return(errors.New("Error 1451 (23000): Cannot delete or update a parent row")).Once()
+	s.merchantRepo.On("Purge", mock.Anything, s.trx, uint64(5)).Return(nil).Once()
+	for _, id := range []uint64{3, 5} {
+		s.auditUseCase.On("Record", mock.Anything, auditEntity.ActionPurge, auditEntity.EntityMerchant, id, mock.Anything, nil).Once()
+	}
+
+	purged, err := s.usecase.PurgeMerchants(context.Background(), time.Now(), 2)
+
+	s.Require().NoError(err)
+	// A full page of merchants with financial history does not stop the run, and a
+	// merchant still referenced is left for the next run
+	s.Equal(2, purged)
+}
+
+func (s *MerchantUsecaseTestSuite) TestDeleteMissingMerchant() {
+	s.merchantRepo.On("LockByID", mock.Anything, s.trx, uint64(99)).Return(nil, nil).Once()
+
+	err := s.usecase.DeleteMerchantByID(context.Background(), 99)
+
+	s.Require().Error(err)
+	s.Equal(http.StatusNotFound, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.merchantRepo.AssertNotCalled(s.T(), "DeleteByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *MerchantUsecaseTestSuite) TestSetParentRejectsStaleVersion() {
+	s.merchantRepo.On("FindByID", mock.Anything, uint64(10)).
+		Return(&mEntity.MerchantEntity{ID: 10, Type: mEntity.MerchantTypeCorporate, Version: 1}, nil)
+	s.merchantRepo.On("LockByID", mock.Anything, s.trx, uint64(11)).
+		Return(func(ctx context.Context, dbTrx mysql.TrxObj, id uint64) *mEntity.MerchantEntity {
+			return &mEntity.MerchantEntity{ID: 11, Type: mEntity.MerchantTypeIndividual, Version: 3}
+		}, nil)
+	req := &entity.MerchantParentRequest{ID: 11, ParentID: 10, Version: 2}
+
+	_, err := s.usecase.SetParent(context.Background(), req)
+	s.Require().Error(err)
+	s.Equal(http.StatusPreconditionFailed, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.merchantRepo.AssertNotCalled(s.T(), "UpdateParent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	s.merchantRepo.On("UpdateParent", mock.Anything, s.trx, mock.Anything, &req.ParentID, false).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*mEntity.MerchantEntity).Version++
+		}).
+		Return(nil).Once()
+	s.auditUseCase.On("Record", mock.Anything, auditEntity.ActionSetParent, auditEntity.EntityMerchant, uint64(11), mock.Anything, mock.Anything).Once()
+
+	req.Version = 3
+	merchant, err := s.usecase.SetParent(context.Background(), req)
+	s.Require().NoError(err)
+	s.Equal(uint64(4), merchant.Version)
+}
+
+func (s *MerchantUsecaseTestSuite) TestRemoveParentRejectsStaleVersion() {
+	parentID := uint64(10)
+	s.merchantRepo.On("LockByID", mock.Anything, s.trx, uint64(11)).
+		Return(func(ctx context.Context, dbTrx mysql.TrxObj, id uint64) *mEntity.MerchantEntity {
+			return &mEntity.MerchantEntity{ID: 11, ParentID: &parentID, SettleAtParent: true, Version: 3}
+		}, nil)
+
+	_, err := s.usecase.RemoveParent(context.Background(), 11, 2)
+	s.Require().Error(err)
+	s.Equal(http.StatusPreconditionFailed, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.merchantRepo.AssertNotCalled(s.T(), "UpdateParent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	s.merchantRepo.On("UpdateParent", mock.Anything, s.trx, mock.Anything, (*uint64)(nil), false).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*mEntity.MerchantEntity).Version++
+		}).
+		Return(nil).Once()
+	s.auditUseCase.On("Record", mock.Anything, auditEntity.ActionRemoveParent, auditEntity.EntityMerchant, uint64(11), mock.Anything, mock.Anything).Once()
+
+	merchant, err := s.usecase.RemoveParent(context.Background(), 11, 3)
+	s.Require().NoError(err)
+	s.Nil(merchant.ParentID)
+	s.False(merchant.SettleAtParent)
+	s.Equal(uint64(4), merchant.Version)
+}
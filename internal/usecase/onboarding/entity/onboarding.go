@@ -0,0 +1,51 @@
+package entity
+
+type DocumentRequest struct {
+	MerchantID  uint64
+	Type        string `validate:"required,oneof=ktp npwp store_photo"`
+	FileName    string `validate:"required,max=255"`
+	ContentType string `validate:"required"`
+	Size        int64  `validate:"gt=0"`
+}
+
+// StatusRequest moves a merchant to Status. Rejecting or suspending a merchant
+// needs a Comment telling the merchant why. Only Backoffice callers review merchants,
+// a merchant's own account can only submit it.
+type StatusRequest struct {
+	MerchantID uint64 `json:"-"`
+	Status     string `json:"status" validate:"required,oneof=submitted under_review approved rejected suspended"`
+	Comment    string `json:"comment" validate:"omitempty,max=500"`
+	Actor      string `json:"-" validate:"required,max=100"`
+	Backoffice bool   `json:"-"`
+}
+
+type DocumentResponse struct {
+	ID          uint64 `json:"id"`
+	MerchantID  uint64 `json:"merchant_id"`
+	Type        string `json:"type"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type ReviewResponse struct {
+	ID         uint64 `json:"id"`
+	MerchantID uint64 `json:"merchant_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	Actor      string `json:"actor"`
+	Comment    string `json:"comment,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type StatusResponse struct {
+	MerchantID uint64 `json:"merchant_id"`
+	Status     string `json:"status"`
+}
+
+// DocumentFile is a KYC document on disk
+type DocumentFile struct {
+	Path     string
+	Filename string
+}
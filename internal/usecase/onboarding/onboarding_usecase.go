@@ -0,0 +1,365 @@
+package usecase_onboarding
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/onboarding/entity"
+	errWrap "github.com/pkg/errors"
+)
+
+// documentDirectory is where KYC documents are kept, relative to config.StorageDirectory
+const documentDirectory = "merchants"
+
+// documentContentTypes are the documents accepted, scans and photos
+var documentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+// requiredDocuments must all be uploaded before a merchant can be submitted
+var requiredDocuments = []string{
+	mEntity.MerchantDocumentKTP,
+	mEntity.MerchantDocumentNPWP,
+	mEntity.MerchantDocumentStorePhoto,
+}
+
+// transitions lists the statuses a merchant can move to from each status. A rejected
+// merchant fixes its documents and submits again, a suspended one can be reinstated.
+var transitions = map[string][]string{
+	mEntity.MerchantStatusDraft:       {mEntity.MerchantStatusSubmitted},
+	mEntity.MerchantStatusSubmitted:   {mEntity.MerchantStatusUnderReview},
+	mEntity.MerchantStatusUnderReview: {mEntity.MerchantStatusApproved, mEntity.MerchantStatusRejected},
+	mEntity.MerchantStatusRejected:    {mEntity.MerchantStatusSubmitted},
+	mEntity.MerchantStatusApproved:    {mEntity.MerchantStatusSuspended},
+	mEntity.MerchantStatusSuspended:   {mEntity.MerchantStatusApproved},
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type OnboardingUseCase struct {
+	logUseCase     usecase_log.ILogUseCase
+	onboardingRepo mysql.IMerchantOnboardingRepository
+	merchantRepo   mysql.IMerchantRepository
+	auditUseCase   usecase_audit.IAuditUseCase
+	option         *config.OnboardingOption
+}
+
+func NewOnboardingUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	onboardingRepo mysql.IMerchantOnboardingRepository,
+	merchantRepo mysql.IMerchantRepository,
+	auditUseCase usecase_audit.IAuditUseCase,
+	option *config.OnboardingOption,
+) *OnboardingUseCase {
+	return &OnboardingUseCase{
+		logUseCase:     logUseCase,
+		onboardingRepo: onboardingRepo,
+		merchantRepo:   merchantRepo,
+		auditUseCase:   auditUseCase,
+		option:         option,
+	}
+}
+
+// IOnboardingUseCase runs the KYC review of merchants. A merchant starts as a draft,
+// uploads its KTP, NPWP and store photo, is submitted and reviewed by the backoffice,
+// and only takes payments once approved. Every status change is kept as a review.
+type IOnboardingUseCase interface {
+	UploadDocument(ctx context.Context, req *entity.DocumentRequest, file io.Reader) (*entity.DocumentResponse, error)
+	ListDocuments(ctx context.Context, merchantID uint64) ([]*entity.DocumentResponse, error)
+	GetDocumentFile(ctx context.Context, merchantID uint64, documentID uint64) (*entity.DocumentFile, error)
+	ChangeStatus(ctx context.Context, req *entity.StatusRequest) (*entity.StatusResponse, error)
+	ListReviews(ctx context.Context, merchantID uint64) ([]*entity.ReviewResponse, error)
+}
+
+// UploadDocument stores a KYC document of the merchant, documents can only be changed
+// while the merchant is a draft or after it was rejected
+func (u *OnboardingUseCase) UploadDocument(ctx context.Context, req *entity.DocumentRequest, file io.Reader) (*entity.DocumentResponse, error) {
+	funcName := "OnboardingUseCase.UploadDocument"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+	if !documentContentTypes[req.ContentType] {
+		return nil, apperr.CustomError("Document must be a PDF, JPEG or PNG file", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+	if req.Size > int64(u.option.MaxDocumentSizeMB)<<20 {
+		return nil, apperr.CustomError(
+			fmt.Sprintf("Document file must not exceed %d MB", u.option.MaxDocumentSizeMB),
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	merchant, err := u.merchantRepo.FindByID(ctx, req.MerchantID)
+	if err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if merchant.Status != mEntity.MerchantStatusDraft && merchant.Status != mEntity.MerchantStatusRejected {
+		return nil, apperr.CustomError("Documents can only be changed before the merchant is submitted", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	}
+
+	filePath, err := u.saveDocumentFile(merchant.ID, req.FileName, file)
+	if err != nil {
+		u.logUseCase.Error("OnboardingUseCase.saveDocumentFile", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	document := &mEntity.MerchantDocumentEntity{
+		MerchantID:  merchant.ID,
+		Type:        req.Type,
+		FileName:    req.FileName,
+		FilePath:    filePath,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+	}
+	if err := u.onboardingRepo.CreateDocument(ctx, nil, document); err != nil {
+		u.logUseCase.Error("onboardingRepo.CreateDocument", funcName, err, captureFieldError)
+		os.Remove(filePath)
+		return nil, err
+	}
+
+	return toDocumentResponse(document), nil
+}
+
+// saveDocumentFile writes the upload under a name made unique by its upload time,
+// the original name is only kept as a hint and stripped of path characters
+func (u *OnboardingUseCase) saveDocumentFile(merchantID uint64, fileName string, file io.Reader) (string, error) {
+	directory := filepath.Join(config.StorageDirectory, documentDirectory, helper.ToString(merchantID))
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return "", err
+	}
+
+	safeName := unsafeFileNameChars.ReplaceAllString(filepath.Base(fileName), "_")
+	filePath := filepath.Join(directory, fmt.Sprintf("%d_%s", time.Now().UnixNano(), safeName))
+
+	out, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, file); err != nil {
+		out.Close()
+		os.Remove(filePath)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+func (u *OnboardingUseCase) ListDocuments(ctx context.Context, merchantID uint64) ([]*entity.DocumentResponse, error) {
+	funcName := "OnboardingUseCase.ListDocuments"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, merchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	documents, err := u.onboardingRepo.FindDocumentsByMerchantID(ctx, merchantID)
+	if err != nil {
+		u.logUseCase.Error("onboardingRepo.FindDocumentsByMerchantID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	responses := make([]*entity.DocumentResponse, 0, len(documents))
+	for i := range documents {
+		responses = append(responses, toDocumentResponse(&documents[i]))
+	}
+	return responses, nil
+}
+
+func (u *OnboardingUseCase) GetDocumentFile(ctx context.Context, merchantID uint64, documentID uint64) (*entity.DocumentFile, error) {
+	funcName := "OnboardingUseCase.GetDocumentFile"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+		"documentID": helper.ToString(documentID),
+	}
+
+	document, err := u.onboardingRepo.FindDocumentByID(ctx, documentID)
+	if err != nil {
+		u.logUseCase.Error("onboardingRepo.FindDocumentByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if document.MerchantID != merchantID {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	return &entity.DocumentFile{
+		Path:     document.FilePath,
+		Filename: document.FileName,
+	}, nil
+}
+
+// ChangeStatus moves the merchant along the onboarding workflow and records who did
+// it in the same DB transaction. Everything but submitting is up to the backoffice.
+func (u *OnboardingUseCase) ChangeStatus(ctx context.Context, req *entity.StatusRequest) (*entity.StatusResponse, error) {
+	funcName := "OnboardingUseCase.ChangeStatus"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+	if req.Status != mEntity.MerchantStatusSubmitted && !req.Backoffice {
+		return nil, apperr.ErrForbidden()
+	}
+	if (req.Status == mEntity.MerchantStatusRejected || req.Status == mEntity.MerchantStatusSuspended) && req.Comment == "" {
+		return nil, apperr.CustomError("A comment is required to reject or suspend a merchant", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+
+	var before *entity.StatusResponse
+	if err := mysql.DBTransaction(u.onboardingRepo, func(dbTrx mysql.TrxObj) error {
+		merchant, err := u.merchantRepo.LockByID(ctx, dbTrx, req.MerchantID)
+		if err != nil {
+			return err
+		}
+		if merchant == nil {
+			return apperr.ErrRecordNotFound()
+		}
+		if !canTransition(merchant.Status, req.Status) {
+			return apperr.CustomError(
+				fmt.Sprintf("Merchant cannot move from %s to %s", merchant.Status, req.Status),
+				generalEntity.BAD_REQUEST_CODE,
+				http.StatusConflict,
+			)
+		}
+		if req.Status == mEntity.MerchantStatusSubmitted {
+			if err := u.checkDocuments(ctx, merchant.ID); err != nil {
+				return err
+			}
+		}
+
+		before = &entity.StatusResponse{MerchantID: merchant.ID, Status: merchant.Status}
+		review := &mEntity.MerchantReviewEntity{
+			MerchantID: merchant.ID,
+			FromStatus: merchant.Status,
+			ToStatus:   req.Status,
+			Actor:      req.Actor,
+			Comment:    req.Comment,
+		}
+		if err := u.merchantRepo.Update(ctx, dbTrx, merchant, &mEntity.MerchantEntity{
+			Status:    req.Status,
+			UpdatedAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+		return u.onboardingRepo.CreateReview(ctx, dbTrx, review)
+	}); err != nil {
+		u.logUseCase.Error("OnboardingUseCase.ChangeStatus", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	result := &entity.StatusResponse{MerchantID: req.MerchantID, Status: req.Status}
+	u.auditUseCase.Record(ctx, auditEntity.ActionChangeStatus, auditEntity.EntityMerchant, req.MerchantID, before, result)
+	return result, nil
+}
+
+// checkDocuments fails unless every required document type has been uploaded
+func (u *OnboardingUseCase) checkDocuments(ctx context.Context, merchantID uint64) error {
+	documents, err := u.onboardingRepo.FindDocumentsByMerchantID(ctx, merchantID)
+	if err != nil {
+		return err
+	}
+
+	uploaded := make(map[string]bool, len(documents))
+	for _, document := range documents {
+		uploaded[document.Type] = true
+	}
+	for _, documentType := range requiredDocuments {
+		if !uploaded[documentType] {
+			return apperr.CustomError(
+				fmt.Sprintf("Document %s must be uploaded before submitting", documentType),
+				generalEntity.BAD_REQUEST_CODE,
+				http.StatusUnprocessableEntity,
+			)
+		}
+	}
+	return nil
+}
+
+func (u *OnboardingUseCase) ListReviews(ctx context.Context, merchantID uint64) ([]*entity.ReviewResponse, error) {
+	funcName := "OnboardingUseCase.ListReviews"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, merchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	reviews, err := u.onboardingRepo.FindReviewsByMerchantID(ctx, merchantID)
+	if err != nil {
+		u.logUseCase.Error("onboardingRepo.FindReviewsByMerchantID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	responses := make([]*entity.ReviewResponse, 0, len(reviews))
+	for i := range reviews {
+		responses = append(responses, toReviewResponse(&reviews[i]))
+	}
+	return responses, nil
+}
+
+func canTransition(from string, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func toDocumentResponse(document *mEntity.MerchantDocumentEntity) *entity.DocumentResponse {
+	return &entity.DocumentResponse{
+		ID:          document.ID,
+		MerchantID:  document.MerchantID,
+		Type:        document.Type,
+		FileName:    document.FileName,
+		ContentType: document.ContentType,
+		Size:        document.Size,
+		CreatedAt:   helper.ConvertToJakartaTime(document.CreatedAt),
+	}
+}
+
+func toReviewResponse(review *mEntity.MerchantReviewEntity) *entity.ReviewResponse {
+	return &entity.ReviewResponse{
+		ID:         review.ID,
+		MerchantID: review.MerchantID,
+		FromStatus: review.FromStatus,
+		ToStatus:   review.ToStatus,
+		Actor:      review.Actor,
+		Comment:    review.Comment,
+		CreatedAt:  helper.ConvertToJakartaTime(review.CreatedAt),
+	}
+}
@@ -0,0 +1,165 @@
+package usecase_onboarding
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/onboarding/entity"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type trxStub struct{}
+
+func (trxStub) Commit() error   { return nil }
+func (trxStub) Rollback() error { return nil }
+
+type onboardingRepoStub struct {
+	mysql.IMerchantOnboardingRepository
+	documents []mEntity.MerchantDocumentEntity
+	reviews   []mEntity.MerchantReviewEntity
+}
+
+func (r *onboardingRepoStub) Begin() (mysql.TrxObj, error) {
+	return trxStub{}, nil
+}
+
+func (r *onboardingRepoStub) FindDocumentsByMerchantID(ctx context.Context, merchantID uint64) ([]mEntity.MerchantDocumentEntity, error) {
+	return r.documents, nil
+}
+
+func (r *onboardingRepoStub) CreateReview(ctx context.Context, dbTrx mysql.TrxObj, params *mEntity.MerchantReviewEntity) error {
+	r.reviews = append(r.reviews, *params)
+	return nil
+}
+
+type merchantRepoStub struct {
+	mysql.IMerchantRepository
+	merchant *mEntity.MerchantEntity
+}
+
+func (r *merchantRepoStub) LockByID(ctx context.Context, dbTrx mysql.TrxObj, id uint64) (*mEntity.MerchantEntity, error) {
+	return r.merchant, nil
+}
+
+func (r *merchantRepoStub) Update(ctx context.Context, dbTrx mysql.TrxObj, params *mEntity.MerchantEntity, changes *mEntity.MerchantEntity) error {
+	params.Status = changes.Status
+	return nil
+}
+
+type logStub struct {
+	usecase_log.ILogUseCase
+}
+
+func (logStub) Error(process string, funcName string, err error, logFields map[string]string) {}
+
+type auditUseCaseStub struct {
+	usecase_audit.IAuditUseCase
+	actions []string
+}
+
+func (u *auditUseCaseStub) Record(ctx context.Context, action string, entityType string, entityID uint64, before interface{}, after interface{}) {
+	u.actions = append(u.actions, action)
+}
+
+type OnboardingUseCaseTestSuite struct {
+	suite.Suite
+
+	repo         *onboardingRepoStub
+	merchantRepo *merchantRepoStub
+	auditUseCase *auditUseCaseStub
+	usecase      *OnboardingUseCase
+}
+
+func (s *OnboardingUseCaseTestSuite) SetupTest() {
+	s.repo = &onboardingRepoStub{}
+	s.merchantRepo = &merchantRepoStub{merchant: &mEntity.MerchantEntity{ID: 1, Status: mEntity.MerchantStatusDraft}}
+	s.auditUseCase = &auditUseCaseStub{}
+	s.usecase = NewOnboardingUseCase(logStub{}, s.repo, s.merchantRepo, s.auditUseCase, &config.OnboardingOption{MaxDocumentSizeMB: 5})
+}
+
+func TestOnboardingUseCase(t *testing.T) {
+	suite.Run(t, new(OnboardingUseCaseTestSuite))
+}
+
+func (s *OnboardingUseCaseTestSuite) changeStatus(status string, comment string) error {
+	_, err := s.usecase.ChangeStatus(context.Background(), &entity.StatusRequest{
+		MerchantID: 1,
+		Status:     status,
+		Comment:    comment,
+		Actor:      "backoffice",
+		Backoffice: true,
+	})
+	return err
+}
+
+func (s *OnboardingUseCaseTestSuite) TestSubmitRequiresEveryDocument() {
+	s.repo.documents = []mEntity.MerchantDocumentEntity{{Type: mEntity.MerchantDocumentKTP}, {Type: mEntity.MerchantDocumentNPWP}}
+	err := s.changeStatus(mEntity.MerchantStatusSubmitted, "")
+	s.Require().Error(err)
+	s.Equal(http.StatusUnprocessableEntity, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.Empty(s.repo.reviews)
+
+	s.repo.documents = append(s.repo.documents, mEntity.MerchantDocumentEntity{Type: mEntity.MerchantDocumentStorePhoto})
+	s.NoError(s.changeStatus(mEntity.MerchantStatusSubmitted, ""))
+	s.Equal(mEntity.MerchantStatusSubmitted, s.merchantRepo.merchant.Status)
+}
+
+func (s *OnboardingUseCaseTestSuite) TestChangeStatusRecordsReview() {
+	s.merchantRepo.merchant.Status = mEntity.MerchantStatusUnderReview
+
+	s.NoError(s.changeStatus(mEntity.MerchantStatusApproved, "Documents verified"))
+	s.Equal([]mEntity.MerchantReviewEntity{{
+		MerchantID: 1,
+		FromStatus: mEntity.MerchantStatusUnderReview,
+		ToStatus:   mEntity.MerchantStatusApproved,
+		Actor:      "backoffice",
+		Comment:    "Documents verified",
+	}}, s.repo.reviews)
+	s.Equal([]string{auditEntity.ActionChangeStatus}, s.auditUseCase.actions)
+}
+
+func (s *OnboardingUseCaseTestSuite) TestChangeStatusRejectsSkippedReview() {
+	err := s.changeStatus(mEntity.MerchantStatusApproved, "")
+	s.Require().Error(err)
+	s.Equal(http.StatusConflict, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.Equal(mEntity.MerchantStatusDraft, s.merchantRepo.merchant.Status)
+}
+
+func (s *OnboardingUseCaseTestSuite) TestRejectRequiresComment() {
+	s.merchantRepo.merchant.Status = mEntity.MerchantStatusUnderReview
+
+	s.Error(s.changeStatus(mEntity.MerchantStatusRejected, ""))
+	s.NoError(s.changeStatus(mEntity.MerchantStatusRejected, "NPWP photo is blurry"))
+	s.Equal(mEntity.MerchantStatusRejected, s.merchantRepo.merchant.Status)
+}
+
+func (s *OnboardingUseCaseTestSuite) TestMerchantAccountCanOnlySubmit() {
+	s.repo.documents = []mEntity.MerchantDocumentEntity{
+		{Type: mEntity.MerchantDocumentKTP},
+		{Type: mEntity.MerchantDocumentNPWP},
+		{Type: mEntity.MerchantDocumentStorePhoto},
+	}
+	changeStatus := func(status string) error {
+		_, err := s.usecase.ChangeStatus(context.Background(), &entity.StatusRequest{
+			MerchantID: 1,
+			Status:     status,
+			Actor:      "merchant-1",
+		})
+		return err
+	}
+
+	s.NoError(changeStatus(mEntity.MerchantStatusSubmitted))
+	s.merchantRepo.merchant.Status = mEntity.MerchantStatusUnderReview
+	s.Equal(apperr.ErrForbidden(), changeStatus(mEntity.MerchantStatusApproved))
+	s.Equal(mEntity.MerchantStatusUnderReview, s.merchantRepo.merchant.Status)
+	s.Len(s.repo.reviews, 1)
+}
@@ -0,0 +1,62 @@
+package entity
+
+// OutletRequest creates or updates an outlet, Status is only taken on update
+type OutletRequest struct {
+	ID          uint64   `json:"-"`
+	MerchantID  uint64   `json:"-"`
+	Name        string   `json:"name" validate:"required,max=255"`
+	Address     string   `json:"address" validate:"required,max=500"`
+	PostalCode  string   `json:"postal_code" validate:"omitempty,max=20"`
+	Province    string   `json:"province" validate:"omitempty,max=100"`
+	District    string   `json:"district" validate:"omitempty,max=100"`
+	SubDistrict string   `json:"subdistrict" validate:"omitempty,max=100"`
+	City        string   `json:"city" validate:"omitempty,max=100"`
+	Latitude    *float64 `json:"latitude" validate:"omitempty,gte=-90,lte=90"`
+	Longitude   *float64 `json:"longitude" validate:"omitempty,gte=-180,lte=180"`
+	Status      string   `json:"status" validate:"omitempty,oneof=active inactive"`
+}
+
+type TerminalRequest struct {
+	MerchantID uint64 `json:"-"`
+	OutletID   uint64 `json:"-"`
+	TerminalID string `json:"terminal_id" validate:"required,alphanum,max=16"`
+}
+
+type TerminalListRequest struct {
+	MerchantID uint64 `query:"-"`
+	OutletID   uint64 `query:"outlet_id"`
+}
+
+type TerminalBindRequest struct {
+	MerchantID   uint64 `json:"-"`
+	TerminalID   string `json:"-"`
+	DeviceSerial string `json:"device_serial" validate:"required,max=100"`
+}
+
+type OutletResponse struct {
+	ID          uint64   `json:"id"`
+	MerchantID  uint64   `json:"merchant_id"`
+	Name        string   `json:"name"`
+	Address     string   `json:"address"`
+	PostalCode  string   `json:"postal_code"`
+	Province    string   `json:"province"`
+	District    string   `json:"district"`
+	SubDistrict string   `json:"subdistrict"`
+	City        string   `json:"city"`
+	Latitude    *float64 `json:"latitude"`
+	Longitude   *float64 `json:"longitude"`
+	Status      string   `json:"status"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+type TerminalResponse struct {
+	ID            uint64 `json:"id"`
+	MerchantID    uint64 `json:"merchant_id"`
+	OutletID      uint64 `json:"outlet_id"`
+	TerminalID    string `json:"terminal_id"`
+	DeviceSerial  string `json:"device_serial,omitempty"`
+	BindingStatus string `json:"binding_status"`
+	BoundAt       string `json:"bound_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
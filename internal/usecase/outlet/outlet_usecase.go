@@ -0,0 +1,388 @@
+package usecase_outlet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/outlet/entity"
+	errWrap "github.com/pkg/errors"
+)
+
+type OutletUseCase struct {
+	logUseCase   usecase_log.ILogUseCase
+	outletRepo   mysql.IOutletRepository
+	merchantRepo mysql.IMerchantRepository
+}
+
+func NewOutletUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	outletRepo mysql.IOutletRepository,
+	merchantRepo mysql.IMerchantRepository,
+) *OutletUseCase {
+	return &OutletUseCase{
+		logUseCase:   logUseCase,
+		outletRepo:   outletRepo,
+		merchantRepo: merchantRepo,
+	}
+}
+
+// IOutletUseCase manages the outlets of a merchant and the terminals in them. QRs are
+// generated per terminal, so a terminal needs a bound device in an active outlet
+// before it can take payments.
+type IOutletUseCase interface {
+	CreateOutlet(ctx context.Context, req *entity.OutletRequest) (*entity.OutletResponse, error)
+	ListOutlets(ctx context.Context, merchantID uint64) ([]*entity.OutletResponse, error)
+	UpdateOutlet(ctx context.Context, req *entity.OutletRequest) (*entity.OutletResponse, error)
+	CreateTerminal(ctx context.Context, req *entity.TerminalRequest) (*entity.TerminalResponse, error)
+	ListTerminals(ctx context.Context, req *entity.TerminalListRequest) ([]*entity.TerminalResponse, error)
+	BindTerminal(ctx context.Context, req *entity.TerminalBindRequest) (*entity.TerminalResponse, error)
+	UnbindTerminal(ctx context.Context, merchantID uint64, terminalID string) (*entity.TerminalResponse, error)
+	BlockTerminal(ctx context.Context, merchantID uint64, terminalID string) (*entity.TerminalResponse, error)
+}
+
+func (u *OutletUseCase) CreateOutlet(ctx context.Context, req *entity.OutletRequest) (*entity.OutletResponse, error) {
+	funcName := "OutletUseCase.CreateOutlet"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, req.MerchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	outlet := &mEntity.OutletEntity{
+		MerchantID:  req.MerchantID,
+		Name:        req.Name,
+		Address:     req.Address,
+		PostalCode:  req.PostalCode,
+		Province:    req.Province,
+		District:    req.District,
+		SubDistrict: req.SubDistrict,
+		City:        req.City,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		Status:      mEntity.OutletStatusActive,
+	}
+	if err := u.outletRepo.CreateOutlet(ctx, nil, outlet); err != nil {
+		u.logUseCase.Error("outletRepo.CreateOutlet", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toOutletResponse(outlet), nil
+}
+
+func (u *OutletUseCase) ListOutlets(ctx context.Context, merchantID uint64) ([]*entity.OutletResponse, error) {
+	funcName := "OutletUseCase.ListOutlets"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, merchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	outlets, err := u.outletRepo.FindOutletsByMerchantID(ctx, merchantID)
+	if err != nil {
+		u.logUseCase.Error("outletRepo.FindOutletsByMerchantID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	responses := make([]*entity.OutletResponse, 0, len(outlets))
+	for i := range outlets {
+		responses = append(responses, toOutletResponse(&outlets[i]))
+	}
+	return responses, nil
+}
+
+// UpdateOutlet replaces the outlet details, an inactive outlet keeps its terminals
+// but none of them can generate QRs
+func (u *OutletUseCase) UpdateOutlet(ctx context.Context, req *entity.OutletRequest) (*entity.OutletResponse, error) {
+	funcName := "OutletUseCase.UpdateOutlet"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	outlet, err := u.findOutlet(ctx, req.MerchantID, req.ID)
+	if err != nil {
+		u.logUseCase.Error("OutletUseCase.findOutlet", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	changes := map[string]interface{}{
+		"name":        req.Name,
+		"address":     req.Address,
+		"postal_code": req.PostalCode,
+		"province":    req.Province,
+		"district":    req.District,
+		"subdistrict": req.SubDistrict,
+		"city":        req.City,
+		"latitude":    req.Latitude,
+		"longitude":   req.Longitude,
+	}
+	if req.Status != "" {
+		changes["status"] = req.Status
+	}
+	if err := u.outletRepo.UpdateOutlet(ctx, nil, outlet, changes); err != nil {
+		u.logUseCase.Error("outletRepo.UpdateOutlet", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	outlet, err = u.outletRepo.FindOutletByID(ctx, outlet.ID)
+	if err != nil {
+		u.logUseCase.Error("outletRepo.FindOutletByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	return toOutletResponse(outlet), nil
+}
+
+func (u *OutletUseCase) CreateTerminal(ctx context.Context, req *entity.TerminalRequest) (*entity.TerminalResponse, error) {
+	funcName := "OutletUseCase.CreateTerminal"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	outlet, err := u.findOutlet(ctx, req.MerchantID, req.OutletID)
+	if err != nil {
+		u.logUseCase.Error("OutletUseCase.findOutlet", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	_, err = u.outletRepo.FindTerminalByTerminalID(ctx, req.TerminalID)
+	if err == nil {
+		return nil, apperr.CustomError("terminal ID is already registered", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	} else if !errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		u.logUseCase.Error("outletRepo.FindTerminalByTerminalID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	terminal := &mEntity.TerminalEntity{
+		MerchantID:    outlet.MerchantID,
+		OutletID:      outlet.ID,
+		TerminalID:    req.TerminalID,
+		BindingStatus: mEntity.TerminalBindingUnbound,
+	}
+	if err := u.outletRepo.CreateTerminal(ctx, nil, terminal); err != nil {
+		u.logUseCase.Error("outletRepo.CreateTerminal", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toTerminalResponse(terminal), nil
+}
+
+func (u *OutletUseCase) ListTerminals(ctx context.Context, req *entity.TerminalListRequest) ([]*entity.TerminalResponse, error) {
+	funcName := "OutletUseCase.ListTerminals"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, req.MerchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	terminals, err := u.outletRepo.FindTerminals(ctx, &mEntity.TerminalFilter{
+		MerchantID: req.MerchantID,
+		OutletID:   req.OutletID,
+	})
+	if err != nil {
+		u.logUseCase.Error("outletRepo.FindTerminals", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	responses := make([]*entity.TerminalResponse, 0, len(terminals))
+	for i := range terminals {
+		responses = append(responses, toTerminalResponse(&terminals[i]))
+	}
+	return responses, nil
+}
+
+// BindTerminal pairs a device with an unbound terminal, a device can only be bound
+// to one terminal at a time
+func (u *OutletUseCase) BindTerminal(ctx context.Context, req *entity.TerminalBindRequest) (*entity.TerminalResponse, error) {
+	funcName := "OutletUseCase.BindTerminal"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	bound, err := u.outletRepo.FindTerminalByDeviceSerial(ctx, req.DeviceSerial)
+	if err == nil && bound.TerminalID != req.TerminalID {
+		return nil, apperr.CustomError("device is already bound to another terminal", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	} else if err != nil && !errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		u.logUseCase.Error("outletRepo.FindTerminalByDeviceSerial", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	terminal, err := u.changeBinding(ctx, req.MerchantID, req.TerminalID, func(terminal *mEntity.TerminalEntity) (map[string]interface{}, error) {
+		if terminal.BindingStatus != mEntity.TerminalBindingUnbound {
+			return nil, apperr.CustomError(
+				fmt.Sprintf("terminal is %s, unbind it first", terminal.BindingStatus),
+				generalEntity.BAD_REQUEST_CODE,
+				http.StatusConflict,
+			)
+		}
+		return map[string]interface{}{
+			"device_serial":  req.DeviceSerial,
+			"binding_status": mEntity.TerminalBindingBound,
+			"bound_at":       time.Now(),
+		}, nil
+	})
+	if err != nil {
+		u.logUseCase.Error("OutletUseCase.changeBinding", funcName, err, captureFieldError)
+		return nil, err
+	}
+	return toTerminalResponse(terminal), nil
+}
+
+// UnbindTerminal releases the device of a terminal, it also lifts a block
+func (u *OutletUseCase) UnbindTerminal(ctx context.Context, merchantID uint64, terminalID string) (*entity.TerminalResponse, error) {
+	funcName := "OutletUseCase.UnbindTerminal"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+		"terminalID": terminalID,
+	}
+
+	terminal, err := u.changeBinding(ctx, merchantID, terminalID, func(terminal *mEntity.TerminalEntity) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"device_serial":  nil,
+			"binding_status": mEntity.TerminalBindingUnbound,
+			"bound_at":       nil,
+		}, nil
+	})
+	if err != nil {
+		u.logUseCase.Error("OutletUseCase.changeBinding", funcName, err, captureFieldError)
+		return nil, err
+	}
+	return toTerminalResponse(terminal), nil
+}
+
+// BlockTerminal stops a terminal from generating QRs, e.g. when its device is lost.
+// The device stays recorded so it cannot be bound elsewhere until unbound.
+func (u *OutletUseCase) BlockTerminal(ctx context.Context, merchantID uint64, terminalID string) (*entity.TerminalResponse, error) {
+	funcName := "OutletUseCase.BlockTerminal"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(merchantID),
+		"terminalID": terminalID,
+	}
+
+	terminal, err := u.changeBinding(ctx, merchantID, terminalID, func(terminal *mEntity.TerminalEntity) (map[string]interface{}, error) {
+		return map[string]interface{}{"binding_status": mEntity.TerminalBindingBlocked}, nil
+	})
+	if err != nil {
+		u.logUseCase.Error("OutletUseCase.changeBinding", funcName, err, captureFieldError)
+		return nil, err
+	}
+	return toTerminalResponse(terminal), nil
+}
+
+// changeBinding locks the terminal and applies the changes returned by change
+func (u *OutletUseCase) changeBinding(
+	ctx context.Context,
+	merchantID uint64,
+	terminalID string,
+	change func(*mEntity.TerminalEntity) (map[string]interface{}, error),
+) (*mEntity.TerminalEntity, error) {
+	found, err := u.outletRepo.FindTerminalByTerminalID(ctx, terminalID)
+	if err != nil {
+		return nil, err
+	}
+	if found.MerchantID != merchantID {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	var terminal *mEntity.TerminalEntity
+	if err := mysql.DBTransaction(u.outletRepo, func(dbTrx mysql.TrxObj) error {
+		terminal, err = u.outletRepo.LockTerminalByID(ctx, dbTrx, found.ID)
+		if err != nil {
+			return err
+		}
+		changes, err := change(terminal)
+		if err != nil {
+			return err
+		}
+		return u.outletRepo.UpdateTerminal(ctx, dbTrx, terminal, changes)
+	}); err != nil {
+		return nil, err
+	}
+
+	return u.outletRepo.FindTerminalByTerminalID(ctx, terminalID)
+}
+
+// findOutlet returns the outlet when it belongs to the merchant
+func (u *OutletUseCase) findOutlet(ctx context.Context, merchantID uint64, outletID uint64) (*mEntity.OutletEntity, error) {
+	outlet, err := u.outletRepo.FindOutletByID(ctx, outletID)
+	if err != nil {
+		return nil, err
+	}
+	if outlet.MerchantID != merchantID {
+		return nil, apperr.ErrRecordNotFound()
+	}
+	return outlet, nil
+}
+
+func toOutletResponse(outlet *mEntity.OutletEntity) *entity.OutletResponse {
+	return &entity.OutletResponse{
+		ID:          outlet.ID,
+		MerchantID:  outlet.MerchantID,
+		Name:        outlet.Name,
+		Address:     outlet.Address,
+		PostalCode:  outlet.PostalCode,
+		Province:    outlet.Province,
+		District:    outlet.District,
+		SubDistrict: outlet.SubDistrict,
+		City:        outlet.City,
+		Latitude:    outlet.Latitude,
+		Longitude:   outlet.Longitude,
+		Status:      outlet.Status,
+		CreatedAt:   helper.ConvertToJakartaTime(outlet.CreatedAt),
+		UpdatedAt:   helper.ConvertToJakartaTime(outlet.UpdatedAt),
+	}
+}
+
+func toTerminalResponse(terminal *mEntity.TerminalEntity) *entity.TerminalResponse {
+	response := &entity.TerminalResponse{
+		ID:            terminal.ID,
+		MerchantID:    terminal.MerchantID,
+		OutletID:      terminal.OutletID,
+		TerminalID:    terminal.TerminalID,
+		BindingStatus: terminal.BindingStatus,
+		CreatedAt:     helper.ConvertToJakartaTime(terminal.CreatedAt),
+	}
+	if terminal.DeviceSerial != nil {
+		response.DeviceSerial = *terminal.DeviceSerial
+	}
+	if terminal.BoundAt != nil {
+		response.BoundAt = helper.ConvertToJakartaTime(*terminal.BoundAt)
+	}
+	return response
+}
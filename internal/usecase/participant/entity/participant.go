@@ -0,0 +1,37 @@
+package entity
+
+type ParticipantRequest struct {
+	Code     string   `json:"code" validate:"required,max=50"`
+	Name     string   `json:"name" validate:"required,max=255"`
+	Roles    []string `json:"roles" validate:"required,min=1,dive,oneof=issuer acquirer switch"`
+	IsActive *bool    `json:"is_active"`
+}
+
+type ParticipantUpdateRequest struct {
+	ID       uint64   `json:"-"`
+	Name     string   `json:"name" validate:"required,max=255"`
+	Roles    []string `json:"roles" validate:"required,min=1,dive,oneof=issuer acquirer switch"`
+	IsActive *bool    `json:"is_active" validate:"required"`
+}
+
+type ParticipantListRequest struct {
+	Role   string `query:"role" validate:"omitempty,oneof=issuer acquirer switch"`
+	Active string `query:"active" validate:"omitempty,oneof=true false"`
+	Search string `query:"q"`
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// ParticipantResponse only carries the client secret when it was just generated,
+// it cannot be read back afterwards
+type ParticipantResponse struct {
+	ID           uint64   `json:"id"`
+	Code         string   `json:"code"`
+	Name         string   `json:"name"`
+	Roles        []string `json:"roles"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	IsActive     bool     `json:"is_active"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
+}
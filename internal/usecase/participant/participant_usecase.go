@@ -0,0 +1,302 @@
+package usecase_participant
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_audit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit"
+	auditEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/participant/entity"
+	errWrap "github.com/pkg/errors"
+)
+
+type ParticipantUseCase struct {
+	logUseCase      usecase_log.ILogUseCase
+	participantRepo mysql.IParticipantRepository
+	transactionRepo mysql.ITransactionRepository
+	auditUseCase    usecase_audit.IAuditUseCase
+}
+
+func NewParticipantUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	participantRepo mysql.IParticipantRepository,
+	transactionRepo mysql.ITransactionRepository,
+	auditUseCase usecase_audit.IAuditUseCase,
+) *ParticipantUseCase {
+	return &ParticipantUseCase{
+		logUseCase:      logUseCase,
+		participantRepo: participantRepo,
+		transactionRepo: transactionRepo,
+		auditUseCase:    auditUseCase,
+	}
+}
+
+type IParticipantUseCase interface {
+	CreateParticipant(ctx context.Context, req *entity.ParticipantRequest) (*entity.ParticipantResponse, error)
+	GetParticipant(ctx context.Context, id uint64) (*entity.ParticipantResponse, error)
+	ListParticipants(ctx context.Context, req *entity.ParticipantListRequest) ([]*entity.ParticipantResponse, *generalEntity.PaginationMeta, error)
+	UpdateParticipant(ctx context.Context, req *entity.ParticipantUpdateRequest) (*entity.ParticipantResponse, error)
+	RotateCredentials(ctx context.Context, id uint64) (*entity.ParticipantResponse, error)
+	DeleteParticipant(ctx context.Context, id uint64) error
+}
+
+// CreateParticipant registers an institution and generates the credential it signs
+// inbound calls with. The secret is only returned in this response.
+func (u *ParticipantUseCase) CreateParticipant(ctx context.Context, req *entity.ParticipantRequest) (*entity.ParticipantResponse, error) {
+	funcName := "ParticipantUseCase.CreateParticipant"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	_, err := u.participantRepo.FindByCode(ctx, req.Code)
+	if err == nil {
+		return nil, apperr.CustomError("participant code is already registered", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	} else if !errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		u.logUseCase.Error("participantRepo.FindByCode", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	clientID, err := randomToken(8)
+	if err != nil {
+		u.logUseCase.Error("randomToken", funcName, err, captureFieldError)
+		return nil, err
+	}
+	clientSecret, err := randomToken(32)
+	if err != nil {
+		u.logUseCase.Error("randomToken", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	participant := &mEntity.ParticipantEntity{
+		Code:         req.Code,
+		Name:         req.Name,
+		Roles:        joinRoles(req.Roles),
+		ClientID:     "ptc_" + clientID,
+		ClientSecret: clientSecret,
+		IsActive:     req.IsActive == nil || *req.IsActive,
+	}
+	if err := u.participantRepo.Create(ctx, nil, participant); err != nil {
+		u.logUseCase.Error("participantRepo.Create", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	response := toParticipantResponse(participant)
+	response.ClientSecret = participant.ClientSecret
+	u.auditUseCase.Record(ctx, auditEntity.ActionCreate, auditEntity.EntityParticipant, participant.ID, nil, response)
+	return response, nil
+}
+
+func (u *ParticipantUseCase) GetParticipant(ctx context.Context, id uint64) (*entity.ParticipantResponse, error) {
+	funcName := "ParticipantUseCase.GetParticipant"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	participant, err := u.participantRepo.FindByID(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("participantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toParticipantResponse(participant), nil
+}
+
+func (u *ParticipantUseCase) ListParticipants(ctx context.Context, req *entity.ParticipantListRequest) ([]*entity.ParticipantResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "ParticipantUseCase.ListParticipants"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	filter := &mEntity.ParticipantFilter{
+		Role:   req.Role,
+		Search: req.Search,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	}
+	if req.Active != "" {
+		active := req.Active == "true"
+		filter.IsActive = &active
+	}
+
+	participants, total, err := u.participantRepo.FindAll(ctx, filter)
+	if err != nil {
+		u.logUseCase.Error("participantRepo.FindAll", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	response := make([]*entity.ParticipantResponse, 0, len(participants))
+	for i := range participants {
+		response = append(response, toParticipantResponse(&participants[i]))
+	}
+
+	return response, generalEntity.NewPaginationMeta(page, limit, total), nil
+}
+
+// UpdateParticipant changes the name, roles and active flag. The code is the key
+// transactions refer to and cannot be changed.
+func (u *ParticipantUseCase) UpdateParticipant(ctx context.Context, req *entity.ParticipantUpdateRequest) (*entity.ParticipantResponse, error) {
+	funcName := "ParticipantUseCase.UpdateParticipant"
+	captureFieldError := generalEntity.CaptureFields{
+		"id":      helper.ToString(req.ID),
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	var participant *mEntity.ParticipantEntity
+	if err := mysql.DBTransaction(u.participantRepo, func(dbTrx mysql.TrxObj) error {
+		var err error
+		participant, err = u.participantRepo.LockByID(ctx, dbTrx, req.ID)
+		if err != nil {
+			u.logUseCase.Error("participantRepo.LockByID", funcName, err, captureFieldError)
+			return err
+		}
+
+		changes := map[string]interface{}{
+			"name":      req.Name,
+			"roles":     joinRoles(req.Roles),
+			"is_active": *req.IsActive,
+		}
+		if err := u.participantRepo.Update(ctx, dbTrx, participant, changes); err != nil {
+			u.logUseCase.Error("participantRepo.Update", funcName, err, captureFieldError)
+			return err
+		}
+		participant.Name = req.Name
+		participant.Roles = changes["roles"].(string)
+		participant.IsActive = *req.IsActive
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return toParticipantResponse(participant), nil
+}
+
+// RotateCredentials replaces the client secret, the old one stops working immediately
+func (u *ParticipantUseCase) RotateCredentials(ctx context.Context, id uint64) (*entity.ParticipantResponse, error) {
+	funcName := "ParticipantUseCase.RotateCredentials"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	clientSecret, err := randomToken(32)
+	if err != nil {
+		u.logUseCase.Error("randomToken", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	var participant *mEntity.ParticipantEntity
+	var before *entity.ParticipantResponse
+	if err := mysql.DBTransaction(u.participantRepo, func(dbTrx mysql.TrxObj) error {
+		var err error
+		participant, err = u.participantRepo.LockByID(ctx, dbTrx, id)
+		if err != nil {
+			u.logUseCase.Error("participantRepo.LockByID", funcName, err, captureFieldError)
+			return err
+		}
+		before = toParticipantResponse(participant)
+		before.ClientSecret = participant.ClientSecret
+
+		if err := u.participantRepo.Update(ctx, dbTrx, participant, map[string]interface{}{
+			"client_secret": clientSecret,
+		}); err != nil {
+			u.logUseCase.Error("participantRepo.Update", funcName, err, captureFieldError)
+			return err
+		}
+		participant.ClientSecret = clientSecret
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	response := toParticipantResponse(participant)
+	response.ClientSecret = participant.ClientSecret
+	u.auditUseCase.Record(ctx, auditEntity.ActionRotateCredentials, auditEntity.EntityParticipant, id, before, response)
+	return response, nil
+}
+
+// DeleteParticipant removes a participant no transaction refers to, used participants
+// are kept for reporting and can only be deactivated
+func (u *ParticipantUseCase) DeleteParticipant(ctx context.Context, id uint64) error {
+	funcName := "ParticipantUseCase.DeleteParticipant"
+	captureFieldError := generalEntity.CaptureFields{"id": helper.ToString(id)}
+
+	participant, err := u.participantRepo.FindByID(ctx, id)
+	if err != nil {
+		u.logUseCase.Error("participantRepo.FindByID", funcName, err, captureFieldError)
+		return err
+	}
+
+	used, err := u.transactionRepo.ExistsByParticipantCode(ctx, participant.Code)
+	if err != nil {
+		u.logUseCase.Error("transactionRepo.ExistsByParticipantCode", funcName, err, captureFieldError)
+		return err
+	}
+	if used {
+		return apperr.CustomError("participant has transactions, deactivate it instead", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	}
+
+	if err := u.participantRepo.DeleteByID(ctx, nil, id); err != nil {
+		u.logUseCase.Error("participantRepo.DeleteByID", funcName, err, captureFieldError)
+		return err
+	}
+
+	return nil
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// joinRoles formats roles for the SET column, without duplicates and in a stable order
+func joinRoles(roles []string) string {
+	unique := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		unique[role] = true
+	}
+	sorted := make([]string, 0, len(unique))
+	for role := range unique {
+		sorted = append(sorted, role)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func toParticipantResponse(participant *mEntity.ParticipantEntity) *entity.ParticipantResponse {
+	return &entity.ParticipantResponse{
+		ID:        participant.ID,
+		Code:      participant.Code,
+		Name:      participant.Name,
+		Roles:     strings.Split(participant.Roles, ","),
+		ClientID:  participant.ClientID,
+		IsActive:  participant.IsActive,
+		CreatedAt: helper.ConvertToJakartaDate(participant.CreatedAt),
+		UpdatedAt: helper.ConvertToJakartaDate(participant.UpdatedAt),
+	}
+}
@@ -0,0 +1,54 @@
+package entity
+
+type PayoutBatchRequest struct {
+	BatchDate string `json:"batch_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type PayoutBatchResponse struct {
+	ID          uint64            `json:"id"`
+	BatchDate   string            `json:"batch_date"`
+	FileFormat  string            `json:"file_format"`
+	PayoutCount int64             `json:"payout_count"`
+	TotalAmount float64           `json:"total_amount"`
+	Status      string            `json:"status"`
+	SentAt      string            `json:"sent_at,omitempty"`
+	CompletedAt string            `json:"completed_at,omitempty"`
+	CreatedAt   string            `json:"created_at"`
+	Payouts     []*PayoutResponse `json:"payouts,omitempty"`
+}
+
+type PayoutResponse struct {
+	ID              uint64  `json:"id"`
+	BatchID         uint64  `json:"batch_id"`
+	MerchantID      uint64  `json:"merchant_id"`
+	Reference       string  `json:"reference"`
+	AccountNumber   string  `json:"account_number"`
+	AccountName     string  `json:"account_name"`
+	Amount          float64 `json:"amount"`
+	Status          string  `json:"status"`
+	RejectionReason string  `json:"rejection_reason,omitempty"`
+	SentAt          string  `json:"sent_at,omitempty"`
+	ResolvedAt      string  `json:"resolved_at,omitempty"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+type PayoutListRequest struct {
+	MerchantID uint64 `query:"-"`
+	Page       int    `query:"page" validate:"omitempty,min=1"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// PayoutImportResponse lists the references of a bank return file by outcome.
+// Skipped payouts were already resolved, unknown references do not belong to the batch.
+type PayoutImportResponse struct {
+	Confirmed []string `json:"confirmed"`
+	Rejected  []string `json:"rejected"`
+	Skipped   []string `json:"skipped"`
+	Unknown   []string `json:"unknown"`
+}
+
+// PayoutFile is the disbursement file of a batch on disk
+type PayoutFile struct {
+	Path     string
+	Filename string
+}
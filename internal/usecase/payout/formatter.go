@@ -0,0 +1,198 @@
+package usecase_payout
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+)
+
+// DisbursementResult is one payout line of a file returned by the bank
+type DisbursementResult struct {
+	Reference string
+	Confirmed bool
+	Reason    string
+}
+
+// DisbursementFormatter writes the bank disbursement file of a batch and reads the
+// file the bank returns with the outcome of each payout
+type DisbursementFormatter interface {
+	Extension() string
+	Write(w io.Writer, batch *mEntity.PayoutBatchEntity, payouts []mEntity.PayoutEntity) error
+	ParseReturn(r io.Reader) ([]DisbursementResult, error)
+}
+
+func newDisbursementFormatter(format string, sourceAccount string) (DisbursementFormatter, error) {
+	switch format {
+	case mEntity.PayoutFileFormatCSV:
+		return &csvFormatter{sourceAccount}, nil
+	case mEntity.PayoutFileFormatFixedWidth:
+		return &fixedWidthFormatter{sourceAccount}, nil
+	default:
+		return nil, fmt.Errorf("unsupported disbursement file format %q", format)
+	}
+}
+
+// toCents keeps file amounts exact, DECIMAL(15, 2) values do not survive float formatting everywhere
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// csvFormatter writes one row per payout:
+//
+//	reference,batch_date,source_account,account_number,account_name,amount
+//
+// and reads returns as reference,status,reason where status is confirmed or rejected
+type csvFormatter struct {
+	sourceAccount string
+}
+
+func (f *csvFormatter) Extension() string {
+	return "csv"
+}
+
+func (f *csvFormatter) Write(w io.Writer, batch *mEntity.PayoutBatchEntity, payouts []mEntity.PayoutEntity) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"reference", "batch_date", "source_account", "account_number", "account_name", "amount"}); err != nil {
+		return err
+	}
+
+	batchDate := batch.BatchDate.Format("2006-01-02")
+	for _, payout := range payouts {
+		if err := writer.Write([]string{
+			payout.Reference,
+			batchDate,
+			f.sourceAccount,
+			payout.AccountNumber,
+			payout.AccountName,
+			fmt.Sprintf("%.2f", float64(toCents(payout.Amount))/100),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (f *csvFormatter) ParseReturn(r io.Reader) ([]DisbursementResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]DisbursementResult, 0, len(records))
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(record[0], "reference") {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected reference and status", i+1)
+		}
+
+		result := DisbursementResult{Reference: strings.TrimSpace(record[0])}
+		switch strings.ToLower(strings.TrimSpace(record[1])) {
+		case mEntity.PayoutStatusConfirmed:
+			result.Confirmed = true
+		case mEntity.PayoutStatusRejected:
+		default:
+			return nil, fmt.Errorf("line %d: unknown status %q", i+1, record[1])
+		}
+		if len(record) > 2 {
+			result.Reason = strings.TrimSpace(record[2])
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// fixedWidthFormatter writes the layout most bank host-to-host channels accept:
+//
+//	H | batch date (8) | source account (20) | payout count (6) | total in cents (17)
+//	D | reference (20) | account number (20) | account name (40) | amount in cents (17)
+//	T | payout count (6) | total in cents (17)
+//
+// Returns are read as D | reference (20) | status C or R (1) | reason (40), other lines are skipped
+type fixedWidthFormatter struct {
+	sourceAccount string
+}
+
+func (f *fixedWidthFormatter) Extension() string {
+	return "txt"
+}
+
+func (f *fixedWidthFormatter) Write(w io.Writer, batch *mEntity.PayoutBatchEntity, payouts []mEntity.PayoutEntity) error {
+	var total int64
+	for _, payout := range payouts {
+		total += toCents(payout.Amount)
+	}
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "H%s%s%06d%017d\n", batch.BatchDate.Format("20060102"), fixedText(f.sourceAccount, 20), len(payouts), total)
+	for _, payout := range payouts {
+		fmt.Fprintf(writer, "D%s%s%s%017d\n",
+			fixedText(payout.Reference, 20),
+			fixedText(payout.AccountNumber, 20),
+			fixedText(payout.AccountName, 40),
+			toCents(payout.Amount),
+		)
+	}
+	fmt.Fprintf(writer, "T%06d%017d\n", len(payouts), total)
+
+	return writer.Flush()
+}
+
+func (f *fixedWidthFormatter) ParseReturn(r io.Reader) ([]DisbursementResult, error) {
+	results := make([]DisbursementResult, 0)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if !strings.HasPrefix(text, "D") {
+			continue
+		}
+		if len(text) < 22 {
+			return nil, fmt.Errorf("line %d: detail record too short", line)
+		}
+
+		result := DisbursementResult{Reference: strings.TrimSpace(text[1:21])}
+		switch text[21] {
+		case 'C':
+			result.Confirmed = true
+		case 'R':
+		default:
+			return nil, fmt.Errorf("line %d: unknown status %q", line, text[21:22])
+		}
+		if len(text) > 22 {
+			result.Reason = strings.TrimSpace(text[22:])
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// fixedText cuts or right pads a value with spaces to exactly width characters.
+// Bank channels only take ASCII, other characters are replaced by a space.
+func fixedText(value string, width int) string {
+	ascii := make([]byte, 0, width)
+	for _, char := range value {
+		if len(ascii) == width {
+			break
+		}
+		if char < 0x20 || char > 0x7e {
+			char = ' '
+		}
+		ascii = append(ascii, byte(char))
+	}
+	return string(ascii) + strings.Repeat(" ", width-len(ascii))
+}
@@ -0,0 +1,129 @@
+package usecase_payout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatterTestSuite struct {
+	suite.Suite
+
+	batch   *mEntity.PayoutBatchEntity
+	payouts []mEntity.PayoutEntity
+}
+
+func (s *FormatterTestSuite) SetupTest() {
+	s.batch = &mEntity.PayoutBatchEntity{
+		ID:        1,
+		BatchDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+	s.payouts = []mEntity.PayoutEntity{
+		{Reference: "PO2025070100000001", AccountNumber: "1234567890", AccountName: "Toko Sejahtera", Amount: 150000.5},
+		{Reference: "PO2025070100000002", AccountNumber: "0987654321", AccountName: "Kopi Kenangan Café", Amount: 20000},
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	suite.Run(t, new(FormatterTestSuite))
+}
+
+func (s *FormatterTestSuite) TestCSVWrite() {
+	formatter, err := newDisbursementFormatter(mEntity.PayoutFileFormatCSV, "0011223344")
+	s.Require().NoError(err)
+
+	var buf bytes.Buffer
+	s.Require().NoError(formatter.Write(&buf, s.batch, s.payouts))
+
+	s.Equal(
+		"reference,batch_date,source_account,account_number,account_name,amount\n"+
+			"PO2025070100000001,2025-07-01,0011223344,1234567890,Toko Sejahtera,150000.50\n"+
+			"PO2025070100000002,2025-07-01,0011223344,0987654321,Kopi Kenangan Café,20000.00\n",
+		buf.String(),
+	)
+}
+
+func (s *FormatterTestSuite) TestFixedWidthWrite() {
+	formatter, err := newDisbursementFormatter(mEntity.PayoutFileFormatFixedWidth, "0011223344")
+	s.Require().NoError(err)
+
+	var buf bytes.Buffer
+	s.Require().NoError(formatter.Write(&buf, s.batch, s.payouts))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	s.Require().Len(lines, 4)
+	s.Equal("H202507010011223344          00000200000000017000050", lines[0])
+	s.Equal("DPO2025070100000001  1234567890          Toko Sejahtera                          00000000015000050", lines[1])
+	// Non ASCII characters are blanked so every record keeps its width
+	s.Equal("DPO2025070100000002  0987654321          Kopi Kenangan Caf                       00000000002000000", lines[2])
+	s.Equal("T00000200000000017000050", lines[3])
+}
+
+func (s *FormatterTestSuite) TestParseReturn() {
+	testcases := []struct {
+		name     string
+		format   string
+		file     string
+		expected []DisbursementResult
+		wantErr  bool
+	}{
+		{
+			name:   "csv",
+			format: mEntity.PayoutFileFormatCSV,
+			file:   "reference,status,reason\nPO2025070100000001,confirmed,\nPO2025070100000002,REJECTED,Account closed\n",
+			expected: []DisbursementResult{
+				{Reference: "PO2025070100000001", Confirmed: true},
+				{Reference: "PO2025070100000002", Reason: "Account closed"},
+			},
+		},
+		{
+			name:    "csv unknown status",
+			format:  mEntity.PayoutFileFormatCSV,
+			file:    "PO2025070100000001,pending\n",
+			wantErr: true,
+		},
+		{
+			name:   "fixed width",
+			format: mEntity.PayoutFileFormatFixedWidth,
+			file: "H202507010011223344          00000200000000017000050\r\n" +
+				"DPO2025070100000001  C\r\n" +
+				"DPO2025070100000002  RAccount closed\r\n" +
+				"T00000200000000017000050\r\n",
+			expected: []DisbursementResult{
+				{Reference: "PO2025070100000001", Confirmed: true},
+				{Reference: "PO2025070100000002", Reason: "Account closed"},
+			},
+		},
+		{
+			name:    "fixed width short record",
+			format:  mEntity.PayoutFileFormatFixedWidth,
+			file:    "DPO20250701\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		s.Run(tc.name, func() {
+			formatter, err := newDisbursementFormatter(tc.format, "0011223344")
+			s.Require().NoError(err)
+
+			results, err := formatter.ParseReturn(strings.NewReader(tc.file))
+			if tc.wantErr {
+				s.Error(err)
+				return
+			}
+			s.NoError(err)
+			s.Equal(tc.expected, results)
+		})
+	}
+}
+
+func (s *FormatterTestSuite) TestUnsupportedFormat() {
+	_, err := newDisbursementFormatter("xml", "0011223344")
+	s.Error(err)
+}
@@ -0,0 +1,526 @@
+package usecase_payout
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/config"
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_ledger "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/ledger"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/payout/entity"
+	errWrap "github.com/pkg/errors"
+)
+
+// payoutDirectory is where disbursement files are kept, relative to config.StorageDirectory
+const payoutDirectory = "payouts"
+
+type PayoutUseCase struct {
+	logUseCase    usecase_log.ILogUseCase
+	payoutRepo    mysql.IPayoutRepository
+	ledgerRepo    mysql.ILedgerRepository
+	merchantRepo  mysql.IMerchantRepository
+	ledgerUseCase usecase_ledger.ILedgerUseCase
+	option        *config.PayoutOption
+}
+
+func NewPayoutUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	payoutRepo mysql.IPayoutRepository,
+	ledgerRepo mysql.ILedgerRepository,
+	merchantRepo mysql.IMerchantRepository,
+	ledgerUseCase usecase_ledger.ILedgerUseCase,
+	option *config.PayoutOption,
+) *PayoutUseCase {
+	return &PayoutUseCase{
+		logUseCase:    logUseCase,
+		payoutRepo:    payoutRepo,
+		ledgerRepo:    ledgerRepo,
+		merchantRepo:  merchantRepo,
+		ledgerUseCase: ledgerUseCase,
+		option:        option,
+	}
+}
+
+// IPayoutUseCase pays merchants' available balances out to their bank accounts.
+// A batch moves created -> sent -> completed, each payout in it moves
+// created -> sent -> confirmed or rejected as the bank return file is imported.
+type IPayoutUseCase interface {
+	CreateBatch(ctx context.Context, req *entity.PayoutBatchRequest) (*entity.PayoutBatchResponse, error)
+	GetBatch(ctx context.Context, batchID uint64) (*entity.PayoutBatchResponse, error)
+	GetBatchFile(ctx context.Context, batchID uint64) (*entity.PayoutFile, error)
+	MarkBatchSent(ctx context.Context, batchID uint64) (*entity.PayoutBatchResponse, error)
+	ImportReturnFile(ctx context.Context, batchID uint64, file io.Reader) (*entity.PayoutImportResponse, error)
+	ListMerchantPayouts(ctx context.Context, req *entity.PayoutListRequest) ([]*entity.PayoutResponse, *generalEntity.PaginationMeta, error)
+}
+
+// CreateBatch adds a payout for every merchant whose available balance reaches the
+// minimum payout amount to the batch of the given day (today when empty) and
+// regenerates its disbursement file. Running it again for the same day only adds
+// the merchants not paid yet, a batch already sent is returned unchanged.
+func (u *PayoutUseCase) CreateBatch(ctx context.Context, req *entity.PayoutBatchRequest) (*entity.PayoutBatchResponse, error) {
+	funcName := "PayoutUseCase.CreateBatch"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	batchDate, _ := helper.ParseDate(helper.DateNowJakarta())
+	if req.BatchDate != "" {
+		batchDate, _ = helper.ParseDate(req.BatchDate)
+	}
+
+	batch, err := u.payoutRepo.FindOrCreateBatch(ctx, batchDate, u.option.FileFormat)
+	if err != nil {
+		u.logUseCase.Error("payoutRepo.FindOrCreateBatch", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if batch.Status != mEntity.PayoutBatchStatusCreated {
+		return u.GetBatch(ctx, batch.ID)
+	}
+
+	payouts, err := u.payoutRepo.FindPayoutsByBatchID(ctx, nil, batch.ID)
+	if err != nil {
+		u.logUseCase.Error("payoutRepo.FindPayoutsByBatchID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	paid := make(map[uint64]bool, len(payouts))
+	for _, payout := range payouts {
+		paid[payout.MerchantID] = true
+	}
+
+	balances, err := u.ledgerRepo.FindMerchantBalancesAbove(ctx, mEntity.LedgerAccountMerchantAvailable, u.option.MinAmount)
+	if err != nil {
+		u.logUseCase.Error("ledgerRepo.FindMerchantBalancesAbove", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	for _, balance := range balances {
+		if paid[balance.MerchantID] {
+			continue
+		}
+
+		// A soft deleted merchant is still owed its remaining balance
+		merchant, err := u.merchantRepo.FindByIDWithDeleted(ctx, balance.MerchantID)
+		if err != nil {
+			u.logUseCase.Error("merchantRepo.FindByIDWithDeleted", funcName, err, captureFieldError)
+			return nil, err
+		}
+		beneficiary, err := u.payoutBeneficiary(ctx, merchant)
+		if err != nil {
+			u.logUseCase.Error("PayoutUseCase.payoutBeneficiary", funcName, err, captureFieldError)
+			return nil, err
+		}
+		// Without a bank account there is nowhere to pay, the balance stays available
+		if beneficiary.AccountNumber == "" {
+			continue
+		}
+
+		if err := u.createPayout(ctx, batch, merchant, beneficiary, balance.Balance); err != nil {
+			u.logUseCase.Error("PayoutUseCase.createPayout", funcName, err, captureFieldError)
+			return nil, err
+		}
+	}
+
+	if err := u.writeBatchFile(ctx, batch); err != nil {
+		u.logUseCase.Error("PayoutUseCase.writeBatchFile", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return u.GetBatch(ctx, batch.ID)
+}
+
+// payoutBeneficiary returns the merchant whose bank account receives the payout, the
+// corporate parent for a child merchant that settles at its parent
+func (u *PayoutUseCase) payoutBeneficiary(ctx context.Context, merchant *mEntity.MerchantEntity) (*mEntity.MerchantEntity, error) {
+	if merchant.ParentID == nil || !merchant.SettleAtParent {
+		return merchant, nil
+	}
+	return u.merchantRepo.FindByIDWithDeleted(ctx, *merchant.ParentID)
+}
+
+// createPayout stores the payout and takes its amount off the merchant's available
+// balance in the same DB transaction
+func (u *PayoutUseCase) createPayout(ctx context.Context, batch *mEntity.PayoutBatchEntity, merchant *mEntity.MerchantEntity, beneficiary *mEntity.MerchantEntity, amount float64) error {
+	return mysql.DBTransaction(u.payoutRepo, func(dbTrx mysql.TrxObj) error {
+		// The batch may have been sent since it was read, nothing is added to a sent batch
+		locked, err := u.payoutRepo.LockBatchByID(ctx, dbTrx, batch.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != mEntity.PayoutBatchStatusCreated {
+			return apperr.CustomError("Payout batch has already been sent", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+		}
+
+		payout := &mEntity.PayoutEntity{
+			BatchID:       batch.ID,
+			MerchantID:    merchant.ID,
+			Reference:     fmt.Sprintf("PO%s%08d", batch.BatchDate.Format("20060102"), merchant.ID),
+			AccountNumber: beneficiary.AccountNumber,
+			AccountName:   beneficiary.Name,
+			Amount:        amount,
+			Status:        mEntity.PayoutStatusCreated,
+		}
+		if err := u.payoutRepo.CreatePayout(ctx, dbTrx, payout); err != nil {
+			return err
+		}
+		return u.ledgerUseCase.PostPayout(ctx, dbTrx, merchant.ID, payout.ID, payout.Amount)
+	})
+}
+
+// writeBatchFile writes the disbursement file of all payouts in the batch to a temp
+// file and only moves it to its final name once complete
+func (u *PayoutUseCase) writeBatchFile(ctx context.Context, batch *mEntity.PayoutBatchEntity) error {
+	formatter, err := newDisbursementFormatter(batch.FileFormat, u.option.SourceAccount)
+	if err != nil {
+		return err
+	}
+
+	payouts, err := u.payoutRepo.FindPayoutsByBatchID(ctx, nil, batch.ID)
+	if err != nil {
+		return err
+	}
+
+	directory := filepath.Join(config.StorageDirectory, payoutDirectory)
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return err
+	}
+
+	filePath := filepath.Join(directory, fmt.Sprintf("payout_%s_%d.%s", batch.BatchDate.Format("20060102"), batch.ID, formatter.Extension()))
+	tmpPath := filePath + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	if err := formatter.Write(file, batch, payouts); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return err
+	}
+
+	var totalCents int64
+	for _, payout := range payouts {
+		totalCents += toCents(payout.Amount)
+	}
+	return u.payoutRepo.UpdateBatch(ctx, nil, batch, map[string]interface{}{
+		"file_path":    filePath,
+		"payout_count": len(payouts),
+		"total_amount": float64(totalCents) / 100,
+	})
+}
+
+func (u *PayoutUseCase) GetBatch(ctx context.Context, batchID uint64) (*entity.PayoutBatchResponse, error) {
+	funcName := "PayoutUseCase.GetBatch"
+	captureFieldError := generalEntity.CaptureFields{
+		"batchID": helper.ToString(batchID),
+	}
+
+	batch, err := u.payoutRepo.FindBatchByID(ctx, batchID)
+	if err != nil {
+		u.logUseCase.Error("payoutRepo.FindBatchByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	payouts, err := u.payoutRepo.FindPayoutsByBatchID(ctx, nil, batchID)
+	if err != nil {
+		u.logUseCase.Error("payoutRepo.FindPayoutsByBatchID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	response := toPayoutBatchResponse(batch)
+	response.Payouts = make([]*entity.PayoutResponse, 0, len(payouts))
+	for i := range payouts {
+		response.Payouts = append(response.Payouts, toPayoutResponse(&payouts[i]))
+	}
+	return response, nil
+}
+
+func (u *PayoutUseCase) GetBatchFile(ctx context.Context, batchID uint64) (*entity.PayoutFile, error) {
+	funcName := "PayoutUseCase.GetBatchFile"
+	captureFieldError := generalEntity.CaptureFields{
+		"batchID": helper.ToString(batchID),
+	}
+
+	batch, err := u.payoutRepo.FindBatchByID(ctx, batchID)
+	if err != nil {
+		u.logUseCase.Error("payoutRepo.FindBatchByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if batch.FilePath == "" {
+		return nil, apperr.ErrNotReady()
+	}
+
+	return &entity.PayoutFile{
+		Path:     batch.FilePath,
+		Filename: filepath.Base(batch.FilePath),
+	}, nil
+}
+
+// MarkBatchSent records that the disbursement file has been handed to the bank,
+// the batch and its payouts can no longer change until the bank answers
+func (u *PayoutUseCase) MarkBatchSent(ctx context.Context, batchID uint64) (*entity.PayoutBatchResponse, error) {
+	funcName := "PayoutUseCase.MarkBatchSent"
+	captureFieldError := generalEntity.CaptureFields{
+		"batchID": helper.ToString(batchID),
+	}
+
+	if err := mysql.DBTransaction(u.payoutRepo, func(dbTrx mysql.TrxObj) error {
+		batch, err := u.payoutRepo.LockBatchByID(ctx, dbTrx, batchID)
+		if err != nil {
+			return err
+		}
+		if batch.Status != mEntity.PayoutBatchStatusCreated {
+			return apperr.CustomError("Payout batch has already been sent", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+		}
+		if batch.PayoutCount == 0 {
+			return apperr.CustomError("Payout batch has no payouts", generalEntity.BAD_REQUEST_CODE, http.StatusUnprocessableEntity)
+		}
+
+		sentAt := time.Now()
+		if err := u.payoutRepo.UpdatePayoutsByBatch(ctx, dbTrx, batch.ID, mEntity.PayoutStatusCreated, map[string]interface{}{
+			"status":  mEntity.PayoutStatusSent,
+			"sent_at": sentAt,
+		}); err != nil {
+			return err
+		}
+		return u.payoutRepo.UpdateBatch(ctx, dbTrx, batch, map[string]interface{}{
+			"status":  mEntity.PayoutBatchStatusSent,
+			"sent_at": sentAt,
+		})
+	}); err != nil {
+		u.logUseCase.Error("PayoutUseCase.MarkBatchSent", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return u.GetBatch(ctx, batchID)
+}
+
+// ImportReturnFile applies the outcome of each payout in a bank return file. A
+// confirmed payout leaves the ledger for good, a rejected one goes back to the
+// merchant's available balance. Payouts already resolved are skipped, so the same
+// file can be imported twice. The batch completes once no payout is left waiting.
+func (u *PayoutUseCase) ImportReturnFile(ctx context.Context, batchID uint64, file io.Reader) (*entity.PayoutImportResponse, error) {
+	funcName := "PayoutUseCase.ImportReturnFile"
+	captureFieldError := generalEntity.CaptureFields{
+		"batchID": helper.ToString(batchID),
+	}
+
+	batch, err := u.payoutRepo.FindBatchByID(ctx, batchID)
+	if err != nil {
+		u.logUseCase.Error("payoutRepo.FindBatchByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if batch.Status == mEntity.PayoutBatchStatusCreated {
+		return nil, apperr.CustomError("Payout batch has not been sent yet", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	}
+
+	formatter, err := newDisbursementFormatter(batch.FileFormat, u.option.SourceAccount)
+	if err != nil {
+		u.logUseCase.Error("newDisbursementFormatter", funcName, err, captureFieldError)
+		return nil, err
+	}
+	results, err := formatter.ParseReturn(file)
+	if err != nil {
+		u.logUseCase.Error("formatter.ParseReturn", funcName, err, captureFieldError)
+		return nil, apperr.CustomError(fmt.Sprintf("Invalid return file: %s", err.Error()), generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+
+	response := &entity.PayoutImportResponse{
+		Confirmed: []string{},
+		Rejected:  []string{},
+		Skipped:   []string{},
+		Unknown:   []string{},
+	}
+	for _, result := range results {
+		outcome, err := u.resolvePayout(ctx, batch.ID, result)
+		if err != nil {
+			u.logUseCase.Error("PayoutUseCase.resolvePayout", funcName, err, captureFieldError)
+			return nil, err
+		}
+
+		switch outcome {
+		case mEntity.PayoutStatusConfirmed:
+			response.Confirmed = append(response.Confirmed, result.Reference)
+		case mEntity.PayoutStatusRejected:
+			response.Rejected = append(response.Rejected, result.Reference)
+		case payoutOutcomeSkipped:
+			response.Skipped = append(response.Skipped, result.Reference)
+		default:
+			response.Unknown = append(response.Unknown, result.Reference)
+		}
+	}
+
+	if err := u.completeBatch(ctx, batch.ID); err != nil {
+		u.logUseCase.Error("PayoutUseCase.completeBatch", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return response, nil
+}
+
+const (
+	payoutOutcomeSkipped = "skipped"
+	payoutOutcomeUnknown = "unknown"
+)
+
+// resolvePayout moves one sent payout to confirmed or rejected together with its
+// ledger entry and reports what was done with it
+func (u *PayoutUseCase) resolvePayout(ctx context.Context, batchID uint64, result DisbursementResult) (string, error) {
+	outcome := payoutOutcomeUnknown
+	err := mysql.DBTransaction(u.payoutRepo, func(dbTrx mysql.TrxObj) error {
+		payout, err := u.payoutRepo.LockPayoutByReference(ctx, dbTrx, result.Reference)
+		if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if payout.BatchID != batchID {
+			return nil
+		}
+		if payout.Status != mEntity.PayoutStatusSent {
+			outcome = payoutOutcomeSkipped
+			return nil
+		}
+
+		changes := map[string]interface{}{"resolved_at": time.Now()}
+		if result.Confirmed {
+			changes["status"] = mEntity.PayoutStatusConfirmed
+			err = u.ledgerUseCase.PostPayoutConfirmation(ctx, dbTrx, payout.MerchantID, payout.ID, payout.Amount)
+		} else {
+			changes["status"] = mEntity.PayoutStatusRejected
+			changes["rejection_reason"] = result.Reason
+			err = u.ledgerUseCase.PostPayoutReversal(ctx, dbTrx, payout.MerchantID, payout.ID, payout.Amount)
+		}
+		if err != nil {
+			return err
+		}
+		if err := u.payoutRepo.UpdatePayout(ctx, dbTrx, payout, changes); err != nil {
+			return err
+		}
+
+		outcome = changes["status"].(string)
+		return nil
+	})
+	return outcome, err
+}
+
+func (u *PayoutUseCase) completeBatch(ctx context.Context, batchID uint64) error {
+	return mysql.DBTransaction(u.payoutRepo, func(dbTrx mysql.TrxObj) error {
+		batch, err := u.payoutRepo.LockBatchByID(ctx, dbTrx, batchID)
+		if err != nil {
+			return err
+		}
+		if batch.Status != mEntity.PayoutBatchStatusSent {
+			return nil
+		}
+
+		payouts, err := u.payoutRepo.FindPayoutsByBatchID(ctx, dbTrx, batchID)
+		if err != nil {
+			return err
+		}
+		for _, payout := range payouts {
+			if payout.Status == mEntity.PayoutStatusSent {
+				return nil
+			}
+		}
+
+		return u.payoutRepo.UpdateBatch(ctx, dbTrx, batch, map[string]interface{}{
+			"status":       mEntity.PayoutBatchStatusCompleted,
+			"completed_at": time.Now(),
+		})
+	})
+}
+
+func (u *PayoutUseCase) ListMerchantPayouts(ctx context.Context, req *entity.PayoutListRequest) ([]*entity.PayoutResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "PayoutUseCase.ListMerchantPayouts"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	if _, err := u.merchantRepo.FindByID(ctx, req.MerchantID); err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	payouts, total, err := u.payoutRepo.FindPayoutsByMerchantID(ctx, req.MerchantID, limit, (page-1)*limit)
+	if err != nil {
+		u.logUseCase.Error("payoutRepo.FindPayoutsByMerchantID", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	responses := make([]*entity.PayoutResponse, 0, len(payouts))
+	for i := range payouts {
+		responses = append(responses, toPayoutResponse(&payouts[i]))
+	}
+	return responses, generalEntity.NewPaginationMeta(page, limit, total), nil
+}
+
+func toPayoutBatchResponse(batch *mEntity.PayoutBatchEntity) *entity.PayoutBatchResponse {
+	response := &entity.PayoutBatchResponse{
+		ID:          batch.ID,
+		BatchDate:   batch.BatchDate.Format("2006-01-02"),
+		FileFormat:  batch.FileFormat,
+		PayoutCount: batch.PayoutCount,
+		TotalAmount: batch.TotalAmount,
+		Status:      batch.Status,
+		CreatedAt:   helper.ConvertToJakartaTime(batch.CreatedAt),
+	}
+	if batch.SentAt != nil {
+		response.SentAt = helper.ConvertToJakartaTime(*batch.SentAt)
+	}
+	if batch.CompletedAt != nil {
+		response.CompletedAt = helper.ConvertToJakartaTime(*batch.CompletedAt)
+	}
+	return response
+}
+
+func toPayoutResponse(payout *mEntity.PayoutEntity) *entity.PayoutResponse {
+	response := &entity.PayoutResponse{
+		ID:              payout.ID,
+		BatchID:         payout.BatchID,
+		MerchantID:      payout.MerchantID,
+		Reference:       payout.Reference,
+		AccountNumber:   payout.AccountNumber,
+		AccountName:     payout.AccountName,
+		Amount:          payout.Amount,
+		Status:          payout.Status,
+		RejectionReason: payout.RejectionReason,
+		CreatedAt:       helper.ConvertToJakartaTime(payout.CreatedAt),
+	}
+	if payout.SentAt != nil {
+		response.SentAt = helper.ConvertToJakartaTime(*payout.SentAt)
+	}
+	if payout.ResolvedAt != nil {
+		response.ResolvedAt = helper.ConvertToJakartaTime(*payout.ResolvedAt)
+	}
+	return response
+}
@@ -2,14 +2,41 @@ package entity
 
 type QRRequest struct {
 	MerchantID uint64  `json:"merchant_id"`
+	TerminalID string  `json:"terminal_id"`
 	Amount     float64 `json:"amount"`
 	Currency   string  `json:"currency"`
 	Expiration int64   `json:"expiration"` // in seconds
+	ClientIP   string  `json:"-"`
 }
 
 type QRResponse struct {
 	QRCode     string  `json:"qr_code"`
 	BillingID  string  `json:"billing_id"`
+	TerminalID string  `json:"terminal_id"`
 	Amount     float64 `json:"amount"`
 	Expiration int64   `json:"expiration"` // in seconds
 }
+
+type QREvent struct {
+	Event      string  `json:"event"`
+	BillingID  string  `json:"billing_id"`
+	RefID      string  `json:"reference_id,omitempty"`
+	MerchantID uint64  `json:"merchant_id"`
+	Amount     float64 `json:"amount"`
+	OccurredAt string  `json:"occurred_at"`
+}
+
+// QRSubscription streams the events of a single QR until it reaches a final state.
+// Close must be called once the caller stops reading Events.
+type QRSubscription struct {
+	Current *QREvent
+	Events  <-chan *QREvent
+	Close   func()
+}
+
+type QRCancelResponse struct {
+	BillingID  string  `json:"billing_id"`
+	MerchantID uint64  `json:"merchant_id"`
+	Amount     float64 `json:"amount"`
+	Status     string  `json:"status"`
+}
@@ -4,36 +4,63 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"strings"
 	"time"
 
 	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
 	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis"
 	rEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	usecase_blocklist "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist"
+	bEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist/entity"
+	usecase_limit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/limit"
 	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/qr/entity"
+	errWrap "github.com/pkg/errors"
 )
 
 type QRUseCase struct {
-	logUseCase   usecase_log.ILogUseCase
-	qrRepo       redis.IQRRepository
-	merchantRepo mysql.IMerchantRepository
+	logUseCase       usecase_log.ILogUseCase
+	qrRepo           redis.IQRRepository
+	qrEventRepo      redis.IQREventRepository
+	merchantRepo     mysql.IMerchantRepository
+	transactionRepo  mysql.ITransactionRepository
+	limitUseCase     usecase_limit.ILimitUseCase
+	blocklistUseCase usecase_blocklist.IBlocklistUseCase
+	outletRepo       mysql.IOutletRepository
 }
 
-func NewQRUseCase(logUseCase usecase_log.ILogUseCase, qrRepo redis.IQRRepository, merchantRepo mysql.IMerchantRepository) *QRUseCase {
+func NewQRUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	qrRepo redis.IQRRepository,
+	qrEventRepo redis.IQREventRepository,
+	merchantRepo mysql.IMerchantRepository,
+	transactionRepo mysql.ITransactionRepository,
+	limitUseCase usecase_limit.ILimitUseCase,
+	blocklistUseCase usecase_blocklist.IBlocklistUseCase,
+	outletRepo mysql.IOutletRepository,
+) *QRUseCase {
 	return &QRUseCase{
-		logUseCase:   logUseCase,
-		qrRepo:       qrRepo,
-		merchantRepo: merchantRepo,
+		logUseCase:       logUseCase,
+		qrRepo:           qrRepo,
+		qrEventRepo:      qrEventRepo,
+		merchantRepo:     merchantRepo,
+		transactionRepo:  transactionRepo,
+		limitUseCase:     limitUseCase,
+		blocklistUseCase: blocklistUseCase,
+		outletRepo:       outletRepo,
 	}
 }
 
 type IQRUseCase interface {
 	GenerateQR(ctx context.Context, request entity.QRRequest) (*entity.QRResponse, error)
 	ValidateQR(ctx context.Context, billingID string) (bool, error)
+	SubscribeEvents(ctx context.Context, billingID string) (*entity.QRSubscription, error)
+	CancelQR(ctx context.Context, caller *generalEntity.Caller, billingID string) (*entity.QRCancelResponse, error)
 }
 
 func (u *QRUseCase) GenerateQR(ctx context.Context, request entity.QRRequest) (*entity.QRResponse, error) {
@@ -58,14 +85,41 @@ func (u *QRUseCase) GenerateQR(ctx context.Context, request entity.QRRequest) (*
 		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
 		return nil, err
 	}
-	qrCode := generateQRISPayload(merchant, request)
+	// Only merchants approved through onboarding take payments
+	if merchant.Status != mEntity.MerchantStatusApproved {
+		return nil, apperr.ErrMerchantNotApproved()
+	}
+
+	if err := u.blocklistUseCase.Check(ctx, &bEntity.BlocklistSubject{
+		MerchantID: merchant.ID,
+		NMID:       merchant.NMID,
+		IP:         request.ClientIP,
+	}); err != nil {
+		return nil, err
+	}
+
+	terminal, err := u.terminalForQR(ctx, merchant.ID, request.TerminalID)
+	if err != nil {
+		u.logUseCase.Error("QRUseCase.terminalForQR", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	// Only checked here, the amount is counted once the transaction is created
+	if err := u.limitUseCase.CheckLimits(ctx, merchant.ID, request.Amount); err != nil {
+		return nil, err
+	}
+
+	qrCode := generateQRISPayload(merchant, request, billingID)
 
 	err = u.qrRepo.Create(ctx, &rEntity.QREntity{
 		MerchantID: request.MerchantID,
+		OutletID:   terminal.OutletID,
+		TerminalID: terminal.ID,
 		BillingID:  billingID,
 		Amount:     request.Amount,
 		QRCode:     qrCode,
 		Expiration: request.Expiration,
+		ExpiredAt:  time.Now().Add(time.Duration(request.Expiration) * time.Second).Unix(),
 	})
 	if err != nil {
 		u.logUseCase.Error("qrRepo.Create", funcName, err, captureFieldError)
@@ -76,11 +130,44 @@ func (u *QRUseCase) GenerateQR(ctx context.Context, request entity.QRRequest) (*
 	return &entity.QRResponse{
 		QRCode:     qrCode,
 		BillingID:  billingID,
+		TerminalID: terminal.TerminalID,
 		Amount:     request.Amount,
 		Expiration: request.Expiration,
 	}, nil
 }
 
+// terminalForQR returns the terminal a QR is generated for. It must belong to the
+// merchant, have a bound device and be in an active outlet.
+func (u *QRUseCase) terminalForQR(ctx context.Context, merchantID uint64, terminalID string) (*mEntity.TerminalEntity, error) {
+	if terminalID == "" {
+		return nil, apperr.CustomError("terminal_id is required", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+
+	terminal, err := u.outletRepo.FindTerminalByTerminalID(ctx, terminalID)
+	if errWrap.Is(err, apperr.ErrRecordNotFound()) || (err == nil && terminal.MerchantID != merchantID) {
+		return nil, apperr.CustomError("Unknown terminal for the merchant", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	} else if err != nil {
+		return nil, err
+	}
+	if terminal.BindingStatus != mEntity.TerminalBindingBound {
+		return nil, apperr.CustomError(
+			fmt.Sprintf("Terminal is %s, only bound terminals can generate QRs", terminal.BindingStatus),
+			generalEntity.BAD_REQUEST_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	outlet, err := u.outletRepo.FindOutletByID(ctx, terminal.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	if outlet.Status != mEntity.OutletStatusActive {
+		return nil, apperr.CustomError("Outlet of the terminal is inactive", generalEntity.BAD_REQUEST_CODE, http.StatusUnprocessableEntity)
+	}
+
+	return terminal, nil
+}
+
 func (u *QRUseCase) ValidateQR(ctx context.Context, billingID string) (bool, error) {
 	// Implement QR code validation logic here
 	// This is a placeholder implementation
@@ -97,6 +184,150 @@ func (u *QRUseCase) ValidateQR(ctx context.Context, billingID string) (bool, err
 	return true, nil
 }
 
+// SubscribeEvents opens a stream of payment status events for a pending QR.
+// The stream ends after the first paid, expired or cancelled event; expiry is detected
+// locally from the QR TTL so every instance emits it without a publisher.
+func (u *QRUseCase) SubscribeEvents(ctx context.Context, billingID string) (*entity.QRSubscription, error) {
+	funcName := "QRUseCase.SubscribeEvents"
+	captureFieldError := generalEntity.CaptureFields{"billingID": billingID}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Subscribe before reading the QR so an event published in between is not lost
+	events, closeSub, err := u.qrEventRepo.Subscribe(ctx, billingID)
+	if err != nil {
+		cancel()
+		u.logUseCase.Error("qrEventRepo.Subscribe", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	qr, err := u.qrRepo.GetByBillingID(ctx, billingID)
+	if err != nil {
+		cancel()
+		closeSub()
+		u.logUseCase.Error("qrRepo.GetByBillingID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	out := make(chan *entity.QREvent)
+	go func() {
+		defer close(out)
+		defer closeSub()
+
+		expiry := time.NewTimer(time.Until(time.Unix(qr.ExpiredAt, 0)))
+		defer expiry.Stop()
+
+		var event *entity.QREvent
+		select {
+		case <-ctx.Done():
+			return
+		case <-expiry.C:
+			event = toQREvent(&rEntity.QREventEntity{
+				Event:      rEntity.QREventExpired,
+				BillingID:  qr.BillingID,
+				MerchantID: qr.MerchantID,
+				Amount:     qr.Amount,
+				OccurredAt: time.Now().Unix(),
+			})
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			event = toQREvent(e)
+		}
+
+		// Every event is final for the QR, so the stream ends once it is delivered
+		select {
+		case out <- event:
+		case <-ctx.Done():
+		}
+	}()
+
+	return &entity.QRSubscription{
+		Current: &entity.QREvent{
+			Event:      "pending",
+			BillingID:  qr.BillingID,
+			MerchantID: qr.MerchantID,
+			Amount:     qr.Amount,
+			OccurredAt: helper.DatetimeNowJakartaString(),
+		},
+		Events: out,
+		Close:  cancel,
+	}, nil
+}
+
+// CancelQR invalidates an unpaid QR and tells anyone waiting on it. Cancelling a
+// QR again returns the same result without a second event, a paid QR cannot be cancelled.
+// Only the caller's own merchants' QRs can be cancelled.
+func (u *QRUseCase) CancelQR(ctx context.Context, caller *generalEntity.Caller, billingID string) (*entity.QRCancelResponse, error) {
+	funcName := "QRUseCase.CancelQR"
+	captureFieldError := generalEntity.CaptureFields{"billingID": billingID}
+
+	// A live QR is checked before it is touched, one that is already cancelled is
+	// checked on the tombstone Cancel returns below
+	qr, err := u.qrRepo.GetByBillingID(ctx, billingID)
+	if err != nil && !errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		u.logUseCase.Error("qrRepo.GetByBillingID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if err == nil && !caller.CanActOn(qr.MerchantID) {
+		return nil, apperr.ErrForbidden()
+	}
+
+	paid, err := u.transactionRepo.ExistsByBillingID(ctx, billingID, []string{
+		mEntity.TransactionStatusCompleted,
+		mEntity.TransactionStatusSettled,
+	})
+	if err != nil {
+		u.logUseCase.Error("transactionRepo.ExistsByBillingID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if paid {
+		return nil, apperr.CustomError("QR has already been paid", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	}
+
+	qr, cancelled, err := u.qrRepo.Cancel(ctx, billingID)
+	if err != nil {
+		u.logUseCase.Error("qrRepo.Cancel", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if !caller.CanActOn(qr.MerchantID) {
+		return nil, apperr.ErrForbidden()
+	}
+
+	// Only the call that cancelled the QR notifies, a failed notification is only
+	// logged since the QR is already unpayable
+	if cancelled {
+		if err := u.qrEventRepo.Publish(ctx, &rEntity.QREventEntity{
+			Event:      rEntity.QREventCancelled,
+			BillingID:  qr.BillingID,
+			MerchantID: qr.MerchantID,
+			Amount:     qr.Amount,
+			OccurredAt: time.Now().Unix(),
+		}); err != nil {
+			u.logUseCase.Error("qrEventRepo.Publish", funcName, err, captureFieldError)
+		}
+	}
+
+	return &entity.QRCancelResponse{
+		BillingID:  qr.BillingID,
+		MerchantID: qr.MerchantID,
+		Amount:     qr.Amount,
+		Status:     string(rEntity.QREventCancelled),
+	}, nil
+}
+
+func toQREvent(e *rEntity.QREventEntity) *entity.QREvent {
+	return &entity.QREvent{
+		Event:      string(e.Event),
+		BillingID:  e.BillingID,
+		RefID:      e.RefID,
+		MerchantID: e.MerchantID,
+		Amount:     e.Amount,
+		OccurredAt: helper.ConvertToJakartaTime(time.Unix(e.OccurredAt, 0)),
+	}
+}
+
 func generateRandomID() int64 {
 	// Generate a random ID for the billing ID
 	return time.Now().UnixNano() + rand.Int63n(1000000)
@@ -106,7 +337,7 @@ func formatTag(tag, value string) string {
 	return fmt.Sprintf("%s%02d%s", tag, len(value), value)
 }
 
-func generateQRISPayload(merchant *mEntity.MerchantEntity, request entity.QRRequest) string {
+func generateQRISPayload(merchant *mEntity.MerchantEntity, request entity.QRRequest, billingID string) string {
 	var payload strings.Builder
 
 	// Format standar QRIS static (contoh merchant statis tanpa acquirer spesifik)
@@ -120,28 +351,18 @@ func generateQRISPayload(merchant *mEntity.MerchantEntity, request entity.QRRequ
 	payload.WriteString(formatTag("60", merchant.City))                       // Merchant City
 	payload.WriteString(formatTag("61", "01"))                                // Transaction Type (01 = Payment)
 	// Tag 62 = Additional Data, optional
-	payload.WriteString(formatTag("62", fmt.Sprintf("01%02d%s", len(merchant.MID), merchant.MID))) // Merchant ID
+	// Subtag 01 = Merchant ID, 05 = Reference Label carrying the billing ID the issuer pays,
+	// 07 = Terminal Label carrying the terminal the QR was generated for
+	additional := formatTag("01", merchant.MID) + formatTag("05", billingID)
+	if request.TerminalID != "" {
+		additional += formatTag("07", request.TerminalID)
+	}
+	payload.WriteString(formatTag("62", additional))
 
 	// Append CRC placeholder
 	data := payload.String()
-	crc := calculateCRC(data + "6304") // CRC untuk seluruh data + "6304" (Tag 63 + Length 4)
+	crc := helper.QRISCRC(data + "6304") // CRC untuk seluruh data + "6304" (Tag 63 + Length 4)
 	final := data + "6304" + strings.ToUpper(crc)
 
 	return final
 }
-
-// CRC-16/CCITT-FALSE calculation
-func calculateCRC(input string) string {
-	crc := uint16(0xFFFF)
-	for _, b := range []byte(input) {
-		crc ^= uint16(b) << 8
-		for i := 0; i < 8; i++ {
-			if crc&0x8000 != 0 {
-				crc = (crc << 1) ^ 0x1021
-			} else {
-				crc <<= 1
-			}
-		}
-	}
-	return fmt.Sprintf("%04X", crc)
-}
@@ -0,0 +1,133 @@
+package usecase_qr
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis"
+	rEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// qrRepoStub keeps live QRs and the tombstones of cancelled ones like the Redis repository
+type qrRepoStub struct {
+	redis.IQRRepository
+	live       map[string]*rEntity.QREntity
+	tombstones map[string]*rEntity.QREntity
+}
+
+func (r *qrRepoStub) GetByBillingID(ctx context.Context, billingID string) (*rEntity.QREntity, error) {
+	qr, ok := r.live[billingID]
+	if !ok {
+		return nil, apperr.ErrRecordNotFound()
+	}
+	return qr, nil
+}
+
+func (r *qrRepoStub) Cancel(ctx context.Context, billingID string) (*rEntity.QREntity, bool, error) {
+	if qr, ok := r.live[billingID]; ok {
+		delete(r.live, billingID)
+		r.tombstones[billingID] = qr
+		return qr, true, nil
+	}
+	if qr, ok := r.tombstones[billingID]; ok {
+		return qr, false, nil
+	}
+	return nil, false, apperr.ErrRecordNotFound()
+}
+
+type qrEventRepoStub struct {
+	redis.IQREventRepository
+	events []*rEntity.QREventEntity
+}
+
+func (r *qrEventRepoStub) Publish(ctx context.Context, event *rEntity.QREventEntity) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+type transactionRepoStub struct {
+	mysql.ITransactionRepository
+	paid bool
+}
+
+func (r *transactionRepoStub) ExistsByBillingID(ctx context.Context, billingID string, statuses []string) (bool, error) {
+	return r.paid, nil
+}
+
+type logStub struct {
+	usecase_log.ILogUseCase
+}
+
+func (logStub) Error(process string, funcName string, err error, logFields map[string]string) {}
+
+type QRUseCaseTestSuite struct {
+	suite.Suite
+
+	qrRepo          *qrRepoStub
+	qrEventRepo     *qrEventRepoStub
+	transactionRepo *transactionRepoStub
+	caller          *generalEntity.Caller
+	usecase         *QRUseCase
+}
+
+func (s *QRUseCaseTestSuite) SetupTest() {
+	s.qrRepo = &qrRepoStub{
+		live:       map[string]*rEntity.QREntity{"BILL1": {BillingID: "BILL1", MerchantID: 1, Amount: 10000}},
+		tombstones: map[string]*rEntity.QREntity{},
+	}
+	s.qrEventRepo = &qrEventRepoStub{}
+	s.transactionRepo = &transactionRepoStub{}
+	s.caller = &generalEntity.Caller{ClientID: "merchant-1", MerchantID: 1}
+	s.usecase = NewQRUseCase(logStub{}, s.qrRepo, s.qrEventRepo, nil, s.transactionRepo, nil, nil, nil)
+}
+
+func TestQRUseCase(t *testing.T) {
+	suite.Run(t, new(QRUseCaseTestSuite))
+}
+
+func (s *QRUseCaseTestSuite) TestCancelIsIdempotent() {
+	first, err := s.usecase.CancelQR(context.Background(), s.caller, "BILL1")
+	s.Require().NoError(err)
+	s.Require().Len(s.qrEventRepo.events, 1)
+	s.Equal(rEntity.QREventCancelled, s.qrEventRepo.events[0].Event)
+
+	// A repeated cancel returns the same result without telling the listeners again
+	second, err := s.usecase.CancelQR(context.Background(), s.caller, "BILL1")
+	s.Require().NoError(err)
+	s.Equal(first, second)
+	s.Len(s.qrEventRepo.events, 1)
+}
+
+func (s *QRUseCaseTestSuite) TestCancelRejectsPaidQR() {
+	s.transactionRepo.paid = true
+
+	_, err := s.usecase.CancelQR(context.Background(), s.caller, "BILL1")
+	s.Require().Error(err)
+	s.Equal(http.StatusConflict, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.Contains(s.qrRepo.live, "BILL1")
+	s.Empty(s.qrEventRepo.events)
+}
+
+func (s *QRUseCaseTestSuite) TestCancelRejectsOtherMerchant() {
+	other := &generalEntity.Caller{ClientID: "merchant-2", MerchantID: 2}
+
+	_, err := s.usecase.CancelQR(context.Background(), other, "BILL1")
+	s.Require().Error(err)
+	s.Equal(http.StatusForbidden, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.Contains(s.qrRepo.live, "BILL1")
+
+	// Nor can it read a cancelled QR through a repeated cancel
+	_, err = s.usecase.CancelQR(context.Background(), s.caller, "BILL1")
+	s.Require().NoError(err)
+	_, err = s.usecase.CancelQR(context.Background(), other, "BILL1")
+	s.Require().Error(err)
+	s.Equal(http.StatusForbidden, err.(apperr.CustomErrorResponse).HTTPCode)
+	s.Len(s.qrEventRepo.events, 1)
+}
@@ -0,0 +1,62 @@
+package usecase_qr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/qr/entity"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QRISTestSuite struct {
+	suite.Suite
+}
+
+func TestQRIS(t *testing.T) {
+	suite.Run(t, new(QRISTestSuite))
+}
+
+func (s *QRISTestSuite) TestGeneratedPayloadParses() {
+	merchant := &mEntity.MerchantEntity{MID: "MID001", MCC: "5812", Name: "Kopi Kenangan", City: "Jakarta"}
+	request := entity.QRRequest{MerchantID: 1, TerminalID: "T0001", Amount: 15000, Currency: "360"}
+
+	payload := generateQRISPayload(merchant, request, "ST-1751340000000000000")
+
+	tags, err := helper.ParseQRIS(payload)
+	s.NoError(err)
+	s.Equal("5812", tags["52"])
+	s.Equal("360", tags["53"])
+	s.Equal("15000.00", tags["54"])
+	s.Equal("Kopi Kenangan", tags["59"])
+
+	additional, err := helper.ParseTLV(tags["62"])
+	s.NoError(err)
+	s.Equal("MID001", additional["01"])
+	s.Equal("ST-1751340000000000000", additional["05"])
+	s.Equal("T0001", additional["07"])
+}
+
+func (s *QRISTestSuite) TestParseQRISRejectsInvalidPayloads() {
+	merchant := &mEntity.MerchantEntity{MID: "MID001", MCC: "5812", Name: "Kopi Kenangan", City: "Jakarta"}
+	payload := generateQRISPayload(merchant, entity.QRRequest{Amount: 15000, Currency: "360"}, "ST-1")
+
+	testcases := []struct {
+		name    string
+		payload string
+	}{
+		{name: "tampered amount", payload: strings.Replace(payload, "15000.00", "19000.00", 1)},
+		{name: "missing CRC", payload: payload[:len(payload)-8]},
+		{name: "truncated tag", payload: "0002016304"},
+		{name: "empty", payload: ""},
+	}
+
+	for _, tc := range testcases {
+		s.Run(tc.name, func() {
+			_, err := helper.ParseQRIS(tc.payload)
+			s.Error(err)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package entity
+
+type ReconciliationRequest struct {
+	Format         string `validate:"required"`
+	SettlementDate string `validate:"required,datetime=2006-01-02"`
+	FileName       string `validate:"required"`
+}
+
+type ReconciliationRunResponse struct {
+	ID                  uint64 `json:"id"`
+	SettlementDate      string `json:"settlement_date"`
+	FileFormat          string `json:"file_format"`
+	FileName            string `json:"file_name"`
+	Status              string `json:"status"`
+	TheirCount          int64  `json:"their_count"`
+	OurCount            int64  `json:"our_count"`
+	MatchedCount        int64  `json:"matched_count"`
+	MissingOursCount    int64  `json:"missing_ours_count"`
+	MissingTheirsCount  int64  `json:"missing_theirs_count"`
+	AmountMismatchCount int64  `json:"amount_mismatch_count"`
+	DetailMismatchCount int64  `json:"detail_mismatch_count"`
+	ErrorMessage        string `json:"error_message,omitempty"`
+	CreatedAt           string `json:"created_at"`
+	CompletedAt         string `json:"completed_at,omitempty"`
+}
+
+type ReconciliationExceptionListRequest struct {
+	RunID  uint64 `query:"-"`
+	Result string `query:"result" validate:"omitempty,oneof=missing_ours missing_theirs amount_mismatch detail_mismatch"`
+	Status string `query:"status" validate:"omitempty,oneof=open resolved"`
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type ReconciliationExceptionResponse struct {
+	ID              uint64   `json:"id"`
+	RunID           uint64   `json:"run_id"`
+	TransactionID   *uint64  `json:"transaction_id"`
+	RefID           string   `json:"reference_id"`
+	BillingID       string   `json:"billing_id"`
+	OurAmount       *float64 `json:"our_amount"`
+	TheirAmount     *float64 `json:"their_amount"`
+	TransactionDate string   `json:"transaction_date,omitempty"`
+	Result          string   `json:"result"`
+	Status          string   `json:"status"`
+	ResolutionNote  string   `json:"resolution_note,omitempty"`
+	ResolvedAt      string   `json:"resolved_at,omitempty"`
+}
+
+type ResolveExceptionRequest struct {
+	ID   uint64 `json:"-"`
+	Note string `json:"note" validate:"required,max=255"`
+}
@@ -0,0 +1,110 @@
+package usecase_reconciliation
+
+import (
+	"math"
+
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+)
+
+// match pairs the switch records with our transactions of the same day. A record
+// is paired by reference ID, or by billing ID and date when the switch did not send
+// a reference. Paired rows are matched, an amount mismatch, or a detail mismatch when
+// the switch reports another billing ID or date. Unpaired rows are missing on the
+// side that does not have them.
+func match(ours []mEntity.TransactionEntity, theirs []SettlementRecord) []mEntity.ReconciliationItemEntity {
+	byRef := make(map[string]*mEntity.TransactionEntity, len(ours))
+	byBilling := make(map[string]*mEntity.TransactionEntity, len(ours))
+	for i := range ours {
+		byRef[ours[i].RefID] = &ours[i]
+		if ours[i].BillingID != "" {
+			byBilling[ours[i].BillingID] = &ours[i]
+		}
+	}
+
+	paired := make(map[uint64]bool, len(ours))
+	items := make([]mEntity.ReconciliationItemEntity, 0, len(theirs)+len(ours))
+	for _, record := range theirs {
+		theirAmount := record.Amount
+		theirDate := record.TransactionDate
+		item := mEntity.ReconciliationItemEntity{
+			RefID:           record.RefID,
+			BillingID:       record.BillingID,
+			TheirAmount:     &theirAmount,
+			TransactionDate: &theirDate,
+			Result:          mEntity.ReconciliationMissingOurs,
+			Status:          mEntity.ReconciliationItemOpen,
+		}
+
+		transaction := byRef[record.RefID]
+		if record.RefID == "" {
+			// A billing ID alone does not identify a payment, the date must agree too
+			transaction = byBilling[record.BillingID]
+			if transaction != nil && !sameDay(transaction, &record) {
+				transaction = nil
+			}
+		}
+		if transaction == nil || paired[transaction.ID] {
+			items = append(items, item)
+			continue
+		}
+
+		paired[transaction.ID] = true
+		transactionID := transaction.ID
+		ourAmount := transaction.TotalAmount
+		item.TransactionID = &transactionID
+		item.RefID = transaction.RefID
+		if item.BillingID == "" {
+			item.BillingID = transaction.BillingID
+		}
+		item.OurAmount = &ourAmount
+		switch {
+		case toCents(ourAmount) != toCents(theirAmount):
+			item.Result = mEntity.ReconciliationAmountMismatch
+		case !sameDetails(transaction, &record):
+			// The item keeps the switch's billing ID and date, ours are on the transaction
+			item.Result = mEntity.ReconciliationDetailMismatch
+		default:
+			item.Result = mEntity.ReconciliationMatched
+			item.Status = mEntity.ReconciliationItemResolved
+		}
+		items = append(items, item)
+	}
+
+	for i := range ours {
+		if paired[ours[i].ID] {
+			continue
+		}
+		transactionID := ours[i].ID
+		ourAmount := ours[i].TotalAmount
+		transactionDate := ours[i].TransactionDate
+		items = append(items, mEntity.ReconciliationItemEntity{
+			TransactionID:   &transactionID,
+			RefID:           ours[i].RefID,
+			BillingID:       ours[i].BillingID,
+			OurAmount:       &ourAmount,
+			TransactionDate: &transactionDate,
+			Result:          mEntity.ReconciliationMissingTheirs,
+			Status:          mEntity.ReconciliationItemOpen,
+		})
+	}
+
+	return items
+}
+
+// sameDetails tells whether the switch reports the payment with our billing ID, when
+// it sends one, and on our date
+func sameDetails(transaction *mEntity.TransactionEntity, record *SettlementRecord) bool {
+	if record.BillingID != "" && record.BillingID != transaction.BillingID {
+		return false
+	}
+	return sameDay(transaction, record)
+}
+
+func sameDay(transaction *mEntity.TransactionEntity, record *SettlementRecord) bool {
+	return transaction.TransactionDate.Format("2006-01-02") == record.TransactionDate.Format("2006-01-02")
+}
+
+// toCents compares DECIMAL(15, 2) amounts without float noise
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
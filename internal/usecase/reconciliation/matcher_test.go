@@ -0,0 +1,133 @@
+package usecase_reconciliation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MatcherTestSuite struct {
+	suite.Suite
+}
+
+func TestMatcher(t *testing.T) {
+	suite.Run(t, new(MatcherTestSuite))
+}
+
+func (s *MatcherTestSuite) TestMatch() {
+	day := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	ours := []mEntity.TransactionEntity{
+		{ID: 1, RefID: "REF1", BillingID: "BILL1", TotalAmount: 10000, TransactionDate: day},
+		{ID: 2, RefID: "REF2", BillingID: "BILL2", TotalAmount: 25000.5, TransactionDate: day},
+		{ID: 3, RefID: "REF3", BillingID: "BILL3", TotalAmount: 5000, TransactionDate: day},
+		{ID: 4, RefID: "REF4", BillingID: "BILL4", TotalAmount: 7000, TransactionDate: day},
+		{ID: 5, RefID: "REF5", BillingID: "BILL5", TotalAmount: 8000, TransactionDate: day},
+		{ID: 6, RefID: "REF6", BillingID: "BILL6", TotalAmount: 9000, TransactionDate: day},
+		{ID: 7, RefID: "REF7", BillingID: "BILL7", TotalAmount: 3000, TransactionDate: day},
+	}
+	theirs := []SettlementRecord{
+		{RefID: "REF1", BillingID: "BILL1", Amount: 10000, TransactionDate: day},
+		{RefID: "REF2", BillingID: "BILL2", Amount: 25000, TransactionDate: day},
+		{RefID: "REF9", BillingID: "BILL9", Amount: 1000, TransactionDate: day},
+		// No reference from the switch, paired on the billing ID
+		{BillingID: "BILL4", Amount: 7000, TransactionDate: day},
+		// The reference pairs the rows, the switch reporting them on another day or
+		// under another billing ID is a mismatch rather than two missing rows
+		{RefID: "REF5", BillingID: "BILL5", Amount: 8000, TransactionDate: day.AddDate(0, 0, 1)},
+		{RefID: "REF6", BillingID: "BILL60", Amount: 9000, TransactionDate: day},
+		// Without a reference the billing ID only pairs rows of the same day
+		{BillingID: "BILL7", Amount: 3000, TransactionDate: day.AddDate(0, 0, 1)},
+	}
+
+	items := match(ours, theirs)
+
+	results := make(map[string][]string)
+	for _, item := range items {
+		results[item.Result] = append(results[item.Result], item.RefID)
+	}
+	s.Equal([]string{"REF1", "REF4"}, results[mEntity.ReconciliationMatched])
+	s.Equal([]string{"REF2"}, results[mEntity.ReconciliationAmountMismatch])
+	s.Equal([]string{"REF5", "REF6"}, results[mEntity.ReconciliationDetailMismatch])
+	s.Equal([]string{"REF9", ""}, results[mEntity.ReconciliationMissingOurs])
+	s.Equal([]string{"REF3", "REF7"}, results[mEntity.ReconciliationMissingTheirs])
+
+	for _, item := range items {
+		switch item.Result {
+		case mEntity.ReconciliationMatched:
+			s.Equal(mEntity.ReconciliationItemResolved, item.Status)
+			s.NotNil(item.TransactionID)
+		case mEntity.ReconciliationDetailMismatch:
+			s.Equal(mEntity.ReconciliationItemOpen, item.Status)
+			s.NotNil(item.TransactionID)
+			s.NotNil(item.OurAmount)
+		case mEntity.ReconciliationMissingOurs:
+			s.Equal(mEntity.ReconciliationItemOpen, item.Status)
+			s.Nil(item.TransactionID)
+			s.Nil(item.OurAmount)
+		case mEntity.ReconciliationMissingTheirs:
+			s.Equal(mEntity.ReconciliationItemOpen, item.Status)
+			s.Nil(item.TheirAmount)
+		}
+	}
+}
+
+func (s *MatcherTestSuite) TestDelimitedParser() {
+	testcases := []struct {
+		name     string
+		format   string
+		file     string
+		expected []SettlementRecord
+		wantErr  bool
+	}{
+		{
+			name:   "csv with columns in any order",
+			format: "csv",
+			file:   "amount,reference_id,transaction_date,billing_id,issuer\n10000.00,REF1,2025-07-01 10:00:00,BILL1,BANK A\n",
+			expected: []SettlementRecord{
+				{RefID: "REF1", BillingID: "BILL1", Amount: 10000, TransactionDate: time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:   "pipe separated",
+			format: "psv",
+			file:   "reference_id|billing_id|amount|transaction_date\nREF2||2500.50|2025-07-01\n",
+			expected: []SettlementRecord{
+				{RefID: "REF2", Amount: 2500.5, TransactionDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:    "missing column",
+			format:  "csv",
+			file:    "reference_id,amount,transaction_date\nREF1,100,2025-07-01\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid amount",
+			format:  "csv",
+			file:    "reference_id,billing_id,amount,transaction_date\nREF1,BILL1,abc,2025-07-01\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty file",
+			format:  "csv",
+			file:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		s.Run(tc.name, func() {
+			records, err := settlementParsers[tc.format].Parse(strings.NewReader(tc.file))
+			if tc.wantErr {
+				s.Error(err)
+				return
+			}
+			s.NoError(err)
+			s.Equal(tc.expected, records)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package usecase_reconciliation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SettlementRecord is one transaction as reported in the switch settlement file
+type SettlementRecord struct {
+	RefID           string
+	BillingID       string
+	Amount          float64
+	TransactionDate time.Time
+}
+
+// SettlementParser reads a settlement file in one switch format
+type SettlementParser interface {
+	Parse(r io.Reader) ([]SettlementRecord, error)
+}
+
+// settlementParsers holds the file formats a reconciliation can be run with, keyed
+// by the format name given on upload
+var settlementParsers = map[string]SettlementParser{
+	"csv": &delimitedParser{comma: ','},
+	"psv": &delimitedParser{comma: '|'},
+}
+
+// RegisterParser makes another settlement file format available, a format already
+// registered under the same name is replaced
+func RegisterParser(format string, parser SettlementParser) {
+	settlementParsers[format] = parser
+}
+
+// delimitedParser reads files with a header row naming the columns reference_id,
+// billing_id, amount and transaction_date, in any order. Other columns are ignored.
+type delimitedParser struct {
+	comma rune
+}
+
+var settlementDateLayouts = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00", "2006-01-02"}
+
+func (p *delimitedParser) Parse(r io.Reader) ([]SettlementRecord, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = p.comma
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"reference_id", "billing_id", "amount", "transaction_date"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	records := make([]SettlementRecord, 0)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		amount, err := strconv.ParseFloat(strings.TrimSpace(row[columns["amount"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount %q", line, row[columns["amount"]])
+		}
+		transactionDate, err := parseSettlementDate(strings.TrimSpace(row[columns["transaction_date"]]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid transaction_date %q", line, row[columns["transaction_date"]])
+		}
+
+		records = append(records, SettlementRecord{
+			RefID:           strings.TrimSpace(row[columns["reference_id"]]),
+			BillingID:       strings.TrimSpace(row[columns["billing_id"]]),
+			Amount:          amount,
+			TransactionDate: transactionDate,
+		})
+	}
+	return records, nil
+}
+
+func parseSettlementDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range settlementDateLayouts {
+		var date time.Time
+		if date, err = time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,279 @@
+package usecase_reconciliation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reconciliation/entity"
+	errWrap "github.com/pkg/errors"
+)
+
+// reconciledStatuses are the transaction statuses the switch is expected to settle
+var reconciledStatuses = []string{mEntity.TransactionStatusCompleted, mEntity.TransactionStatusSettled}
+
+type ReconciliationUseCase struct {
+	logUseCase         usecase_log.ILogUseCase
+	reconciliationRepo mysql.IReconciliationRepository
+	transactionRepo    mysql.ITransactionRepository
+}
+
+func NewReconciliationUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	reconciliationRepo mysql.IReconciliationRepository,
+	transactionRepo mysql.ITransactionRepository,
+) *ReconciliationUseCase {
+	return &ReconciliationUseCase{
+		logUseCase:         logUseCase,
+		reconciliationRepo: reconciliationRepo,
+		transactionRepo:    transactionRepo,
+	}
+}
+
+type IReconciliationUseCase interface {
+	Reconcile(ctx context.Context, req *entity.ReconciliationRequest, file io.Reader) (*entity.ReconciliationRunResponse, error)
+	GetRun(ctx context.Context, runID uint64) (*entity.ReconciliationRunResponse, error)
+	ListExceptions(ctx context.Context, req *entity.ReconciliationExceptionListRequest) ([]*entity.ReconciliationExceptionResponse, *generalEntity.PaginationMeta, error)
+	ResolveException(ctx context.Context, req *entity.ResolveExceptionRequest) (*entity.ReconciliationExceptionResponse, error)
+}
+
+// Reconcile compares a switch settlement file with our completed and settled
+// transactions of the settlement date and stores the outcome of every row as a run
+func (u *ReconciliationUseCase) Reconcile(ctx context.Context, req *entity.ReconciliationRequest, file io.Reader) (*entity.ReconciliationRunResponse, error) {
+	funcName := "ReconciliationUseCase.Reconcile"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	parser, ok := settlementParsers[req.Format]
+	if !ok {
+		return nil, apperr.CustomError(
+			fmt.Sprintf("Unsupported settlement file format %q", req.Format),
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	settlementDate, _ := helper.ParseDate(req.SettlementDate)
+	run := &mEntity.ReconciliationRunEntity{
+		SettlementDate: settlementDate,
+		FileFormat:     req.Format,
+		FileName:       req.FileName,
+		Status:         mEntity.ReconciliationRunProcessing,
+	}
+	if err := u.reconciliationRepo.CreateRun(ctx, nil, run); err != nil {
+		u.logUseCase.Error("reconciliationRepo.CreateRun", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	theirs, err := parser.Parse(file)
+	if err != nil {
+		u.logUseCase.Error("parser.Parse", funcName, err, captureFieldError)
+		u.failRun(ctx, run, err)
+		return nil, apperr.CustomError(fmt.Sprintf("Invalid settlement file: %s", err.Error()), generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+
+	ours := make([]mEntity.TransactionEntity, 0)
+	if err := u.transactionRepo.StreamByDate(ctx, settlementDate, settlementDate.AddDate(0, 0, 1), reconciledStatuses, func(transaction *mEntity.TransactionEntity) error {
+		ours = append(ours, *transaction)
+		return nil
+	}); err != nil {
+		u.logUseCase.Error("transactionRepo.StreamByDate", funcName, err, captureFieldError)
+		u.failRun(ctx, run, err)
+		return nil, err
+	}
+
+	items := match(ours, theirs)
+	changes := map[string]interface{}{
+		"status":       mEntity.ReconciliationRunCompleted,
+		"their_count":  len(theirs),
+		"our_count":    len(ours),
+		"completed_at": time.Now(),
+	}
+	counts := map[string]int{}
+	for i := range items {
+		items[i].RunID = run.ID
+		counts[items[i].Result]++
+	}
+	changes["matched_count"] = counts[mEntity.ReconciliationMatched]
+	changes["missing_ours_count"] = counts[mEntity.ReconciliationMissingOurs]
+	changes["missing_theirs_count"] = counts[mEntity.ReconciliationMissingTheirs]
+	changes["amount_mismatch_count"] = counts[mEntity.ReconciliationAmountMismatch]
+	changes["detail_mismatch_count"] = counts[mEntity.ReconciliationDetailMismatch]
+
+	if err := mysql.DBTransaction(u.reconciliationRepo, func(dbTrx mysql.TrxObj) error {
+		if err := u.reconciliationRepo.CreateItems(ctx, dbTrx, items); err != nil {
+			return err
+		}
+		return u.reconciliationRepo.UpdateRun(ctx, dbTrx, run, changes)
+	}); err != nil {
+		u.logUseCase.Error("reconciliationRepo.CreateItems", funcName, err, captureFieldError)
+		u.failRun(ctx, run, err)
+		return nil, err
+	}
+
+	return u.GetRun(ctx, run.ID)
+}
+
+func (u *ReconciliationUseCase) failRun(ctx context.Context, run *mEntity.ReconciliationRunEntity, cause error) {
+	if err := u.reconciliationRepo.UpdateRun(ctx, nil, run, map[string]interface{}{
+		"status":        mEntity.ReconciliationRunFailed,
+		"error_message": cause.Error(),
+	}); err != nil {
+		u.logUseCase.Error("reconciliationRepo.UpdateRun", "ReconciliationUseCase.failRun", err, generalEntity.CaptureFields{
+			"runID": helper.ToString(run.ID),
+		})
+	}
+}
+
+func (u *ReconciliationUseCase) GetRun(ctx context.Context, runID uint64) (*entity.ReconciliationRunResponse, error) {
+	funcName := "ReconciliationUseCase.GetRun"
+	captureFieldError := generalEntity.CaptureFields{
+		"runID": helper.ToString(runID),
+	}
+
+	run, err := u.reconciliationRepo.FindRunByID(ctx, runID)
+	if err != nil {
+		u.logUseCase.Error("reconciliationRepo.FindRunByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	return toRunResponse(run), nil
+}
+
+// ListExceptions pages through the rows of a run that did not match
+func (u *ReconciliationUseCase) ListExceptions(ctx context.Context, req *entity.ReconciliationExceptionListRequest) ([]*entity.ReconciliationExceptionResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "ReconciliationUseCase.ListExceptions"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	if _, err := u.reconciliationRepo.FindRunByID(ctx, req.RunID); err != nil {
+		u.logUseCase.Error("reconciliationRepo.FindRunByID", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	items, total, err := u.reconciliationRepo.FindExceptions(ctx, &mEntity.ReconciliationItemFilter{
+		RunID:  req.RunID,
+		Result: req.Result,
+		Status: req.Status,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		u.logUseCase.Error("reconciliationRepo.FindExceptions", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	responses := make([]*entity.ReconciliationExceptionResponse, 0, len(items))
+	for i := range items {
+		responses = append(responses, toExceptionResponse(&items[i]))
+	}
+	return responses, generalEntity.NewPaginationMeta(page, limit, total), nil
+}
+
+// ResolveException closes an exception once ops has dealt with it, the note says how
+func (u *ReconciliationUseCase) ResolveException(ctx context.Context, req *entity.ResolveExceptionRequest) (*entity.ReconciliationExceptionResponse, error) {
+	funcName := "ReconciliationUseCase.ResolveException"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	item, err := u.reconciliationRepo.FindItemByID(ctx, req.ID)
+	if err != nil {
+		u.logUseCase.Error("reconciliationRepo.FindItemByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	// Matched rows are not exceptions, there is nothing to resolve
+	if item.Result == mEntity.ReconciliationMatched {
+		return nil, apperr.ErrRecordNotFound()
+	}
+	if item.Status == mEntity.ReconciliationItemResolved {
+		return nil, apperr.CustomError("Exception has already been resolved", generalEntity.BAD_REQUEST_CODE, http.StatusConflict)
+	}
+
+	resolvedAt := time.Now()
+	if err := u.reconciliationRepo.UpdateItem(ctx, nil, item, map[string]interface{}{
+		"status":          mEntity.ReconciliationItemResolved,
+		"resolution_note": req.Note,
+		"resolved_at":     resolvedAt,
+	}); err != nil {
+		u.logUseCase.Error("reconciliationRepo.UpdateItem", funcName, err, captureFieldError)
+		return nil, err
+	}
+	item.Status = mEntity.ReconciliationItemResolved
+	item.ResolutionNote = req.Note
+	item.ResolvedAt = &resolvedAt
+
+	return toExceptionResponse(item), nil
+}
+
+func toRunResponse(run *mEntity.ReconciliationRunEntity) *entity.ReconciliationRunResponse {
+	response := &entity.ReconciliationRunResponse{
+		ID:                  run.ID,
+		SettlementDate:      run.SettlementDate.Format("2006-01-02"),
+		FileFormat:          run.FileFormat,
+		FileName:            run.FileName,
+		Status:              run.Status,
+		TheirCount:          run.TheirCount,
+		OurCount:            run.OurCount,
+		MatchedCount:        run.MatchedCount,
+		MissingOursCount:    run.MissingOursCount,
+		MissingTheirsCount:  run.MissingTheirsCount,
+		AmountMismatchCount: run.AmountMismatchCount,
+		DetailMismatchCount: run.DetailMismatchCount,
+		ErrorMessage:        run.ErrorMessage,
+		CreatedAt:           helper.ConvertToJakartaTime(run.CreatedAt),
+	}
+	if run.CompletedAt != nil {
+		response.CompletedAt = helper.ConvertToJakartaTime(*run.CompletedAt)
+	}
+	return response
+}
+
+func toExceptionResponse(item *mEntity.ReconciliationItemEntity) *entity.ReconciliationExceptionResponse {
+	response := &entity.ReconciliationExceptionResponse{
+		ID:             item.ID,
+		RunID:          item.RunID,
+		TransactionID:  item.TransactionID,
+		RefID:          item.RefID,
+		BillingID:      item.BillingID,
+		OurAmount:      item.OurAmount,
+		TheirAmount:    item.TheirAmount,
+		Result:         item.Result,
+		Status:         item.Status,
+		ResolutionNote: item.ResolutionNote,
+	}
+	if item.TransactionDate != nil {
+		response.TransactionDate = item.TransactionDate.Format("2006-01-02")
+	}
+	if item.ResolvedAt != nil {
+		response.ResolvedAt = helper.ConvertToJakartaTime(*item.ResolvedAt)
+	}
+	return response
+}
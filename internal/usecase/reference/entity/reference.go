@@ -0,0 +1,59 @@
+package entity
+
+type MCCListRequest struct {
+	Search string `query:"q" validate:"omitempty,max=100"`
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type MCCResponse struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// RegionListRequest lists the children of ParentCode, or the provinces when no
+// parent is given
+type RegionListRequest struct {
+	Level      string `query:"level" validate:"omitempty,oneof=province city district subdistrict"`
+	ParentCode string `query:"parent_code" validate:"omitempty,max=13"`
+	Search     string `query:"q" validate:"omitempty,max=100"`
+}
+
+type RegionResponse struct {
+	Code       string  `json:"code"`
+	ParentCode *string `json:"parent_code"`
+	Level      string  `json:"level"`
+	Name       string  `json:"name"`
+	PostalCode *string `json:"postal_code,omitempty"`
+}
+
+type RegionRef struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// PostalCodeAreaResponse is a subdistrict served by a postal code together with the
+// regions above it, enough to fill in every region field of a form
+type PostalCodeAreaResponse struct {
+	PostalCode  string    `json:"postal_code"`
+	Province    RegionRef `json:"province"`
+	City        RegionRef `json:"city"`
+	District    RegionRef `json:"district"`
+	SubDistrict RegionRef `json:"subdistrict"`
+}
+
+// MerchantReference holds the fields of a merchant that must match the reference
+// data, empty region fields are not checked
+type MerchantReference struct {
+	MCC         string
+	Province    string
+	City        string
+	District    string
+	SubDistrict string
+	PostalCode  string
+}
+
+type SeedResult struct {
+	MCCs    int `json:"mccs"`
+	Regions int `json:"regions"`
+}
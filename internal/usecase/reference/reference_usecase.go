@@ -0,0 +1,424 @@
+package usecase_reference
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference/entity"
+	errWrap "github.com/pkg/errors"
+)
+
+// seedChunkSize bounds the rows of one upsert statement
+const seedChunkSize = 500
+
+var mccPattern = regexp.MustCompile(`^[0-9]{4}$`)
+
+// regionLevels runs from the widest level down, a region's parent sits one level up
+var regionLevels = []string{
+	mEntity.RegionLevelProvince,
+	mEntity.RegionLevelCity,
+	mEntity.RegionLevelDistrict,
+	mEntity.RegionLevelSubDistrict,
+}
+
+type ReferenceUseCase struct {
+	logUseCase    usecase_log.ILogUseCase
+	referenceRepo mysql.IReferenceRepository
+}
+
+func NewReferenceUseCase(logUseCase usecase_log.ILogUseCase, referenceRepo mysql.IReferenceRepository) *ReferenceUseCase {
+	return &ReferenceUseCase{
+		logUseCase:    logUseCase,
+		referenceRepo: referenceRepo,
+	}
+}
+
+// IReferenceUseCase serves the MCC and region reference data used to fill in and
+// validate a merchant's category and address
+type IReferenceUseCase interface {
+	ListMCCs(ctx context.Context, req *entity.MCCListRequest) ([]*entity.MCCResponse, *generalEntity.PaginationMeta, error)
+	ListRegions(ctx context.Context, req *entity.RegionListRequest) ([]*entity.RegionResponse, error)
+	LookupPostalCode(ctx context.Context, postalCode string) ([]*entity.PostalCodeAreaResponse, error)
+	ValidateMerchantReference(ctx context.Context, ref *entity.MerchantReference) ([]generalEntity.ErrorResponse, error)
+	Seed(ctx context.Context, mccs io.Reader, regions io.Reader) (*entity.SeedResult, error)
+}
+
+func (u *ReferenceUseCase) ListMCCs(ctx context.Context, req *entity.MCCListRequest) ([]*entity.MCCResponse, *generalEntity.PaginationMeta, error) {
+	funcName := "ReferenceUseCase.ListMCCs"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	page, limit := generalEntity.NormalizePage(req.Page, req.Limit)
+	mccs, total, err := u.referenceRepo.FindMCCs(ctx, &mEntity.MCCFilter{
+		Search: req.Search,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		u.logUseCase.Error("referenceRepo.FindMCCs", funcName, err, captureFieldError)
+		return nil, nil, err
+	}
+
+	response := make([]*entity.MCCResponse, 0, len(mccs))
+	for _, mcc := range mccs {
+		response = append(response, &entity.MCCResponse{Code: mcc.Code, Description: mcc.Description})
+	}
+	return response, generalEntity.NewPaginationMeta(page, limit, total), nil
+}
+
+func (u *ReferenceUseCase) ListRegions(ctx context.Context, req *entity.RegionListRequest) ([]*entity.RegionResponse, error) {
+	funcName := "ReferenceUseCase.ListRegions"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	filter := &mEntity.RegionFilter{
+		Level:      req.Level,
+		ParentCode: req.ParentCode,
+		Search:     req.Search,
+	}
+	if filter.ParentCode == "" && filter.Level == "" {
+		filter.Level = mEntity.RegionLevelProvince
+	}
+
+	regions, err := u.referenceRepo.FindRegions(ctx, filter)
+	if err != nil {
+		u.logUseCase.Error("referenceRepo.FindRegions", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	response := make([]*entity.RegionResponse, 0, len(regions))
+	for _, region := range regions {
+		response = append(response, &entity.RegionResponse{
+			Code:       region.Code,
+			ParentCode: region.ParentCode,
+			Level:      region.Level,
+			Name:       region.Name,
+			PostalCode: region.PostalCode,
+		})
+	}
+	return response, nil
+}
+
+// LookupPostalCode returns every subdistrict served by the postal code with the
+// district, city and province above it
+func (u *ReferenceUseCase) LookupPostalCode(ctx context.Context, postalCode string) ([]*entity.PostalCodeAreaResponse, error) {
+	funcName := "ReferenceUseCase.LookupPostalCode"
+	captureFieldError := generalEntity.CaptureFields{"postal_code": postalCode}
+
+	subDistricts, err := u.referenceRepo.FindRegions(ctx, &mEntity.RegionFilter{
+		Level:      mEntity.RegionLevelSubDistrict,
+		PostalCode: postalCode,
+	})
+	if err != nil {
+		u.logUseCase.Error("referenceRepo.FindRegions", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if len(subDistricts) == 0 {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	// Kemendagri codes nest, so the ancestors of 34.71.01.1001 are 34.71.01, 34.71
+	// and 34
+	ancestorCodes := make([]string, 0, len(subDistricts)*3)
+	for _, subDistrict := range subDistricts {
+		parts := strings.Split(subDistrict.Code, ".")
+		for i := 1; i < len(parts); i++ {
+			ancestorCodes = append(ancestorCodes, strings.Join(parts[:i], "."))
+		}
+	}
+	ancestors, err := u.referenceRepo.FindRegionsByCodes(ctx, ancestorCodes)
+	if err != nil {
+		u.logUseCase.Error("referenceRepo.FindRegionsByCodes", funcName, err, captureFieldError)
+		return nil, err
+	}
+	regionByCode := make(map[string]mEntity.RegionEntity, len(ancestors))
+	for _, ancestor := range ancestors {
+		regionByCode[ancestor.Code] = ancestor
+	}
+	ref := func(code *string) entity.RegionRef {
+		if code == nil {
+			return entity.RegionRef{}
+		}
+		region := regionByCode[*code]
+		return entity.RegionRef{Code: region.Code, Name: region.Name}
+	}
+
+	response := make([]*entity.PostalCodeAreaResponse, 0, len(subDistricts))
+	for _, subDistrict := range subDistricts {
+		area := &entity.PostalCodeAreaResponse{
+			PostalCode:  postalCode,
+			SubDistrict: entity.RegionRef{Code: subDistrict.Code, Name: subDistrict.Name},
+		}
+		area.District = ref(subDistrict.ParentCode)
+		area.City = ref(regionByCode[area.District.Code].ParentCode)
+		area.Province = ref(regionByCode[area.City.Code].ParentCode)
+		response = append(response, area)
+	}
+	return response, nil
+}
+
+// ValidateMerchantReference checks the MCC against ISO 18245 and walks the region
+// fields from the province down, each one has to lie within the one above it. A
+// region may be left empty only together with every region below it. Mismatches
+// come back as field errors, the error is only set when the lookup itself failed.
+func (u *ReferenceUseCase) ValidateMerchantReference(ctx context.Context, ref *entity.MerchantReference) ([]generalEntity.ErrorResponse, error) {
+	funcName := "ReferenceUseCase.ValidateMerchantReference"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(ref),
+	}
+
+	var fieldErrors []generalEntity.ErrorResponse
+	if ref.MCC != "" {
+		_, err := u.referenceRepo.FindMCC(ctx, ref.MCC)
+		if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+			fieldErrors = append(fieldErrors, fieldError("MCC", "mcc",
+				fmt.Sprintf("MCC %s is not an ISO 18245 merchant category code", ref.MCC)))
+		} else if err != nil {
+			u.logUseCase.Error("referenceRepo.FindMCC", funcName, err, captureFieldError)
+			return nil, err
+		}
+	}
+
+	regionError, err := u.validateRegion(ctx, ref)
+	if err != nil {
+		u.logUseCase.Error("ReferenceUseCase.validateRegion", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if regionError != nil {
+		fieldErrors = append(fieldErrors, *regionError)
+	}
+	return fieldErrors, nil
+}
+
+// validateRegion stops at the first mismatch, the fields below it cannot be checked
+// against a region that does not exist
+func (u *ReferenceUseCase) validateRegion(ctx context.Context, ref *entity.MerchantReference) (*generalEntity.ErrorResponse, error) {
+	fields := []struct {
+		field string
+		level string
+		value string
+	}{
+		{"Province", mEntity.RegionLevelProvince, ref.Province},
+		{"City", mEntity.RegionLevelCity, ref.City},
+		{"District", mEntity.RegionLevelDistrict, ref.District},
+		{"SubDistrict", mEntity.RegionLevelSubDistrict, ref.SubDistrict},
+	}
+
+	var parent *mEntity.RegionEntity
+	for i, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		if i > 0 && fields[i-1].value == "" {
+			return fieldErrorRef(field.field, "region",
+				fmt.Sprintf("%s %s needs its %s", field.level, field.value, fields[i-1].level)), nil
+		}
+
+		parentCode := ""
+		if parent != nil {
+			parentCode = parent.Code
+		}
+		region, err := u.referenceRepo.FindRegion(ctx, field.level, parentCode, field.value)
+		if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+			message := fmt.Sprintf("unknown %s %s", field.level, field.value)
+			if parent != nil {
+				message = fmt.Sprintf("%s %s is not in %s", field.level, field.value, parent.Name)
+			}
+			return fieldErrorRef(field.field, "region", message), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent = region
+	}
+
+	if ref.PostalCode == "" {
+		return nil, nil
+	}
+	if parent != nil && parent.Level == mEntity.RegionLevelSubDistrict {
+		if parent.PostalCode == nil || *parent.PostalCode != ref.PostalCode {
+			return fieldErrorRef("PostalCode", "postal_code",
+				fmt.Sprintf("postal code %s does not serve %s", ref.PostalCode, parent.Name)), nil
+		}
+		return nil, nil
+	}
+
+	// Without a subdistrict the postal code has to serve one of the subdistricts
+	// under the deepest region given
+	subDistricts, err := u.referenceRepo.FindRegions(ctx, &mEntity.RegionFilter{
+		Level:      mEntity.RegionLevelSubDistrict,
+		PostalCode: ref.PostalCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(subDistricts) == 0 {
+		return fieldErrorRef("PostalCode", "postal_code", fmt.Sprintf("unknown postal code %s", ref.PostalCode)), nil
+	}
+	if parent == nil {
+		return nil, nil
+	}
+	for _, subDistrict := range subDistricts {
+		if strings.HasPrefix(subDistrict.Code, parent.Code+".") {
+			return nil, nil
+		}
+	}
+	return fieldErrorRef("PostalCode", "postal_code",
+		fmt.Sprintf("postal code %s is not in %s", ref.PostalCode, parent.Name)), nil
+}
+
+func fieldError(field string, tag string, message string) generalEntity.ErrorResponse {
+	return generalEntity.ErrorResponse{FailedField: field, Tag: tag, Message: message}
+}
+
+func fieldErrorRef(field string, tag string, message string) *generalEntity.ErrorResponse {
+	fieldErr := fieldError(field, tag, message)
+	return &fieldErr
+}
+
+// Seed upserts the reference data from CSV, mccs with the columns code,description
+// and regions with code,parent_code,level,name,postal_code. Both files are loaded in
+// one transaction so a bad row leaves the tables as they were.
+func (u *ReferenceUseCase) Seed(ctx context.Context, mccs io.Reader, regions io.Reader) (*entity.SeedResult, error) {
+	funcName := "ReferenceUseCase.Seed"
+
+	mccEntities, err := parseMCCs(mccs)
+	if err != nil {
+		return nil, errWrap.Wrap(err, "mccs")
+	}
+	regionEntities, err := parseRegions(regions)
+	if err != nil {
+		return nil, errWrap.Wrap(err, "regions")
+	}
+	captureFieldError := generalEntity.CaptureFields{
+		"mccs":    helper.ToString(len(mccEntities)),
+		"regions": helper.ToString(len(regionEntities)),
+	}
+
+	if err := mysql.DBTransaction(u.referenceRepo, func(dbTrx mysql.TrxObj) error {
+		for start := 0; start < len(mccEntities); start += seedChunkSize {
+			end := min(start+seedChunkSize, len(mccEntities))
+			if err := u.referenceRepo.UpsertMCCs(ctx, dbTrx, mccEntities[start:end]); err != nil {
+				u.logUseCase.Error("referenceRepo.UpsertMCCs", funcName, err, captureFieldError)
+				return err
+			}
+		}
+		for start := 0; start < len(regionEntities); start += seedChunkSize {
+			end := min(start+seedChunkSize, len(regionEntities))
+			if err := u.referenceRepo.UpsertRegions(ctx, dbTrx, regionEntities[start:end]); err != nil {
+				u.logUseCase.Error("referenceRepo.UpsertRegions", funcName, err, captureFieldError)
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &entity.SeedResult{MCCs: len(mccEntities), Regions: len(regionEntities)}, nil
+}
+
+func parseMCCs(reader io.Reader) ([]mEntity.MCCEntity, error) {
+	records, err := readSeedFile(reader, []string{"code", "description"})
+	if err != nil {
+		return nil, err
+	}
+
+	mccs := make([]mEntity.MCCEntity, 0, len(records))
+	for i, record := range records {
+		if !mccPattern.MatchString(record[0]) {
+			return nil, fmt.Errorf("line %d: MCC %q is not 4 digits", i+2, record[0])
+		}
+		mccs = append(mccs, mEntity.MCCEntity{Code: record[0], Description: record[1]})
+	}
+	return mccs, nil
+}
+
+// parseRegions orders the regions by level so every parent is written before its
+// children
+func parseRegions(reader io.Reader) ([]mEntity.RegionEntity, error) {
+	records, err := readSeedFile(reader, []string{"code", "parent_code", "level", "name", "postal_code"})
+	if err != nil {
+		return nil, err
+	}
+
+	levelRank := make(map[string]int, len(regionLevels))
+	for i, level := range regionLevels {
+		levelRank[level] = i
+	}
+
+	regions := make([]mEntity.RegionEntity, 0, len(records))
+	for i, record := range records {
+		line := i + 2
+		region := mEntity.RegionEntity{Code: record[0], Level: record[2], Name: record[3]}
+		rank, ok := levelRank[region.Level]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown level %q", line, region.Level)
+		}
+		if record[1] != "" {
+			region.ParentCode = &record[1]
+		}
+		if (rank == 0) != (region.ParentCode == nil) {
+			return nil, fmt.Errorf("line %d: only provinces have no parent", line)
+		}
+		if record[4] != "" {
+			region.PostalCode = &record[4]
+		}
+		regions = append(regions, region)
+	}
+
+	sort.SliceStable(regions, func(i, j int) bool {
+		return levelRank[regions[i].Level] < levelRank[regions[j].Level]
+	})
+	return regions, nil
+}
+
+// readSeedFile checks the header and returns the trimmed records below it
+func readSeedFile(reader io.Reader, header []string) ([][]string, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = len(header)
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty file")
+	}
+	for i, column := range header {
+		// Spreadsheets often save CSV with a byte order mark
+		if strings.ToLower(strings.TrimSpace(strings.TrimPrefix(records[0][i], "\ufeff"))) != column {
+			return nil, fmt.Errorf("expected header %s", strings.Join(header, ","))
+		}
+	}
+
+	records = records[1:]
+	for _, record := range records {
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,166 @@
+package usecase_reference
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/reference/entity"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type referenceRepoStub struct {
+	mysql.IReferenceRepository
+	mccs    map[string]bool
+	regions []mEntity.RegionEntity
+}
+
+func (r *referenceRepoStub) FindMCC(ctx context.Context, code string) (*mEntity.MCCEntity, error) {
+	if !r.mccs[code] {
+		return nil, apperr.ErrRecordNotFound()
+	}
+	return &mEntity.MCCEntity{Code: code}, nil
+}
+
+func (r *referenceRepoStub) FindRegion(ctx context.Context, level string, parentCode string, name string) (*mEntity.RegionEntity, error) {
+	for i, region := range r.regions {
+		parent := ""
+		if region.ParentCode != nil {
+			parent = *region.ParentCode
+		}
+		if region.Level == level && parent == parentCode && strings.EqualFold(region.Name, name) {
+			return &r.regions[i], nil
+		}
+	}
+	return nil, apperr.ErrRecordNotFound()
+}
+
+func (r *referenceRepoStub) FindRegions(ctx context.Context, filter *mEntity.RegionFilter) ([]mEntity.RegionEntity, error) {
+	var regions []mEntity.RegionEntity
+	for _, region := range r.regions {
+		if region.PostalCode != nil && *region.PostalCode == filter.PostalCode {
+			regions = append(regions, region)
+		}
+	}
+	return regions, nil
+}
+
+func region(code string, parentCode string, level string, name string, postalCode string) mEntity.RegionEntity {
+	regionEntity := mEntity.RegionEntity{Code: code, Level: level, Name: name}
+	if parentCode != "" {
+		regionEntity.ParentCode = &parentCode
+	}
+	if postalCode != "" {
+		regionEntity.PostalCode = &postalCode
+	}
+	return regionEntity
+}
+
+type ReferenceUsecaseTestSuite struct {
+	suite.Suite
+
+	usecase *ReferenceUseCase
+}
+
+func (s *ReferenceUsecaseTestSuite) SetupTest() {
+	s.usecase = NewReferenceUseCase(nil, &referenceRepoStub{
+		mccs: map[string]bool{"5812": true},
+		regions: []mEntity.RegionEntity{
+			region("34", "", mEntity.RegionLevelProvince, "DI Yogyakarta", ""),
+			region("34.71", "34", mEntity.RegionLevelCity, "Kota Yogyakarta", ""),
+			region("34.71.01", "34.71", mEntity.RegionLevelDistrict, "Mantrijeron", ""),
+			region("34.71.01.1001", "34.71.01", mEntity.RegionLevelSubDistrict, "Gedongkiwo", "55142"),
+			region("34.71.01.1002", "34.71.01", mEntity.RegionLevelSubDistrict, "Suryodiningratan", "55141"),
+			region("31", "", mEntity.RegionLevelProvince, "DKI Jakarta", ""),
+			region("31.71", "31", mEntity.RegionLevelCity, "Kota Administrasi Jakarta Pusat", ""),
+			region("31.71.01", "31.71", mEntity.RegionLevelDistrict, "Gambir", ""),
+			region("31.71.01.1001", "31.71.01", mEntity.RegionLevelSubDistrict, "Gambir", "10110"),
+		},
+	})
+}
+
+func TestReferenceUsecase(t *testing.T) {
+	suite.Run(t, new(ReferenceUsecaseTestSuite))
+}
+
+func (s *ReferenceUsecaseTestSuite) TestValidateMerchantReference() {
+	valid := entity.MerchantReference{
+		MCC:         "5812",
+		Province:    "DI Yogyakarta",
+		City:        "kota yogyakarta",
+		District:    "Mantrijeron",
+		SubDistrict: "Gedongkiwo",
+		PostalCode:  "55142",
+	}
+
+	testcases := []struct {
+		name   string
+		modify func(ref *entity.MerchantReference)
+		want   []string
+	}{
+		{name: "consistent", modify: func(ref *entity.MerchantReference) {}},
+		{name: "no region", modify: func(ref *entity.MerchantReference) { *ref = entity.MerchantReference{MCC: "5812"} }},
+		{name: "province only", modify: func(ref *entity.MerchantReference) {
+			*ref = entity.MerchantReference{MCC: "5812", Province: "DKI Jakarta"}
+		}},
+		{name: "unknown mcc", modify: func(ref *entity.MerchantReference) { ref.MCC = "0411" }, want: []string{"MCC"}},
+		{name: "unknown province", modify: func(ref *entity.MerchantReference) { ref.Province = "Yogyakarta" }, want: []string{"Province"}},
+		{name: "city of another province", modify: func(ref *entity.MerchantReference) { ref.Province = "DKI Jakarta" }, want: []string{"City"}},
+		{name: "subdistrict without district", modify: func(ref *entity.MerchantReference) { ref.District = "" }, want: []string{"SubDistrict"}},
+		{name: "postal code of a neighbour", modify: func(ref *entity.MerchantReference) { ref.PostalCode = "55141" }, want: []string{"PostalCode"}},
+		{name: "postal code within district", modify: func(ref *entity.MerchantReference) {
+			ref.SubDistrict = ""
+			ref.PostalCode = "55141"
+		}},
+		{name: "postal code outside district", modify: func(ref *entity.MerchantReference) {
+			ref.SubDistrict = ""
+			ref.PostalCode = "10110"
+		}, want: []string{"PostalCode"}},
+		{name: "unknown postal code", modify: func(ref *entity.MerchantReference) {
+			ref.SubDistrict = ""
+			ref.PostalCode = "99999"
+		}, want: []string{"PostalCode"}},
+		{name: "mcc and region", modify: func(ref *entity.MerchantReference) {
+			ref.MCC = "0411"
+			ref.District = "Gambir"
+		}, want: []string{"MCC", "District"}},
+	}
+
+	for _, tt := range testcases {
+		s.T().Run(tt.name, func(t *testing.T) {
+			ref := valid
+			tt.modify(&ref)
+
+			fieldErrors, err := s.usecase.ValidateMerchantReference(context.Background(), &ref)
+
+			s.NoError(err)
+			fields := make([]string, 0, len(fieldErrors))
+			for _, fieldErr := range fieldErrors {
+				fields = append(fields, fieldErr.FailedField)
+			}
+			if tt.want == nil {
+				s.Empty(fields)
+			} else {
+				s.Equal(tt.want, fields)
+			}
+		})
+	}
+}
+
+func (s *ReferenceUsecaseTestSuite) TestParseRegions() {
+	regions, err := parseRegions(strings.NewReader("code,parent_code,level,name,postal_code\n" +
+		"34.71.01,34.71,district,Mantrijeron,\n" +
+		"34,,province,DI Yogyakarta,\n" +
+		"34.71,34,city,Kota Yogyakarta,\n"))
+
+	s.Require().NoError(err)
+	s.Equal([]string{"34", "34.71", "34.71.01"}, []string{regions[0].Code, regions[1].Code, regions[2].Code})
+	s.Nil(regions[0].ParentCode)
+
+	_, err = parseRegions(strings.NewReader("code,parent_code,level,name,postal_code\n34.71,,city,Kota Yogyakarta,\n"))
+	s.EqualError(err, "line 2: only provinces have no parent")
+}
@@ -12,8 +12,11 @@ type TransactionRequest struct {
 	PaymentMethod string  `json:"payment_method"`
 	Currency      string  `json:"currency"`
 	Type          string  `json:"type"`
+	Issuer        string  `json:"issuer"`
+	Acquirer      string  `json:"acquirer"`
 	CustomerMPAN  string  `json:"customer_mpan"`
 	Status        string  `json:"status"`
+	ClientIP      string  `json:"-"`
 }
 
 type TransactionResponse struct {
@@ -21,6 +24,8 @@ type TransactionResponse struct {
 	RefID           string  `json:"reference_id"`
 	BillingID       string  `json:"billing_id"`
 	MerchantID      uint64  `json:"merchant_id"`
+	OutletID        *uint64 `json:"outlet_id,omitempty"`
+	TerminalID      *uint64 `json:"terminal_id,omitempty"`
 	Amount          float64 `json:"amount"`
 	FeeAmount       float64 `json:"fee_amount"`
 	TotalAmount     float64 `json:"total_amount"`
@@ -38,3 +43,119 @@ type TransactionResponse struct {
 	CreatedAt       string  `json:"created_at"`
 	UpdatedAt       string  `json:"updated_at"`
 }
+
+type TransactionSearchRequest struct {
+	MerchantID    uint64  `query:"-"`
+	OutletID      uint64  `query:"outlet_id"`
+	TerminalID    uint64  `query:"terminal_id"`
+	Status        string  `query:"status" validate:"omitempty,oneof=pending completed settled failed voided"`
+	Type          string  `query:"type" validate:"omitempty,oneof=payment refund"`
+	PaymentMethod string  `query:"payment_method" validate:"omitempty,oneof=credit_card debit_card bank_transfer ewallet"`
+	MinAmount     float64 `query:"min_amount" validate:"omitempty,gte=0"`
+	MaxAmount     float64 `query:"max_amount" validate:"omitempty,gte=0"`
+	DateFrom      string  `query:"date_from" validate:"omitempty,datetime=2006-01-02"`
+	DateTo        string  `query:"date_to" validate:"omitempty,datetime=2006-01-02"`
+	Cursor        string  `query:"cursor"`
+	Limit         int     `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type TransactionSummaryRequest struct {
+	MerchantID  uint64 `query:"-"`
+	From        string `query:"from" validate:"required,datetime=2006-01-02"`
+	To          string `query:"to" validate:"required,datetime=2006-01-02"`
+	Granularity string `query:"granularity" validate:"omitempty,oneof=day week month"`
+	// IncludeChildren consolidates a corporate merchant with its child merchants
+	IncludeChildren bool `query:"include_children"`
+}
+
+type TransactionTotals struct {
+	TransactionCount int64   `json:"transaction_count"`
+	PaymentCount     int64   `json:"payment_count"`
+	RefundCount      int64   `json:"refund_count"`
+	FailedCount      int64   `json:"failed_count"`
+	GrossVolume      float64 `json:"gross_volume"`
+	MDRAmount        float64 `json:"mdr_amount"`
+	RefundVolume     float64 `json:"refund_volume"`
+	NetAmount        float64 `json:"net_amount"`
+}
+
+type TransactionPeriodSummary struct {
+	Period string `json:"period"`
+	TransactionTotals
+}
+
+type TransactionBreakdown struct {
+	Key              string  `json:"key"`
+	Name             string  `json:"name,omitempty"`
+	TransactionCount int64   `json:"transaction_count"`
+	Volume           float64 `json:"volume"`
+	MDRAmount        float64 `json:"mdr_amount"`
+}
+
+type TransactionSummaryResponse struct {
+	MerchantID     uint64                     `json:"merchant_id"`
+	From           string                     `json:"from"`
+	To             string                     `json:"to"`
+	Granularity    string                     `json:"granularity"`
+	Totals         TransactionTotals          `json:"totals"`
+	Series         []TransactionPeriodSummary `json:"series"`
+	PaymentMethods []TransactionBreakdown     `json:"payment_methods"`
+	Issuers        []TransactionBreakdown     `json:"issuers"`
+	Outlets        []TransactionBreakdown     `json:"outlets"`
+	Merchants      []TransactionBreakdown     `json:"merchants,omitempty"`
+}
+
+// TransactionStatusChangedEvent is published on the transaction.status_changed topic
+type TransactionStatusChangedEvent struct {
+	TransactionID uint64 `json:"transaction_id"`
+	RefID         string `json:"reference_id"`
+	BillingID     string `json:"billing_id"`
+	MerchantID    uint64 `json:"merchant_id"`
+	FromStatus    string `json:"from_status"`
+	ToStatus      string `json:"to_status"`
+	Reason        string `json:"reason"`
+	OccurredAt    string `json:"occurred_at"`
+}
+
+// Response codes returned to the switch for a payment notification
+const (
+	NotificationApproved           = "00"
+	NotificationInvalidMerchant    = "03"
+	NotificationInvalidBilling     = "14"
+	NotificationInvalidAmount      = "13"
+	NotificationInvalidParticipant = "15"
+	NotificationRestricted         = "62"
+	NotificationFormatError        = "30"
+	NotificationDuplicate          = "94"
+	NotificationSystemError        = "96"
+)
+
+const (
+	NotificationStatusSuccess = "success"
+	NotificationStatusFailed  = "failed"
+)
+
+// PaymentNotificationRequest is sent by the switch once the issuer debited the customer,
+// or with status failed when the issuer declined the payment
+type PaymentNotificationRequest struct {
+	Status        string  `json:"status" validate:"omitempty,oneof=success failed"`
+	RefID         string  `json:"reference_id" validate:"required,max=100"`
+	BillingID     string  `json:"billing_id" validate:"required,max=100"`
+	Amount        float64 `json:"amount" validate:"required,gt=0"`
+	Currency      string  `json:"currency" validate:"required,len=3"`
+	PaymentMethod string  `json:"payment_method" validate:"required,oneof=credit_card debit_card bank_transfer ewallet"`
+	Issuer        string  `json:"issuer" validate:"required,max=50"`
+	Acquirer      string  `json:"acquirer" validate:"required,max=50"`
+	CustomerMPAN  string  `json:"customer_mpan" validate:"required,max=100"`
+}
+
+// PaymentNotificationResponse is the acknowledgement the switch expects, the response
+// code tells it whether the payment was accepted
+type PaymentNotificationResponse struct {
+	ResponseCode    string `json:"response_code"`
+	ResponseMessage string `json:"response_message"`
+	RefID           string `json:"reference_id,omitempty"`
+	BillingID       string `json:"billing_id,omitempty"`
+	TransactionID   uint64 `json:"transaction_id,omitempty"`
+	TransactionDate string `json:"transaction_date,omitempty"`
+}
@@ -3,39 +3,102 @@ package usecase_transaction
 import (
 	"context"
 	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	generalEntity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/helper"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/queue"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
 	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis"
+	rEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase"
+	usecase_blocklist "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist"
+	bEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/blocklist/entity"
+	usecase_fraud "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud"
+	fEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud/entity"
+	usecase_ledger "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/ledger"
+	usecase_limit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/limit"
 	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
 	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/transaction/entity"
 	errWrap "github.com/pkg/errors"
 )
 
 type TransactionUseCase struct {
-	logUseCase      usecase_log.ILogUseCase
-	transactionRepo mysql.ITransactionRepository
-	qrRepo          redis.IQRRepository
+	logUseCase       usecase_log.ILogUseCase
+	queue            queue.Queue
+	transactionRepo  mysql.ITransactionRepository
+	qrRepo           redis.IQRRepository
+	qrEventRepo      redis.IQREventRepository
+	ledgerUseCase    usecase_ledger.ILedgerUseCase
+	participantRepo  mysql.IParticipantRepository
+	limitUseCase     usecase_limit.ILimitUseCase
+	fraudUseCase     usecase_fraud.IFraudUseCase
+	blocklistUseCase usecase_blocklist.IBlocklistUseCase
+	merchantRepo     mysql.IMerchantRepository
+	outletRepo       mysql.IOutletRepository
 }
 
-func NewTransactionUseCase(logUseCase usecase_log.ILogUseCase, transactionRepo mysql.ITransactionRepository, qrRepo redis.IQRRepository) *TransactionUseCase {
+func NewTransactionUseCase(
+	logUseCase usecase_log.ILogUseCase,
+	queue queue.Queue,
+	transactionRepo mysql.ITransactionRepository,
+	qrRepo redis.IQRRepository,
+	qrEventRepo redis.IQREventRepository,
+	ledgerUseCase usecase_ledger.ILedgerUseCase,
+	participantRepo mysql.IParticipantRepository,
+	limitUseCase usecase_limit.ILimitUseCase,
+	fraudUseCase usecase_fraud.IFraudUseCase,
+	blocklistUseCase usecase_blocklist.IBlocklistUseCase,
+	merchantRepo mysql.IMerchantRepository,
+	outletRepo mysql.IOutletRepository,
+) *TransactionUseCase {
 	return &TransactionUseCase{
-		logUseCase:      logUseCase,
-		transactionRepo: transactionRepo,
-		qrRepo:          qrRepo,
+		logUseCase:       logUseCase,
+		queue:            queue,
+		transactionRepo:  transactionRepo,
+		qrRepo:           qrRepo,
+		qrEventRepo:      qrEventRepo,
+		ledgerUseCase:    ledgerUseCase,
+		participantRepo:  participantRepo,
+		limitUseCase:     limitUseCase,
+		fraudUseCase:     fraudUseCase,
+		blocklistUseCase: blocklistUseCase,
+		merchantRepo:     merchantRepo,
+		outletRepo:       outletRepo,
 	}
 }
 
 type ITransactionUseCase interface {
 	CreateTransaction(ctx context.Context, req *entity.TransactionRequest) (*entity.TransactionResponse, error)
-	GetTransactionsByMerchantID(ctx context.Context, merchantID uint64) ([]*entity.TransactionResponse, error)
+	SearchTransactions(ctx context.Context, req *entity.TransactionSearchRequest) ([]*entity.TransactionResponse, *generalEntity.CursorMeta, error)
 	GetTransactionsByRefID(ctx context.Context, refID string) (*entity.TransactionResponse, error)
+	VoidTransaction(ctx context.Context, refID string) (*entity.TransactionResponse, error)
+	NotifyPayment(ctx context.Context, req *entity.PaymentNotificationRequest) (*entity.PaymentNotificationResponse, error)
+	GetMerchantSummary(ctx context.Context, req *entity.TransactionSummaryRequest) (*entity.TransactionSummaryResponse, error)
+	SettleTransactions(ctx context.Context, cutoff time.Time, batchSize int) (int, error)
+	ExpirePendingTransactions(ctx context.Context, timeouts map[string]time.Duration, defaultTimeout time.Duration, batchSize int) (int, error)
 }
 
+// summaryMaxRangeDays keeps a daily series to a readable size
+const summaryMaxRangeDays = 366
+
+// transactionTypePayment is the type of a customer payment, refunds are created by disputes
+const transactionTypePayment = "payment"
+
+// Reasons recorded in the status history of pending transactions
+const (
+	pendingExpiryReason   = "issuer confirmation timed out"
+	paymentDeclineReason  = "declined by issuer"
+	paymentApproveReason  = "approved by issuer"
+	transactionVoidReason = "voided by merchant"
+)
+
 func (u *TransactionUseCase) CreateTransaction(ctx context.Context, req *entity.TransactionRequest) (*entity.TransactionResponse, error) {
 	funcName := "TransactionUseCase.CreateTransaction"
 	captureFieldError := generalEntity.CaptureFields{
@@ -53,6 +116,33 @@ func (u *TransactionUseCase) CreateTransaction(ctx context.Context, req *entity.
 		return nil, err
 	}
 
+	// Issuer and acquirer are optional until the payment is made, but must be known when given
+	role, err := u.invalidParticipantRole(ctx, req.Issuer, req.Acquirer)
+	if err != nil {
+		u.logUseCase.Error("TransactionUseCase.invalidParticipantRole", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if role != "" {
+		return nil, apperr.CustomError(
+			fmt.Sprintf("unknown or inactive %s", role),
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	// Only the issuer's notification completes a payment, it consumes the QR so it
+	// cannot be paid twice
+	if req.Status == "" {
+		req.Status = mEntity.TransactionStatusPending
+	}
+	if req.Status != mEntity.TransactionStatusPending || req.Type != transactionTypePayment {
+		return nil, apperr.CustomError(
+			"only pending payments can be created",
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
 	qr, err := u.qrRepo.GetByBillingID(ctx, req.BillingID)
 	if err != nil {
 		u.logUseCase.Error("qrRepo.GetByBillingID", funcName, err, captureFieldError)
@@ -64,78 +154,167 @@ func (u *TransactionUseCase) CreateTransaction(ctx context.Context, req *entity.
 		u.logUseCase.Error("qrRepo.GetByBillingID", funcName, err, captureFieldError)
 		return nil, err
 	}
+	if req.MerchantID != qr.MerchantID {
+		return nil, apperr.CustomError(
+			"merchant does not match the QR",
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+	if math.Round(req.TotalAmount*100) != math.Round(qr.Amount*100) {
+		return nil, apperr.CustomError(
+			"amount does not match the QR",
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	// Only merchants approved through onboarding take payments
+	merchant, err := u.merchantRepo.FindByID(ctx, req.MerchantID)
+	if err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if merchant.Status != mEntity.MerchantStatusApproved {
+		return nil, apperr.ErrMerchantNotApproved()
+	}
+
+	if err := u.blocklistUseCase.Check(ctx, &bEntity.BlocklistSubject{
+		CustomerMPAN: req.CustomerMPAN,
+		MerchantID:   merchant.ID,
+		NMID:         merchant.NMID,
+		IP:           req.ClientIP,
+	}); err != nil {
+		return nil, err
+	}
+
+	// Scored before the limits so a blocked transaction does not use them up
+	decision, err := u.fraudUseCase.Evaluate(ctx, &fEntity.FraudSubject{
+		RefID:        req.RefID,
+		BillingID:    req.BillingID,
+		MerchantID:   req.MerchantID,
+		CustomerMPAN: req.CustomerMPAN,
+		Amount:       req.TotalAmount,
+		At:           helper.DatetimeNowJakarta(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if decision.Decision == mEntity.FraudDecisionBlock {
+		if err := u.fraudUseCase.RecordDecision(ctx, nil, decision); err != nil {
+			return nil, err
+		}
+		return nil, apperr.ErrFraudBlocked()
+	}
+
+	consumedAt := helper.DatetimeNowJakarta()
+	if err := u.limitUseCase.ConsumeLimits(ctx, req.MerchantID, req.TotalAmount, consumedAt); err != nil {
+		return nil, err
+	}
 
 	transaction := &mEntity.TransactionEntity{
-		RefID:         req.RefID,
-		BillingID:     req.BillingID,
-		MerchantID:    req.MerchantID,
-		Amount:        req.Amount,
-		FeeAmount:     req.FeeAmount,
-		TotalAmount:   req.TotalAmount,
-		MDRAmount:     req.MDRAmount,
-		MDRPercent:    req.MDRPercent,
-		PaymentMethod: req.PaymentMethod,
-		Currency:      req.Currency,
-		Type:          req.Type,
-		CustomerMPAN:  req.CustomerMPAN,
-		// Issuer:          req.Issuer,
-		// Acquirer:        req.Acquirer,
-		TransactionDate: time.Now(),
+		RefID:           req.RefID,
+		BillingID:       req.BillingID,
+		MerchantID:      req.MerchantID,
+		OutletID:        qrOutletID(qr),
+		TerminalID:      qrTerminalID(qr),
+		Amount:          req.Amount,
+		FeeAmount:       req.FeeAmount,
+		TotalAmount:     req.TotalAmount,
+		MDRAmount:       req.MDRAmount,
+		MDRPercent:      req.MDRPercent,
+		PaymentMethod:   req.PaymentMethod,
+		Currency:        req.Currency,
+		Type:            req.Type,
+		CustomerMPAN:    req.CustomerMPAN,
+		Issuer:          req.Issuer,
+		Acquirer:        req.Acquirer,
+		TransactionDate: consumedAt,
 		Status:          req.Status,
 	}
 
-	if err := u.transactionRepo.Create(ctx, nil, transaction, true); err != nil {
-		helper.LogError("transactionRepo.Create", funcName, err, captureFieldError, "")
+	if err := mysql.DBTransaction(u.transactionRepo, func(dbTrx mysql.TrxObj) error {
+		if err := u.transactionRepo.Create(ctx, dbTrx, transaction, true); err != nil {
+			helper.LogError("transactionRepo.Create", funcName, err, captureFieldError, "")
+			return err
+		}
+		decision.TransactionID = &transaction.ID
+		return u.fraudUseCase.RecordDecision(ctx, dbTrx, decision)
+	}); err != nil {
+		// Nothing was stored, so the transaction must not count against the limits
+		if err := u.limitUseCase.ReleaseLimits(ctx, req.MerchantID, req.TotalAmount, consumedAt); err != nil {
+			u.logUseCase.Error("limitUseCase.ReleaseLimits", funcName, err, captureFieldError)
+		}
 		return nil, err
 	}
 
-	return &entity.TransactionResponse{
-		ID:              transaction.ID,
-		MerchantID:      transaction.MerchantID,
-		RefID:           transaction.RefID,
-		BillingID:       transaction.BillingID,
-		Type:            transaction.Type,
-		Amount:          transaction.Amount,
-		PaymentMethod:   transaction.PaymentMethod,
-		TotalAmount:     transaction.TotalAmount,
-		TransactionDate: helper.ConvertToJakartaDate(transaction.TransactionDate),
-		SettlementDate:  helper.ConvertToJakartaDate(transaction.SettlementDate),
-		Status:          transaction.Status,
-		CreatedAt:       helper.ConvertToJakartaDate(transaction.CreatedAt),
-		UpdatedAt:       helper.ConvertToJakartaDate(transaction.UpdatedAt),
-	}, nil
+	return toTransactionResponse(transaction), nil
 }
 
-func (u *TransactionUseCase) GetTransactionsByMerchantID(ctx context.Context, merchantID uint64) ([]*entity.TransactionResponse, error) {
-	funcName := "TransactionUseCase.GetTransactionsByMerchantID"
-	captureFieldError := generalEntity.CaptureFields{"merchantID": helper.ToString(merchantID)}
+func (u *TransactionUseCase) SearchTransactions(ctx context.Context, req *entity.TransactionSearchRequest) ([]*entity.TransactionResponse, *generalEntity.CursorMeta, error) {
+	funcName := "TransactionUseCase.SearchTransactions"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(req.MerchantID),
+		"payload":    helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	_, limit := generalEntity.NormalizePage(1, req.Limit)
+	filter := &mEntity.TransactionFilter{
+		MerchantID:    req.MerchantID,
+		OutletID:      req.OutletID,
+		TerminalID:    req.TerminalID,
+		Status:        req.Status,
+		Type:          req.Type,
+		PaymentMethod: req.PaymentMethod,
+		MinAmount:     req.MinAmount,
+		MaxAmount:     req.MaxAmount,
+		// Fetch one extra row to know whether another page exists
+		Limit: limit + 1,
+	}
+	if req.DateFrom != "" {
+		dateFrom, _ := helper.ParseDate(req.DateFrom)
+		filter.DateFrom = &dateFrom
+	}
+	if req.DateTo != "" {
+		// The end date is inclusive, so filter up to the start of the next day
+		dateTo, _ := helper.ParseDate(req.DateTo)
+		dateTo = dateTo.AddDate(0, 0, 1)
+		filter.DateTo = &dateTo
+	}
+	if req.Cursor != "" {
+		transactionDate, id, err := helper.DecodeCursor(req.Cursor)
+		if err != nil {
+			u.logUseCase.Error("helper.DecodeCursor", funcName, err, captureFieldError)
+			return nil, nil, apperr.ErrInvalidRequest()
+		}
+		filter.Cursor = &mEntity.TransactionCursor{TransactionDate: transactionDate, ID: id}
+	}
 
-	transactions, err := u.transactionRepo.FindByMerchantID(ctx, merchantID)
+	transactions, err := u.transactionRepo.Search(ctx, filter)
 	if err != nil {
-		u.logUseCase.Error("transactionRepo.FindByMerchantID", funcName, err, captureFieldError)
-		return nil, err
+		u.logUseCase.Error("transactionRepo.Search", funcName, err, captureFieldError)
+		return nil, nil, err
 	}
 
-	var response []*entity.TransactionResponse
-	for _, transaction := range transactions {
-		response = append(response, &entity.TransactionResponse{
-			ID:              transaction.ID,
-			MerchantID:      transaction.MerchantID,
-			RefID:           transaction.RefID,
-			BillingID:       transaction.BillingID,
-			Type:            transaction.Type,
-			Amount:          transaction.Amount,
-			PaymentMethod:   transaction.PaymentMethod,
-			TotalAmount:     transaction.TotalAmount,
-			TransactionDate: helper.ConvertToJakartaDate(transaction.TransactionDate),
-			SettlementDate:  helper.ConvertToJakartaDate(transaction.SettlementDate),
-			Status:          transaction.Status,
-			CreatedAt:       helper.ConvertToJakartaDate(transaction.CreatedAt),
-			UpdatedAt:       helper.ConvertToJakartaDate(transaction.UpdatedAt),
-		})
+	meta := &generalEntity.CursorMeta{Limit: limit}
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		last := transactions[limit-1]
+		meta.HasMore = true
+		meta.NextCursor = helper.EncodeCursor(last.TransactionDate, last.ID)
 	}
 
-	return response, nil
+	response := make([]*entity.TransactionResponse, 0, len(transactions))
+	for i := range transactions {
+		response = append(response, toTransactionResponse(&transactions[i]))
+	}
+
+	return response, meta, nil
 }
 
 func (u *TransactionUseCase) GetTransactionsByRefID(ctx context.Context, refID string) (*entity.TransactionResponse, error) {
@@ -148,19 +327,944 @@ func (u *TransactionUseCase) GetTransactionsByRefID(ctx context.Context, refID s
 		return nil, err
 	}
 
+	return toTransactionResponse(transaction), nil
+}
+
+// VoidTransaction voids a pending transaction and cancels its QR so it cannot be paid
+// afterwards. Voiding a voided transaction returns it unchanged, any other status is final.
+func (u *TransactionUseCase) VoidTransaction(ctx context.Context, refID string) (*entity.TransactionResponse, error) {
+	funcName := "TransactionUseCase.VoidTransaction"
+	captureFieldError := generalEntity.CaptureFields{"refID": refID}
+
+	transaction, err := u.transactionRepo.FindByRefID(ctx, refID)
+	if err != nil {
+		u.logUseCase.Error("transactionRepo.FindByRefID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	voided := false
+	if err := mysql.DBTransaction(u.transactionRepo, func(dbTrx mysql.TrxObj) error {
+		// Lock so a concurrent payment cannot complete the transaction while it is voided
+		transaction, err = u.transactionRepo.LockByID(ctx, dbTrx, transaction.ID)
+		if err != nil {
+			u.logUseCase.Error("transactionRepo.LockByID", funcName, err, captureFieldError)
+			return err
+		}
+
+		switch transaction.Status {
+		case mEntity.TransactionStatusVoided:
+			return nil
+		case mEntity.TransactionStatusPending:
+		default:
+			return apperr.CustomError(
+				fmt.Sprintf("transaction with status %s cannot be voided", transaction.Status),
+				generalEntity.BAD_REQUEST_CODE,
+				http.StatusConflict,
+			)
+		}
+
+		if err := u.transactionRepo.Update(ctx, dbTrx, transaction, map[string]interface{}{
+			"status": mEntity.TransactionStatusVoided,
+		}); err != nil {
+			u.logUseCase.Error("transactionRepo.Update", funcName, err, captureFieldError)
+			return err
+		}
+		if err := u.transactionRepo.CreateStatusHistory(ctx, dbTrx, &mEntity.TransactionStatusHistoryEntity{
+			TransactionID: transaction.ID,
+			FromStatus:    mEntity.TransactionStatusPending,
+			ToStatus:      mEntity.TransactionStatusVoided,
+			Reason:        transactionVoidReason,
+		}); err != nil {
+			u.logUseCase.Error("transactionRepo.CreateStatusHistory", funcName, err, captureFieldError)
+			return err
+		}
+		transaction.Status = mEntity.TransactionStatusVoided
+		voided = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// The void is already stored, so the QR cleanup and notification are only logged
+	// on failure. The QR may have expired or been cancelled already.
+	if voided {
+		u.publishStatusChanged(funcName, transaction, mEntity.TransactionStatusPending, transactionVoidReason, captureFieldError)
+		_, cancelled, err := u.qrRepo.Cancel(ctx, transaction.BillingID)
+		if err != nil && !errWrap.Is(err, apperr.ErrRecordNotFound()) {
+			u.logUseCase.Error("qrRepo.Cancel", funcName, err, captureFieldError)
+		}
+		if cancelled {
+			if err := u.qrEventRepo.Publish(ctx, &rEntity.QREventEntity{
+				Event:      rEntity.QREventCancelled,
+				BillingID:  transaction.BillingID,
+				RefID:      transaction.RefID,
+				MerchantID: transaction.MerchantID,
+				Amount:     transaction.TotalAmount,
+				OccurredAt: time.Now().Unix(),
+			}); err != nil {
+				u.logUseCase.Error("qrEventRepo.Publish", funcName, err, captureFieldError)
+			}
+		}
+	}
+
+	return toTransactionResponse(transaction), nil
+}
+
+// NotifyPayment records a payment the switch reports for a QR. The QR is consumed so
+// it cannot be paid twice, then the pending transaction of the QR is completed or a
+// completed one is created. A notification the switch retries for a paid QR is
+// approved again with the same transaction. Rejections are returned as response codes,
+// the error is only set when the notification could not be processed.
+func (u *TransactionUseCase) NotifyPayment(ctx context.Context, req *entity.PaymentNotificationRequest) (*entity.PaymentNotificationResponse, error) {
+	funcName := "TransactionUseCase.NotifyPayment"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return rejectNotification(req, entity.NotificationFormatError, "Format error"), nil
+	}
+
+	role, err := u.invalidParticipantRole(ctx, req.Issuer, req.Acquirer)
+	if err != nil {
+		u.logUseCase.Error("TransactionUseCase.invalidParticipantRole", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if role != "" {
+		return rejectNotification(req, entity.NotificationInvalidParticipant, fmt.Sprintf("Unknown %s", role)), nil
+	}
+	if req.Status == entity.NotificationStatusFailed {
+		return u.declinePayment(ctx, req)
+	}
+
+	qr, err := u.qrRepo.GetByBillingID(ctx, req.BillingID)
+	if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		return u.notifyConsumedQR(ctx, req)
+	} else if err != nil {
+		u.logUseCase.Error("qrRepo.GetByBillingID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if math.Round(req.Amount*100) != math.Round(qr.Amount*100) {
+		return rejectNotification(req, entity.NotificationInvalidAmount, "Amount does not match the QR"), nil
+	}
+
+	// The merchant may have been suspended after the QR was generated
+	merchant, err := u.merchantRepo.FindByID(ctx, qr.MerchantID)
+	if err != nil {
+		u.logUseCase.Error("merchantRepo.FindByID", funcName, err, captureFieldError)
+		return nil, err
+	}
+	if merchant.Status != mEntity.MerchantStatusApproved {
+		return rejectNotification(req, entity.NotificationInvalidMerchant, "Merchant is not approved"), nil
+	}
+
+	err = u.blocklistUseCase.Check(ctx, &bEntity.BlocklistSubject{
+		CustomerMPAN: req.CustomerMPAN,
+		MerchantID:   merchant.ID,
+		NMID:         merchant.NMID,
+	})
+	if errWrap.Is(err, apperr.ErrBlocklisted()) {
+		return rejectNotification(req, entity.NotificationRestricted, "Customer or merchant is blocklisted"), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// A pending transaction was scored and counted against the limits when it was
+	// created, a payment without one is scored and counted now
+	pending, err := u.transactionRepo.FindByBillingID(ctx, req.BillingID, []string{mEntity.TransactionStatusPending})
+	if err != nil && !errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		u.logUseCase.Error("transactionRepo.FindByBillingID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	// Scored before the QR is consumed and the limits are counted, so a blocked payment
+	// leaves both untouched
+	var decision *mEntity.FraudDecisionEntity
+	if pending == nil {
+		decision, err = u.evaluatePayment(ctx, req, merchant)
+		if err != nil {
+			return nil, err
+		}
+		if decision.Decision == mEntity.FraudDecisionBlock {
+			if err := u.fraudUseCase.RecordDecision(ctx, nil, decision); err != nil {
+				return nil, err
+			}
+			return rejectNotification(req, entity.NotificationRestricted, "Payment blocked by fraud screening"), nil
+		}
+	}
+
+	// Of concurrent notifications for the QR only one consumes it
+	qr, err = u.qrRepo.Consume(ctx, req.BillingID)
+	if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		return u.notifyConsumedQR(ctx, req)
+	} else if err != nil {
+		u.logUseCase.Error("qrRepo.Consume", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	consumedAt := helper.DatetimeNowJakarta()
+	if pending == nil {
+		err := u.limitUseCase.ConsumeLimits(ctx, qr.MerchantID, qr.Amount, consumedAt)
+		if err != nil {
+			u.restoreQR(ctx, qr)
+			if usecase_limit.IsLimitBreach(err) {
+				return rejectNotification(req, entity.NotificationRestricted, "Merchant transaction limit exceeded"), nil
+			}
+			return nil, err
+		}
+	}
+
+	transaction, err := u.completePayment(ctx, req, qr, merchant, pending, decision)
+	if err != nil {
+		u.logUseCase.Error("TransactionUseCase.completePayment", funcName, err, captureFieldError)
+		if pending == nil {
+			if err := u.limitUseCase.ReleaseLimits(ctx, qr.MerchantID, qr.Amount, consumedAt); err != nil {
+				u.logUseCase.Error("limitUseCase.ReleaseLimits", funcName, err, captureFieldError)
+			}
+		}
+		u.restoreQR(ctx, qr)
+		return nil, err
+	}
+
+	// The payment is already stored, so a failed notification is only logged
+	if err := u.qrEventRepo.Publish(ctx, &rEntity.QREventEntity{
+		Event:      rEntity.QREventPaid,
+		BillingID:  transaction.BillingID,
+		RefID:      transaction.RefID,
+		MerchantID: transaction.MerchantID,
+		Amount:     transaction.TotalAmount,
+		OccurredAt: transaction.TransactionDate.Unix(),
+	}); err != nil {
+		u.logUseCase.Error("qrEventRepo.Publish", funcName, err, captureFieldError)
+	}
+
+	return approveNotification(transaction), nil
+}
+
+// declinePayment records a payment the issuer declined. The pending transaction of the
+// QR fails, the QR itself stays payable so the customer can try again.
+func (u *TransactionUseCase) declinePayment(ctx context.Context, req *entity.PaymentNotificationRequest) (*entity.PaymentNotificationResponse, error) {
+	funcName := "TransactionUseCase.declinePayment"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	pending, err := u.transactionRepo.FindByBillingID(ctx, req.BillingID, []string{mEntity.TransactionStatusPending})
+	if err == nil {
+		if _, err := u.failPendingTransaction(ctx, pending.ID, paymentDeclineReason); err != nil {
+			u.logUseCase.Error("TransactionUseCase.failPendingTransaction", funcName, err, captureFieldError)
+			return nil, err
+		}
+	} else if !errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		u.logUseCase.Error("transactionRepo.FindByBillingID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	response := &entity.PaymentNotificationResponse{
+		ResponseCode:    entity.NotificationApproved,
+		ResponseMessage: "Decline recorded",
+		RefID:           req.RefID,
+		BillingID:       req.BillingID,
+	}
+	if pending != nil {
+		response.TransactionID = pending.ID
+	}
+	return response, nil
+}
+
+// notifyConsumedQR answers a notification for a QR that is no longer payable. It is
+// approved when it repeats the notification the QR was paid with.
+func (u *TransactionUseCase) notifyConsumedQR(ctx context.Context, req *entity.PaymentNotificationRequest) (*entity.PaymentNotificationResponse, error) {
+	funcName := "TransactionUseCase.notifyConsumedQR"
+	captureFieldError := generalEntity.CaptureFields{
+		"payload": helper.ToString(req),
+	}
+
+	transaction, err := u.transactionRepo.FindByBillingID(ctx, req.BillingID, []string{
+		mEntity.TransactionStatusCompleted,
+		mEntity.TransactionStatusSettled,
+	})
+	if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+		return rejectNotification(req, entity.NotificationInvalidBilling, "QR is expired, cancelled or unknown"), nil
+	} else if err != nil {
+		u.logUseCase.Error("transactionRepo.FindByBillingID", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	if transaction.CustomerMPAN != req.CustomerMPAN || transaction.Issuer != req.Issuer {
+		return rejectNotification(req, entity.NotificationDuplicate, "QR has already been paid"), nil
+	}
+	return approveNotification(transaction), nil
+}
+
+// restoreQR puts a consumed QR back so the switch can retry the notification, a
+// failure is only logged since the QR expires anyway
+func (u *TransactionUseCase) restoreQR(ctx context.Context, qr *rEntity.QREntity) {
+	remaining := time.Until(time.Unix(qr.ExpiredAt, 0))
+	if remaining <= time.Second {
+		return
+	}
+	qr.Expiration = int64(remaining.Seconds())
+	if err := u.qrRepo.Create(ctx, qr); err != nil {
+		u.logUseCase.Error("qrRepo.Create", "TransactionUseCase.restoreQR", err, generalEntity.CaptureFields{
+			"billingID": qr.BillingID,
+		})
+	}
+}
+
+// completePayment completes the pending transaction of the QR, or creates a completed
+// one charged the merchant's MDR when there is none. The fraud decision of a created
+// payment is stored with it, a pending transaction already has its own.
+func (u *TransactionUseCase) completePayment(
+	ctx context.Context,
+	req *entity.PaymentNotificationRequest,
+	qr *rEntity.QREntity,
+	merchant *mEntity.MerchantEntity,
+	pending *mEntity.TransactionEntity,
+	decision *mEntity.FraudDecisionEntity,
+) (*mEntity.TransactionEntity, error) {
+	var transaction *mEntity.TransactionEntity
+	completedPending := false
+	err := mysql.DBTransaction(u.transactionRepo, func(dbTrx mysql.TrxObj) error {
+		if pending != nil {
+			var err error
+			transaction, err = u.transactionRepo.LockByID(ctx, dbTrx, pending.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// The pending transaction may have been voided or expired before the lock
+		if transaction != nil && transaction.Status == mEntity.TransactionStatusPending {
+			changes := map[string]interface{}{
+				"status":           mEntity.TransactionStatusCompleted,
+				"issuer":           req.Issuer,
+				"acquirer":         req.Acquirer,
+				"customer_mpan":    req.CustomerMPAN,
+				"transaction_date": time.Now(),
+			}
+			if err := u.transactionRepo.Update(ctx, dbTrx, transaction, changes); err != nil {
+				return err
+			}
+			if err := u.transactionRepo.CreateStatusHistory(ctx, dbTrx, &mEntity.TransactionStatusHistoryEntity{
+				TransactionID: transaction.ID,
+				FromStatus:    mEntity.TransactionStatusPending,
+				ToStatus:      mEntity.TransactionStatusCompleted,
+				Reason:        paymentApproveReason,
+			}); err != nil {
+				return err
+			}
+			completedPending = true
+			transaction.Status = mEntity.TransactionStatusCompleted
+			transaction.Issuer = req.Issuer
+			transaction.Acquirer = req.Acquirer
+			transaction.CustomerMPAN = req.CustomerMPAN
+			transaction.TransactionDate = changes["transaction_date"].(time.Time)
+		} else {
+			transaction = &mEntity.TransactionEntity{
+				RefID:           req.RefID,
+				BillingID:       req.BillingID,
+				MerchantID:      qr.MerchantID,
+				OutletID:        qrOutletID(qr),
+				TerminalID:      qrTerminalID(qr),
+				Amount:          qr.Amount,
+				TotalAmount:     qr.Amount,
+				MDRAmount:       mdrAmount(qr.Amount, merchant.MDRPercent),
+				MDRPercent:      merchant.MDRPercent,
+				PaymentMethod:   req.PaymentMethod,
+				Currency:        req.Currency,
+				Type:            transactionTypePayment,
+				Issuer:          req.Issuer,
+				Acquirer:        req.Acquirer,
+				CustomerMPAN:    req.CustomerMPAN,
+				TransactionDate: time.Now(),
+				Status:          mEntity.TransactionStatusCompleted,
+			}
+			if err := u.transactionRepo.Create(ctx, dbTrx, transaction, true); err != nil {
+				return err
+			}
+
+			// The pending transaction ended before the lock and keeps its decision, so
+			// the payment is scored now. The switch has already approved it, a block
+			// can only be recorded.
+			if decision == nil {
+				var err error
+				decision, err = u.evaluatePayment(ctx, req, merchant)
+				if err != nil {
+					return err
+				}
+			}
+			decision.TransactionID = &transaction.ID
+			if err := u.fraudUseCase.RecordDecision(ctx, dbTrx, decision); err != nil {
+				return err
+			}
+		}
+
+		return u.ledgerUseCase.PostTransaction(ctx, dbTrx, transaction)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if completedPending {
+		u.publishStatusChanged("TransactionUseCase.completePayment", transaction, mEntity.TransactionStatusPending, paymentApproveReason, generalEntity.CaptureFields{
+			"payload": helper.ToString(req),
+		})
+	}
+	return transaction, nil
+}
+
+// evaluatePayment scores a payment the switch reports for a merchant
+func (u *TransactionUseCase) evaluatePayment(ctx context.Context, req *entity.PaymentNotificationRequest, merchant *mEntity.MerchantEntity) (*mEntity.FraudDecisionEntity, error) {
+	return u.fraudUseCase.Evaluate(ctx, &fEntity.FraudSubject{
+		RefID:        req.RefID,
+		BillingID:    req.BillingID,
+		MerchantID:   merchant.ID,
+		CustomerMPAN: req.CustomerMPAN,
+		Amount:       req.Amount,
+		At:           helper.DatetimeNowJakarta(),
+	})
+}
+
+// mdrAmount is the MDR charged on an amount, rounded to the cent
+func mdrAmount(amount float64, percent float64) float64 {
+	return math.Round(amount*percent) / 100
+}
+
+// qrOutletID and qrTerminalID return where a QR was generated, QRs generated before
+// terminals existed carry neither
+func qrOutletID(qr *rEntity.QREntity) *uint64 {
+	if qr.OutletID == 0 {
+		return nil
+	}
+	return &qr.OutletID
+}
+
+func qrTerminalID(qr *rEntity.QREntity) *uint64 {
+	if qr.TerminalID == 0 {
+		return nil
+	}
+	return &qr.TerminalID
+}
+
+// invalidParticipantRole returns the first role whose institution code does not belong
+// to an active participant with that role. Empty codes are not checked.
+func (u *TransactionUseCase) invalidParticipantRole(ctx context.Context, issuer string, acquirer string) (string, error) {
+	for _, check := range []struct{ role, code string }{
+		{mEntity.ParticipantRoleIssuer, issuer},
+		{mEntity.ParticipantRoleAcquirer, acquirer},
+	} {
+		if check.code == "" {
+			continue
+		}
+		participant, err := u.participantRepo.FindByCode(ctx, check.code)
+		if errWrap.Is(err, apperr.ErrRecordNotFound()) {
+			return check.role, nil
+		} else if err != nil {
+			return "", err
+		}
+		if !participant.IsActive || !participant.HasRole(check.role) {
+			return check.role, nil
+		}
+	}
+	return "", nil
+}
+
+func approveNotification(transaction *mEntity.TransactionEntity) *entity.PaymentNotificationResponse {
+	return &entity.PaymentNotificationResponse{
+		ResponseCode:    entity.NotificationApproved,
+		ResponseMessage: "Approved",
+		RefID:           transaction.RefID,
+		BillingID:       transaction.BillingID,
+		TransactionID:   transaction.ID,
+		TransactionDate: helper.ConvertToJakartaTime(transaction.TransactionDate),
+	}
+}
+
+func rejectNotification(req *entity.PaymentNotificationRequest, code string, message string) *entity.PaymentNotificationResponse {
+	return &entity.PaymentNotificationResponse{
+		ResponseCode:    code,
+		ResponseMessage: message,
+		RefID:           req.RefID,
+		BillingID:       req.BillingID,
+	}
+}
+
+// SettleTransactions marks completed transactions made before the cutoff as settled
+// and moves their net amount to the merchant's available balance. It works in
+// batches until nothing is left and returns how many transactions were settled.
+func (u *TransactionUseCase) SettleTransactions(ctx context.Context, cutoff time.Time, batchSize int) (int, error) {
+	funcName := "TransactionUseCase.SettleTransactions"
+	captureFieldError := generalEntity.CaptureFields{
+		"cutoff": helper.ToString(cutoff),
+	}
+
+	settled := 0
+	for {
+		transactions, err := u.transactionRepo.FindSettleable(ctx, cutoff, batchSize)
+		if err != nil {
+			u.logUseCase.Error("transactionRepo.FindSettleable", funcName, err, captureFieldError)
+			return settled, err
+		}
+
+		for i := range transactions {
+			if err := u.settleTransaction(ctx, transactions[i].ID); err != nil {
+				captureFieldError["transactionID"] = helper.ToString(transactions[i].ID)
+				u.logUseCase.Error("TransactionUseCase.settleTransaction", funcName, err, captureFieldError)
+				return settled, err
+			}
+			settled++
+		}
+
+		if len(transactions) < batchSize {
+			return settled, nil
+		}
+	}
+}
+
+func (u *TransactionUseCase) settleTransaction(ctx context.Context, id uint64) error {
+	return mysql.DBTransaction(u.transactionRepo, func(dbTrx mysql.TrxObj) error {
+		transaction, err := u.transactionRepo.LockByID(ctx, dbTrx, id)
+		if err != nil {
+			return err
+		}
+		// Another run may have settled it between the lookup and the lock
+		if transaction.Status != mEntity.TransactionStatusCompleted {
+			return nil
+		}
+
+		if err := u.transactionRepo.Update(ctx, dbTrx, transaction, map[string]interface{}{
+			"status":          mEntity.TransactionStatusSettled,
+			"settlement_date": time.Now(),
+		}); err != nil {
+			return err
+		}
+		return u.ledgerUseCase.PostSettlement(ctx, dbTrx, transaction)
+	})
+}
+
+// ExpirePendingTransactions fails pending transactions older than the timeout of their
+// payment method, methods without their own timeout use defaultTimeout. Every expired
+// transaction gets a status history entry and a status changed event. It returns how
+// many transactions were expired.
+func (u *TransactionUseCase) ExpirePendingTransactions(ctx context.Context, timeouts map[string]time.Duration, defaultTimeout time.Duration, batchSize int) (int, error) {
+	now := time.Now()
+
+	methods := make([]string, 0, len(timeouts))
+	for method := range timeouts {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	filters := make([]*mEntity.PendingTransactionFilter, 0, len(methods)+1)
+	for _, method := range methods {
+		filters = append(filters, &mEntity.PendingTransactionFilter{
+			Before:        now.Add(-timeouts[method]),
+			PaymentMethod: method,
+			Limit:         batchSize,
+		})
+	}
+	filters = append(filters, &mEntity.PendingTransactionFilter{
+		Before:                now.Add(-defaultTimeout),
+		ExcludePaymentMethods: methods,
+		Limit:                 batchSize,
+	})
+
+	expired := 0
+	for _, filter := range filters {
+		count, err := u.expirePending(ctx, filter)
+		expired += count
+		if err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+func (u *TransactionUseCase) expirePending(ctx context.Context, filter *mEntity.PendingTransactionFilter) (int, error) {
+	funcName := "TransactionUseCase.expirePending"
+	captureFieldError := generalEntity.CaptureFields{
+		"filter": helper.ToString(filter),
+	}
+
+	expired := 0
+	for {
+		transactions, err := u.transactionRepo.FindPendingBefore(ctx, filter)
+		if err != nil {
+			u.logUseCase.Error("transactionRepo.FindPendingBefore", funcName, err, captureFieldError)
+			return expired, err
+		}
+
+		for i := range transactions {
+			ok, err := u.failPendingTransaction(ctx, transactions[i].ID, pendingExpiryReason)
+			if err != nil {
+				captureFieldError["transactionID"] = helper.ToString(transactions[i].ID)
+				u.logUseCase.Error("TransactionUseCase.failPendingTransaction", funcName, err, captureFieldError)
+				return expired, err
+			}
+			if ok {
+				expired++
+			}
+		}
+
+		if len(transactions) < filter.Limit {
+			return expired, nil
+		}
+	}
+}
+
+// failPendingTransaction fails a pending transaction with a status history entry and
+// a status changed event, and releases its limits. It reports false when the transaction stopped being pending
+// before it was locked.
+func (u *TransactionUseCase) failPendingTransaction(ctx context.Context, id uint64, reason string) (bool, error) {
+	funcName := "TransactionUseCase.failPendingTransaction"
+	captureFieldError := generalEntity.CaptureFields{
+		"transactionID": helper.ToString(id),
+	}
+
+	var transaction *mEntity.TransactionEntity
+	if err := mysql.DBTransaction(u.transactionRepo, func(dbTrx mysql.TrxObj) error {
+		locked, err := u.transactionRepo.LockByID(ctx, dbTrx, id)
+		if err != nil {
+			return err
+		}
+		if locked.Status != mEntity.TransactionStatusPending {
+			return nil
+		}
+
+		if err := u.transactionRepo.Update(ctx, dbTrx, locked, map[string]interface{}{
+			"status": mEntity.TransactionStatusFailed,
+		}); err != nil {
+			return err
+		}
+		if err := u.transactionRepo.CreateStatusHistory(ctx, dbTrx, &mEntity.TransactionStatusHistoryEntity{
+			TransactionID: locked.ID,
+			FromStatus:    mEntity.TransactionStatusPending,
+			ToStatus:      mEntity.TransactionStatusFailed,
+			Reason:        reason,
+		}); err != nil {
+			return err
+		}
+		// CreateTransaction counted the pending transaction against the limits at its
+		// transaction date, a failed one must not keep using them up
+		if err := u.limitUseCase.ReleaseLimits(ctx, locked.MerchantID, locked.TotalAmount, helper.DatetimeInJakarta(locked.TransactionDate)); err != nil {
+			return err
+		}
+
+		locked.Status = mEntity.TransactionStatusFailed
+		transaction = locked
+		return nil
+	}); err != nil {
+		return false, err
+	}
+	if transaction == nil {
+		return false, nil
+	}
+
+	u.publishStatusChanged(funcName, transaction, mEntity.TransactionStatusPending, reason, captureFieldError)
+	return true, nil
+}
+
+// publishStatusChanged announces a status the transaction was moved to. The status is
+// already stored, so a failed event is only logged.
+func (u *TransactionUseCase) publishStatusChanged(
+	funcName string,
+	transaction *mEntity.TransactionEntity,
+	fromStatus string,
+	reason string,
+	captureFieldError generalEntity.CaptureFields,
+) {
+	payload, _ := helper.Serialize(&entity.TransactionStatusChangedEvent{
+		TransactionID: transaction.ID,
+		RefID:         transaction.RefID,
+		BillingID:     transaction.BillingID,
+		MerchantID:    transaction.MerchantID,
+		FromStatus:    fromStatus,
+		ToStatus:      transaction.Status,
+		Reason:        reason,
+		OccurredAt:    helper.DatetimeNowJakartaString(),
+	})
+	if err := u.queue.Publish(queue.ProcessTransactionStatusChanged, payload, 1); err != nil {
+		u.logUseCase.Error("queue.Publish", funcName, err, captureFieldError)
+	}
+}
+
+// GetMerchantSummary aggregates a merchant's transactions between two dates (both
+// inclusive) into totals, a time series and payment method, issuer and outlet breakdowns.
+// With IncludeChildren a corporate merchant's children are counted too and broken
+// down per merchant.
+func (u *TransactionUseCase) GetMerchantSummary(ctx context.Context, req *entity.TransactionSummaryRequest) (*entity.TransactionSummaryResponse, error) {
+	funcName := "TransactionUseCase.GetMerchantSummary"
+	captureFieldError := generalEntity.CaptureFields{
+		"merchantID": helper.ToString(req.MerchantID),
+		"payload":    helper.ToString(req),
+	}
+
+	if err := usecase.ValidateStruct(*req); err != "" {
+		u.logUseCase.Error("usecase.ValidateStruct", funcName, fmt.Errorf("%s", err), captureFieldError)
+		return nil, errWrap.Wrap(fmt.Errorf(generalEntity.INVALID_PAYLOAD_CODE), err)
+	}
+
+	if req.Granularity == "" {
+		req.Granularity = mEntity.SummaryGranularityDay
+	}
+
+	from, _ := helper.ParseDate(req.From)
+	to, _ := helper.ParseDate(req.To)
+	if to.Before(from) {
+		return nil, apperr.CustomError("to must not be before from", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+	if to.Sub(from) >= summaryMaxRangeDays*24*time.Hour {
+		return nil, apperr.CustomError(
+			fmt.Sprintf("Summary range must not exceed %d days", summaryMaxRangeDays),
+			generalEntity.INVALID_PAYLOAD_CODE,
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	filter := &mEntity.TransactionSummaryFilter{
+		MerchantID:  req.MerchantID,
+		DateFrom:    from,
+		DateTo:      to.AddDate(0, 0, 1),
+		Granularity: req.Granularity,
+	}
+
+	var merchants []mEntity.MerchantEntity
+	if req.IncludeChildren {
+		var err error
+		merchants, err = u.consolidatedMerchants(ctx, req.MerchantID)
+		if err != nil {
+			u.logUseCase.Error("TransactionUseCase.consolidatedMerchants", funcName, err, captureFieldError)
+			return nil, err
+		}
+		for _, merchant := range merchants {
+			filter.MerchantIDs = append(filter.MerchantIDs, merchant.ID)
+		}
+	}
+
+	totals, err := u.transactionRepo.Summarize(ctx, filter)
+	if err != nil {
+		u.logUseCase.Error("transactionRepo.Summarize", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	periods, err := u.transactionRepo.SummarizeByPeriod(ctx, filter)
+	if err != nil {
+		u.logUseCase.Error("transactionRepo.SummarizeByPeriod", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	paymentMethods, err := u.transactionRepo.SummarizeBy(ctx, filter, "payment_method")
+	if err != nil {
+		u.logUseCase.Error("transactionRepo.SummarizeBy", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	issuers, err := u.transactionRepo.SummarizeBy(ctx, filter, "issuer")
+	if err != nil {
+		u.logUseCase.Error("transactionRepo.SummarizeBy", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	outlets, err := u.transactionRepo.SummarizeBy(ctx, filter, "outlet_id")
+	if err != nil {
+		u.logUseCase.Error("transactionRepo.SummarizeBy", funcName, err, captureFieldError)
+		return nil, err
+	}
+
+	var merchantBreakdowns []entity.TransactionBreakdown
+	if req.IncludeChildren {
+		byMerchant, err := u.transactionRepo.SummarizeBy(ctx, filter, "merchant_id")
+		if err != nil {
+			u.logUseCase.Error("transactionRepo.SummarizeBy", funcName, err, captureFieldError)
+			return nil, err
+		}
+		merchantBreakdowns = nameMerchants(toTransactionBreakdowns(byMerchant), merchants)
+	}
+
+	// Periods without transactions are missing from the query, charts expect them as zeroes
+	byPeriod := make(map[string]mEntity.TransactionAggregate, len(periods))
+	for _, period := range periods {
+		byPeriod[period.Period] = period.TransactionAggregate
+	}
+	series := make([]entity.TransactionPeriodSummary, 0)
+	for _, period := range summaryPeriods(from, to, req.Granularity) {
+		aggregate := byPeriod[period]
+		series = append(series, entity.TransactionPeriodSummary{
+			Period:            period,
+			TransactionTotals: toTransactionTotals(&aggregate),
+		})
+	}
+
+	return &entity.TransactionSummaryResponse{
+		MerchantID:     req.MerchantID,
+		From:           req.From,
+		To:             req.To,
+		Granularity:    req.Granularity,
+		Totals:         toTransactionTotals(totals),
+		Series:         series,
+		PaymentMethods: toTransactionBreakdowns(paymentMethods),
+		Issuers:        u.nameIssuers(ctx, toTransactionBreakdowns(issuers)),
+		Outlets:        u.nameOutlets(ctx, toTransactionBreakdowns(outlets)),
+		Merchants:      merchantBreakdowns,
+	}, nil
+}
+
+// consolidatedMerchants returns a corporate merchant followed by its children
+func (u *TransactionUseCase) consolidatedMerchants(ctx context.Context, merchantID uint64) ([]mEntity.MerchantEntity, error) {
+	merchant, err := u.merchantRepo.FindByID(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !merchant.IsCorporate() {
+		return nil, apperr.CustomError("Only corporate merchants can include children", generalEntity.INVALID_PAYLOAD_CODE, http.StatusUnprocessableEntity)
+	}
+
+	children, err := u.merchantRepo.FindChildren(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]mEntity.MerchantEntity{*merchant}, children...), nil
+}
+
+func nameMerchants(breakdowns []entity.TransactionBreakdown, merchants []mEntity.MerchantEntity) []entity.TransactionBreakdown {
+	names := make(map[string]string, len(merchants))
+	for _, merchant := range merchants {
+		names[strconv.FormatUint(merchant.ID, 10)] = merchant.Name
+	}
+	for i := range breakdowns {
+		breakdowns[i].Name = names[breakdowns[i].Key]
+	}
+	return breakdowns
+}
+
+// summaryPeriods lists the period keys between from and to in the same format the
+// repository buckets them: days and weeks (starting Monday) as dates, months as 2006-01
+func summaryPeriods(from time.Time, to time.Time, granularity string) []string {
+	periods := make([]string, 0)
+	switch granularity {
+	case mEntity.SummaryGranularityWeek:
+		start := from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+		for day := start; !day.After(to); day = day.AddDate(0, 0, 7) {
+			periods = append(periods, day.Format("2006-01-02"))
+		}
+	case mEntity.SummaryGranularityMonth:
+		start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+		for month := start; !month.After(to); month = month.AddDate(0, 1, 0) {
+			periods = append(periods, month.Format("2006-01"))
+		}
+	default:
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			periods = append(periods, day.Format("2006-01-02"))
+		}
+	}
+	return periods
+}
+
+func toTransactionTotals(aggregate *mEntity.TransactionAggregate) entity.TransactionTotals {
+	return entity.TransactionTotals{
+		TransactionCount: aggregate.TransactionCount,
+		PaymentCount:     aggregate.PaymentCount,
+		RefundCount:      aggregate.RefundCount,
+		FailedCount:      aggregate.FailedCount,
+		GrossVolume:      aggregate.GrossVolume,
+		MDRAmount:        aggregate.MDRAmount,
+		RefundVolume:     aggregate.RefundVolume,
+		NetAmount:        aggregate.NetAmount,
+	}
+}
+
+// nameIssuers adds the participant name to issuer breakdowns. The summary is still
+// useful with bare codes, so a failed lookup is only logged.
+func (u *TransactionUseCase) nameIssuers(ctx context.Context, breakdowns []entity.TransactionBreakdown) []entity.TransactionBreakdown {
+	codes := make([]string, 0, len(breakdowns))
+	for _, breakdown := range breakdowns {
+		codes = append(codes, breakdown.Key)
+	}
+
+	participants, err := u.participantRepo.FindByCodes(ctx, codes)
+	if err != nil {
+		u.logUseCase.Error("participantRepo.FindByCodes", "TransactionUseCase.nameIssuers", err, generalEntity.CaptureFields{
+			"codes": helper.ToString(codes),
+		})
+		return breakdowns
+	}
+
+	names := make(map[string]string, len(participants))
+	for _, participant := range participants {
+		names[participant.Code] = participant.Name
+	}
+	for i := range breakdowns {
+		breakdowns[i].Name = names[breakdowns[i].Key]
+	}
+	return breakdowns
+}
+
+// nameOutlets adds the outlet name to outlet breakdowns, payments made without an
+// outlet keep an empty key and name. A failed lookup is only logged.
+func (u *TransactionUseCase) nameOutlets(ctx context.Context, breakdowns []entity.TransactionBreakdown) []entity.TransactionBreakdown {
+	ids := make([]uint64, 0, len(breakdowns))
+	for _, breakdown := range breakdowns {
+		if id, err := strconv.ParseUint(breakdown.Key, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	outlets, err := u.outletRepo.FindOutletsByIDs(ctx, ids)
+	if err != nil {
+		u.logUseCase.Error("outletRepo.FindOutletsByIDs", "TransactionUseCase.nameOutlets", err, generalEntity.CaptureFields{
+			"ids": helper.ToString(ids),
+		})
+		return breakdowns
+	}
+
+	names := make(map[string]string, len(outlets))
+	for _, outlet := range outlets {
+		names[strconv.FormatUint(outlet.ID, 10)] = outlet.Name
+	}
+	for i := range breakdowns {
+		breakdowns[i].Name = names[breakdowns[i].Key]
+	}
+	return breakdowns
+}
+
+func toTransactionBreakdowns(aggregates []mEntity.TransactionBreakdownAggregate) []entity.TransactionBreakdown {
+	breakdowns := make([]entity.TransactionBreakdown, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		breakdowns = append(breakdowns, entity.TransactionBreakdown{
+			Key:              aggregate.Key,
+			TransactionCount: aggregate.TransactionCount,
+			Volume:           aggregate.Volume,
+			MDRAmount:        aggregate.MDRAmount,
+		})
+	}
+	return breakdowns
+}
+
+func toTransactionResponse(transaction *mEntity.TransactionEntity) *entity.TransactionResponse {
 	return &entity.TransactionResponse{
 		ID:              transaction.ID,
 		MerchantID:      transaction.MerchantID,
+		OutletID:        transaction.OutletID,
+		TerminalID:      transaction.TerminalID,
 		RefID:           transaction.RefID,
 		BillingID:       transaction.BillingID,
 		Type:            transaction.Type,
 		Amount:          transaction.Amount,
-		PaymentMethod:   transaction.PaymentMethod,
+		FeeAmount:       transaction.FeeAmount,
 		TotalAmount:     transaction.TotalAmount,
+		MDRPercent:      transaction.MDRPercent,
+		MDRAmount:       transaction.MDRAmount,
+		PaymentMethod:   transaction.PaymentMethod,
+		Currency:        transaction.Currency,
+		Issuer:          transaction.Issuer,
+		Aquirer:         transaction.Acquirer,
+		CustomerMPAN:    transaction.CustomerMPAN,
 		TransactionDate: helper.ConvertToJakartaDate(transaction.TransactionDate),
 		SettlementDate:  helper.ConvertToJakartaDate(transaction.SettlementDate),
 		Status:          transaction.Status,
 		CreatedAt:       helper.ConvertToJakartaDate(transaction.CreatedAt),
 		UpdatedAt:       helper.ConvertToJakartaDate(transaction.UpdatedAt),
-	}, nil
+	}
 }
@@ -0,0 +1,359 @@
+package usecase_transaction
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	apperr "github.com/kharisma-wardhana/final-project-spe-academy/error"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/queue"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+	mEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis"
+	rEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/redis/entity"
+	usecase_fraud "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud"
+	fEntity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/fraud/entity"
+	usecase_ledger "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/ledger"
+	usecase_limit "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/limit"
+	usecase_log "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/log"
+	"github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/transaction/entity"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type trxStub struct{}
+
+func (trxStub) Commit() error   { return nil }
+func (trxStub) Rollback() error { return nil }
+
+type transactionRepoStub struct {
+	mysql.ITransactionRepository
+	transactions map[uint64]*mEntity.TransactionEntity
+	histories    []mEntity.TransactionStatusHistoryEntity
+	// changedBeforeLock moves a transaction to another status between the
+	// query and the lock, like a concurrent payment would
+	changedBeforeLock map[uint64]string
+}
+
+func (r *transactionRepoStub) Begin() (mysql.TrxObj, error) {
+	return trxStub{}, nil
+}
+
+func (r *transactionRepoStub) FindByRefID(ctx context.Context, refID string) (*mEntity.TransactionEntity, error) {
+	for _, transaction := range r.transactions {
+		if transaction.RefID == refID {
+			found := *transaction
+			return &found, nil
+		}
+	}
+	return nil, apperr.ErrRecordNotFound()
+}
+
+func (r *transactionRepoStub) LockByID(ctx context.Context, dbTrx mysql.TrxObj, id uint64) (*mEntity.TransactionEntity, error) {
+	if status, ok := r.changedBeforeLock[id]; ok {
+		r.transactions[id].Status = status
+	}
+	found := *r.transactions[id]
+	return &found, nil
+}
+
+func (r *transactionRepoStub) FindPendingBefore(ctx context.Context, filter *mEntity.PendingTransactionFilter) ([]mEntity.TransactionEntity, error) {
+	transactions := []mEntity.TransactionEntity{}
+	for _, id := range slices.Sorted(maps.Keys(r.transactions)) {
+		transaction := r.transactions[id]
+		if transaction.Status != mEntity.TransactionStatusPending || !transaction.TransactionDate.Before(filter.Before) {
+			continue
+		}
+		if filter.PaymentMethod != "" && transaction.PaymentMethod != filter.PaymentMethod {
+			continue
+		}
+		if slices.Contains(filter.ExcludePaymentMethods, transaction.PaymentMethod) {
+			continue
+		}
+		transactions = append(transactions, *transaction)
+	}
+	return transactions, nil
+}
+
+func (r *transactionRepoStub) Create(ctx context.Context, dbTrx mysql.TrxObj, params *mEntity.TransactionEntity, nonZeroVal bool) error {
+	params.ID = uint64(len(r.transactions) + 1)
+	created := *params
+	r.transactions[params.ID] = &created
+	return nil
+}
+
+func (r *transactionRepoStub) CreateStatusHistory(ctx context.Context, dbTrx mysql.TrxObj, params *mEntity.TransactionStatusHistoryEntity) error {
+	r.histories = append(r.histories, *params)
+	return nil
+}
+
+func (r *transactionRepoStub) Update(ctx context.Context, dbTrx mysql.TrxObj, params *mEntity.TransactionEntity, changes map[string]interface{}) error {
+	r.transactions[params.ID].Status = changes["status"].(string)
+	return nil
+}
+
+type qrRepoStub struct {
+	redis.IQRRepository
+	cancelled map[string]bool
+}
+
+func (r *qrRepoStub) Cancel(ctx context.Context, billingID string) (*rEntity.QREntity, bool, error) {
+	alreadyCancelled := r.cancelled[billingID]
+	r.cancelled[billingID] = true
+	return &rEntity.QREntity{BillingID: billingID, MerchantID: 1}, !alreadyCancelled, nil
+}
+
+type qrEventRepoStub struct {
+	redis.IQREventRepository
+	events []*rEntity.QREventEntity
+}
+
+func (r *qrEventRepoStub) Publish(ctx context.Context, event *rEntity.QREventEntity) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+type queueStub struct {
+	queue.Queue
+	keys []string
+}
+
+func (q *queueStub) Publish(key string, message []byte, attempts int32) error {
+	q.keys = append(q.keys, key)
+	return nil
+}
+
+type limitUseCaseStub struct {
+	usecase_limit.ILimitUseCase
+	// consumed is the amount counted against each merchant's limits
+	consumed map[uint64]float64
+}
+
+func (u *limitUseCaseStub) ReleaseLimits(ctx context.Context, merchantID uint64, amount float64, at time.Time) error {
+	u.consumed[merchantID] -= amount
+	return nil
+}
+
+type fraudUseCaseStub struct {
+	usecase_fraud.IFraudUseCase
+	evaluated int
+	recorded  []mEntity.FraudDecisionEntity
+}
+
+func (u *fraudUseCaseStub) Evaluate(ctx context.Context, subject *fEntity.FraudSubject) (*mEntity.FraudDecisionEntity, error) {
+	u.evaluated++
+	return &mEntity.FraudDecisionEntity{RefID: subject.RefID, Decision: mEntity.FraudDecisionAllow}, nil
+}
+
+func (u *fraudUseCaseStub) RecordDecision(ctx context.Context, dbTrx mysql.TrxObj, decision *mEntity.FraudDecisionEntity) error {
+	u.recorded = append(u.recorded, *decision)
+	return nil
+}
+
+type ledgerUseCaseStub struct {
+	usecase_ledger.ILedgerUseCase
+	posted []mEntity.TransactionEntity
+}
+
+func (u *ledgerUseCaseStub) PostTransaction(ctx context.Context, dbTrx mysql.TrxObj, transaction *mEntity.TransactionEntity) error {
+	u.posted = append(u.posted, *transaction)
+	return nil
+}
+
+type logStub struct {
+	usecase_log.ILogUseCase
+}
+
+func (logStub) Error(process string, funcName string, err error, logFields map[string]string) {}
+
+type TransactionUseCaseTestSuite struct {
+	suite.Suite
+
+	transactionRepo *transactionRepoStub
+	queue           *queueStub
+	qrRepo          *qrRepoStub
+	qrEventRepo     *qrEventRepoStub
+	limitUseCase    *limitUseCaseStub
+	fraudUseCase    *fraudUseCaseStub
+	ledgerUseCase   *ledgerUseCaseStub
+	usecase         *TransactionUseCase
+}
+
+func (s *TransactionUseCaseTestSuite) SetupTest() {
+	s.transactionRepo = &transactionRepoStub{transactions: map[uint64]*mEntity.TransactionEntity{
+		1: {ID: 1, RefID: "REF1", BillingID: "BILL1", MerchantID: 1, TotalAmount: 10000, Status: mEntity.TransactionStatusPending},
+	}}
+	s.queue = &queueStub{}
+	s.qrRepo = &qrRepoStub{cancelled: map[string]bool{}}
+	s.qrEventRepo = &qrEventRepoStub{}
+	s.limitUseCase = &limitUseCaseStub{consumed: map[uint64]float64{}}
+	s.fraudUseCase = &fraudUseCaseStub{}
+	s.ledgerUseCase = &ledgerUseCaseStub{}
+	s.usecase = NewTransactionUseCase(logStub{}, s.queue, s.transactionRepo, s.qrRepo, s.qrEventRepo, s.ledgerUseCase, nil, s.limitUseCase, s.fraudUseCase, nil, nil, nil)
+}
+
+func TestTransactionUseCase(t *testing.T) {
+	suite.Run(t, new(TransactionUseCaseTestSuite))
+}
+
+func (s *TransactionUseCaseTestSuite) TestVoidCancelsQROnce() {
+	transaction, err := s.usecase.VoidTransaction(context.Background(), "REF1")
+	s.Require().NoError(err)
+	s.Equal(mEntity.TransactionStatusVoided, transaction.Status)
+	s.True(s.qrRepo.cancelled["BILL1"])
+	s.Require().Len(s.qrEventRepo.events, 1)
+	s.Equal(rEntity.QREventCancelled, s.qrEventRepo.events[0].Event)
+
+	// Voiding again is a no-op that returns the voided transaction
+	transaction, err = s.usecase.VoidTransaction(context.Background(), "REF1")
+	s.Require().NoError(err)
+	s.Equal(mEntity.TransactionStatusVoided, transaction.Status)
+	s.Len(s.qrEventRepo.events, 1)
+
+	s.Equal([]mEntity.TransactionStatusHistoryEntity{{
+		TransactionID: 1,
+		FromStatus:    mEntity.TransactionStatusPending,
+		ToStatus:      mEntity.TransactionStatusVoided,
+		Reason:        transactionVoidReason,
+	}}, s.transactionRepo.histories)
+	s.Equal([]string{queue.ProcessTransactionStatusChanged}, s.queue.keys)
+}
+
+func (s *TransactionUseCaseTestSuite) TestVoidRejectsFinishedTransaction() {
+	for _, status := range []string{mEntity.TransactionStatusCompleted, mEntity.TransactionStatusSettled, mEntity.TransactionStatusFailed} {
+		s.transactionRepo.transactions[1].Status = status
+
+		_, err := s.usecase.VoidTransaction(context.Background(), "REF1")
+		s.Require().Error(err, status)
+		s.Equal(http.StatusConflict, err.(apperr.CustomErrorResponse).HTTPCode, status)
+		s.Equal(status, s.transactionRepo.transactions[1].Status)
+	}
+	s.Empty(s.qrRepo.cancelled)
+	s.Empty(s.qrEventRepo.events)
+	s.Empty(s.transactionRepo.histories)
+	s.Empty(s.queue.keys)
+}
+
+func (s *TransactionUseCaseTestSuite) TestVoidPublishesOnlyWhenQRWasCancelled() {
+	// The QR was cancelled through the QR endpoint before the transaction was voided
+	s.qrRepo.cancelled["BILL1"] = true
+
+	_, err := s.usecase.VoidTransaction(context.Background(), "REF1")
+	s.Require().NoError(err)
+	s.Equal(mEntity.TransactionStatusVoided, s.transactionRepo.transactions[1].Status)
+	s.Empty(s.qrEventRepo.events)
+}
+
+func (s *TransactionUseCaseTestSuite) TestExpireUsesMethodTimeouts() {
+	now := time.Now()
+	s.transactionRepo.transactions = map[uint64]*mEntity.TransactionEntity{
+		1: {ID: 1, PaymentMethod: "ewallet", TransactionDate: now.Add(-10 * time.Minute), Status: mEntity.TransactionStatusPending},
+		2: {ID: 2, PaymentMethod: "ewallet", TransactionDate: now.Add(-2 * time.Minute), Status: mEntity.TransactionStatusPending},
+		3: {ID: 3, PaymentMethod: "bank_transfer", TransactionDate: now.Add(-10 * time.Minute), Status: mEntity.TransactionStatusPending},
+		4: {ID: 4, PaymentMethod: "bank_transfer", TransactionDate: now.Add(-20 * time.Minute), Status: mEntity.TransactionStatusPending},
+		5: {ID: 5, PaymentMethod: "ewallet", TransactionDate: now.Add(-time.Hour), Status: mEntity.TransactionStatusCompleted},
+	}
+
+	expired, err := s.usecase.ExpirePendingTransactions(context.Background(), map[string]time.Duration{
+		"ewallet": 5 * time.Minute,
+	}, 15*time.Minute, 10)
+	s.Require().NoError(err)
+	s.Equal(2, expired)
+
+	// ewallet payments expire after their own timeout, the rest after the default
+	s.Equal(mEntity.TransactionStatusFailed, s.transactionRepo.transactions[1].Status)
+	s.Equal(mEntity.TransactionStatusPending, s.transactionRepo.transactions[2].Status)
+	s.Equal(mEntity.TransactionStatusPending, s.transactionRepo.transactions[3].Status)
+	s.Equal(mEntity.TransactionStatusFailed, s.transactionRepo.transactions[4].Status)
+	s.Equal(mEntity.TransactionStatusCompleted, s.transactionRepo.transactions[5].Status)
+
+	s.Require().Len(s.transactionRepo.histories, 2)
+	for _, history := range s.transactionRepo.histories {
+		s.Equal(pendingExpiryReason, history.Reason)
+	}
+	s.Len(s.queue.keys, 2)
+}
+
+func (s *TransactionUseCaseTestSuite) TestExpireSkipsTransactionsNoLongerPending() {
+	s.transactionRepo.transactions[1].TransactionDate = time.Now().Add(-time.Hour)
+	s.transactionRepo.changedBeforeLock = map[uint64]string{1: mEntity.TransactionStatusCompleted}
+
+	expired, err := s.usecase.ExpirePendingTransactions(context.Background(), nil, 15*time.Minute, 10)
+	s.Require().NoError(err)
+	s.Zero(expired)
+	s.Equal(mEntity.TransactionStatusCompleted, s.transactionRepo.transactions[1].Status)
+	s.Empty(s.transactionRepo.histories)
+	s.Empty(s.queue.keys)
+}
+
+func (s *TransactionUseCaseTestSuite) TestExpireReleasesLimits() {
+	now := time.Now()
+	s.transactionRepo.transactions = map[uint64]*mEntity.TransactionEntity{
+		1: {ID: 1, MerchantID: 1, TotalAmount: 10000, TransactionDate: now.Add(-time.Hour), Status: mEntity.TransactionStatusPending},
+		2: {ID: 2, MerchantID: 1, TotalAmount: 5000, TransactionDate: now.Add(-time.Minute), Status: mEntity.TransactionStatusPending},
+	}
+	s.limitUseCase.consumed[1] = 15000
+
+	expired, err := s.usecase.ExpirePendingTransactions(context.Background(), nil, 15*time.Minute, 10)
+	s.Require().NoError(err)
+	s.Equal(1, expired)
+
+	// Only the expired transaction stops counting against the limits
+	s.Equal(5000.0, s.limitUseCase.consumed[1])
+}
+
+func (s *TransactionUseCaseTestSuite) TestCompletePaymentChargesMerchantMDR() {
+	transaction, err := s.usecase.completePayment(
+		context.Background(),
+		&entity.PaymentNotificationRequest{RefID: "REF2", BillingID: "BILL2", Amount: 25000},
+		&rEntity.QREntity{BillingID: "BILL2", MerchantID: 1, Amount: 25000},
+		&mEntity.MerchantEntity{ID: 1, MDRPercent: 0.7},
+		nil,
+		&mEntity.FraudDecisionEntity{Decision: mEntity.FraudDecisionAllow},
+	)
+	s.Require().NoError(err)
+
+	// A payment without a pending transaction is charged the merchant's MDR, so the
+	// ledger books its MDR leg
+	s.Equal(0.7, transaction.MDRPercent)
+	s.Equal(175.0, transaction.MDRAmount)
+	s.Require().Len(s.ledgerUseCase.posted, 1)
+	s.Equal(175.0, s.ledgerUseCase.posted[0].MDRAmount)
+	s.Require().Len(s.fraudUseCase.recorded, 1)
+	s.Equal(transaction.ID, *s.fraudUseCase.recorded[0].TransactionID)
+}
+
+func (s *TransactionUseCaseTestSuite) TestCompletePendingPaymentKeepsItsDecision() {
+	req := &entity.PaymentNotificationRequest{RefID: "REF1", BillingID: "BILL1", Amount: 10000}
+	qr := &rEntity.QREntity{BillingID: "BILL1", MerchantID: 1, Amount: 10000}
+	merchant := &mEntity.MerchantEntity{ID: 1}
+
+	transaction, err := s.usecase.completePayment(context.Background(), req, qr, merchant, s.transactionRepo.transactions[1], nil)
+	s.Require().NoError(err)
+	s.Equal(uint64(1), transaction.ID)
+	s.Equal(mEntity.TransactionStatusCompleted, transaction.Status)
+
+	// The pending transaction was scored when it was created
+	s.Zero(s.fraudUseCase.evaluated)
+	s.Empty(s.fraudUseCase.recorded)
+	s.Len(s.ledgerUseCase.posted, 1)
+}
+
+func (s *TransactionUseCaseTestSuite) TestCompletePaymentScoresWhenPendingEnded() {
+	s.transactionRepo.changedBeforeLock = map[uint64]string{1: mEntity.TransactionStatusFailed}
+	req := &entity.PaymentNotificationRequest{RefID: "REF2", BillingID: "BILL1", Amount: 10000}
+	qr := &rEntity.QREntity{BillingID: "BILL1", MerchantID: 1, Amount: 10000}
+	merchant := &mEntity.MerchantEntity{ID: 1}
+
+	transaction, err := s.usecase.completePayment(context.Background(), req, qr, merchant, s.transactionRepo.transactions[1], nil)
+	s.Require().NoError(err)
+	s.NotEqual(uint64(1), transaction.ID)
+
+	// The payment is stored as a new transaction with a decision of its own
+	s.Equal(1, s.fraudUseCase.evaluated)
+	s.Require().Len(s.fraudUseCase.recorded, 1)
+	s.Equal(transaction.ID, *s.fraudUseCase.recorded[0].TransactionID)
+}
@@ -63,12 +63,16 @@ func ValidateStructProcess(data interface{}) []entity.ErrorResponse {
 }
 
 func ValidateStruct(data interface{}) string {
-	validate := ValidateStructProcess(data)
-	jsonString, _ := json.Marshal(validate)
+	return FormatValidationErrors(ValidateStructProcess(data))
+}
 
-	if string(jsonString) != "null" {
-		return string(jsonString) + "XX"
+// FormatValidationErrors encodes field errors the way ValidateStruct does, so checks
+// the validate tags cannot express reach the client in the same shape
+func FormatValidationErrors(errors []entity.ErrorResponse) string {
+	if len(errors) == 0 {
+		return ""
 	}
 
-	return ""
+	jsonString, _ := json.Marshal(errors)
+	return string(jsonString) + "XX"
 }
@@ -1,21 +1,36 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
 package mocks
 
 import (
 	context "context"
 
-	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
-	"github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
-	"github.com/stretchr/testify/mock"
+	entity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	mysql "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+
+	time "time"
 )
 
+// AccountRepository is an autogenerated mock type for the IAccountRepository type
 type AccountRepository struct {
 	mock.Mock
 }
 
+// Begin provides a mock function with no fields
 func (_m *AccountRepository) Begin() (mysql.TrxObj, error) {
 	ret := _m.Called()
 
+	if len(ret) == 0 {
+		panic("no return value specified for Begin")
+	}
+
 	var r0 mysql.TrxObj
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (mysql.TrxObj, error)); ok {
+		return rf()
+	}
 	if rf, ok := ret.Get(0).(func() mysql.TrxObj); ok {
 		r0 = rf()
 	} else {
@@ -24,7 +39,6 @@ func (_m *AccountRepository) Begin() (mysql.TrxObj, error) {
 		}
 	}
 
-	var r1 error
 	if rf, ok := ret.Get(1).(func() error); ok {
 		r1 = rf()
 	} else {
@@ -34,12 +48,17 @@ func (_m *AccountRepository) Begin() (mysql.TrxObj, error) {
 	return r0, r1
 }
 
-func (_m *AccountRepository) Create(ctx context.Context, dbTrx mysql.TrxObj, account *entity.AccountEntity) error {
-	ret := _m.Called(ctx, dbTrx, account)
+// Create provides a mock function with given fields: ctx, dbTrx, params, nonZeroVal
+func (_m *AccountRepository) Create(ctx context.Context, dbTrx mysql.TrxObj, params *entity.AccountEntity, nonZeroVal bool) error {
+	ret := _m.Called(ctx, dbTrx, params, nonZeroVal)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, *entity.AccountEntity) error); ok {
-		r0 = rf(ctx, dbTrx, account)
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, *entity.AccountEntity, bool) error); ok {
+		r0 = rf(ctx, dbTrx, params, nonZeroVal)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -47,21 +66,95 @@ func (_m *AccountRepository) Create(ctx context.Context, dbTrx mysql.TrxObj, acc
 	return r0
 }
 
-func (_m *AccountRepository) FindByClientID(ctx context.Context, dbTrx mysql.TrxObj, clientID string) (*entity.AccountEntity, error) {
-	ret := _m.Called(ctx, dbTrx, clientID)
+// DeleteByID provides a mock function with given fields: ctx, dbTrx, id
+func (_m *AccountRepository) DeleteByID(ctx context.Context, dbTrx mysql.TrxObj, id uint64) error {
+	ret := _m.Called(ctx, dbTrx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64) error); ok {
+		r0 = rf(ctx, dbTrx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteByMerchantID provides a mock function with given fields: ctx, dbTrx, merchantID, deletedAt
+func (_m *AccountRepository) DeleteByMerchantID(ctx context.Context, dbTrx mysql.TrxObj, merchantID uint64, deletedAt time.Time) error {
+	ret := _m.Called(ctx, dbTrx, merchantID, deletedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByMerchantID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64, time.Time) error); ok {
+		r0 = rf(ctx, dbTrx, merchantID, deletedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByClientID provides a mock function with given fields: ctx, clientID
+func (_m *AccountRepository) FindByClientID(ctx context.Context, clientID string) (*entity.AccountEntity, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByClientID")
+	}
 
 	var r0 *entity.AccountEntity
-	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, string) *entity.AccountEntity); ok {
-		r0 = rf(ctx, dbTrx, clientID)
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.AccountEntity, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.AccountEntity); ok {
+		r0 = rf(ctx, clientID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*entity.AccountEntity)
 		}
 	}
 
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *AccountRepository) FindByID(ctx context.Context, id uint64) (*entity.AccountEntity, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 *entity.AccountEntity
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, mysql.TrxObj, string) error); ok {
-		r1 = rf(ctx, dbTrx, clientID)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*entity.AccountEntity, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *entity.AccountEntity); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.AccountEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -69,10 +162,79 @@ func (_m *AccountRepository) FindByClientID(ctx context.Context, dbTrx mysql.Trx
 	return r0, r1
 }
 
-func (_m *AccountRepository) FindByID(ctx context.Context, dbTrx mysql.TrxObj, id uint64) (*entity.AccountEntity, error) {
+// FindByMerchantID provides a mock function with given fields: ctx, merchantID
+func (_m *AccountRepository) FindByMerchantID(ctx context.Context, merchantID uint64) (*entity.AccountEntity, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByMerchantID")
+	}
+
+	var r0 *entity.AccountEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*entity.AccountEntity, error)); ok {
+		return rf(ctx, merchantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *entity.AccountEntity); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.AccountEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindDeletedByID provides a mock function with given fields: ctx, id
+func (_m *AccountRepository) FindDeletedByID(ctx context.Context, id uint64) (*entity.AccountEntity, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindDeletedByID")
+	}
+
+	var r0 *entity.AccountEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*entity.AccountEntity, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *entity.AccountEntity); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.AccountEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockByID provides a mock function with given fields: ctx, dbTrx, id
+func (_m *AccountRepository) LockByID(ctx context.Context, dbTrx mysql.TrxObj, id uint64) (*entity.AccountEntity, error) {
 	ret := _m.Called(ctx, dbTrx, id)
 
+	if len(ret) == 0 {
+		panic("no return value specified for LockByID")
+	}
+
 	var r0 *entity.AccountEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64) (*entity.AccountEntity, error)); ok {
+		return rf(ctx, dbTrx, id)
+	}
 	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64) *entity.AccountEntity); ok {
 		r0 = rf(ctx, dbTrx, id)
 	} else {
@@ -81,7 +243,6 @@ func (_m *AccountRepository) FindByID(ctx context.Context, dbTrx mysql.TrxObj, i
 		}
 	}
 
-	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, mysql.TrxObj, uint64) error); ok {
 		r1 = rf(ctx, dbTrx, id)
 	} else {
@@ -91,12 +252,84 @@ func (_m *AccountRepository) FindByID(ctx context.Context, dbTrx mysql.TrxObj, i
 	return r0, r1
 }
 
-type mockConstructorTestingTNewAccountRepository interface {
-	mock.TestingT
-	Cleanup(func())
+// PurgeByMerchantID provides a mock function with given fields: ctx, dbTrx, merchantID
+func (_m *AccountRepository) PurgeByMerchantID(ctx context.Context, dbTrx mysql.TrxObj, merchantID uint64) error {
+	ret := _m.Called(ctx, dbTrx, merchantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeByMerchantID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64) error); ok {
+		r0 = rf(ctx, dbTrx, merchantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
-func NewAccountRepository(t mockConstructorTestingTNewAccountRepository) *AccountRepository {
+// Restore provides a mock function with given fields: ctx, dbTrx, id
+func (_m *AccountRepository) Restore(ctx context.Context, dbTrx mysql.TrxObj, id uint64) error {
+	ret := _m.Called(ctx, dbTrx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64) error); ok {
+		r0 = rf(ctx, dbTrx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreByMerchantID provides a mock function with given fields: ctx, dbTrx, merchantID, deletedAt
+func (_m *AccountRepository) RestoreByMerchantID(ctx context.Context, dbTrx mysql.TrxObj, merchantID uint64, deletedAt time.Time) error {
+	ret := _m.Called(ctx, dbTrx, merchantID, deletedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreByMerchantID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64, time.Time) error); ok {
+		r0 = rf(ctx, dbTrx, merchantID, deletedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, dbTrx, params, changes
+func (_m *AccountRepository) Update(ctx context.Context, dbTrx mysql.TrxObj, params *entity.AccountEntity, changes *entity.AccountEntity) error {
+	ret := _m.Called(ctx, dbTrx, params, changes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, *entity.AccountEntity, *entity.AccountEntity) error); ok {
+		r0 = rf(ctx, dbTrx, params, changes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAccountRepository creates a new instance of AccountRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAccountRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AccountRepository {
 	mock := &AccountRepository{}
 	mock.Mock.Test(t)
 
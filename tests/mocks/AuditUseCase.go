@@ -0,0 +1,75 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	final_project_spe_academyentity "github.com/kharisma-wardhana/final-project-spe-academy/entity"
+	entity "github.com/kharisma-wardhana/final-project-spe-academy/internal/usecase/audit/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuditUseCase is an autogenerated mock type for the IAuditUseCase type
+type AuditUseCase struct {
+	mock.Mock
+}
+
+// ListAudits provides a mock function with given fields: ctx, req
+func (_m *AuditUseCase) ListAudits(ctx context.Context, req *entity.AuditListRequest) ([]*entity.AuditResponse, *final_project_spe_academyentity.PaginationMeta, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAudits")
+	}
+
+	var r0 []*entity.AuditResponse
+	var r1 *final_project_spe_academyentity.PaginationMeta
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.AuditListRequest) ([]*entity.AuditResponse, *final_project_spe_academyentity.PaginationMeta, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.AuditListRequest) []*entity.AuditResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.AuditResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.AuditListRequest) *final_project_spe_academyentity.PaginationMeta); ok {
+		r1 = rf(ctx, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*final_project_spe_academyentity.PaginationMeta)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *entity.AuditListRequest) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Record provides a mock function with given fields: ctx, action, entityType, entityID, before, after
+func (_m *AuditUseCase) Record(ctx context.Context, action string, entityType string, entityID uint64, before interface{}, after interface{}) {
+	_m.Called(ctx, action, entityType, entityID, before, after)
+}
+
+// NewAuditUseCase creates a new instance of AuditUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuditUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuditUseCase {
+	mock := &AuditUseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
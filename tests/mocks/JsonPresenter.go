@@ -41,6 +41,20 @@ func (_m *JsonPresenter) BuildSuccess(c *fiber.Ctx, data interface{}, message st
 	return r0
 }
 
+// BuildSuccessWithMeta provides a mock function with given fields: c, data, meta, message, code
+func (_m *JsonPresenter) BuildSuccessWithMeta(c *fiber.Ctx, data interface{}, meta interface{}, message string, code int) error {
+	ret := _m.Called(c, data, meta, message, code)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*fiber.Ctx, interface{}, interface{}, string, int) error); ok {
+		r0 = rf(c, data, meta, message, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewJsonPresenter interface {
 	mock.TestingT
 	Cleanup(func())
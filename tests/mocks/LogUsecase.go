@@ -1,4 +1,4 @@
-// Code generated by mockery v2.28.2. DO NOT EDIT.
+// Code generated by mockery v2.53.3. DO NOT EDIT.
 
 package mocks
 
@@ -7,23 +7,32 @@ import (
 	mock "github.com/stretchr/testify/mock"
 )
 
-// LogUsecase is an autogenerated mock type for the LogUsecase type
+// LogUsecase is an autogenerated mock type for the ILogUseCase type
 type LogUsecase struct {
 	mock.Mock
 }
 
+// Error provides a mock function with given fields: process, funcName, err, logFields
+func (_m *LogUsecase) Error(process string, funcName string, err error, logFields map[string]string) {
+	_m.Called(process, funcName, err, logFields)
+}
+
+// Info provides a mock function with given fields: message, funcName, logFields, processName
+func (_m *LogUsecase) Info(message string, funcName string, logFields map[string]string, processName string) {
+	_m.Called(message, funcName, logFields, processName)
+}
+
 // Log provides a mock function with given fields: status, message, funcName, err, logFields, processName
 func (_m *LogUsecase) Log(status entity.LogType, message string, funcName string, err error, logFields map[string]string, processName string) {
 	_m.Called(status, message, funcName, err, logFields, processName)
 }
 
-type mockConstructorTestingTNewLogUsecase interface {
+// NewLogUsecase creates a new instance of LogUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogUsecase(t interface {
 	mock.TestingT
 	Cleanup(func())
-}
-
-// NewLogUsecase creates a new instance of LogUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-func NewLogUsecase(t mockConstructorTestingTNewLogUsecase) *LogUsecase {
+}) *LogUsecase {
 	mock := &LogUsecase{}
 	mock.Mock.Test(t)
 
@@ -0,0 +1,434 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	mysql "github.com/kharisma-wardhana/final-project-spe-academy/internal/repository/mysql"
+
+	time "time"
+)
+
+// MerchantRepository is an autogenerated mock type for the IMerchantRepository type
+type MerchantRepository struct {
+	mock.Mock
+}
+
+// Begin provides a mock function with no fields
+func (_m *MerchantRepository) Begin() (mysql.TrxObj, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Begin")
+	}
+
+	var r0 mysql.TrxObj
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (mysql.TrxObj, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() mysql.TrxObj); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(mysql.TrxObj)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, dbTrx, params, nonZeroVal
+func (_m *MerchantRepository) Create(ctx context.Context, dbTrx mysql.TrxObj, params *entity.MerchantEntity, nonZeroVal bool) error {
+	ret := _m.Called(ctx, dbTrx, params, nonZeroVal)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, *entity.MerchantEntity, bool) error); ok {
+		r0 = rf(ctx, dbTrx, params, nonZeroVal)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateBatch provides a mock function with given fields: ctx, dbTrx, merchants
+func (_m *MerchantRepository) CreateBatch(ctx context.Context, dbTrx mysql.TrxObj, merchants []*entity.MerchantEntity) error {
+	ret := _m.Called(ctx, dbTrx, merchants)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBatch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, []*entity.MerchantEntity) error); ok {
+		r0 = rf(ctx, dbTrx, merchants)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteByID provides a mock function with given fields: ctx, dbTrx, id, deletedAt
+func (_m *MerchantRepository) DeleteByID(ctx context.Context, dbTrx mysql.TrxObj, id uint64, deletedAt time.Time) error {
+	ret := _m.Called(ctx, dbTrx, id, deletedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64, time.Time) error); ok {
+		r0 = rf(ctx, dbTrx, id, deletedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindAll provides a mock function with given fields: ctx, filter
+func (_m *MerchantRepository) FindAll(ctx context.Context, filter *entity.MerchantFilter) ([]entity.MerchantEntity, int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAll")
+	}
+
+	var r0 []entity.MerchantEntity
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.MerchantFilter) ([]entity.MerchantEntity, int64, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.MerchantFilter) []entity.MerchantEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.MerchantEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.MerchantFilter) int64); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *entity.MerchantFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *MerchantRepository) FindByID(ctx context.Context, id uint64) (*entity.MerchantEntity, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 *entity.MerchantEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*entity.MerchantEntity, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *entity.MerchantEntity); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.MerchantEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByIDWithDeleted provides a mock function with given fields: ctx, id
+func (_m *MerchantRepository) FindByIDWithDeleted(ctx context.Context, id uint64) (*entity.MerchantEntity, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByIDWithDeleted")
+	}
+
+	var r0 *entity.MerchantEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*entity.MerchantEntity, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *entity.MerchantEntity); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.MerchantEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByMID provides a mock function with given fields: ctx, mid
+func (_m *MerchantRepository) FindByMID(ctx context.Context, mid string) (*entity.MerchantEntity, error) {
+	ret := _m.Called(ctx, mid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByMID")
+	}
+
+	var r0 *entity.MerchantEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.MerchantEntity, error)); ok {
+		return rf(ctx, mid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.MerchantEntity); ok {
+		r0 = rf(ctx, mid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.MerchantEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, mid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindChildren provides a mock function with given fields: ctx, parentID
+func (_m *MerchantRepository) FindChildren(ctx context.Context, parentID uint64) ([]entity.MerchantEntity, error) {
+	ret := _m.Called(ctx, parentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindChildren")
+	}
+
+	var r0 []entity.MerchantEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) ([]entity.MerchantEntity, error)); ok {
+		return rf(ctx, parentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []entity.MerchantEntity); ok {
+		r0 = rf(ctx, parentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.MerchantEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, parentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindDeletedBefore provides a mock function with given fields: ctx, deletedBefore, afterID, limit
+func (_m *MerchantRepository) FindDeletedBefore(ctx context.Context, deletedBefore time.Time, afterID uint64, limit int) ([]entity.MerchantEntity, error) {
+	ret := _m.Called(ctx, deletedBefore, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindDeletedBefore")
+	}
+
+	var r0 []entity.MerchantEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, uint64, int) ([]entity.MerchantEntity, error)); ok {
+		return rf(ctx, deletedBefore, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, uint64, int) []entity.MerchantEntity); ok {
+		r0 = rf(ctx, deletedBefore, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.MerchantEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, uint64, int) error); ok {
+		r1 = rf(ctx, deletedBefore, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HasFinancialHistory provides a mock function with given fields: ctx, id
+func (_m *MerchantRepository) HasFinancialHistory(ctx context.Context, id uint64) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HasFinancialHistory")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (bool, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockByID provides a mock function with given fields: ctx, dbTrx, id
+func (_m *MerchantRepository) LockByID(ctx context.Context, dbTrx mysql.TrxObj, id uint64) (*entity.MerchantEntity, error) {
+	ret := _m.Called(ctx, dbTrx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockByID")
+	}
+
+	var r0 *entity.MerchantEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64) (*entity.MerchantEntity, error)); ok {
+		return rf(ctx, dbTrx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64) *entity.MerchantEntity); ok {
+		r0 = rf(ctx, dbTrx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.MerchantEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, mysql.TrxObj, uint64) error); ok {
+		r1 = rf(ctx, dbTrx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, dbTrx, id
+func (_m *MerchantRepository) Purge(ctx context.Context, dbTrx mysql.TrxObj, id uint64) error {
+	ret := _m.Called(ctx, dbTrx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64) error); ok {
+		r0 = rf(ctx, dbTrx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: ctx, dbTrx, id
+func (_m *MerchantRepository) Restore(ctx context.Context, dbTrx mysql.TrxObj, id uint64) error {
+	ret := _m.Called(ctx, dbTrx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, uint64) error); ok {
+		r0 = rf(ctx, dbTrx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, dbTrx, params, changes
+func (_m *MerchantRepository) Update(ctx context.Context, dbTrx mysql.TrxObj, params *entity.MerchantEntity, changes *entity.MerchantEntity) error {
+	ret := _m.Called(ctx, dbTrx, params, changes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, *entity.MerchantEntity, *entity.MerchantEntity) error); ok {
+		r0 = rf(ctx, dbTrx, params, changes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateParent provides a mock function with given fields: ctx, dbTrx, params, parentID, settleAtParent
+func (_m *MerchantRepository) UpdateParent(ctx context.Context, dbTrx mysql.TrxObj, params *entity.MerchantEntity, parentID *uint64, settleAtParent bool) error {
+	ret := _m.Called(ctx, dbTrx, params, parentID, settleAtParent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateParent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.TrxObj, *entity.MerchantEntity, *uint64, bool) error); ok {
+		r0 = rf(ctx, dbTrx, params, parentID, settleAtParent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMerchantRepository creates a new instance of MerchantRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMerchantRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MerchantRepository {
+	mock := &MerchantRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}